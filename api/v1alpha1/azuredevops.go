@@ -0,0 +1,24 @@
+package v1alpha1
+
+type AzureDevOpsProject struct {
+	// API specifies the URL of the Azure DevOps organization collection to talk to, e.g.
+	// https://azuredevops.example.com/tfs for Azure DevOps Server. If blank, uses https://dev.azure.com.
+	// +optional
+	API *string `json:"api,omitempty"`
+
+	// Organization specifies the Azure DevOps organization
+	// +required
+	Organization string `json:"organization"`
+
+	// Project specifies the Azure DevOps project that contains the repository
+	// +required
+	Project string `json:"project"`
+
+	// Repo specifies the name of the repository.
+	// +required
+	Repo string `json:"repo"`
+
+	// TokenRef specifies a secret and key to load the personal access token (PAT) from
+	// +optional
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+}
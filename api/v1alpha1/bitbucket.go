@@ -0,0 +1,43 @@
+package v1alpha1
+
+type BitbucketServerProject struct {
+	// API specifies the URL of the Bitbucket Server/Data Center instance to talk to, e.g. https://bitbucket.example.com.
+	// +required
+	API string `json:"api"`
+
+	// Project specifies the key of the project that contains the repository
+	// +required
+	Project string `json:"project"`
+
+	// Repo specifies the slug of the repository.
+	// +required
+	Repo string `json:"repo"`
+
+	// TokenRef specifies a secret and key to load a personal or HTTP access token from. The token is sent as bearer
+	// token.
+	// +optional
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+
+	// BasicAuth specifies a username and password to authenticate with. Takes precedence over TokenRef.
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+}
+
+type BitbucketCloudProject struct {
+	// Workspace specifies the workspace that contains the repository
+	// +required
+	Workspace string `json:"workspace"`
+
+	// Repo specifies the slug of the repository.
+	// +required
+	Repo string `json:"repo"`
+
+	// BasicAuth specifies a username and app password to authenticate with. Takes precedence over TokenRef.
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+
+	// TokenRef specifies a secret and key to load a repository, project or workspace access token from. The token is
+	// sent as bearer token.
+	// +optional
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+}
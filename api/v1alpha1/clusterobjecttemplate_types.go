@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterObjectTemplateSpec defines the desired state of ClusterObjectTemplate
+type ClusterObjectTemplateSpec struct {
+	// ServiceAccountNamespace specifies the namespace of the service account to impersonate. Namespaced objects
+	// without a namespace and matrix objects without a namespace are also defaulted to this namespace.
+	// +required
+	ServiceAccountNamespace string `json:"serviceAccountNamespace"`
+
+	ObjectTemplateSpec `json:",inline"`
+}
+
+// GetConditions returns the status conditions of the object.
+func (in *ClusterObjectTemplate) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the status conditions on the object.
+func (in *ClusterObjectTemplate) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+
+// ClusterObjectTemplate is the Schema for the clusterobjecttemplates API
+type ClusterObjectTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterObjectTemplateSpec `json:"spec,omitempty"`
+	Status ObjectTemplateStatus      `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterObjectTemplateList contains a list of ClusterObjectTemplate
+type ClusterObjectTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterObjectTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterObjectTemplate{}, &ClusterObjectTemplateList{})
+}
@@ -1,5 +1,7 @@
 package v1alpha1
 
+const CommentFinalizer = "finalizers.templates.kluctl.io"
+
 type CommentSpec struct {
 	// Id specifies the identifier to be used by the controller when it needs to find the actual comment when it does
 	// not know the internal id. This Id is written into the comment inside a comment, so that a simple text search
@@ -11,6 +13,11 @@ type CommentSpec struct {
 	// Text, ConfigMap and TextTemplate
 	// +required
 	Source CommentSourceSpec `json:"source"`
+
+	// Prune enables deletion of the comment when this object gets deleted
+	// +kubebuilder:default:=false
+	// +optional
+	Prune bool `json:"prune,omitempty"`
 }
 
 type CommentSourceSpec struct {
@@ -0,0 +1,20 @@
+package v1alpha1
+
+type GiteaProject struct {
+	// API specifies the URL of the Gitea or Forgejo instance to talk to, e.g. https://gitea.example.com.
+	// If blank, uses https://gitea.com.
+	// +optional
+	API *string `json:"api,omitempty"`
+
+	// Owner specifies the Gitea user or organisation that owns the repository
+	// +required
+	Owner string `json:"owner"`
+
+	// Repo specifies the repository name.
+	// +required
+	Repo string `json:"repo"`
+
+	// TokenRef specifies a secret and key to load the Gitea API token from
+	// +optional
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+}
@@ -11,9 +11,34 @@ type GithubProject struct {
 	// +required
 	Repo string `json:"repo"`
 
+	// API specifies the GitHub API URL to talk to, e.g. https://github.example.com/api/v3/ for GitHub Enterprise
+	// Server. If blank, uses https://api.github.com/.
+	// +optional
+	API *string `json:"api,omitempty"`
+
 	// TokenRef specifies a secret and key to load the GitHub API token from
 	// +optional
 	TokenRef *SecretRef `json:"tokenRef"`
+
+	// App specifies a GitHub App installation to authenticate with. Takes precedence over TokenRef.
+	// +optional
+	App *GithubApp `json:"app,omitempty"`
+}
+
+// GithubApp specifies a GitHub App installation to authenticate with. Installation tokens are short-lived and
+// requested on demand, so that no long-lived personal access token needs to be stored.
+type GithubApp struct {
+	// AppId specifies the ID of the GitHub App
+	// +required
+	AppId int64 `json:"appId"`
+
+	// InstallationId specifies the ID of the GitHub App installation that has access to the repository
+	// +required
+	InstallationId int64 `json:"installationId"`
+
+	// PrivateKeyRef specifies a secret and key to load the PEM encoded private key of the GitHub App from
+	// +required
+	PrivateKeyRef SecretRef `json:"privateKeyRef"`
 }
 
 type GithubPullRequestRef struct {
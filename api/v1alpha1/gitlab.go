@@ -13,9 +13,89 @@ type GitlabProject struct {
 	// +optional
 	API *string `json:"api,omitempty"`
 
+	GitlabAuth `json:",inline"`
+}
+
+// GitlabAuth specifies how to authenticate against the GitLab API. If nothing is specified, requests are
+// unauthenticated.
+type GitlabAuth struct {
 	// TokenRef specifies a secret and key to load the Gitlab API token from
 	// +optional
-	TokenRef *SecretRef `json:"tokenRef"`
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+
+	// JobTokenRef specifies a secret and key to load a GitLab CI job token from. Job tokens are only valid while the
+	// CI job that created them is running, so that no long-lived token needs to be stored. Takes precedence over
+	// TokenRef.
+	// +optional
+	JobTokenRef *SecretRef `json:"jobTokenRef,omitempty"`
+
+	// OIDC specifies to exchange a service account token for a short-lived GitLab OAuth token. Takes precedence over
+	// TokenRef and JobTokenRef.
+	// +optional
+	OIDC *GitlabOIDC `json:"oidc,omitempty"`
+}
+
+// GitlabOIDC specifies a security token service that exchanges an OIDC ID token of a Kubernetes service account for a
+// short-lived GitLab OAuth token, using OAuth 2.0 Token Exchange (RFC 8693). The ID token is requested via the
+// TokenRequest API and is only valid for a few minutes.
+type GitlabOIDC struct {
+	// TokenURL specifies the token exchange endpoint of the security token service. It is also used as the audience
+	// of the requested service account token.
+	// +required
+	TokenURL string `json:"tokenURL"`
+
+	// Scope specifies the space separated scopes to request for the GitLab token, e.g. read_api
+	// +optional
+	Scope string `json:"scope,omitempty"`
+
+	// ServiceAccountName specifies the service account in the namespace of the referencing object whose token is
+	// exchanged. The token is requested by impersonating the service account, so it must be allowed to create tokens
+	// for itself. Defaults to "default".
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// GitlabProjectOrGroup is like GitlabProject, but allows to specify a group instead of a single project
+type GitlabProjectOrGroup struct {
+	// Project specifies the Gitlab group and project (separated by slash) to
+	// use, or the numeric project id. Mutually exclusive with Group.
+	// +optional
+	Project *intstr.IntOrString `json:"project,omitempty"`
+
+	// Group specifies a Gitlab group to scan for projects. Mutually exclusive with Project.
+	// +optional
+	Group *GitlabGroup `json:"group,omitempty"`
+
+	// API specifies the GitLab API URL to talk to.
+	// If blank, uses https://gitlab.com/.
+	// +optional
+	API *string `json:"api,omitempty"`
+
+	GitlabAuth `json:",inline"`
+}
+
+type GitlabGroup struct {
+	// Path specifies the full path (e.g. my-group/my-subgroup) or the numeric id of the group
+	// +required
+	Path string `json:"path"`
+
+	// IncludeSubgroups specifies whether projects of subgroups are included as well
+	// +optional
+	IncludeSubgroups bool `json:"includeSubgroups,omitempty"`
+
+	// IncludeArchived specifies whether archived projects are included as well
+	// +optional
+	IncludeArchived bool `json:"includeArchived,omitempty"`
+
+	// IncludeProjects specifies a regular expression that the project path (including the group, e.g.
+	// my-group/my-project) must match. If omitted, all projects are included.
+	// +optional
+	IncludeProjects *string `json:"includeProjects,omitempty"`
+
+	// ExcludeProjects specifies a regular expression for project paths (including the group) to exclude. Takes
+	// precedence over IncludeProjects.
+	// +optional
+	ExcludeProjects *string `json:"excludeProjects,omitempty"`
 }
 
 type GitlabMergeRequestRef struct {
@@ -44,14 +44,51 @@ type GitProjectorSpec struct {
 	// +optional
 	Reference *GitRef `json:"ref,omitempty"`
 
+	// SortBy specifies how matching refs are ordered. "semver" parses the branch or tag names as semantic versions (an
+	// optional "v" prefix is allowed) and orders them from the highest to the lowest version. Refs that are not valid
+	// semantic versions are ignored in that case. "commitTime" orders the refs from the newest to the oldest commit.
+	// Defaults to "name"
+	// +optional
+	// +kubebuilder:validation:Enum=name;semver;commitTime
+	// +kubebuilder:default:="name"
+	SortBy string `json:"sortBy,omitempty"`
+
+	// LatestN limits the projection to the first N matching refs after sorting, e.g. to the N highest versions if
+	// SortBy is "semver"
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	LatestN *int `json:"latestN,omitempty"`
+
 	// Files specifies the list of files to include in the projection
 	// +optional
 	Files []GitFile `json:"files,omitempty"`
 
+	// Directories specifies the list of directories to include in the projection
+	// +optional
+	Directories []GitDirectory `json:"directories,omitempty"`
+
 	// SecretRefs specifies a Secret use for Git authentication. The contents of the secret must conform to:
 	// https://kluctl.io/docs/flux/spec/v1alpha1/kluctldeployment/#git-authentication
 	// +optional
 	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Verify enables verification of commit signatures. All matching refs must point to commits that are signed by
+	// one of the trusted keys, otherwise nothing is projected
+	// +optional
+	Verify *GitVerify `json:"verify,omitempty"`
+}
+
+type GitVerify struct {
+	// PublicKeysRef specifies a Secret containing ASCII armored PGP public keys. All keys found in the Secret are
+	// trusted. If the controller is configured with a trusted keys namespace, the Secret is read from that namespace
+	// +required
+	PublicKeysRef LocalObjectReference `json:"publicKeysRef"`
+
+	// ServiceAccountName specifies the name of the Kubernetes service account to impersonate when reading the
+	// PublicKeysRef Secret. If omitted, the "default" service account is used. Ignored if the controller is configured
+	// with a trusted keys namespace
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 }
 
 type GitFile struct {
@@ -64,6 +101,24 @@ type GitFile struct {
 	// +optional
 	// +kubebuilder:default:=false
 	ParseYaml bool `json:"parseYaml,omitempty"`
+
+	// Exclude causes all files matching the glob to be excluded from the projection, even if they are matched by
+	// another glob
+	// +optional
+	// +kubebuilder:default:=false
+	Exclude bool `json:"exclude,omitempty"`
+}
+
+type GitDirectory struct {
+	// Glob specifies a glob to use for directory matching.
+	// +required
+	Glob string `json:"glob"`
+
+	// Exclude causes all directories matching the glob to be excluded from the projection, even if they are matched
+	// by another glob
+	// +optional
+	// +kubebuilder:default:=false
+	Exclude bool `json:"exclude,omitempty"`
 }
 
 // GitProjectorStatus defines the observed state of GitProjector
@@ -82,8 +137,41 @@ type GitProjectorResult struct {
 	// +required
 	Reference GitRef `json:"ref"`
 
+	// Commit is the SHA of the commit the ref points to. Annotated tags are resolved to the tagged commit.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// CommitInfo contains details about the commit the ref points to.
+	// +optional
+	CommitInfo *GitCommitInfo `json:"commitInfo,omitempty"`
+
 	// +required
 	Files []GitProjectorResultFile `json:"files"`
+
+	// +optional
+	Directories []GitProjectorResultDirectory `json:"directories,omitempty"`
+}
+
+type GitCommitInfo struct {
+	// ShortCommit is the abbreviated (7 characters) SHA of the commit
+	// +required
+	ShortCommit string `json:"shortCommit"`
+
+	// AuthorName is the name of the commit author
+	// +required
+	AuthorName string `json:"authorName"`
+
+	// AuthorEmail is the email address of the commit author
+	// +required
+	AuthorEmail string `json:"authorEmail"`
+
+	// Message is the full commit message
+	// +required
+	Message string `json:"message"`
+
+	// Timestamp is the committer timestamp of the commit
+	// +required
+	Timestamp metav1.Time `json:"timestamp"`
 }
 
 type GitProjectorResultFile struct {
@@ -98,6 +186,11 @@ type GitProjectorResultFile struct {
 	Parsed []*runtime.RawExtension `json:"parsed,omitempty"`
 }
 
+type GitProjectorResultDirectory struct {
+	// +required
+	Path string `json:"path"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
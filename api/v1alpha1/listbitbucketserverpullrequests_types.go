@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ListBitbucketServerPullRequestsSpec defines the desired state of ListBitbucketServerPullRequests
+type ListBitbucketServerPullRequestsSpec struct {
+	// Interval is the interval at which to query the Bitbucket Server API.
+	// Defaults to 5m.
+	// +optional
+	// +kubebuilder:default:="5m"
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	Interval metav1.Duration `json:"interval"`
+
+	BitbucketServerProject `json:",inline"`
+
+	// SourceBranch specifies the source branch to filter for. Can contain regular expressions.
+	// +optional
+	SourceBranch *string `json:"sourceBranch,omitempty"`
+
+	// TargetBranch specifies the target branch to filter for. Can contain regular expressions.
+	// +optional
+	TargetBranch *string `json:"targetBranch,omitempty"`
+
+	// State is an additional PR filter to get only those with a certain state. Default: "ALL"
+	// +optional
+	// +kubebuilder:validation:Enum=ALL;OPEN;DECLINED;MERGED
+	// +kubebuilder:default:="ALL"
+	State string `json:"state,omitempty"`
+
+	PullRequestFilters `json:",inline"`
+
+	// Limit limits the maximum number of pull requests to fetch. Defaults to 100
+	// +kubebuilder:default:=100
+	Limit int `json:"limit"`
+
+	// HashFields specifies a list of fields (dot separated for nested fields, e.g. "author.user.emailAddress") to
+	// replace with their SHA256 hash before storing the pull requests in the status. Use this for sensitive fields that
+	// must not end up in plaintext in etcd. The hash is calculated from the JSON encoded value and prefixed with "sha256:"
+	// +optional
+	HashFields []string `json:"hashFields,omitempty"`
+}
+
+// ListBitbucketServerPullRequestsStatus defines the observed state of ListBitbucketServerPullRequests
+type ListBitbucketServerPullRequestsStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PullRequests []runtime.RawExtension `json:"pullRequests,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ListBitbucketServerPullRequests is the Schema for the listbitbucketserverpullrequests API
+type ListBitbucketServerPullRequests struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ListBitbucketServerPullRequestsSpec   `json:"spec,omitempty"`
+	Status ListBitbucketServerPullRequestsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ListBitbucketServerPullRequestsList contains a list of ListBitbucketServerPullRequests
+type ListBitbucketServerPullRequestsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ListBitbucketServerPullRequests `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ListBitbucketServerPullRequests{}, &ListBitbucketServerPullRequestsList{})
+}
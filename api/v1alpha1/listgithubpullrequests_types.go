@@ -23,7 +23,7 @@ import (
 
 // ListGithubPullRequestsSpec defines the desired state of ListGithubPullRequests
 type ListGithubPullRequestsSpec struct {
-	// Interval is the interval at which to query the Gitlab API.
+	// Interval is the interval at which to query the GitHub API.
 	// Defaults to 5m.
 	// +optional
 	// +kubebuilder:default:="5m"
@@ -51,9 +51,17 @@ type ListGithubPullRequestsSpec struct {
 	// +kubebuilder:default:="all"
 	State string `json:"state,omitempty"`
 
+	PullRequestFilters `json:",inline"`
+
 	// Limit limits the maximum number of pull requests to fetch. Defaults to 100
 	// +kubebuilder:default:=100
 	Limit int `json:"limit"`
+
+	// HashFields specifies a list of fields (dot separated for nested fields, e.g. "author.email") to replace with
+	// their SHA256 hash before storing the pull requests in the status. Use this for sensitive fields that must not end
+	// up in plaintext in etcd. The hash is calculated from the JSON encoded value and prefixed with "sha256:"
+	// +optional
+	HashFields []string `json:"hashFields,omitempty"`
 }
 
 // ListGithubPullRequestsStatus defines the observed state of ListGithubPullRequests
@@ -31,7 +31,7 @@ type ListGitlabMergeRequestsSpec struct {
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
 	Interval metav1.Duration `json:"interval"`
 
-	GitlabProject `json:",inline"`
+	GitlabProjectOrGroup `json:",inline"`
 
 	// TargetBranch specifies the target branch to filter for
 	// +optional
@@ -50,9 +50,17 @@ type ListGitlabMergeRequestsSpec struct {
 	// +kubebuilder:default:="all"
 	State *string `json:"state,omitempty"`
 
+	PullRequestFilters `json:",inline"`
+
 	// Limit limits the maximum number of merge requests to fetch. Defaults to 100
 	// +kubebuilder:default:=100
 	Limit int `json:"limit"`
+
+	// HashFields specifies a list of fields (dot separated for nested fields, e.g. "author.email") to replace with
+	// their SHA256 hash before storing the merge requests in the status. Use this for sensitive fields that must not end
+	// up in plaintext in etcd. The hash is calculated from the JSON encoded value and prefixed with "sha256:"
+	// +optional
+	HashFields []string `json:"hashFields,omitempty"`
 }
 
 // ListGitlabMergeRequestsStatus defines the observed state of ListGitlabMergeRequests
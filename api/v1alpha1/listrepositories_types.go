@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListRepositoriesSpec defines the desired state of ListRepositories
+type ListRepositoriesSpec struct {
+	// Interval is the interval at which to query the SCM API.
+	// Defaults to 5m.
+	// +optional
+	// +kubebuilder:default:="5m"
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	Interval metav1.Duration `json:"interval"`
+
+	// Github specifies a GitHub organisation or user to list repositories of. Mutually exclusive with Gitlab.
+	// +optional
+	Github *ListRepositoriesGithub `json:"github,omitempty"`
+
+	// Gitlab specifies a Gitlab group to list projects of. Mutually exclusive with Github.
+	// +optional
+	Gitlab *ListRepositoriesGitlab `json:"gitlab,omitempty"`
+
+	// Path specifies a regular expression that the full path of the repository (e.g. my-org/my-repo) must match.
+	// +optional
+	Path *string `json:"path,omitempty"`
+
+	// ExcludePath specifies a regular expression for full repository paths to exclude. Takes precedence over Path.
+	// +optional
+	ExcludePath *string `json:"excludePath,omitempty"`
+
+	// Topics is used to filter the repositories that you want to target. All topics must be present on a repository.
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+
+	// IncludeArchived specifies whether archived repositories are included as well.
+	// +optional
+	IncludeArchived bool `json:"includeArchived,omitempty"`
+
+	// Limit limits the maximum number of repositories to fetch. Defaults to 100
+	// +kubebuilder:default:=100
+	Limit int `json:"limit"`
+}
+
+type ListRepositoriesGithub struct {
+	// Owner specifies the GitHub user or organisation to list repositories of
+	// +required
+	Owner string `json:"owner"`
+
+	// API specifies the GitHub API URL to talk to, e.g. https://github.example.com/api/v3/ for GitHub Enterprise
+	// Server. If blank, uses https://api.github.com/.
+	// +optional
+	API *string `json:"api,omitempty"`
+
+	// TokenRef specifies a secret and key to load the GitHub API token from
+	// +optional
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+
+	// App specifies a GitHub App installation to authenticate with. Takes precedence over TokenRef.
+	// +optional
+	App *GithubApp `json:"app,omitempty"`
+}
+
+type ListRepositoriesGitlab struct {
+	// Group specifies the full path (e.g. my-group/my-subgroup) or the numeric id of the group to list projects of
+	// +required
+	Group string `json:"group"`
+
+	// IncludeSubgroups specifies whether projects of subgroups are included as well
+	// +optional
+	IncludeSubgroups bool `json:"includeSubgroups,omitempty"`
+
+	// API specifies the GitLab API URL to talk to.
+	// If blank, uses https://gitlab.com/.
+	// +optional
+	API *string `json:"api,omitempty"`
+
+	GitlabAuth `json:",inline"`
+}
+
+// ListRepositoriesStatus defines the observed state of ListRepositories
+type ListRepositoriesStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// +optional
+	Repositories []RepositoryInfo `json:"repositories,omitempty"`
+}
+
+// RepositoryInfo describes a single repository found by ListRepositories. The fields are the same for all SCM
+// providers.
+type RepositoryInfo struct {
+	// ID is the provider specific numeric id of the repository
+	ID int64 `json:"id"`
+
+	// Owner is the user, organisation or group (including parent groups) that owns the repository
+	Owner string `json:"owner"`
+
+	// Name is the name of the repository
+	Name string `json:"name"`
+
+	// FullName is the full path of the repository, e.g. my-org/my-repo
+	FullName string `json:"fullName"`
+
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// +optional
+	DefaultBranch string `json:"defaultBranch,omitempty"`
+
+	// +optional
+	Topics []string `json:"topics,omitempty"`
+
+	// +optional
+	Archived bool `json:"archived,omitempty"`
+
+	// +optional
+	Private bool `json:"private,omitempty"`
+
+	// +optional
+	CloneUrl string `json:"cloneUrl,omitempty"`
+
+	// +optional
+	SshUrl string `json:"sshUrl,omitempty"`
+
+	// +optional
+	WebUrl string `json:"webUrl,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ListRepositories is the Schema for the listrepositories API
+type ListRepositories struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ListRepositoriesSpec   `json:"spec,omitempty"`
+	Status ListRepositoriesStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ListRepositoriesList contains a list of ListRepositories
+type ListRepositoriesList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ListRepositories `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ListRepositories{}, &ListRepositoriesList{})
+}
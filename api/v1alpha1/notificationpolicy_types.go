@@ -0,0 +1,228 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// NotificationPolicySpec defines the desired state of NotificationPolicy
+type NotificationPolicySpec struct {
+	// +kubebuilder:default:="1m"
+	Interval metav1.Duration `json:"interval"`
+
+	// Suspend can be used to suspend the reconciliation of this object
+	// +optional
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend"`
+
+	// Sources specifies the objects to observe
+	// +required
+	Sources []NotificationSource `json:"sources"`
+
+	// ConditionType specifies the condition type to observe
+	// +kubebuilder:default:="Ready"
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+
+	// Rules specifies which condition transitions result in notifications. The first matching rule determines the
+	// severity. Transitions that do not match any rule are not notified.
+	// +required
+	Rules []NotificationRule `json:"rules"`
+
+	// Destinations specifies where to send notifications to
+	// +required
+	Destinations []NotificationDestination `json:"destinations"`
+
+	// Throttle specifies the minimum duration between two notifications for the same object. Transitions happening
+	// in between are recorded but not notified.
+	// +kubebuilder:default:="5m"
+	// +optional
+	Throttle metav1.Duration `json:"throttle,omitempty"`
+}
+
+type NotificationSource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Namespace specifies the namespace to observe objects in. Defaults to the namespace of the NotificationPolicy
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name specifies the name of the object to observe. If omitted, all objects matching the selector are observed
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Selector specifies a label selector to filter objects
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type NotificationRule struct {
+	// Status specifies the condition status to match. If omitted, all statuses match
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	// +optional
+	Status *metav1.ConditionStatus `json:"status,omitempty"`
+
+	// Reason specifies a regular expression to match the condition reason against. If omitted, all reasons match
+	// +optional
+	Reason *string `json:"reason,omitempty"`
+
+	// Severity specifies the severity of matching transitions
+	// +kubebuilder:validation:Enum=info;warning;error
+	// +kubebuilder:default:="info"
+	// +optional
+	Severity string `json:"severity,omitempty"`
+}
+
+type NotificationDestination struct {
+	// Name specifies the name of the destination, used in status and error messages
+	// +required
+	Name string `json:"name"`
+
+	// MinSeverity specifies the minimum severity of notifications to send to this destination
+	// +kubebuilder:validation:Enum=info;warning;error
+	// +kubebuilder:default:="info"
+	// +optional
+	MinSeverity string `json:"minSeverity,omitempty"`
+
+	// +optional
+	Slack *SlackDestination `json:"slack,omitempty"`
+
+	// +optional
+	Webhook *WebhookDestination `json:"webhook,omitempty"`
+
+	// +optional
+	Email *EmailDestination `json:"email,omitempty"`
+}
+
+type SlackDestination struct {
+	// WebhookUrlRef specifies the secret containing the Slack incoming webhook URL
+	// +required
+	WebhookUrlRef SecretRef `json:"webhookUrlRef"`
+
+	// Channel optionally overrides the channel configured for the incoming webhook
+	// +optional
+	Channel string `json:"channel,omitempty"`
+}
+
+type WebhookDestination struct {
+	// Url specifies the URL to POST notifications to. Either Url or UrlRef must be specified
+	// +optional
+	Url string `json:"url,omitempty"`
+
+	// UrlRef specifies a secret containing the URL to POST notifications to
+	// +optional
+	UrlRef *SecretRef `json:"urlRef,omitempty"`
+
+	// Headers specifies additional headers to send
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type EmailDestination struct {
+	// Host specifies the SMTP server host
+	// +required
+	Host string `json:"host"`
+
+	// Port specifies the SMTP server port
+	// +kubebuilder:default:=587
+	// +optional
+	Port int `json:"port,omitempty"`
+
+	// From specifies the sender address
+	// +required
+	From string `json:"from"`
+
+	// To specifies the recipient addresses
+	// +required
+	To []string `json:"to"`
+
+	// UsernameRef specifies the secret containing the SMTP username
+	// +optional
+	UsernameRef *SecretRef `json:"usernameRef,omitempty"`
+
+	// PasswordRef specifies the secret containing the SMTP password
+	// +optional
+	PasswordRef *SecretRef `json:"passwordRef,omitempty"`
+}
+
+// NotificationPolicyStatus defines the observed state of NotificationPolicy
+type NotificationPolicyStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// +optional
+	ObservedObjects []NotificationObjectStatus `json:"observedObjects,omitempty"`
+}
+
+type NotificationObjectStatus struct {
+	Ref ObjectRef `json:"ref"`
+
+	// Status is the last observed condition status
+	// +optional
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+
+	// Reason is the last observed condition reason
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// LastNotificationTime is the time of the last successfully sent notification for this object
+	// +optional
+	LastNotificationTime *metav1.Time `json:"lastNotificationTime,omitempty"`
+}
+
+// GetConditions returns the status conditions of the object.
+func (in *NotificationPolicy) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the status conditions on the object.
+func (in *NotificationPolicy) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// NotificationPolicy is the Schema for the notificationpolicies API
+type NotificationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotificationPolicySpec   `json:"spec,omitempty"`
+	Status NotificationPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NotificationPolicyList contains a list of NotificationPolicy
+type NotificationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationPolicy{}, &NotificationPolicyList{})
+}
@@ -153,6 +153,39 @@ type ObjectHandlerStatus struct {
 
 	// +optional
 	HandlerStatus []*HandlerStatus `json:"handlerStatus"`
+
+	// Health is the combined health of the object referenced by forObject. If the object is an ObjectTemplate or
+	// ClusterObjectTemplate, the health of all applied resources is included.
+	// +optional
+	Health *ObjectHealth `json:"health,omitempty"`
+}
+
+const (
+	HealthHealthy     = "Healthy"
+	HealthProgressing = "Progressing"
+	HealthDegraded    = "Degraded"
+	HealthUnknown     = "Unknown"
+)
+
+type ObjectHealth struct {
+	// +kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Unknown
+	Status string `json:"status"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	Resources []ResourceHealth `json:"resources,omitempty"`
+}
+
+type ResourceHealth struct {
+	Ref ObjectRef `json:"ref"`
+
+	// +kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Unknown
+	Status string `json:"status"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -24,6 +24,37 @@ import (
 
 const (
 	ObjectTemplateFinalizer = "finalizers.templates.kluctl.io"
+
+	// TemplateAnnotation is set on all applied objects and contains the kind, namespace and name of the owning template
+	// in the form <kind>/<namespace>/<name>
+	TemplateAnnotation = "templates.kluctl.io/template"
+	// MatrixKeyAnnotation is set on all applied objects and contains a hash of the matrix entry that was used while
+	// rendering the object
+	MatrixKeyAnnotation = "templates.kluctl.io/matrix-key"
+	// RenderedHashAnnotation is set on all applied objects and contains a hash of the rendered object
+	RenderedHashAnnotation = "templates.kluctl.io/rendered-hash"
+
+	// OwnerLabel is set on all applied objects and contains a shortened hash of the TemplateAnnotation. It allows to
+	// find all objects of a template via label selectors, even if the list of applied resources got lost
+	OwnerLabel = "templates.kluctl.io/owner"
+	// MatrixKeyLabel is set on all applied objects and contains a shortened MatrixKeyAnnotation
+	MatrixKeyLabel = "templates.kluctl.io/matrix-key"
+
+	// WaveAnnotation can be set on rendered objects to apply them in waves. Objects with lower waves are applied first
+	// and must become ready before the next wave is applied. Defaults to 0
+	WaveAnnotation = "templates.kluctl.io/wave"
+	// DependsOnAnnotation can be set on rendered objects to apply them only after the referenced rendered objects have
+	// been applied and became ready. Contains a comma separated list of references in the form
+	// <kind>[.<group>]/[<namespace>/]<name>
+	DependsOnAnnotation = "templates.kluctl.io/depends-on"
+	// PruneAnnotation can be set to PruneDisabled on rendered objects to prevent them from being deleted when they
+	// disappear from the rendered objects or when the template is deleted
+	PruneAnnotation = "templates.kluctl.io/prune"
+	PruneDisabled   = "disabled"
+
+	// AllowClusterScopedObjectsAnnotation can be set on namespaces by cluster admins to allow templates inside the
+	// namespace to apply cluster-scoped objects
+	AllowClusterScopedObjectsAnnotation = "templates.kluctl.io/allow-cluster-scoped-objects"
 )
 
 // ObjectTemplateSpec defines the desired state of ObjectTemplate
@@ -43,11 +74,109 @@ type ObjectTemplateSpec struct {
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 
-	// Prune enables pruning of previously created objects when these disappear from the list of rendered objects
+	// FieldManager optionally overrides the field manager name used when server-side applying rendered objects. If
+	// omitted, the field manager of the controller is used, which defaults to "template-controller"
+	// +kubebuilder:validation:MaxLength=128
+	// +optional
+	FieldManager string `json:"fieldManager,omitempty"`
+
+	// Force causes the controller to take ownership of fields that are owned by other field managers when applying
+	// rendered objects. If omitted, conflicting objects are not applied and the conflicts are recorded in the status
+	// +optional
+	Force bool `json:"force,omitempty"`
+
+	// AdoptExisting allows the controller to take over objects that already exist but were not created by this
+	// template. Objects that are being deleted, managed by other templates or controlled by other objects are never
+	// adopted. If omitted, applying fails for such objects
+	// +optional
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// Prune enables pruning of previously created objects when these disappear from the list of rendered objects, e.g.
+	// because the pull request of a matrix input got merged
 	// +kubebuilder:default:=false
 	// +optional
 	Prune bool `json:"prune"`
 
+	// DeletionPolicy specifies what happens to the applied objects when the template gets deleted. `Delete` deletes all
+	// applied objects, `Orphan` leaves them behind. Defaults to `Orphan`
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +kubebuilder:default:=Orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// DryRun causes rendered objects to be applied with server-side dry-run instead of being applied for real. The
+	// changes that would be performed are recorded in status.dryRun. Nothing is pruned while dry-run is enabled
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// DriftDetection specifies how changes to applied objects that were made outside of the template are handled.
+	// `Warn` reports drifted objects without touching them, `Correct` reports them and re-applies the rendered state.
+	// Unchanged objects are only checked for drift every DriftDetectionInterval. Defaults to `Off`, which re-applies
+	// all objects on every reconciliation without detecting drift
+	// +kubebuilder:validation:Enum=Off;Warn;Correct
+	// +optional
+	DriftDetection string `json:"driftDetection,omitempty"`
+
+	// DriftDetectionInterval specifies the interval at which unchanged objects are checked for drift. Defaults to 10m
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	DriftDetectionInterval *metav1.Duration `json:"driftDetectionInterval,omitempty"`
+
+	// Schedule optionally specifies when the template is active. Outside of the active windows or after the TTL has
+	// expired, the generated objects are either left untouched or pruned, depending on the schedule action
+	// +optional
+	Schedule *ObjectTemplateSchedule `json:"schedule,omitempty"`
+
+	// Rollout optionally specifies a strategy to roll out changes gradually across matrix entries, instead of applying
+	// the changes of all matrix entries at once
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+
+	// GitOutput optionally specifies a Git repository to which the rendered objects are committed and pushed. When
+	// specified, rendered objects are not applied to the cluster
+	// +optional
+	GitOutput *GitOutput `json:"gitOutput,omitempty"`
+
+	// OCIOutput optionally specifies an OCI repository to which the rendered objects are pushed as OCI artifacts, one
+	// artifact per matrix entry. When specified, rendered objects are not applied to the cluster. Can not be combined
+	// with GitOutput
+	// +optional
+	OCIOutput *OCIOutput `json:"ociOutput,omitempty"`
+
+	// TemplateEngine specifies the engine used to render the templates. "jinja2" uses the Jinja2 engine of Kluctl,
+	// "gotemplate" uses Go templates with the matrix and the other variables as data. Defaults to "jinja2"
+	// +kubebuilder:validation:Enum=jinja2;gotemplate
+	// +kubebuilder:default:="jinja2"
+	// +optional
+	TemplateEngine string `json:"templateEngine,omitempty"`
+
+	// Strict causes rendering to fail when a template references an undefined variable, instead of rendering an empty
+	// value. Go templates are always strict
+	// +optional
+	Strict bool `json:"strict,omitempty"`
+
+	// Delimiters optionally overrides the delimiters used by templates, e.g. to render objects that contain Helm or
+	// Prometheus templates without escaping them
+	// +optional
+	Delimiters *TemplateDelimiters `json:"delimiters,omitempty"`
+
+	// Includes optionally specifies ConfigMaps with shared template snippets and macros. Each key of the ConfigMaps
+	// can be included or imported by its name from the templates. The service account used by the ObjectTemplate must
+	// have proper permissions to get the ConfigMaps
+	// +optional
+	Includes []TemplateInclude `json:"includes,omitempty"`
+
+	// Vars optionally specifies ConfigMaps and Secrets whose data is made available to the templates via the `vars`
+	// variable. Keys of later entries override keys of earlier entries. The service account used by the ObjectTemplate
+	// must have proper permissions to get the ConfigMaps and Secrets
+	// +optional
+	Vars []TemplateVarsSource `json:"vars,omitempty"`
+
+	// Decryption optionally enables decryption of SOPS encrypted documents found in matrix inputs and vars
+	// +optional
+	Decryption *Decryption `json:"decryption,omitempty"`
+
 	// Matrix specifies the input matrix
 	// +required
 	Matrix []*MatrixEntry `json:"matrix"`
@@ -57,6 +186,143 @@ type ObjectTemplateSpec struct {
 	Templates []Template `json:"templates"`
 }
 
+const (
+	DeletionPolicyDelete = "Delete"
+	DeletionPolicyOrphan = "Orphan"
+)
+
+const (
+	DriftDetectionOff     = "Off"
+	DriftDetectionWarn    = "Warn"
+	DriftDetectionCorrect = "Correct"
+)
+
+const (
+	ScheduleActionSuspend = "Suspend"
+	ScheduleActionPrune   = "Prune"
+	ScheduleActionResume  = "Resume"
+)
+
+type ObjectTemplateSchedule struct {
+	// TTL specifies the duration after the creation of the template after which the template becomes inactive
+	// permanently
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// ActiveWindows specifies the time windows in which the template is active. If omitted, the template is always
+	// active until the TTL expires
+	// +optional
+	ActiveWindows []ScheduleWindow `json:"activeWindows,omitempty"`
+
+	// Action specifies what happens to the generated objects while the template is inactive. `Suspend` stops
+	// reconciliation and leaves the objects untouched, `Prune` deletes all generated objects
+	// +kubebuilder:validation:Enum=Suspend;Prune
+	// +kubebuilder:default:="Suspend"
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+type RolloutStrategy struct {
+	// MaxConcurrent limits the number of matrix entries whose objects are applied concurrently. If omitted, the
+	// objects of all matrix entries are applied concurrently
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrent int `json:"maxConcurrent,omitempty"`
+
+	// BatchSize limits the number of changed matrix entries that are applied per batch. A matrix entry is changed if
+	// one of its rendered objects differs from the last applied version or does not exist yet. The next batch is
+	// applied after BatchInterval. If omitted, all changed matrix entries are applied at once
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BatchSize int `json:"batchSize,omitempty"`
+
+	// BatchInterval specifies the time to wait between batches. Defaults to 30s
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	BatchInterval *metav1.Duration `json:"batchInterval,omitempty"`
+
+	// PauseOnFailure pauses the rollout when applying a matrix entry of a batch fails or one of its objects becomes
+	// degraded. While paused, only the failed matrix entries are retried. The rollout continues when these succeed or
+	// when the ObjectTemplate is changed
+	// +optional
+	PauseOnFailure bool `json:"pauseOnFailure,omitempty"`
+}
+
+type ScheduleWindow struct {
+	// Days specifies the days of the week on which the window starts. If omitted, the window starts every day
+	// +optional
+	Days []ScheduleWeekday `json:"days,omitempty"`
+
+	// Start specifies the start time of the window in the form HH:MM
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	// +required
+	Start string `json:"start"`
+
+	// End specifies the end time of the window in the form HH:MM. If End is before Start, the window ends on the
+	// next day
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	// +required
+	End string `json:"end"`
+
+	// TimeZone specifies the IANA time zone of Start and End, e.g. "Europe/Berlin". Defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type ScheduleWeekday string
+
+type OCIOutput struct {
+	// URL specifies the OCI repository to push artifacts to, e.g. oci://ghcr.io/example/manifests
+	// +kubebuilder:validation:Pattern="^oci://.*$"
+	// +required
+	URL string `json:"url"`
+
+	// Tag specifies a template for the tag of the artifact of a single matrix entry. It is rendered with the same
+	// variables as the templates. Defaults to the matrix key.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// SecretRef specifies a Secret of type kubernetes.io/dockerconfigjson used for registry authentication. The service
+	// account used by the ObjectTemplate must have proper permissions to get this secret
+	// +optional
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+type GitOutput struct {
+	// URL specifies the Git url to push rendered objects to
+	// +required
+	URL string `json:"url"`
+
+	// Branch specifies the branch to commit rendered objects to. The branch is created if it does not exist yet
+	// +required
+	Branch string `json:"branch"`
+
+	// Path specifies the directory inside the repository that is managed by this template. The whole directory is
+	// replaced on every write, so it must not be shared with other templates or manually maintained files
+	// +required
+	Path string `json:"path"`
+
+	// ElementPath specifies a template for the sub-directory of Path to which the objects of a single matrix entry are
+	// written. It is rendered with the same variables as the templates. Defaults to the matrix key.
+	// +optional
+	ElementPath string `json:"elementPath,omitempty"`
+
+	// CommitMessage specifies the message used for commits
+	// +kubebuilder:default:="Update rendered objects"
+	// +optional
+	CommitMessage string `json:"commitMessage,omitempty"`
+
+	// SecretRef specifies a Secret use for Git authentication. The contents of the secret must conform to:
+	// https://kluctl.io/docs/flux/spec/v1alpha1/kluctldeployment/#git-authentication
+	// The service account used by the ObjectTemplate must have proper permissions to get this secret
+	// +optional
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+}
+
 type MatrixEntry struct {
 	// Name specifies the name this matrix input is available while rendering templates
 	// +required
@@ -73,6 +339,95 @@ type MatrixEntry struct {
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	List []runtime.RawExtension `json:"list,omitempty"`
+
+	// Clusters specifies to discover registered clusters from their secrets. One matrix input is made available per
+	// cluster. The service account used by the ObjectTemplate must have proper permissions to list secrets in the
+	// namespace of the cluster secrets
+	// +optional
+	Clusters *MatrixEntryClusters `json:"clusters,omitempty"`
+
+	// ConfigMap specifies a ConfigMap to read matrix inputs from. The service account used by the ObjectTemplate must
+	// have proper permissions to get this ConfigMap
+	// +optional
+	ConfigMap *MatrixEntryData `json:"configMap,omitempty"`
+
+	// Secret specifies a Secret to read matrix inputs from. The service account used by the ObjectTemplate must have
+	// proper permissions to get this Secret
+	// +optional
+	Secret *MatrixEntryData `json:"secret,omitempty"`
+
+	// Objects specifies to list objects of a given kind. One matrix input is made available per object. The service
+	// account used by the ObjectTemplate must have proper permissions to list these objects
+	// +optional
+	Objects *MatrixEntryObjects `json:"objects,omitempty"`
+
+	// Schedule specifies time windows in which a single matrix input is made available. Outside of the windows, no
+	// input is made available, which results in no objects being rendered for this matrix entry
+	// +optional
+	Schedule *MatrixEntrySchedule `json:"schedule,omitempty"`
+
+	// Plugin specifies an external HTTP endpoint to query for matrix inputs. One matrix input is made available per
+	// returned parameter set
+	// +optional
+	Plugin *MatrixEntryPlugin `json:"plugin,omitempty"`
+
+	// ImageTags specifies to list the tags of a container image repository. One matrix input is made available per
+	// matching tag
+	// +optional
+	ImageTags *MatrixEntryImageTags `json:"imageTags,omitempty"`
+
+	// HTTP specifies an HTTP(S) endpoint returning JSON to query for matrix inputs. One matrix input is made available
+	// per element of the returned (or extracted) list
+	// +optional
+	HTTP *MatrixEntryHTTP `json:"http,omitempty"`
+
+	// GraphQL specifies a GraphQL query to execute. One matrix input is made available per element of the extracted
+	// list
+	// +optional
+	GraphQL *MatrixEntryGraphQL `json:"graphql,omitempty"`
+
+	// Vault specifies to read secrets below a path of a HashiCorp Vault KV secrets engine. One matrix input is made
+	// available per secret
+	// +optional
+	Vault *MatrixEntryVault `json:"vault,omitempty"`
+
+	// AWS specifies to read secrets from AWS Secrets Manager or parameters from AWS Systems Manager Parameter Store.
+	// One matrix input is made available per secret or parameter
+	// +optional
+	AWS *MatrixEntryAWS `json:"aws,omitempty"`
+
+	// Namespaces specifies to list namespaces. One matrix input is made available per namespace. The service account
+	// used by the ObjectTemplate must have proper permissions to list namespaces
+	// +optional
+	Namespaces *MatrixEntryNamespaces `json:"namespaces,omitempty"`
+
+	// Nodes specifies to list nodes. One matrix input is made available per node. The service account used by the
+	// ObjectTemplate must have proper permissions to list nodes
+	// +optional
+	Nodes *MatrixEntryNodes `json:"nodes,omitempty"`
+
+	// Matrix specifies a nested list of matrix entries. One matrix input is made available per combination of the
+	// inputs of the nested entries, containing the inputs of the nested entries under their names. Nested entries
+	// support the same fields as top-level entries, including further nesting
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Matrix []runtime.RawExtension `json:"matrix,omitempty"`
+
+	// Merge specifies to merge the inputs of nested matrix entries by common keys
+	// +optional
+	Merge *MatrixEntryMerge `json:"merge,omitempty"`
+
+	// Values optionally specifies static values that are merged into each input of this entry, overriding existing
+	// fields of the input. All inputs must be objects when values are specified
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Values *runtime.RawExtension `json:"values,omitempty"`
+
+	// Transform optionally specifies a Jinja2 template that is rendered once per input of this entry, with the input
+	// being available as `item`. The rendered result is parsed as YAML and replaces the input. Inputs for which the
+	// template renders to an empty string or null are dropped
+	// +optional
+	Transform *string `json:"transform,omitempty"`
 }
 
 type MatrixEntryObject struct {
@@ -93,6 +448,394 @@ type MatrixEntryObject struct {
 	ExpandLists bool `json:"expandLists,omitempty"`
 }
 
+const (
+	ClusterSecretTypeArgoCD     = "ArgoCD"
+	ClusterSecretTypeClusterAPI = "ClusterAPI"
+)
+
+type MatrixEntryClusters struct {
+	// Type specifies the format of the cluster secrets. "ArgoCD" discovers Argo CD cluster secrets (labeled with
+	// argocd.argoproj.io/secret-type=cluster). "ClusterAPI" discovers the kubeconfig secrets written by Cluster API
+	// (labeled with cluster.x-k8s.io/cluster-name). Defaults to "ArgoCD"
+	// +kubebuilder:validation:Enum=ArgoCD;ClusterAPI
+	// +kubebuilder:default:="ArgoCD"
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Namespace specifies the namespace of the cluster secrets. Defaults to the namespace of the ObjectTemplate, or to
+	// the service account namespace for ClusterObjectTemplates
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector optionally specifies a label selector to filter the cluster secrets
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type MatrixEntryData struct {
+	// Name specifies the name of the ConfigMap or Secret
+	// +required
+	Name string `json:"name"`
+
+	// Namespace optionally specifies the namespace of the ConfigMap or Secret. Defaults to the namespace of the
+	// ObjectTemplate
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key optionally specifies a key that contains a YAML list. One matrix input is made available per list element in
+	// that case. If omitted, one matrix input with the fields "key" and "value" is made available per key
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+type MatrixEntryObjects struct {
+	// APIVersion specifies the apiVersion of the objects to list
+	// +required
+	APIVersion string `json:"apiVersion"`
+
+	// Kind specifies the kind of the objects to list
+	// +required
+	Kind string `json:"kind"`
+
+	// Namespace specifies the namespace to list objects from. Defaults to the namespace of the ObjectTemplate. Ignored
+	// for cluster-scoped kinds
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector optionally specifies a label selector to filter the objects
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// FieldSelector optionally specifies a field selector to filter the objects, e.g. "status.phase=Running". Only
+	// fields that are supported by the API server for the given kind can be used
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// JsonPath optionally specifies a sub-field to load from each object. When specified, the sub-field (and not the
+	// whole object) is made available while rendering templates. Objects without a match are skipped
+	// +optional
+	JsonPath *string `json:"jsonPath,omitempty"`
+}
+
+type MatrixEntrySchedule struct {
+	// Windows specifies the time windows in which the matrix input is available. The input contains the start and end
+	// of the current window
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	Windows []ScheduleWindow `json:"windows"`
+}
+
+type MatrixEntryPlugin struct {
+	// URL specifies the URL of the plugin endpoint. The endpoint is called with a POST request containing the input
+	// as {"input": {"parameters": ...}} and must respond with {"output": {"parameters": [...]}}
+	// +required
+	URL string `json:"url"`
+
+	// Input optionally specifies arbitrary parameters that are passed to the plugin
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Input *runtime.RawExtension `json:"input,omitempty"`
+
+	// TokenRef specifies a secret and key to load a token from. The token is sent as bearer token.
+	// +optional
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+
+	// BasicAuth specifies a username and password to authenticate with. Takes precedence over TokenRef.
+	// +optional
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+
+	// Timeout specifies the timeout of the request to the plugin. Defaults to 30s.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type MatrixEntryImageTags struct {
+	// Repository specifies the image repository to list tags from, e.g. ghcr.io/example/app
+	// +required
+	Repository string `json:"repository"`
+
+	// Regex optionally specifies a regular expression that tags must fully match
+	// +optional
+	Regex *string `json:"regex,omitempty"`
+
+	// SemVer optionally specifies a semantic version constraint, e.g. ">= 1.2.0-rc.0". When specified, tags that are
+	// not valid semantic versions or do not satisfy the constraint are skipped and the remaining tags are ordered from
+	// the highest to the lowest version. Otherwise, tags are ordered by name
+	// +optional
+	SemVer *string `json:"semver,omitempty"`
+
+	// LatestN optionally limits the matrix inputs to the first N matching tags after ordering
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	LatestN *int `json:"latestN,omitempty"`
+
+	// SecretRef specifies a Secret of type kubernetes.io/dockerconfigjson used for registry authentication. The service
+	// account used by the ObjectTemplate must have proper permissions to get this secret
+	// +optional
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+type MatrixEntryHTTP struct {
+	// URL specifies the URL of the endpoint
+	// +required
+	URL string `json:"url"`
+
+	// Method specifies the HTTP method to use. Defaults to GET
+	// +kubebuilder:validation:Enum=GET;POST
+	// +kubebuilder:default:="GET"
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Body optionally specifies the request body
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// Headers specifies additional headers to send
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// SecretHeaders specifies additional headers to send with their values loaded from secrets, e.g. to send an
+	// Authorization header
+	// +optional
+	SecretHeaders []HTTPSecretHeader `json:"secretHeaders,omitempty"`
+
+	// JsonPath optionally specifies the sub-field of the response to extract the matrix inputs from. If the extracted
+	// value is a list, each element is made available as individual matrix input
+	// +optional
+	JsonPath *string `json:"jsonPath,omitempty"`
+
+	// Timeout specifies the timeout of the request. Defaults to 30s.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type HTTPSecretHeader struct {
+	// Name specifies the name of the header
+	// +required
+	Name string `json:"name"`
+
+	// ValueRef specifies a secret and key to load the value of the header from
+	// +required
+	ValueRef SecretRef `json:"valueRef"`
+}
+
+type MatrixEntryGraphQL struct {
+	// URL specifies the URL of the GraphQL endpoint, e.g. https://api.github.com/graphql
+	// +required
+	URL string `json:"url"`
+
+	// Query specifies the GraphQL query to execute
+	// +required
+	Query string `json:"query"`
+
+	// Variables optionally specifies the variables to pass to the query
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Variables *runtime.RawExtension `json:"variables,omitempty"`
+
+	// Headers specifies additional headers to send
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// SecretHeaders specifies additional headers to send with their values loaded from secrets, e.g. to send an
+	// Authorization header
+	// +optional
+	SecretHeaders []HTTPSecretHeader `json:"secretHeaders,omitempty"`
+
+	// JsonPath specifies the sub-field of the response data to extract the matrix inputs from. If the extracted value
+	// is a list, each element is made available as individual matrix input
+	// +required
+	JsonPath string `json:"jsonPath"`
+
+	// Timeout specifies the timeout of the request. Defaults to 30s.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type MatrixEntryVault struct {
+	// Address specifies the address of the Vault server, e.g. https://vault.example.com:8200
+	// +required
+	Address string `json:"address"`
+
+	// Mount specifies the mount path of the KV secrets engine. Defaults to "secret"
+	// +kubebuilder:default:="secret"
+	// +optional
+	Mount string `json:"mount,omitempty"`
+
+	// Path specifies the path below the mount to list secrets from. Only secrets directly below the path are read,
+	// sub-paths are ignored
+	// +required
+	Path string `json:"path"`
+
+	// KVVersion specifies the version of the KV secrets engine. Defaults to 2
+	// +kubebuilder:validation:Enum=1;2
+	// +kubebuilder:default:=2
+	// +optional
+	KVVersion int `json:"kvVersion,omitempty"`
+
+	// KubernetesAuth specifies how to authenticate against Vault via the Kubernetes auth method. A token for the
+	// service account used by the ObjectTemplate is requested and used to log in
+	// +required
+	KubernetesAuth VaultKubernetesAuth `json:"kubernetesAuth"`
+}
+
+type VaultKubernetesAuth struct {
+	// Role specifies the Vault role to log in with
+	// +required
+	Role string `json:"role"`
+
+	// Mount specifies the mount path of the Kubernetes auth method. Defaults to "kubernetes"
+	// +kubebuilder:default:="kubernetes"
+	// +optional
+	Mount string `json:"mount,omitempty"`
+
+	// Audience optionally specifies the audience of the requested service account token. Defaults to the audience of
+	// the API server
+	// +optional
+	Audience string `json:"audience,omitempty"`
+}
+
+type MatrixEntryAWS struct {
+	// Region specifies the AWS region, e.g. eu-central-1
+	// +required
+	Region string `json:"region"`
+
+	// RoleArn specifies the IAM role to assume. A token for the service account used by the ObjectTemplate is
+	// requested and used to assume the role via web identity federation, similar to IAM roles for service accounts
+	// +required
+	RoleArn string `json:"roleArn"`
+
+	// Audience specifies the audience of the requested service account token. Defaults to "sts.amazonaws.com"
+	// +kubebuilder:default:="sts.amazonaws.com"
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// SecretsManager specifies to read secrets from AWS Secrets Manager
+	// +optional
+	SecretsManager *AWSSecretsManagerSource `json:"secretsManager,omitempty"`
+
+	// ParameterStore specifies to read parameters from AWS Systems Manager Parameter Store
+	// +optional
+	ParameterStore *AWSParameterStoreSource `json:"parameterStore,omitempty"`
+}
+
+type AWSSecretsManagerSource struct {
+	// Prefix specifies the prefix of the names of the secrets to read
+	// +required
+	Prefix string `json:"prefix"`
+}
+
+type AWSParameterStoreSource struct {
+	// Path specifies the path of the parameters to read, e.g. /environments/
+	// +required
+	Path string `json:"path"`
+
+	// Recursive specifies to also read parameters in sub-paths
+	// +optional
+	Recursive bool `json:"recursive,omitempty"`
+}
+
+type MatrixEntryNamespaces struct {
+	// Selector optionally specifies a label selector to filter the namespaces
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type MatrixEntryNodes struct {
+	// Selector optionally specifies a label selector to filter the nodes
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type MatrixEntryMerge struct {
+	// Entries specifies the nested matrix entries to merge. The inputs of the first entry are the base inputs, the
+	// inputs of all following entries are merged into the base inputs with equal values for all merge keys. Inputs
+	// without a matching base input are ignored. Names of the nested entries are optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +required
+	Entries []runtime.RawExtension `json:"entries"`
+
+	// MergeKeys specifies the fields (as dot separated paths) used to match inputs
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	MergeKeys []string `json:"mergeKeys"`
+}
+
+type TemplateInclude struct {
+	// ConfigMap specifies a ConfigMap to include. All keys of the ConfigMap are made available to the templates
+	// +optional
+	ConfigMap *TemplateIncludeConfigMap `json:"configMap,omitempty"`
+}
+
+type TemplateIncludeConfigMap struct {
+	// Name specifies the name of the ConfigMap
+	// +required
+	Name string `json:"name"`
+
+	// Namespace optionally specifies the namespace of the ConfigMap. Defaults to the namespace of the ObjectTemplate
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type Decryption struct {
+	// Provider specifies the decryption provider. Only "sops" is supported
+	// +kubebuilder:validation:Enum=sops
+	// +required
+	Provider string `json:"provider"`
+
+	// SecretRef specifies a Secret with the decryption keys. All keys ending with .agekey are parsed as age identities.
+	// The key sops.aws-kms may contain AWS credentials (aws_access_key_id, aws_secret_access_key and
+	// aws_session_token) used to decrypt with AWS KMS. The service account used by the ObjectTemplate must have proper
+	// permissions to get this secret
+	// +optional
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+type TemplateDelimiters struct {
+	// VariableStart replaces "{{". It is also used as the left action delimiter of Go templates
+	// +required
+	VariableStart string `json:"variableStart"`
+
+	// VariableEnd replaces "}}". It is also used as the right action delimiter of Go templates
+	// +required
+	VariableEnd string `json:"variableEnd"`
+
+	// BlockStart optionally replaces "{%". Only supported by Jinja2
+	// +optional
+	BlockStart string `json:"blockStart,omitempty"`
+
+	// BlockEnd optionally replaces "%}". Only supported by Jinja2
+	// +optional
+	BlockEnd string `json:"blockEnd,omitempty"`
+}
+
+type TemplateVarsSource struct {
+	// ConfigMapRef specifies a ConfigMap whose data is made available as variables
+	// +optional
+	ConfigMapRef *TemplateVarsObjectRef `json:"configMapRef,omitempty"`
+
+	// SecretRef specifies a Secret whose data is made available as variables
+	// +optional
+	SecretRef *TemplateVarsObjectRef `json:"secretRef,omitempty"`
+}
+
+type TemplateVarsObjectRef struct {
+	// Name specifies the name of the object
+	// +required
+	Name string `json:"name"`
+
+	// Namespace optionally specifies the namespace of the object. Defaults to the namespace of the ObjectTemplate
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
 type Template struct {
 	// Object specifies a structured object in YAML form. Each field value is rendered independently.
 	// +optional
@@ -101,9 +844,86 @@ type Template struct {
 
 	// Raw specifies a raw string to be interpreted/parsed as YAML. The whole string is rendered in one go, allowing to
 	// use advanced Jinja2 control structures. Raw object might also be required when a templated value must not be
-	// interpreted as a string (which would be done in Object).
+	// interpreted as a string (which would be done in Object), or when map keys, whole blocks or even apiVersion and kind
+	// must be templated. The rendered string may contain multiple YAML documents. Empty documents are ignored.
 	// +optional
 	Raw *string `json:"raw,omitempty"`
+
+	// Library references a named and versioned template from a TemplateLibrary. The service account used by the
+	// ObjectTemplate must have proper permissions to get the TemplateLibrary.
+	// +optional
+	Library *LibraryTemplateRef `json:"library,omitempty"`
+
+	// GitRepository loads raw templates from the artifact of a Flux GitRepository. The service account used by the
+	// ObjectTemplate must have proper permissions to get the GitRepository.
+	// +optional
+	GitRepository *GitRepositoryTemplateSource `json:"gitRepository,omitempty"`
+
+	// OCI loads raw templates from an OCI artifact, e.g. one pushed via `flux push artifact`.
+	// +optional
+	OCI *OCITemplateSource `json:"oci,omitempty"`
+
+	// ForEach optionally specifies a JSON path into the template variables, e.g. `matrix.input1.services[*]`. The
+	// template is then rendered once per result, with the result being available as `each`. Lists are expanded into
+	// their elements.
+	// +optional
+	ForEach *string `json:"forEach,omitempty"`
+}
+
+type GitRepositoryTemplateSource struct {
+	// Name specifies the name of the GitRepository
+	// +required
+	Name string `json:"name"`
+
+	// Namespace specifies the namespace of the GitRepository. Defaults to the namespace of the referencing template
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Path specifies a file or directory inside the artifact. If a directory is specified, all files with the .yaml,
+	// .yml or .j2 extension found directly inside the directory are loaded in alphabetical order. Each file is
+	// treated as a raw template
+	// +required
+	Path string `json:"path"`
+}
+
+type OCITemplateSource struct {
+	// URL specifies the OCI repository to pull the artifact from, e.g. oci://ghcr.io/example/templates
+	// +kubebuilder:validation:Pattern="^oci://.*$"
+	// +required
+	URL string `json:"url"`
+
+	// Tag specifies the tag of the artifact. Defaults to "latest". Ignored if a digest is specified
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest optionally pins the artifact to the given manifest digest, e.g. sha256:2c26b46b...
+	// +kubebuilder:validation:Pattern="^sha256:[a-f0-9]{64}$"
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// SecretRef specifies a Secret of type kubernetes.io/dockerconfigjson used for registry authentication. The service
+	// account used by the ObjectTemplate must have proper permissions to get this secret
+	// +optional
+	SecretRef *LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Path specifies a file or directory inside the artifact. If a directory is specified, all files with the .yaml,
+	// .yml or .j2 extension found directly inside the directory are loaded in alphabetical order. Each file is
+	// treated as a raw template
+	// +required
+	Path string `json:"path"`
+
+	// Verify enables verification of cosign signatures. Only artifacts that are signed by one of the trusted keys are
+	// loaded
+	// +optional
+	Verify *OCIVerify `json:"verify,omitempty"`
+}
+
+type OCIVerify struct {
+	// PublicKeysRef specifies a Secret containing PEM encoded cosign public keys. All keys found in the Secret are
+	// trusted. The service account used by the ObjectTemplate must have proper permissions to get this secret. If the
+	// controller is configured with a trusted keys namespace, the Secret is read from that namespace instead
+	// +required
+	PublicKeysRef LocalObjectReference `json:"publicKeysRef"`
 }
 
 // ObjectTemplateStatus defines the observed state of ObjectTemplate
@@ -113,6 +933,150 @@ type ObjectTemplateStatus struct {
 
 	// +optional
 	AppliedResources []AppliedResourceInfo `json:"appliedResources,omitempty"`
+
+	// +optional
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+
+	// +optional
+	GitOutput *GitOutputStatus `json:"gitOutput,omitempty"`
+
+	// +optional
+	OCIOutput *OCIOutputStatus `json:"ociOutput,omitempty"`
+
+	// DryRun contains the result of the last dry-run. It is only set while spec.dryRun is enabled
+	// +optional
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+
+	// Rollout contains the progress of the rollout. It is only set while spec.rollout.batchSize is set
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// LastDriftCheckTime is the time at which unchanged objects were last checked for drift. It is only set while
+	// spec.driftDetection is enabled
+	// +optional
+	LastDriftCheckTime *metav1.Time `json:"lastDriftCheckTime,omitempty"`
+
+	// PendingApply contains the rendered objects that are not applied yet because the objects they depend on are not
+	// ready yet. It is only set while objects are pending
+	// +optional
+	PendingApply *PendingApplyStatus `json:"pendingApply,omitempty"`
+}
+
+// PendingApplyStatus describes rendered objects that wait for earlier waves or dependencies to become ready
+type PendingApplyStatus struct {
+	// Wave is the first wave that is not applied yet because objects of an earlier wave are not ready
+	// +optional
+	Wave *int `json:"wave,omitempty"`
+
+	// Objects contains the objects of already applied waves that wait for their dependencies to become ready
+	// +optional
+	Objects []string `json:"objects,omitempty"`
+
+	// NotReady contains the objects that must become ready before the pending objects are applied
+	NotReady []string `json:"notReady"`
+}
+
+type RolloutStatus struct {
+	// PendingEntries is the number of changed matrix entries that were not applied yet
+	PendingEntries int `json:"pendingEntries"`
+
+	// Paused is true if the rollout is paused due to failed matrix entries
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// FailedEntries contains the matrix keys of the matrix entries that failed in the last batch
+	// +optional
+	FailedEntries []string `json:"failedEntries,omitempty"`
+
+	// FailedGeneration is the generation of the ObjectTemplate at which FailedEntries were recorded
+	// +optional
+	FailedGeneration int64 `json:"failedGeneration,omitempty"`
+
+	// LastBatchTime is the time at which the last batch of changed matrix entries was applied
+	// +optional
+	LastBatchTime *metav1.Time `json:"lastBatchTime,omitempty"`
+}
+
+type GitOutputStatus struct {
+	// Commit is the commit that contains the last written rendered objects
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// Paths is the list of directories that rendered objects were written to
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+}
+
+type ScheduleStatus struct {
+	// Active is true if the template is currently active according to its schedule
+	Active bool `json:"active"`
+
+	// ExpiresAt is the time at which the TTL expires
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// NextAction is the next scheduled action, either Suspend, Prune or Resume
+	// +optional
+	NextAction string `json:"nextAction,omitempty"`
+
+	// NextActionTime is the time at which NextAction is performed
+	// +optional
+	NextActionTime *metav1.Time `json:"nextActionTime,omitempty"`
+}
+
+type OCIOutputStatus struct {
+	// Artifacts is the list of artifacts that rendered objects were pushed to
+	// +optional
+	Artifacts []OCIArtifactInfo `json:"artifacts,omitempty"`
+}
+
+type OCIArtifactInfo struct {
+	// Tag is the tag of the artifact
+	Tag string `json:"tag"`
+
+	// Digest is the digest of the last pushed artifact
+	Digest string `json:"digest"`
+}
+
+const (
+	DryRunActionCreate    = "Create"
+	DryRunActionUpdate    = "Update"
+	DryRunActionUnchanged = "Unchanged"
+	DryRunActionDelete    = "Delete"
+)
+
+type DryRunStatus struct {
+	// Objects contains the would-be changes for every rendered object and every object that would be pruned
+	// +optional
+	Objects []DryRunObjectInfo `json:"objects,omitempty"`
+}
+
+type DryRunObjectInfo struct {
+	Ref ObjectRef `json:"ref"`
+
+	// Action is the action that would be performed on the object, either Create, Update, Unchanged or Delete
+	// +optional
+	Action string `json:"action,omitempty"`
+
+	// Changes contains the fields that would be changed by an update
+	// +optional
+	Changes []DryRunChange `json:"changes,omitempty"`
+
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+type DryRunChange struct {
+	// Path is the path of the changed field, e.g. spec.replicas
+	Path string `json:"path"`
+
+	// Old is the JSON encoded value of the field in the cluster, or empty if the field would be added
+	// +optional
+	Old string `json:"old,omitempty"`
+
+	// New is the JSON encoded value of the field after applying, or empty if the field would be removed
+	// +optional
+	New string `json:"new,omitempty"`
 }
 
 type AppliedResourceInfo struct {
@@ -122,6 +1086,37 @@ type AppliedResourceInfo struct {
 
 	// +optional
 	Error string `json:"error,omitempty"`
+
+	// RenderedHash is the rendered hash of the object as of the last successful apply
+	// +optional
+	RenderedHash string `json:"renderedHash,omitempty"`
+
+	// Health is the health of the object as of the last apply, either Healthy, Progressing, Degraded or Unknown
+	// +kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Unknown
+	// +optional
+	Health string `json:"health,omitempty"`
+
+	// HealthMessage is a human readable explanation of the health
+	// +optional
+	HealthMessage string `json:"healthMessage,omitempty"`
+
+	// Conflicts contains the fields that could not be applied because they are owned by other field managers
+	// +optional
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+
+	// DriftedFields contains the paths of the fields that were changed outside of the template, as detected during the
+	// last drift check. Only set if drift detection is enabled
+	// +optional
+	DriftedFields []string `json:"driftedFields,omitempty"`
+}
+
+type FieldConflict struct {
+	// Field is the path of the conflicting field, e.g. .spec.replicas
+	Field string `json:"field"`
+
+	// Manager is the field manager that owns the field
+	// +optional
+	Manager string `json:"manager,omitempty"`
 }
 
 // GetConditions returns the status conditions of the object.
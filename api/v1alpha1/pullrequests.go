@@ -0,0 +1,63 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PullRequestFilters specifies filters and ordering options that are supported by all objects that list pull requests or
+// merge requests. All filters are applied after the pull requests have been fetched from the API, meaning that Limit is
+// applied before filtering.
+type PullRequestFilters struct {
+	// ExcludeLabels excludes all pull requests that have at least one of the given labels
+	// +optional
+	ExcludeLabels []string `json:"excludeLabels,omitempty"`
+
+	// ExcludeSourceBranch excludes all pull requests with a source branch matching the given regex
+	// +optional
+	ExcludeSourceBranch *string `json:"excludeSourceBranch,omitempty"`
+
+	// Authors limits the pull requests to those created by one of the given users. Usernames are compared
+	// case-insensitively
+	// +optional
+	Authors []string `json:"authors,omitempty"`
+
+	// ExcludeAuthors excludes all pull requests created by one of the given users. Usernames are compared
+	// case-insensitively
+	// +optional
+	ExcludeAuthors []string `json:"excludeAuthors,omitempty"`
+
+	// ExcludeBots excludes all pull requests created by bots, e.g. Renovate or Dependabot
+	// +optional
+	ExcludeBots bool `json:"excludeBots,omitempty"`
+
+	// ExcludeDrafts excludes all draft pull requests. Pull requests with a title starting with a WIP or draft marker
+	// (e.g. "WIP:" or "Draft:") are treated as drafts as well
+	// +optional
+	ExcludeDrafts bool `json:"excludeDrafts,omitempty"`
+
+	// ChangedPaths limits the pull requests to those that change at least one file matching one of the given globs,
+	// e.g. "deploy/**". This requires one additional API request per pull request that passed all other filters.
+	// +optional
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+
+	// MaxAge excludes all pull requests that were not updated within the given duration, e.g. "336h" to drop pull
+	// requests that were abandoned for two weeks
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// SortBy specifies the order in which pull requests are fetched and listed in the status, either by the time of
+	// creation ("created") or of the last update ("updated"). In combination with Limit, this allows to only list the
+	// most recent pull requests. If omitted, the default order of the API is used and the status is ordered by ID.
+	// +kubebuilder:validation:Enum=created;updated
+	// +optional
+	SortBy string `json:"sortBy,omitempty"`
+
+	// SortDirection specifies the direction used for SortBy, either "asc" or "desc". Defaults to "desc", meaning that the
+	// newest pull requests come first
+	// +kubebuilder:validation:Enum=asc;desc
+	// +kubebuilder:default:="desc"
+	// +optional
+	SortDirection string `json:"sortDirection,omitempty"`
+}
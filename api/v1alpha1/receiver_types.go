@@ -0,0 +1,142 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ReconcileRequestedAtAnnotation can be set on objects to request an immediate reconciliation, independent of
+	// the object's generation. Receivers set it on all triggered resources.
+	ReconcileRequestedAtAnnotation = "templates.kluctl.io/reconcile-requested-at"
+)
+
+const (
+	ReceiverTypeGeneric     = "generic"
+	ReceiverTypeGenericHmac = "generic-hmac"
+	ReceiverTypeGithub      = "github"
+	ReceiverTypeGitlab      = "gitlab"
+)
+
+// ReceiverSpec defines the desired state of Receiver
+type ReceiverSpec struct {
+	// Type specifies how incoming requests are validated.
+	// `generic` does not validate requests, the webhook path itself acts as the secret.
+	// `generic-hmac` requires the X-Signature header to contain the HMAC-SHA256 of the body, in the form `sha256=<hex>`.
+	// `github` validates the X-Hub-Signature-256 header and matches events against the X-GitHub-Event header.
+	// `gitlab` validates the X-Gitlab-Token header and matches events against the X-Gitlab-Event header.
+	// +kubebuilder:validation:Enum=generic;generic-hmac;github;gitlab
+	// +required
+	Type string `json:"type"`
+
+	// +kubebuilder:default:="10m"
+	Interval metav1.Duration `json:"interval"`
+
+	// Suspend can be used to suspend the reconciliation of this object and to ignore incoming events
+	// +optional
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend"`
+
+	// SecretRef specifies the secret containing the token used to compute the webhook path and to validate requests
+	// +required
+	SecretRef SecretRef `json:"secretRef"`
+
+	// Events specifies the list of events to handle. If omitted, all events are handled. Only used by the github
+	// and gitlab types.
+	// +optional
+	Events []string `json:"events,omitempty"`
+
+	// Resources specifies the resources to trigger a reconciliation for. Resources must be in the same namespace as
+	// the Receiver.
+	// +required
+	Resources []ReceiverResource `json:"resources"`
+
+	// Debounce specifies the duration for which events are coalesced before the resources are triggered. Set it to
+	// 0 to trigger immediately.
+	// +kubebuilder:default:="10s"
+	// +optional
+	Debounce metav1.Duration `json:"debounce,omitempty"`
+}
+
+type ReceiverResource struct {
+	// +kubebuilder:default:="templates.kluctl.io/v1alpha1"
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// +kubebuilder:validation:Enum=ObjectTemplate;TextTemplate;ObjectHandler;ListGitlabMergeRequests;ListGithubPullRequests;GitProjector
+	// +required
+	Kind string `json:"kind"`
+
+	// Name specifies the name of the resource. Either Name or MatchLabels must be specified
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// MatchLabels selects all resources of the given kind with matching labels
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// ReceiverStatus defines the observed state of Receiver
+type ReceiverStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// WebhookPath is the path that incoming events must be sent to. It is computed from the token, name and namespace
+	// of the Receiver.
+	// +optional
+	WebhookPath string `json:"webhookPath,omitempty"`
+
+	// LastEventTime is the time of the last accepted event
+	// +optional
+	LastEventTime *metav1.Time `json:"lastEventTime,omitempty"`
+}
+
+// GetConditions returns the status conditions of the object.
+func (in *Receiver) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the status conditions on the object.
+func (in *Receiver) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// Receiver is the Schema for the receivers API
+type Receiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReceiverSpec   `json:"spec,omitempty"`
+	Status ReceiverStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReceiverList contains a list of Receiver
+type ReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Receiver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Receiver{}, &ReceiverList{})
+}
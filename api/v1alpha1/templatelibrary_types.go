@@ -0,0 +1,89 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TemplateLibrarySpec defines the desired state of TemplateLibrary
+type TemplateLibrarySpec struct {
+	// Templates specifies a list of named and versioned templates
+	// +required
+	Templates []LibraryTemplate `json:"templates"`
+}
+
+type LibraryTemplate struct {
+	// Name specifies the name under which the template can be referenced
+	// +required
+	Name string `json:"name"`
+
+	// Version specifies the version of the template. Multiple versions of the same template may exist in the same
+	// library. Versions should follow semantic versioning so that version constraints can be used when referencing
+	// the template.
+	// +required
+	Version string `json:"version"`
+
+	// Macros specifies Jinja2 macros that are made available to raw templates. The macros are prepended to the raw
+	// template before rendering.
+	// +optional
+	Macros *string `json:"macros,omitempty"`
+
+	Template `json:",inline"`
+}
+
+type LibraryTemplateRef struct {
+	// Library specifies the name of the TemplateLibrary
+	// +required
+	Library string `json:"library"`
+
+	// Namespace specifies the namespace of the TemplateLibrary. Defaults to the namespace of the referencing template
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name specifies the name of the template inside the library
+	// +required
+	Name string `json:"name"`
+
+	// Version specifies the exact version or a semantic version constraint (e.g. "^1.2") of the template. If a
+	// constraint is specified, the highest matching version is used.
+	// +required
+	Version string `json:"version"`
+}
+
+//+kubebuilder:object:root=true
+
+// TemplateLibrary is the Schema for the templatelibraries API
+type TemplateLibrary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TemplateLibrarySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TemplateLibraryList contains a list of TemplateLibrary
+type TemplateLibraryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TemplateLibrary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TemplateLibrary{}, &TemplateLibraryList{})
+}
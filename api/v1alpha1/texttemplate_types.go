@@ -40,6 +40,12 @@ type TextTemplateSpec struct {
 
 	// +optional
 	TemplateRef *TemplateRef `json:"templateRef,omitempty"`
+
+	// Target optionally specifies a ConfigMap or Secret in the same namespace into which the rendered result is
+	// written. The object is created if it does not exist yet, other keys of the object are left untouched. The
+	// service account must have permissions to get, create and patch the object
+	// +optional
+	Target *TextTemplateTarget `json:"target,omitempty"`
 }
 
 type TextTemplateInput struct {
@@ -74,6 +80,22 @@ type TemplateRefConfigMap struct {
 	Key string `json:"key"`
 }
 
+type TextTemplateTarget struct {
+	// Kind specifies the kind of the target object. Results written into Secrets are not written into the status
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default:="ConfigMap"
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name specifies the name of the target object
+	// +required
+	Name string `json:"name"`
+
+	// Key specifies the key of the target object into which the result is written
+	// +required
+	Key string `json:"key"`
+}
+
 // TextTemplateStatus defines the observed state of TextTemplate
 type TextTemplateStatus struct {
 	// +optional
@@ -12,10 +12,31 @@ type LocalObjectReference struct {
 	Name string `json:"name"`
 }
 
+// SecretAllowedNamespacesAnnotation can be set on secrets to grant objects from other namespaces access to the secret.
+// The value is a comma separated list of namespace glob patterns.
+const SecretAllowedNamespacesAnnotation = "templates.kluctl.io/allowed-namespaces"
+
 // Utility struct for a reference to a secret key.
 type SecretRef struct {
 	SecretName string `json:"secretName"`
 	Key        string `json:"key"`
+
+	// Namespace optionally specifies the namespace of the secret. If omitted, the namespace of the referencing object is
+	// used. Secrets in other namespaces must explicitly grant access via the templates.kluctl.io/allowed-namespaces
+	// annotation.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// BasicAuth specifies a username and a secret containing the password to use for HTTP basic authentication.
+type BasicAuth struct {
+	// Username specifies the username to authenticate with
+	// +required
+	Username string `json:"username"`
+
+	// PasswordRef specifies a secret and key to load the password from
+	// +required
+	PasswordRef SecretRef `json:"passwordRef"`
 }
 
 type ConfigMapRef struct {
@@ -27,10 +27,50 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSParameterStoreSource) DeepCopyInto(out *AWSParameterStoreSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSParameterStoreSource.
+func (in *AWSParameterStoreSource) DeepCopy() *AWSParameterStoreSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSParameterStoreSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSecretsManagerSource) DeepCopyInto(out *AWSSecretsManagerSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSecretsManagerSource.
+func (in *AWSSecretsManagerSource) DeepCopy() *AWSSecretsManagerSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSecretsManagerSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppliedResourceInfo) DeepCopyInto(out *AppliedResourceInfo) {
 	*out = *in
 	out.Ref = in.Ref
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]FieldConflict, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftedFields != nil {
+		in, out := &in.DriftedFields, &out.DriftedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedResourceInfo.
@@ -44,88 +84,93 @@ func (in *AppliedResourceInfo) DeepCopy() *AppliedResourceInfo {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommentSourceSpec) DeepCopyInto(out *CommentSourceSpec) {
+func (in *AzureDevOpsProject) DeepCopyInto(out *AzureDevOpsProject) {
 	*out = *in
-	if in.Text != nil {
-		in, out := &in.Text, &out.Text
-		*out = new(string)
-		**out = **in
-	}
-	if in.ConfigMap != nil {
-		in, out := &in.ConfigMap, &out.ConfigMap
-		*out = new(ConfigMapRef)
-		**out = **in
-	}
-	if in.TextTemplate != nil {
-		in, out := &in.TextTemplate, &out.TextTemplate
-		*out = new(LocalObjectReference)
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommentSourceSpec.
-func (in *CommentSourceSpec) DeepCopy() *CommentSourceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureDevOpsProject.
+func (in *AzureDevOpsProject) DeepCopy() *AzureDevOpsProject {
 	if in == nil {
 		return nil
 	}
-	out := new(CommentSourceSpec)
+	out := new(AzureDevOpsProject)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommentSpec) DeepCopyInto(out *CommentSpec) {
+func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
 	*out = *in
-	if in.Id != nil {
-		in, out := &in.Id, &out.Id
-		*out = new(string)
-		**out = **in
-	}
-	in.Source.DeepCopyInto(&out.Source)
+	out.PasswordRef = in.PasswordRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommentSpec.
-func (in *CommentSpec) DeepCopy() *CommentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuth.
+func (in *BasicAuth) DeepCopy() *BasicAuth {
 	if in == nil {
 		return nil
 	}
-	out := new(CommentSpec)
+	out := new(BasicAuth)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
+func (in *BitbucketCloudProject) DeepCopyInto(out *BitbucketCloudProject) {
 	*out = *in
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuth)
+		**out = **in
+	}
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapRef.
-func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketCloudProject.
+func (in *BitbucketCloudProject) DeepCopy() *BitbucketCloudProject {
 	if in == nil {
 		return nil
 	}
-	out := new(ConfigMapRef)
+	out := new(BitbucketCloudProject)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitFile) DeepCopyInto(out *GitFile) {
+func (in *BitbucketServerProject) DeepCopyInto(out *BitbucketServerProject) {
 	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuth)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitFile.
-func (in *GitFile) DeepCopy() *GitFile {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketServerProject.
+func (in *BitbucketServerProject) DeepCopy() *BitbucketServerProject {
 	if in == nil {
 		return nil
 	}
-	out := new(GitFile)
+	out := new(BitbucketServerProject)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitProjector) DeepCopyInto(out *GitProjector) {
+func (in *ClusterObjectTemplate) DeepCopyInto(out *ClusterObjectTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -133,18 +178,18 @@ func (in *GitProjector) DeepCopyInto(out *GitProjector) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjector.
-func (in *GitProjector) DeepCopy() *GitProjector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterObjectTemplate.
+func (in *ClusterObjectTemplate) DeepCopy() *ClusterObjectTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(GitProjector)
+	out := new(ClusterObjectTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitProjector) DeepCopyObject() runtime.Object {
+func (in *ClusterObjectTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -152,31 +197,31 @@ func (in *GitProjector) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitProjectorList) DeepCopyInto(out *GitProjectorList) {
+func (in *ClusterObjectTemplateList) DeepCopyInto(out *ClusterObjectTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GitProjector, len(*in))
+		*out = make([]ClusterObjectTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorList.
-func (in *GitProjectorList) DeepCopy() *GitProjectorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterObjectTemplateList.
+func (in *ClusterObjectTemplateList) DeepCopy() *ClusterObjectTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(GitProjectorList)
+	out := new(ClusterObjectTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitProjectorList) DeepCopyObject() runtime.Object {
+func (in *ClusterObjectTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -184,275 +229,298 @@ func (in *GitProjectorList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitProjectorResult) DeepCopyInto(out *GitProjectorResult) {
+func (in *ClusterObjectTemplateSpec) DeepCopyInto(out *ClusterObjectTemplateSpec) {
 	*out = *in
-	out.Reference = in.Reference
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]GitProjectorResultFile, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectTemplateSpec.DeepCopyInto(&out.ObjectTemplateSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorResult.
-func (in *GitProjectorResult) DeepCopy() *GitProjectorResult {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterObjectTemplateSpec.
+func (in *ClusterObjectTemplateSpec) DeepCopy() *ClusterObjectTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitProjectorResult)
+	out := new(ClusterObjectTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitProjectorResultFile) DeepCopyInto(out *GitProjectorResultFile) {
+func (in *CommentSourceSpec) DeepCopyInto(out *CommentSourceSpec) {
 	*out = *in
-	if in.Raw != nil {
-		in, out := &in.Raw, &out.Raw
+	if in.Text != nil {
+		in, out := &in.Text, &out.Text
 		*out = new(string)
 		**out = **in
 	}
-	if in.Parsed != nil {
-		in, out := &in.Parsed, &out.Parsed
-		*out = make([]*runtime.RawExtension, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(runtime.RawExtension)
-				(*in).DeepCopyInto(*out)
-			}
-		}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapRef)
+		**out = **in
+	}
+	if in.TextTemplate != nil {
+		in, out := &in.TextTemplate, &out.TextTemplate
+		*out = new(LocalObjectReference)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorResultFile.
-func (in *GitProjectorResultFile) DeepCopy() *GitProjectorResultFile {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommentSourceSpec.
+func (in *CommentSourceSpec) DeepCopy() *CommentSourceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitProjectorResultFile)
+	out := new(CommentSourceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitProjectorSpec) DeepCopyInto(out *GitProjectorSpec) {
+func (in *CommentSpec) DeepCopyInto(out *CommentSpec) {
 	*out = *in
-	out.Interval = in.Interval
-	if in.Reference != nil {
-		in, out := &in.Reference, &out.Reference
-		*out = new(GitRef)
-		**out = **in
-	}
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]GitFile, len(*in))
-		copy(*out, *in)
-	}
-	if in.SecretRef != nil {
-		in, out := &in.SecretRef, &out.SecretRef
-		*out = new(LocalObjectReference)
+	if in.Id != nil {
+		in, out := &in.Id, &out.Id
+		*out = new(string)
 		**out = **in
 	}
+	in.Source.DeepCopyInto(&out.Source)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorSpec.
-func (in *GitProjectorSpec) DeepCopy() *GitProjectorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommentSpec.
+func (in *CommentSpec) DeepCopy() *CommentSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitProjectorSpec)
+	out := new(CommentSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitProjectorStatus) DeepCopyInto(out *GitProjectorStatus) {
+func (in *ConfigMapRef) DeepCopyInto(out *ConfigMapRef) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Result != nil {
-		in, out := &in.Result, &out.Result
-		*out = make([]GitProjectorResult, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorStatus.
-func (in *GitProjectorStatus) DeepCopy() *GitProjectorStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapRef.
+func (in *ConfigMapRef) DeepCopy() *ConfigMapRef {
 	if in == nil {
 		return nil
 	}
-	out := new(GitProjectorStatus)
+	out := new(ConfigMapRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitRef) DeepCopyInto(out *GitRef) {
+func (in *Decryption) DeepCopyInto(out *Decryption) {
 	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRef.
-func (in *GitRef) DeepCopy() *GitRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Decryption.
+func (in *Decryption) DeepCopy() *Decryption {
 	if in == nil {
 		return nil
 	}
-	out := new(GitRef)
+	out := new(Decryption)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubComment) DeepCopyInto(out *GithubComment) {
+func (in *DryRunChange) DeepCopyInto(out *DryRunChange) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubComment.
-func (in *GithubComment) DeepCopy() *GithubComment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunChange.
+func (in *DryRunChange) DeepCopy() *DryRunChange {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubComment)
+	out := new(DryRunChange)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GithubComment) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubCommentList) DeepCopyInto(out *GithubCommentList) {
+func (in *DryRunObjectInfo) DeepCopyInto(out *DryRunObjectInfo) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]GithubComment, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out.Ref = in.Ref
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]DryRunChange, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubCommentList.
-func (in *GithubCommentList) DeepCopy() *GithubCommentList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunObjectInfo.
+func (in *DryRunObjectInfo) DeepCopy() *DryRunObjectInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubCommentList)
+	out := new(DryRunObjectInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GithubCommentList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubCommentSpec) DeepCopyInto(out *GithubCommentSpec) {
+func (in *DryRunStatus) DeepCopyInto(out *DryRunStatus) {
 	*out = *in
-	in.GithubPullRequestRef.DeepCopyInto(&out.GithubPullRequestRef)
-	in.CommentSpec.DeepCopyInto(&out.CommentSpec)
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]DryRunObjectInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubCommentSpec.
-func (in *GithubCommentSpec) DeepCopy() *GithubCommentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunStatus.
+func (in *DryRunStatus) DeepCopy() *DryRunStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubCommentSpec)
+	out := new(DryRunStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubCommentStatus) DeepCopyInto(out *GithubCommentStatus) {
+func (in *EmailDestination) DeepCopyInto(out *EmailDestination) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsernameRef != nil {
+		in, out := &in.UsernameRef, &out.UsernameRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.PasswordRef != nil {
+		in, out := &in.PasswordRef, &out.PasswordRef
+		*out = new(SecretRef)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubCommentStatus.
-func (in *GithubCommentStatus) DeepCopy() *GithubCommentStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailDestination.
+func (in *EmailDestination) DeepCopy() *EmailDestination {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubCommentStatus)
+	out := new(EmailDestination)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubProject) DeepCopyInto(out *GithubProject) {
+func (in *FieldConflict) DeepCopyInto(out *FieldConflict) {
 	*out = *in
-	if in.TokenRef != nil {
-		in, out := &in.TokenRef, &out.TokenRef
-		*out = new(SecretRef)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldConflict.
+func (in *FieldConflict) DeepCopy() *FieldConflict {
+	if in == nil {
+		return nil
 	}
+	out := new(FieldConflict)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubProject.
-func (in *GithubProject) DeepCopy() *GithubProject {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitCommitInfo) DeepCopyInto(out *GitCommitInfo) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitCommitInfo.
+func (in *GitCommitInfo) DeepCopy() *GitCommitInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubProject)
+	out := new(GitCommitInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GithubPullRequestRef) DeepCopyInto(out *GithubPullRequestRef) {
+func (in *GitDirectory) DeepCopyInto(out *GitDirectory) {
 	*out = *in
-	in.GithubProject.DeepCopyInto(&out.GithubProject)
-	if in.PullRequestId != nil {
-		in, out := &in.PullRequestId, &out.PullRequestId
-		*out = new(intstr.IntOrString)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitDirectory.
+func (in *GitDirectory) DeepCopy() *GitDirectory {
+	if in == nil {
+		return nil
+	}
+	out := new(GitDirectory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitFile) DeepCopyInto(out *GitFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitFile.
+func (in *GitFile) DeepCopy() *GitFile {
+	if in == nil {
+		return nil
+	}
+	out := new(GitFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOutput) DeepCopyInto(out *GitOutput) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(LocalObjectReference)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubPullRequestRef.
-func (in *GithubPullRequestRef) DeepCopy() *GithubPullRequestRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOutput.
+func (in *GitOutput) DeepCopy() *GitOutput {
 	if in == nil {
 		return nil
 	}
-	out := new(GithubPullRequestRef)
+	out := new(GitOutput)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabComment) DeepCopyInto(out *GitlabComment) {
+func (in *GitOutputStatus) DeepCopyInto(out *GitOutputStatus) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOutputStatus.
+func (in *GitOutputStatus) DeepCopy() *GitOutputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOutputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitProjector) DeepCopyInto(out *GitProjector) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -460,18 +528,18 @@ func (in *GitlabComment) DeepCopyInto(out *GitlabComment) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabComment.
-func (in *GitlabComment) DeepCopy() *GitlabComment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjector.
+func (in *GitProjector) DeepCopy() *GitProjector {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabComment)
+	out := new(GitProjector)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitlabComment) DeepCopyObject() runtime.Object {
+func (in *GitProjector) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -479,31 +547,31 @@ func (in *GitlabComment) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabCommentList) DeepCopyInto(out *GitlabCommentList) {
+func (in *GitProjectorList) DeepCopyInto(out *GitProjectorList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GitlabComment, len(*in))
+		*out = make([]GitProjector, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabCommentList.
-func (in *GitlabCommentList) DeepCopy() *GitlabCommentList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorList.
+func (in *GitProjectorList) DeepCopy() *GitProjectorList {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabCommentList)
+	out := new(GitProjectorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GitlabCommentList) DeepCopyObject() runtime.Object {
+func (in *GitProjectorList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -511,208 +579,2689 @@ func (in *GitlabCommentList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabCommentSpec) DeepCopyInto(out *GitlabCommentSpec) {
+func (in *GitProjectorResult) DeepCopyInto(out *GitProjectorResult) {
 	*out = *in
-	in.GitlabMergeRequestRef.DeepCopyInto(&out.GitlabMergeRequestRef)
-	in.CommentSpec.DeepCopyInto(&out.CommentSpec)
+	out.Reference = in.Reference
+	if in.CommitInfo != nil {
+		in, out := &in.CommitInfo, &out.CommitInfo
+		*out = new(GitCommitInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]GitProjectorResultFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Directories != nil {
+		in, out := &in.Directories, &out.Directories
+		*out = make([]GitProjectorResultDirectory, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabCommentSpec.
-func (in *GitlabCommentSpec) DeepCopy() *GitlabCommentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorResult.
+func (in *GitProjectorResult) DeepCopy() *GitProjectorResult {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabCommentSpec)
+	out := new(GitProjectorResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabCommentStatus) DeepCopyInto(out *GitlabCommentStatus) {
+func (in *GitProjectorResultDirectory) DeepCopyInto(out *GitProjectorResultDirectory) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabCommentStatus.
-func (in *GitlabCommentStatus) DeepCopy() *GitlabCommentStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorResultDirectory.
+func (in *GitProjectorResultDirectory) DeepCopy() *GitProjectorResultDirectory {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabCommentStatus)
+	out := new(GitProjectorResultDirectory)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabMergeRequestRef) DeepCopyInto(out *GitlabMergeRequestRef) {
+func (in *GitProjectorResultFile) DeepCopyInto(out *GitProjectorResultFile) {
 	*out = *in
-	in.GitlabProject.DeepCopyInto(&out.GitlabProject)
-	if in.MergeRequestId != nil {
-		in, out := &in.MergeRequestId, &out.MergeRequestId
-		*out = new(intstr.IntOrString)
+	if in.Raw != nil {
+		in, out := &in.Raw, &out.Raw
+		*out = new(string)
 		**out = **in
 	}
+	if in.Parsed != nil {
+		in, out := &in.Parsed, &out.Parsed
+		*out = make([]*runtime.RawExtension, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(runtime.RawExtension)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabMergeRequestRef.
-func (in *GitlabMergeRequestRef) DeepCopy() *GitlabMergeRequestRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorResultFile.
+func (in *GitProjectorResultFile) DeepCopy() *GitProjectorResultFile {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabMergeRequestRef)
+	out := new(GitProjectorResultFile)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitlabProject) DeepCopyInto(out *GitlabProject) {
+func (in *GitProjectorSpec) DeepCopyInto(out *GitProjectorSpec) {
 	*out = *in
-	if in.Project != nil {
-		in, out := &in.Project, &out.Project
-		*out = new(intstr.IntOrString)
+	out.Interval = in.Interval
+	if in.Reference != nil {
+		in, out := &in.Reference, &out.Reference
+		*out = new(GitRef)
 		**out = **in
 	}
-	if in.API != nil {
-		in, out := &in.API, &out.API
-		*out = new(string)
+	if in.LatestN != nil {
+		in, out := &in.LatestN, &out.LatestN
+		*out = new(int)
 		**out = **in
 	}
-	if in.TokenRef != nil {
-		in, out := &in.TokenRef, &out.TokenRef
-		*out = new(SecretRef)
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]GitFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.Directories != nil {
+		in, out := &in.Directories, &out.Directories
+		*out = make([]GitDirectory, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(GitVerify)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabProject.
-func (in *GitlabProject) DeepCopy() *GitlabProject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorSpec.
+func (in *GitProjectorSpec) DeepCopy() *GitProjectorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitlabProject)
+	out := new(GitProjectorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Handler) DeepCopyInto(out *Handler) {
+func (in *GitProjectorStatus) DeepCopyInto(out *GitProjectorStatus) {
 	*out = *in
-	if in.PullRequestComment != nil {
-		in, out := &in.PullRequestComment, &out.PullRequestComment
-		*out = new(PullRequestCommentReporter)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PullRequestApprove != nil {
-		in, out := &in.PullRequestApprove, &out.PullRequestApprove
-		*out = new(PullRequestApproveReporter)
-		(*in).DeepCopyInto(*out)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.PullRequestCommand != nil {
-		in, out := &in.PullRequestCommand, &out.PullRequestCommand
-		*out = new(PullRequestCommandHandler)
-		(*in).DeepCopyInto(*out)
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = make([]GitProjectorResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Handler.
-func (in *Handler) DeepCopy() *Handler {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitProjectorStatus.
+func (in *GitProjectorStatus) DeepCopy() *GitProjectorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Handler)
+	out := new(GitProjectorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HandlerStatus) DeepCopyInto(out *HandlerStatus) {
+func (in *GitRef) DeepCopyInto(out *GitRef) {
 	*out = *in
-	if in.PullRequestComment != nil {
-		in, out := &in.PullRequestComment, &out.PullRequestComment
-		*out = new(PullRequestCommentReporterStatus)
-		**out = **in
-	}
-	if in.PullRequestApprove != nil {
-		in, out := &in.PullRequestApprove, &out.PullRequestApprove
-		*out = new(PullRequestApproveReporterStatus)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PullRequestCommand != nil {
-		in, out := &in.PullRequestCommand, &out.PullRequestCommand
-		*out = new(PullRequestCommandHandlerStatus)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HandlerStatus.
-func (in *HandlerStatus) DeepCopy() *HandlerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRef.
+func (in *GitRef) DeepCopy() *GitRef {
 	if in == nil {
 		return nil
 	}
-	out := new(HandlerStatus)
+	out := new(GitRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGithubPullRequests) DeepCopyInto(out *ListGithubPullRequests) {
+func (in *GitRepositoryTemplateSource) DeepCopyInto(out *GitRepositoryTemplateSource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequests.
-func (in *ListGithubPullRequests) DeepCopy() *ListGithubPullRequests {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepositoryTemplateSource.
+func (in *GitRepositoryTemplateSource) DeepCopy() *GitRepositoryTemplateSource {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGithubPullRequests)
+	out := new(GitRepositoryTemplateSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ListGithubPullRequests) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGithubPullRequestsList) DeepCopyInto(out *ListGithubPullRequestsList) {
+func (in *GitVerify) DeepCopyInto(out *GitVerify) {
+	*out = *in
+	out.PublicKeysRef = in.PublicKeysRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitVerify.
+func (in *GitVerify) DeepCopy() *GitVerify {
+	if in == nil {
+		return nil
+	}
+	out := new(GitVerify)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GiteaProject) DeepCopyInto(out *GiteaProject) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaProject.
+func (in *GiteaProject) DeepCopy() *GiteaProject {
+	if in == nil {
+		return nil
+	}
+	out := new(GiteaProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubApp) DeepCopyInto(out *GithubApp) {
+	*out = *in
+	out.PrivateKeyRef = in.PrivateKeyRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubApp.
+func (in *GithubApp) DeepCopy() *GithubApp {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubApp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubComment) DeepCopyInto(out *GithubComment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubComment.
+func (in *GithubComment) DeepCopy() *GithubComment {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubComment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubComment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubCommentList) DeepCopyInto(out *GithubCommentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GithubComment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubCommentList.
+func (in *GithubCommentList) DeepCopy() *GithubCommentList {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubCommentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GithubCommentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubCommentSpec) DeepCopyInto(out *GithubCommentSpec) {
+	*out = *in
+	in.GithubPullRequestRef.DeepCopyInto(&out.GithubPullRequestRef)
+	in.CommentSpec.DeepCopyInto(&out.CommentSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubCommentSpec.
+func (in *GithubCommentSpec) DeepCopy() *GithubCommentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubCommentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubCommentStatus) DeepCopyInto(out *GithubCommentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubCommentStatus.
+func (in *GithubCommentStatus) DeepCopy() *GithubCommentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubCommentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubProject) DeepCopyInto(out *GithubProject) {
+	*out = *in
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(string)
+		**out = **in
+	}
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.App != nil {
+		in, out := &in.App, &out.App
+		*out = new(GithubApp)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubProject.
+func (in *GithubProject) DeepCopy() *GithubProject {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubPullRequestRef) DeepCopyInto(out *GithubPullRequestRef) {
+	*out = *in
+	in.GithubProject.DeepCopyInto(&out.GithubProject)
+	if in.PullRequestId != nil {
+		in, out := &in.PullRequestId, &out.PullRequestId
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubPullRequestRef.
+func (in *GithubPullRequestRef) DeepCopy() *GithubPullRequestRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubPullRequestRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabAuth) DeepCopyInto(out *GitlabAuth) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.JobTokenRef != nil {
+		in, out := &in.JobTokenRef, &out.JobTokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(GitlabOIDC)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabAuth.
+func (in *GitlabAuth) DeepCopy() *GitlabAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabComment) DeepCopyInto(out *GitlabComment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabComment.
+func (in *GitlabComment) DeepCopy() *GitlabComment {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabComment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitlabComment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabCommentList) DeepCopyInto(out *GitlabCommentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitlabComment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabCommentList.
+func (in *GitlabCommentList) DeepCopy() *GitlabCommentList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabCommentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitlabCommentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabCommentSpec) DeepCopyInto(out *GitlabCommentSpec) {
+	*out = *in
+	in.GitlabMergeRequestRef.DeepCopyInto(&out.GitlabMergeRequestRef)
+	in.CommentSpec.DeepCopyInto(&out.CommentSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabCommentSpec.
+func (in *GitlabCommentSpec) DeepCopy() *GitlabCommentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabCommentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabCommentStatus) DeepCopyInto(out *GitlabCommentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabCommentStatus.
+func (in *GitlabCommentStatus) DeepCopy() *GitlabCommentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabCommentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabGroup) DeepCopyInto(out *GitlabGroup) {
+	*out = *in
+	if in.IncludeProjects != nil {
+		in, out := &in.IncludeProjects, &out.IncludeProjects
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExcludeProjects != nil {
+		in, out := &in.ExcludeProjects, &out.ExcludeProjects
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabGroup.
+func (in *GitlabGroup) DeepCopy() *GitlabGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabMergeRequestRef) DeepCopyInto(out *GitlabMergeRequestRef) {
+	*out = *in
+	in.GitlabProject.DeepCopyInto(&out.GitlabProject)
+	if in.MergeRequestId != nil {
+		in, out := &in.MergeRequestId, &out.MergeRequestId
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabMergeRequestRef.
+func (in *GitlabMergeRequestRef) DeepCopy() *GitlabMergeRequestRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabMergeRequestRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabOIDC) DeepCopyInto(out *GitlabOIDC) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabOIDC.
+func (in *GitlabOIDC) DeepCopy() *GitlabOIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabOIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabProject) DeepCopyInto(out *GitlabProject) {
+	*out = *in
+	if in.Project != nil {
+		in, out := &in.Project, &out.Project
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(string)
+		**out = **in
+	}
+	in.GitlabAuth.DeepCopyInto(&out.GitlabAuth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabProject.
+func (in *GitlabProject) DeepCopy() *GitlabProject {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitlabProjectOrGroup) DeepCopyInto(out *GitlabProjectOrGroup) {
+	*out = *in
+	if in.Project != nil {
+		in, out := &in.Project, &out.Project
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(GitlabGroup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(string)
+		**out = **in
+	}
+	in.GitlabAuth.DeepCopyInto(&out.GitlabAuth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitlabProjectOrGroup.
+func (in *GitlabProjectOrGroup) DeepCopy() *GitlabProjectOrGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(GitlabProjectOrGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSecretHeader) DeepCopyInto(out *HTTPSecretHeader) {
+	*out = *in
+	out.ValueRef = in.ValueRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSecretHeader.
+func (in *HTTPSecretHeader) DeepCopy() *HTTPSecretHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSecretHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Handler) DeepCopyInto(out *Handler) {
+	*out = *in
+	if in.PullRequestComment != nil {
+		in, out := &in.PullRequestComment, &out.PullRequestComment
+		*out = new(PullRequestCommentReporter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullRequestApprove != nil {
+		in, out := &in.PullRequestApprove, &out.PullRequestApprove
+		*out = new(PullRequestApproveReporter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullRequestCommand != nil {
+		in, out := &in.PullRequestCommand, &out.PullRequestCommand
+		*out = new(PullRequestCommandHandler)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Handler.
+func (in *Handler) DeepCopy() *Handler {
+	if in == nil {
+		return nil
+	}
+	out := new(Handler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HandlerStatus) DeepCopyInto(out *HandlerStatus) {
+	*out = *in
+	if in.PullRequestComment != nil {
+		in, out := &in.PullRequestComment, &out.PullRequestComment
+		*out = new(PullRequestCommentReporterStatus)
+		**out = **in
+	}
+	if in.PullRequestApprove != nil {
+		in, out := &in.PullRequestApprove, &out.PullRequestApprove
+		*out = new(PullRequestApproveReporterStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullRequestCommand != nil {
+		in, out := &in.PullRequestCommand, &out.PullRequestCommand
+		*out = new(PullRequestCommandHandlerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HandlerStatus.
+func (in *HandlerStatus) DeepCopy() *HandlerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HandlerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibraryTemplate) DeepCopyInto(out *LibraryTemplate) {
+	*out = *in
+	if in.Macros != nil {
+		in, out := &in.Macros, &out.Macros
+		*out = new(string)
+		**out = **in
+	}
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibraryTemplate.
+func (in *LibraryTemplate) DeepCopy() *LibraryTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(LibraryTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibraryTemplateRef) DeepCopyInto(out *LibraryTemplateRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibraryTemplateRef.
+func (in *LibraryTemplateRef) DeepCopy() *LibraryTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(LibraryTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListAzureDevOpsPullRequests) DeepCopyInto(out *ListAzureDevOpsPullRequests) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListAzureDevOpsPullRequests.
+func (in *ListAzureDevOpsPullRequests) DeepCopy() *ListAzureDevOpsPullRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ListAzureDevOpsPullRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListAzureDevOpsPullRequests) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListAzureDevOpsPullRequestsList) DeepCopyInto(out *ListAzureDevOpsPullRequestsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListAzureDevOpsPullRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListAzureDevOpsPullRequestsList.
+func (in *ListAzureDevOpsPullRequestsList) DeepCopy() *ListAzureDevOpsPullRequestsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListAzureDevOpsPullRequestsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListAzureDevOpsPullRequestsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListAzureDevOpsPullRequestsSpec) DeepCopyInto(out *ListAzureDevOpsPullRequestsSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	in.AzureDevOpsProject.DeepCopyInto(&out.AzureDevOpsProject)
+	if in.SourceBranch != nil {
+		in, out := &in.SourceBranch, &out.SourceBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetBranch != nil {
+		in, out := &in.TargetBranch, &out.TargetBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PullRequestFilters.DeepCopyInto(&out.PullRequestFilters)
+	if in.HashFields != nil {
+		in, out := &in.HashFields, &out.HashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListAzureDevOpsPullRequestsSpec.
+func (in *ListAzureDevOpsPullRequestsSpec) DeepCopy() *ListAzureDevOpsPullRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListAzureDevOpsPullRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListAzureDevOpsPullRequestsStatus) DeepCopyInto(out *ListAzureDevOpsPullRequestsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListAzureDevOpsPullRequestsStatus.
+func (in *ListAzureDevOpsPullRequestsStatus) DeepCopy() *ListAzureDevOpsPullRequestsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListAzureDevOpsPullRequestsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketCloudPullRequests) DeepCopyInto(out *ListBitbucketCloudPullRequests) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketCloudPullRequests.
+func (in *ListBitbucketCloudPullRequests) DeepCopy() *ListBitbucketCloudPullRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketCloudPullRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListBitbucketCloudPullRequests) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketCloudPullRequestsList) DeepCopyInto(out *ListBitbucketCloudPullRequestsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListBitbucketCloudPullRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketCloudPullRequestsList.
+func (in *ListBitbucketCloudPullRequestsList) DeepCopy() *ListBitbucketCloudPullRequestsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketCloudPullRequestsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListBitbucketCloudPullRequestsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketCloudPullRequestsSpec) DeepCopyInto(out *ListBitbucketCloudPullRequestsSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	in.BitbucketCloudProject.DeepCopyInto(&out.BitbucketCloudProject)
+	if in.SourceBranch != nil {
+		in, out := &in.SourceBranch, &out.SourceBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetBranch != nil {
+		in, out := &in.TargetBranch, &out.TargetBranch
+		*out = new(string)
+		**out = **in
+	}
+	in.PullRequestFilters.DeepCopyInto(&out.PullRequestFilters)
+	if in.HashFields != nil {
+		in, out := &in.HashFields, &out.HashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketCloudPullRequestsSpec.
+func (in *ListBitbucketCloudPullRequestsSpec) DeepCopy() *ListBitbucketCloudPullRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketCloudPullRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketCloudPullRequestsStatus) DeepCopyInto(out *ListBitbucketCloudPullRequestsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketCloudPullRequestsStatus.
+func (in *ListBitbucketCloudPullRequestsStatus) DeepCopy() *ListBitbucketCloudPullRequestsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketCloudPullRequestsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketServerPullRequests) DeepCopyInto(out *ListBitbucketServerPullRequests) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketServerPullRequests.
+func (in *ListBitbucketServerPullRequests) DeepCopy() *ListBitbucketServerPullRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketServerPullRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListBitbucketServerPullRequests) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketServerPullRequestsList) DeepCopyInto(out *ListBitbucketServerPullRequestsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListBitbucketServerPullRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketServerPullRequestsList.
+func (in *ListBitbucketServerPullRequestsList) DeepCopy() *ListBitbucketServerPullRequestsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketServerPullRequestsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListBitbucketServerPullRequestsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketServerPullRequestsSpec) DeepCopyInto(out *ListBitbucketServerPullRequestsSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	in.BitbucketServerProject.DeepCopyInto(&out.BitbucketServerProject)
+	if in.SourceBranch != nil {
+		in, out := &in.SourceBranch, &out.SourceBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetBranch != nil {
+		in, out := &in.TargetBranch, &out.TargetBranch
+		*out = new(string)
+		**out = **in
+	}
+	in.PullRequestFilters.DeepCopyInto(&out.PullRequestFilters)
+	if in.HashFields != nil {
+		in, out := &in.HashFields, &out.HashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketServerPullRequestsSpec.
+func (in *ListBitbucketServerPullRequestsSpec) DeepCopy() *ListBitbucketServerPullRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketServerPullRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListBitbucketServerPullRequestsStatus) DeepCopyInto(out *ListBitbucketServerPullRequestsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListBitbucketServerPullRequestsStatus.
+func (in *ListBitbucketServerPullRequestsStatus) DeepCopy() *ListBitbucketServerPullRequestsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListBitbucketServerPullRequestsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGiteaPullRequests) DeepCopyInto(out *ListGiteaPullRequests) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGiteaPullRequests.
+func (in *ListGiteaPullRequests) DeepCopy() *ListGiteaPullRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGiteaPullRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListGiteaPullRequests) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGiteaPullRequestsList) DeepCopyInto(out *ListGiteaPullRequestsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListGiteaPullRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGiteaPullRequestsList.
+func (in *ListGiteaPullRequestsList) DeepCopy() *ListGiteaPullRequestsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGiteaPullRequestsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListGiteaPullRequestsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGiteaPullRequestsSpec) DeepCopyInto(out *ListGiteaPullRequestsSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	in.GiteaProject.DeepCopyInto(&out.GiteaProject)
+	if in.Head != nil {
+		in, out := &in.Head, &out.Head
+		*out = new(string)
+		**out = **in
+	}
+	if in.Base != nil {
+		in, out := &in.Base, &out.Base
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PullRequestFilters.DeepCopyInto(&out.PullRequestFilters)
+	if in.HashFields != nil {
+		in, out := &in.HashFields, &out.HashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGiteaPullRequestsSpec.
+func (in *ListGiteaPullRequestsSpec) DeepCopy() *ListGiteaPullRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGiteaPullRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGiteaPullRequestsStatus) DeepCopyInto(out *ListGiteaPullRequestsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGiteaPullRequestsStatus.
+func (in *ListGiteaPullRequestsStatus) DeepCopy() *ListGiteaPullRequestsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGiteaPullRequestsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGithubPullRequests) DeepCopyInto(out *ListGithubPullRequests) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequests.
+func (in *ListGithubPullRequests) DeepCopy() *ListGithubPullRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGithubPullRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListGithubPullRequests) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGithubPullRequestsList) DeepCopyInto(out *ListGithubPullRequestsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListGithubPullRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequestsList.
+func (in *ListGithubPullRequestsList) DeepCopy() *ListGithubPullRequestsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGithubPullRequestsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListGithubPullRequestsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGithubPullRequestsSpec) DeepCopyInto(out *ListGithubPullRequestsSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	in.GithubProject.DeepCopyInto(&out.GithubProject)
+	if in.Head != nil {
+		in, out := &in.Head, &out.Head
+		*out = new(string)
+		**out = **in
+	}
+	if in.Base != nil {
+		in, out := &in.Base, &out.Base
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PullRequestFilters.DeepCopyInto(&out.PullRequestFilters)
+	if in.HashFields != nil {
+		in, out := &in.HashFields, &out.HashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequestsSpec.
+func (in *ListGithubPullRequestsSpec) DeepCopy() *ListGithubPullRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGithubPullRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGithubPullRequestsStatus) DeepCopyInto(out *ListGithubPullRequestsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequestsStatus.
+func (in *ListGithubPullRequestsStatus) DeepCopy() *ListGithubPullRequestsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGithubPullRequestsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGitlabMergeRequests) DeepCopyInto(out *ListGitlabMergeRequests) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequests.
+func (in *ListGitlabMergeRequests) DeepCopy() *ListGitlabMergeRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGitlabMergeRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListGitlabMergeRequests) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGitlabMergeRequestsList) DeepCopyInto(out *ListGitlabMergeRequestsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListGitlabMergeRequests, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequestsList.
+func (in *ListGitlabMergeRequestsList) DeepCopy() *ListGitlabMergeRequestsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGitlabMergeRequestsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListGitlabMergeRequestsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGitlabMergeRequestsSpec) DeepCopyInto(out *ListGitlabMergeRequestsSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	in.GitlabProjectOrGroup.DeepCopyInto(&out.GitlabProjectOrGroup)
+	if in.TargetBranch != nil {
+		in, out := &in.TargetBranch, &out.TargetBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.SourceBranch != nil {
+		in, out := &in.SourceBranch, &out.SourceBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.State != nil {
+		in, out := &in.State, &out.State
+		*out = new(string)
+		**out = **in
+	}
+	in.PullRequestFilters.DeepCopyInto(&out.PullRequestFilters)
+	if in.HashFields != nil {
+		in, out := &in.HashFields, &out.HashFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequestsSpec.
+func (in *ListGitlabMergeRequestsSpec) DeepCopy() *ListGitlabMergeRequestsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGitlabMergeRequestsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListGitlabMergeRequestsStatus) DeepCopyInto(out *ListGitlabMergeRequestsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MergeRequests != nil {
+		in, out := &in.MergeRequests, &out.MergeRequests
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequestsStatus.
+func (in *ListGitlabMergeRequestsStatus) DeepCopy() *ListGitlabMergeRequestsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListGitlabMergeRequestsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListRepositories) DeepCopyInto(out *ListRepositories) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListRepositories.
+func (in *ListRepositories) DeepCopy() *ListRepositories {
+	if in == nil {
+		return nil
+	}
+	out := new(ListRepositories)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListRepositories) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListRepositoriesGithub) DeepCopyInto(out *ListRepositoriesGithub) {
+	*out = *in
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(string)
+		**out = **in
+	}
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.App != nil {
+		in, out := &in.App, &out.App
+		*out = new(GithubApp)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListRepositoriesGithub.
+func (in *ListRepositoriesGithub) DeepCopy() *ListRepositoriesGithub {
+	if in == nil {
+		return nil
+	}
+	out := new(ListRepositoriesGithub)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListRepositoriesGitlab) DeepCopyInto(out *ListRepositoriesGitlab) {
+	*out = *in
+	if in.API != nil {
+		in, out := &in.API, &out.API
+		*out = new(string)
+		**out = **in
+	}
+	in.GitlabAuth.DeepCopyInto(&out.GitlabAuth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListRepositoriesGitlab.
+func (in *ListRepositoriesGitlab) DeepCopy() *ListRepositoriesGitlab {
+	if in == nil {
+		return nil
+	}
+	out := new(ListRepositoriesGitlab)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListRepositoriesList) DeepCopyInto(out *ListRepositoriesList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ListRepositories, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListRepositoriesList.
+func (in *ListRepositoriesList) DeepCopy() *ListRepositoriesList {
+	if in == nil {
+		return nil
+	}
+	out := new(ListRepositoriesList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ListRepositoriesList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListRepositoriesSpec) DeepCopyInto(out *ListRepositoriesSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	if in.Github != nil {
+		in, out := &in.Github, &out.Github
+		*out = new(ListRepositoriesGithub)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gitlab != nil {
+		in, out := &in.Gitlab, &out.Gitlab
+		*out = new(ListRepositoriesGitlab)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExcludePath != nil {
+		in, out := &in.ExcludePath, &out.ExcludePath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListRepositoriesSpec.
+func (in *ListRepositoriesSpec) DeepCopy() *ListRepositoriesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ListRepositoriesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListRepositoriesStatus) DeepCopyInto(out *ListRepositoriesStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]RepositoryInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListRepositoriesStatus.
+func (in *ListRepositoriesStatus) DeepCopy() *ListRepositoriesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ListRepositoriesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalObjectReference) DeepCopyInto(out *LocalObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalObjectReference.
+func (in *LocalObjectReference) DeepCopy() *LocalObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntry) DeepCopyInto(out *MatrixEntry) {
+	*out = *in
+	if in.Object != nil {
+		in, out := &in.Object, &out.Object
+		*out = new(MatrixEntryObject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.List != nil {
+		in, out := &in.List, &out.List
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = new(MatrixEntryClusters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(MatrixEntryData)
+		**out = **in
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(MatrixEntryData)
+		**out = **in
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = new(MatrixEntryObjects)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(MatrixEntrySchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugin != nil {
+		in, out := &in.Plugin, &out.Plugin
+		*out = new(MatrixEntryPlugin)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageTags != nil {
+		in, out := &in.ImageTags, &out.ImageTags
+		*out = new(MatrixEntryImageTags)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(MatrixEntryHTTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GraphQL != nil {
+		in, out := &in.GraphQL, &out.GraphQL
+		*out = new(MatrixEntryGraphQL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(MatrixEntryVault)
+		**out = **in
+	}
+	if in.AWS != nil {
+		in, out := &in.AWS, &out.AWS
+		*out = new(MatrixEntryAWS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = new(MatrixEntryNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(MatrixEntryNodes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Merge != nil {
+		in, out := &in.Merge, &out.Merge
+		*out = new(MatrixEntryMerge)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transform != nil {
+		in, out := &in.Transform, &out.Transform
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntry.
+func (in *MatrixEntry) DeepCopy() *MatrixEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryAWS) DeepCopyInto(out *MatrixEntryAWS) {
+	*out = *in
+	if in.SecretsManager != nil {
+		in, out := &in.SecretsManager, &out.SecretsManager
+		*out = new(AWSSecretsManagerSource)
+		**out = **in
+	}
+	if in.ParameterStore != nil {
+		in, out := &in.ParameterStore, &out.ParameterStore
+		*out = new(AWSParameterStoreSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryAWS.
+func (in *MatrixEntryAWS) DeepCopy() *MatrixEntryAWS {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryAWS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryClusters) DeepCopyInto(out *MatrixEntryClusters) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryClusters.
+func (in *MatrixEntryClusters) DeepCopy() *MatrixEntryClusters {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryClusters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryData) DeepCopyInto(out *MatrixEntryData) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryData.
+func (in *MatrixEntryData) DeepCopy() *MatrixEntryData {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryGraphQL) DeepCopyInto(out *MatrixEntryGraphQL) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretHeaders != nil {
+		in, out := &in.SecretHeaders, &out.SecretHeaders
+		*out = make([]HTTPSecretHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryGraphQL.
+func (in *MatrixEntryGraphQL) DeepCopy() *MatrixEntryGraphQL {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryGraphQL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryHTTP) DeepCopyInto(out *MatrixEntryHTTP) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretHeaders != nil {
+		in, out := &in.SecretHeaders, &out.SecretHeaders
+		*out = make([]HTTPSecretHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.JsonPath != nil {
+		in, out := &in.JsonPath, &out.JsonPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryHTTP.
+func (in *MatrixEntryHTTP) DeepCopy() *MatrixEntryHTTP {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryHTTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryImageTags) DeepCopyInto(out *MatrixEntryImageTags) {
+	*out = *in
+	if in.Regex != nil {
+		in, out := &in.Regex, &out.Regex
+		*out = new(string)
+		**out = **in
+	}
+	if in.SemVer != nil {
+		in, out := &in.SemVer, &out.SemVer
+		*out = new(string)
+		**out = **in
+	}
+	if in.LatestN != nil {
+		in, out := &in.LatestN, &out.LatestN
+		*out = new(int)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryImageTags.
+func (in *MatrixEntryImageTags) DeepCopy() *MatrixEntryImageTags {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryImageTags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryMerge) DeepCopyInto(out *MatrixEntryMerge) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]runtime.RawExtension, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MergeKeys != nil {
+		in, out := &in.MergeKeys, &out.MergeKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryMerge.
+func (in *MatrixEntryMerge) DeepCopy() *MatrixEntryMerge {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryMerge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryNamespaces) DeepCopyInto(out *MatrixEntryNamespaces) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryNamespaces.
+func (in *MatrixEntryNamespaces) DeepCopy() *MatrixEntryNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryNodes) DeepCopyInto(out *MatrixEntryNodes) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryNodes.
+func (in *MatrixEntryNodes) DeepCopy() *MatrixEntryNodes {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryNodes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryObject) DeepCopyInto(out *MatrixEntryObject) {
+	*out = *in
+	out.Ref = in.Ref
+	if in.JsonPath != nil {
+		in, out := &in.JsonPath, &out.JsonPath
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryObject.
+func (in *MatrixEntryObject) DeepCopy() *MatrixEntryObject {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryObjects) DeepCopyInto(out *MatrixEntryObjects) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JsonPath != nil {
+		in, out := &in.JsonPath, &out.JsonPath
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryObjects.
+func (in *MatrixEntryObjects) DeepCopy() *MatrixEntryObjects {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryObjects)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryPlugin) DeepCopyInto(out *MatrixEntryPlugin) {
+	*out = *in
+	if in.Input != nil {
+		in, out := &in.Input, &out.Input
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuth)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryPlugin.
+func (in *MatrixEntryPlugin) DeepCopy() *MatrixEntryPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntrySchedule) DeepCopyInto(out *MatrixEntrySchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntrySchedule.
+func (in *MatrixEntrySchedule) DeepCopy() *MatrixEntrySchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntrySchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixEntryVault) DeepCopyInto(out *MatrixEntryVault) {
+	*out = *in
+	out.KubernetesAuth = in.KubernetesAuth
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryVault.
+func (in *MatrixEntryVault) DeepCopy() *MatrixEntryVault {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixEntryVault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationDestination) DeepCopyInto(out *NotificationDestination) {
+	*out = *in
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackDestination)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookDestination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailDestination)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationDestination.
+func (in *NotificationDestination) DeepCopy() *NotificationDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationObjectStatus) DeepCopyInto(out *NotificationObjectStatus) {
+	*out = *in
+	out.Ref = in.Ref
+	if in.LastNotificationTime != nil {
+		in, out := &in.LastNotificationTime, &out.LastNotificationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationObjectStatus.
+func (in *NotificationObjectStatus) DeepCopy() *NotificationObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationPolicy) DeepCopyInto(out *NotificationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationPolicy.
+func (in *NotificationPolicy) DeepCopy() *NotificationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationPolicyList) DeepCopyInto(out *NotificationPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NotificationPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationPolicyList.
+func (in *NotificationPolicyList) DeepCopy() *NotificationPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationPolicySpec) DeepCopyInto(out *NotificationPolicySpec) {
+	*out = *in
+	out.Interval = in.Interval
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]NotificationSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]NotificationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]NotificationDestination, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Throttle = in.Throttle
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationPolicySpec.
+func (in *NotificationPolicySpec) DeepCopy() *NotificationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationPolicyStatus) DeepCopyInto(out *NotificationPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ObservedObjects != nil {
+		in, out := &in.ObservedObjects, &out.ObservedObjects
+		*out = make([]NotificationObjectStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationPolicyStatus.
+func (in *NotificationPolicyStatus) DeepCopy() *NotificationPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationRule) DeepCopyInto(out *NotificationRule) {
+	*out = *in
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(v1.ConditionStatus)
+		**out = **in
+	}
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationRule.
+func (in *NotificationRule) DeepCopy() *NotificationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSource) DeepCopyInto(out *NotificationSource) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSource.
+func (in *NotificationSource) DeepCopy() *NotificationSource {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIArtifactInfo) DeepCopyInto(out *OCIArtifactInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIArtifactInfo.
+func (in *OCIArtifactInfo) DeepCopy() *OCIArtifactInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIArtifactInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIOutput) DeepCopyInto(out *OCIOutput) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIOutput.
+func (in *OCIOutput) DeepCopy() *OCIOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIOutputStatus) DeepCopyInto(out *OCIOutputStatus) {
+	*out = *in
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]OCIArtifactInfo, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIOutputStatus.
+func (in *OCIOutputStatus) DeepCopy() *OCIOutputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIOutputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCITemplateSource) DeepCopyInto(out *OCITemplateSource) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(LocalObjectReference)
+		**out = **in
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(OCIVerify)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCITemplateSource.
+func (in *OCITemplateSource) DeepCopy() *OCITemplateSource {
+	if in == nil {
+		return nil
+	}
+	out := new(OCITemplateSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIVerify) DeepCopyInto(out *OCIVerify) {
+	*out = *in
+	out.PublicKeysRef = in.PublicKeysRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIVerify.
+func (in *OCIVerify) DeepCopy() *OCIVerify {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIVerify)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectHandler) DeepCopyInto(out *ObjectHandler) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandler.
+func (in *ObjectHandler) DeepCopy() *ObjectHandler {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectHandler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ObjectHandler) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectHandlerList) DeepCopyInto(out *ObjectHandlerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ObjectHandler, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandlerList.
+func (in *ObjectHandlerList) DeepCopy() *ObjectHandlerList {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectHandlerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ObjectHandlerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectHandlerSpec) DeepCopyInto(out *ObjectHandlerSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	out.ForObject = in.ForObject
+	if in.Handlers != nil {
+		in, out := &in.Handlers, &out.Handlers
+		*out = make([]Handler, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandlerSpec.
+func (in *ObjectHandlerSpec) DeepCopy() *ObjectHandlerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectHandlerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectHandlerStatus) DeepCopyInto(out *ObjectHandlerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HandlerStatus != nil {
+		in, out := &in.HandlerStatus, &out.HandlerStatus
+		*out = make([]*HandlerStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(HandlerStatus)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(ObjectHealth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandlerStatus.
+func (in *ObjectHandlerStatus) DeepCopy() *ObjectHandlerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectHandlerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectHealth) DeepCopyInto(out *ObjectHealth) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceHealth, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHealth.
+func (in *ObjectHealth) DeepCopy() *ObjectHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectRef) DeepCopyInto(out *ObjectRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectRef.
+func (in *ObjectRef) DeepCopy() *ObjectRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectTemplate) DeepCopyInto(out *ObjectTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplate.
+func (in *ObjectTemplate) DeepCopy() *ObjectTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ObjectTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectTemplateList) DeepCopyInto(out *ObjectTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ListGithubPullRequests, len(*in))
+		*out = make([]ObjectTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequestsList.
-func (in *ListGithubPullRequestsList) DeepCopy() *ListGithubPullRequestsList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateList.
+func (in *ObjectTemplateList) DeepCopy() *ObjectTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGithubPullRequestsList)
+	out := new(ObjectTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ListGithubPullRequestsList) DeepCopyObject() runtime.Object {
+func (in *ObjectTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -720,39 +3269,117 @@ func (in *ListGithubPullRequestsList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGithubPullRequestsSpec) DeepCopyInto(out *ListGithubPullRequestsSpec) {
+func (in *ObjectTemplateSchedule) DeepCopyInto(out *ObjectTemplateSchedule) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ActiveWindows != nil {
+		in, out := &in.ActiveWindows, &out.ActiveWindows
+		*out = make([]ScheduleWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateSchedule.
+func (in *ObjectTemplateSchedule) DeepCopy() *ObjectTemplateSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectTemplateSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectTemplateSpec) DeepCopyInto(out *ObjectTemplateSpec) {
 	*out = *in
 	out.Interval = in.Interval
-	in.GithubProject.DeepCopyInto(&out.GithubProject)
-	if in.Head != nil {
-		in, out := &in.Head, &out.Head
-		*out = new(string)
+	if in.DriftDetectionInterval != nil {
+		in, out := &in.DriftDetectionInterval, &out.DriftDetectionInterval
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.Base != nil {
-		in, out := &in.Base, &out.Base
-		*out = new(string)
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ObjectTemplateSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitOutput != nil {
+		in, out := &in.GitOutput, &out.GitOutput
+		*out = new(GitOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCIOutput != nil {
+		in, out := &in.OCIOutput, &out.OCIOutput
+		*out = new(OCIOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Delimiters != nil {
+		in, out := &in.Delimiters, &out.Delimiters
+		*out = new(TemplateDelimiters)
 		**out = **in
 	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]TemplateInclude, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = make([]TemplateVarsSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Decryption != nil {
+		in, out := &in.Decryption, &out.Decryption
+		*out = new(Decryption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make([]*MatrixEntry, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(MatrixEntry)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]Template, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequestsSpec.
-func (in *ListGithubPullRequestsSpec) DeepCopy() *ListGithubPullRequestsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateSpec.
+func (in *ObjectTemplateSpec) DeepCopy() *ObjectTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGithubPullRequestsSpec)
+	out := new(ObjectTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGithubPullRequestsStatus) DeepCopyInto(out *ListGithubPullRequestsStatus) {
+func (in *ObjectTemplateStatus) DeepCopyInto(out *ObjectTemplateStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -761,215 +3388,339 @@ func (in *ListGithubPullRequestsStatus) DeepCopyInto(out *ListGithubPullRequests
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.PullRequests != nil {
-		in, out := &in.PullRequests, &out.PullRequests
-		*out = make([]runtime.RawExtension, len(*in))
+	if in.AppliedResources != nil {
+		in, out := &in.AppliedResources, &out.AppliedResources
+		*out = make([]AppliedResourceInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitOutput != nil {
+		in, out := &in.GitOutput, &out.GitOutput
+		*out = new(GitOutputStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCIOutput != nil {
+		in, out := &in.OCIOutput, &out.OCIOutput
+		*out = new(OCIOutputStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastDriftCheckTime != nil {
+		in, out := &in.LastDriftCheckTime, &out.LastDriftCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingApply != nil {
+		in, out := &in.PendingApply, &out.PendingApply
+		*out = new(PendingApplyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateStatus.
+func (in *ObjectTemplateStatus) DeepCopy() *ObjectTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingApplyStatus) DeepCopyInto(out *PendingApplyStatus) {
+	*out = *in
+	if in.Wave != nil {
+		in, out := &in.Wave, &out.Wave
+		*out = new(int)
+		**out = **in
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotReady != nil {
+		in, out := &in.NotReady, &out.NotReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingApplyStatus.
+func (in *PendingApplyStatus) DeepCopy() *PendingApplyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingApplyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestApproveReporter) DeepCopyInto(out *PullRequestApproveReporter) {
+	*out = *in
+	in.PullRequestRefHolder.DeepCopyInto(&out.PullRequestRefHolder)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestApproveReporter.
+func (in *PullRequestApproveReporter) DeepCopy() *PullRequestApproveReporter {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestApproveReporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestApproveReporterStatus) DeepCopyInto(out *PullRequestApproveReporterStatus) {
+	*out = *in
+	if in.Approved != nil {
+		in, out := &in.Approved, &out.Approved
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestApproveReporterStatus.
+func (in *PullRequestApproveReporterStatus) DeepCopy() *PullRequestApproveReporterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestApproveReporterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestCommandHandler) DeepCopyInto(out *PullRequestCommandHandler) {
+	*out = *in
+	in.PullRequestRefHolder.DeepCopyInto(&out.PullRequestRefHolder)
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make([]PullRequestCommandHandlerCommandSpec, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGithubPullRequestsStatus.
-func (in *ListGithubPullRequestsStatus) DeepCopy() *ListGithubPullRequestsStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandler.
+func (in *PullRequestCommandHandler) DeepCopy() *PullRequestCommandHandler {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGithubPullRequestsStatus)
+	out := new(PullRequestCommandHandler)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGitlabMergeRequests) DeepCopyInto(out *ListGitlabMergeRequests) {
+func (in *PullRequestCommandHandlerActionAnnotateSpec) DeepCopyInto(out *PullRequestCommandHandlerActionAnnotateSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequests.
-func (in *ListGitlabMergeRequests) DeepCopy() *ListGitlabMergeRequests {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerActionAnnotateSpec.
+func (in *PullRequestCommandHandlerActionAnnotateSpec) DeepCopy() *PullRequestCommandHandlerActionAnnotateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGitlabMergeRequests)
+	out := new(PullRequestCommandHandlerActionAnnotateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ListGitlabMergeRequests) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestCommandHandlerActionSpec) DeepCopyInto(out *PullRequestCommandHandlerActionSpec) {
+	*out = *in
+	if in.Annotate != nil {
+		in, out := &in.Annotate, &out.Annotate
+		*out = new(PullRequestCommandHandlerActionAnnotateSpec)
+		**out = **in
 	}
-	return nil
+	if in.JsonPatch != nil {
+		in, out := &in.JsonPatch, &out.JsonPatch
+		*out = new([]runtime.RawExtension)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]runtime.RawExtension, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerActionSpec.
+func (in *PullRequestCommandHandlerActionSpec) DeepCopy() *PullRequestCommandHandlerActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestCommandHandlerActionSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGitlabMergeRequestsList) DeepCopyInto(out *ListGitlabMergeRequestsList) {
+func (in *PullRequestCommandHandlerCommandSpec) DeepCopyInto(out *PullRequestCommandHandlerCommandSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ListGitlabMergeRequests, len(*in))
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]PullRequestCommandHandlerActionSpec, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequestsList.
-func (in *ListGitlabMergeRequestsList) DeepCopy() *ListGitlabMergeRequestsList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerCommandSpec.
+func (in *PullRequestCommandHandlerCommandSpec) DeepCopy() *PullRequestCommandHandlerCommandSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGitlabMergeRequestsList)
+	out := new(PullRequestCommandHandlerCommandSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ListGitlabMergeRequestsList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGitlabMergeRequestsSpec) DeepCopyInto(out *ListGitlabMergeRequestsSpec) {
+func (in *PullRequestCommandHandlerStatus) DeepCopyInto(out *PullRequestCommandHandlerStatus) {
 	*out = *in
-	out.Interval = in.Interval
-	in.GitlabProject.DeepCopyInto(&out.GitlabProject)
-	if in.TargetBranch != nil {
-		in, out := &in.TargetBranch, &out.TargetBranch
-		*out = new(string)
-		**out = **in
-	}
-	if in.SourceBranch != nil {
-		in, out := &in.SourceBranch, &out.SourceBranch
-		*out = new(string)
-		**out = **in
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.State != nil {
-		in, out := &in.State, &out.State
+	if in.LastProcessedCommentTime != nil {
+		in, out := &in.LastProcessedCommentTime, &out.LastProcessedCommentTime
 		*out = new(string)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequestsSpec.
-func (in *ListGitlabMergeRequestsSpec) DeepCopy() *ListGitlabMergeRequestsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerStatus.
+func (in *PullRequestCommandHandlerStatus) DeepCopy() *PullRequestCommandHandlerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGitlabMergeRequestsSpec)
+	out := new(PullRequestCommandHandlerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ListGitlabMergeRequestsStatus) DeepCopyInto(out *ListGitlabMergeRequestsStatus) {
+func (in *PullRequestCommentReporter) DeepCopyInto(out *PullRequestCommentReporter) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.MergeRequests != nil {
-		in, out := &in.MergeRequests, &out.MergeRequests
-		*out = make([]runtime.RawExtension, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.PullRequestRefHolder.DeepCopyInto(&out.PullRequestRefHolder)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListGitlabMergeRequestsStatus.
-func (in *ListGitlabMergeRequestsStatus) DeepCopy() *ListGitlabMergeRequestsStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommentReporter.
+func (in *PullRequestCommentReporter) DeepCopy() *PullRequestCommentReporter {
 	if in == nil {
 		return nil
 	}
-	out := new(ListGitlabMergeRequestsStatus)
+	out := new(PullRequestCommentReporter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalObjectReference) DeepCopyInto(out *LocalObjectReference) {
+func (in *PullRequestCommentReporterStatus) DeepCopyInto(out *PullRequestCommentReporterStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalObjectReference.
-func (in *LocalObjectReference) DeepCopy() *LocalObjectReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommentReporterStatus.
+func (in *PullRequestCommentReporterStatus) DeepCopy() *PullRequestCommentReporterStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalObjectReference)
+	out := new(PullRequestCommentReporterStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MatrixEntry) DeepCopyInto(out *MatrixEntry) {
+func (in *PullRequestFilters) DeepCopyInto(out *PullRequestFilters) {
 	*out = *in
-	if in.Object != nil {
-		in, out := &in.Object, &out.Object
-		*out = new(MatrixEntryObject)
-		(*in).DeepCopyInto(*out)
+	if in.ExcludeLabels != nil {
+		in, out := &in.ExcludeLabels, &out.ExcludeLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.List != nil {
-		in, out := &in.List, &out.List
-		*out = make([]runtime.RawExtension, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.ExcludeSourceBranch != nil {
+		in, out := &in.ExcludeSourceBranch, &out.ExcludeSourceBranch
+		*out = new(string)
+		**out = **in
+	}
+	if in.Authors != nil {
+		in, out := &in.Authors, &out.Authors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeAuthors != nil {
+		in, out := &in.ExcludeAuthors, &out.ExcludeAuthors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChangedPaths != nil {
+		in, out := &in.ChangedPaths, &out.ChangedPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntry.
-func (in *MatrixEntry) DeepCopy() *MatrixEntry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestFilters.
+func (in *PullRequestFilters) DeepCopy() *PullRequestFilters {
 	if in == nil {
 		return nil
 	}
-	out := new(MatrixEntry)
+	out := new(PullRequestFilters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MatrixEntryObject) DeepCopyInto(out *MatrixEntryObject) {
+func (in *PullRequestRefHolder) DeepCopyInto(out *PullRequestRefHolder) {
 	*out = *in
-	out.Ref = in.Ref
-	if in.JsonPath != nil {
-		in, out := &in.JsonPath, &out.JsonPath
-		*out = new(string)
-		**out = **in
+	if in.Gitlab != nil {
+		in, out := &in.Gitlab, &out.Gitlab
+		*out = new(GitlabMergeRequestRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Github != nil {
+		in, out := &in.Github, &out.Github
+		*out = new(GithubPullRequestRef)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixEntryObject.
-func (in *MatrixEntryObject) DeepCopy() *MatrixEntryObject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestRefHolder.
+func (in *PullRequestRefHolder) DeepCopy() *PullRequestRefHolder {
 	if in == nil {
 		return nil
 	}
-	out := new(MatrixEntryObject)
+	out := new(PullRequestRefHolder)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectHandler) DeepCopyInto(out *ObjectHandler) {
+func (in *Receiver) DeepCopyInto(out *Receiver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -977,18 +3728,18 @@ func (in *ObjectHandler) DeepCopyInto(out *ObjectHandler) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandler.
-func (in *ObjectHandler) DeepCopy() *ObjectHandler {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Receiver.
+func (in *Receiver) DeepCopy() *Receiver {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectHandler)
+	out := new(Receiver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ObjectHandler) DeepCopyObject() runtime.Object {
+func (in *Receiver) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -996,31 +3747,31 @@ func (in *ObjectHandler) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectHandlerList) DeepCopyInto(out *ObjectHandlerList) {
+func (in *ReceiverList) DeepCopyInto(out *ReceiverList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ObjectHandler, len(*in))
+		*out = make([]Receiver, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandlerList.
-func (in *ObjectHandlerList) DeepCopy() *ObjectHandlerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiverList.
+func (in *ReceiverList) DeepCopy() *ReceiverList {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectHandlerList)
+	out := new(ReceiverList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ObjectHandlerList) DeepCopyObject() runtime.Object {
+func (in *ReceiverList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1028,435 +3779,407 @@ func (in *ObjectHandlerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectHandlerSpec) DeepCopyInto(out *ObjectHandlerSpec) {
+func (in *ReceiverResource) DeepCopyInto(out *ReceiverResource) {
 	*out = *in
-	out.Interval = in.Interval
-	out.ForObject = in.ForObject
-	if in.Handlers != nil {
-		in, out := &in.Handlers, &out.Handlers
-		*out = make([]Handler, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandlerSpec.
-func (in *ObjectHandlerSpec) DeepCopy() *ObjectHandlerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiverResource.
+func (in *ReceiverResource) DeepCopy() *ReceiverResource {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectHandlerSpec)
+	out := new(ReceiverResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectHandlerStatus) DeepCopyInto(out *ObjectHandlerStatus) {
+func (in *ReceiverSpec) DeepCopyInto(out *ReceiverSpec) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out.Interval = in.Interval
+	out.SecretRef = in.SecretRef
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.HandlerStatus != nil {
-		in, out := &in.HandlerStatus, &out.HandlerStatus
-		*out = make([]*HandlerStatus, len(*in))
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ReceiverResource, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(HandlerStatus)
-				(*in).DeepCopyInto(*out)
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.Debounce = in.Debounce
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectHandlerStatus.
-func (in *ObjectHandlerStatus) DeepCopy() *ObjectHandlerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiverSpec.
+func (in *ReceiverSpec) DeepCopy() *ReceiverSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectHandlerStatus)
+	out := new(ReceiverSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectRef) DeepCopyInto(out *ObjectRef) {
+func (in *ReceiverStatus) DeepCopyInto(out *ReceiverStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastEventTime != nil {
+		in, out := &in.LastEventTime, &out.LastEventTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectRef.
-func (in *ObjectRef) DeepCopy() *ObjectRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReceiverStatus.
+func (in *ReceiverStatus) DeepCopy() *ReceiverStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectRef)
+	out := new(ReceiverStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectTemplate) DeepCopyInto(out *ObjectTemplate) {
+func (in *RepositoryInfo) DeepCopyInto(out *RepositoryInfo) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Topics != nil {
+		in, out := &in.Topics, &out.Topics
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplate.
-func (in *ObjectTemplate) DeepCopy() *ObjectTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryInfo.
+func (in *RepositoryInfo) DeepCopy() *RepositoryInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectTemplate)
+	out := new(RepositoryInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ObjectTemplate) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectTemplateList) DeepCopyInto(out *ObjectTemplateList) {
+func (in *ResourceHealth) DeepCopyInto(out *ResourceHealth) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ObjectTemplate, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	out.Ref = in.Ref
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateList.
-func (in *ObjectTemplateList) DeepCopy() *ObjectTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceHealth.
+func (in *ResourceHealth) DeepCopy() *ResourceHealth {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectTemplateList)
+	out := new(ResourceHealth)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ObjectTemplateList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectTemplateSpec) DeepCopyInto(out *ObjectTemplateSpec) {
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
 	*out = *in
-	out.Interval = in.Interval
-	if in.Matrix != nil {
-		in, out := &in.Matrix, &out.Matrix
-		*out = make([]*MatrixEntry, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(MatrixEntry)
-				(*in).DeepCopyInto(*out)
-			}
-		}
-	}
-	if in.Templates != nil {
-		in, out := &in.Templates, &out.Templates
-		*out = make([]Template, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.FailedEntries != nil {
+		in, out := &in.FailedEntries, &out.FailedEntries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastBatchTime != nil {
+		in, out := &in.LastBatchTime, &out.LastBatchTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateSpec.
-func (in *ObjectTemplateSpec) DeepCopy() *ObjectTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectTemplateSpec)
+	out := new(RolloutStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectTemplateStatus) DeepCopyInto(out *ObjectTemplateStatus) {
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.AppliedResources != nil {
-		in, out := &in.AppliedResources, &out.AppliedResources
-		*out = make([]AppliedResourceInfo, len(*in))
-		copy(*out, *in)
+	if in.BatchInterval != nil {
+		in, out := &in.BatchInterval, &out.BatchInterval
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplateStatus.
-func (in *ObjectTemplateStatus) DeepCopy() *ObjectTemplateStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectTemplateStatus)
+	out := new(RolloutStrategy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestApproveReporter) DeepCopyInto(out *PullRequestApproveReporter) {
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
 	*out = *in
-	in.PullRequestRefHolder.DeepCopyInto(&out.PullRequestRefHolder)
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NextActionTime != nil {
+		in, out := &in.NextActionTime, &out.NextActionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestApproveReporter.
-func (in *PullRequestApproveReporter) DeepCopy() *PullRequestApproveReporter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestApproveReporter)
+	out := new(ScheduleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestApproveReporterStatus) DeepCopyInto(out *PullRequestApproveReporterStatus) {
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
 	*out = *in
-	if in.Approved != nil {
-		in, out := &in.Approved, &out.Approved
-		*out = new(bool)
-		**out = **in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]ScheduleWeekday, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestApproveReporterStatus.
-func (in *PullRequestApproveReporterStatus) DeepCopy() *PullRequestApproveReporterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestApproveReporterStatus)
+	out := new(ScheduleWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommandHandler) DeepCopyInto(out *PullRequestCommandHandler) {
+func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
-	in.PullRequestRefHolder.DeepCopyInto(&out.PullRequestRefHolder)
-	if in.Commands != nil {
-		in, out := &in.Commands, &out.Commands
-		*out = make([]PullRequestCommandHandlerCommandSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandler.
-func (in *PullRequestCommandHandler) DeepCopy() *PullRequestCommandHandler {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRef.
+func (in *SecretRef) DeepCopy() *SecretRef {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommandHandler)
+	out := new(SecretRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommandHandlerActionAnnotateSpec) DeepCopyInto(out *PullRequestCommandHandlerActionAnnotateSpec) {
+func (in *SlackDestination) DeepCopyInto(out *SlackDestination) {
 	*out = *in
+	out.WebhookUrlRef = in.WebhookUrlRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerActionAnnotateSpec.
-func (in *PullRequestCommandHandlerActionAnnotateSpec) DeepCopy() *PullRequestCommandHandlerActionAnnotateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackDestination.
+func (in *SlackDestination) DeepCopy() *SlackDestination {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommandHandlerActionAnnotateSpec)
+	out := new(SlackDestination)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommandHandlerActionSpec) DeepCopyInto(out *PullRequestCommandHandlerActionSpec) {
+func (in *Template) DeepCopyInto(out *Template) {
 	*out = *in
-	if in.Annotate != nil {
-		in, out := &in.Annotate, &out.Annotate
-		*out = new(PullRequestCommandHandlerActionAnnotateSpec)
+	if in.Object != nil {
+		in, out := &in.Object, &out.Object
+		*out = (*in).DeepCopy()
+	}
+	if in.Raw != nil {
+		in, out := &in.Raw, &out.Raw
+		*out = new(string)
 		**out = **in
 	}
-	if in.JsonPatch != nil {
-		in, out := &in.JsonPatch, &out.JsonPatch
-		*out = new([]runtime.RawExtension)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make([]runtime.RawExtension, len(*in))
-			for i := range *in {
-				(*in)[i].DeepCopyInto(&(*out)[i])
-			}
-		}
+	if in.Library != nil {
+		in, out := &in.Library, &out.Library
+		*out = new(LibraryTemplateRef)
+		**out = **in
+	}
+	if in.GitRepository != nil {
+		in, out := &in.GitRepository, &out.GitRepository
+		*out = new(GitRepositoryTemplateSource)
+		**out = **in
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(OCITemplateSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForEach != nil {
+		in, out := &in.ForEach, &out.ForEach
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerActionSpec.
-func (in *PullRequestCommandHandlerActionSpec) DeepCopy() *PullRequestCommandHandlerActionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Template.
+func (in *Template) DeepCopy() *Template {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommandHandlerActionSpec)
+	out := new(Template)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommandHandlerCommandSpec) DeepCopyInto(out *PullRequestCommandHandlerCommandSpec) {
+func (in *TemplateDelimiters) DeepCopyInto(out *TemplateDelimiters) {
 	*out = *in
-	if in.Actions != nil {
-		in, out := &in.Actions, &out.Actions
-		*out = make([]PullRequestCommandHandlerActionSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerCommandSpec.
-func (in *PullRequestCommandHandlerCommandSpec) DeepCopy() *PullRequestCommandHandlerCommandSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateDelimiters.
+func (in *TemplateDelimiters) DeepCopy() *TemplateDelimiters {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommandHandlerCommandSpec)
+	out := new(TemplateDelimiters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommandHandlerStatus) DeepCopyInto(out *PullRequestCommandHandlerStatus) {
+func (in *TemplateInclude) DeepCopyInto(out *TemplateInclude) {
 	*out = *in
-	if in.LastProcessedCommentTime != nil {
-		in, out := &in.LastProcessedCommentTime, &out.LastProcessedCommentTime
-		*out = new(string)
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(TemplateIncludeConfigMap)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommandHandlerStatus.
-func (in *PullRequestCommandHandlerStatus) DeepCopy() *PullRequestCommandHandlerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateInclude.
+func (in *TemplateInclude) DeepCopy() *TemplateInclude {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommandHandlerStatus)
+	out := new(TemplateInclude)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommentReporter) DeepCopyInto(out *PullRequestCommentReporter) {
+func (in *TemplateIncludeConfigMap) DeepCopyInto(out *TemplateIncludeConfigMap) {
 	*out = *in
-	in.PullRequestRefHolder.DeepCopyInto(&out.PullRequestRefHolder)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommentReporter.
-func (in *PullRequestCommentReporter) DeepCopy() *PullRequestCommentReporter {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateIncludeConfigMap.
+func (in *TemplateIncludeConfigMap) DeepCopy() *TemplateIncludeConfigMap {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommentReporter)
+	out := new(TemplateIncludeConfigMap)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestCommentReporterStatus) DeepCopyInto(out *PullRequestCommentReporterStatus) {
+func (in *TemplateLibrary) DeepCopyInto(out *TemplateLibrary) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestCommentReporterStatus.
-func (in *PullRequestCommentReporterStatus) DeepCopy() *PullRequestCommentReporterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateLibrary.
+func (in *TemplateLibrary) DeepCopy() *TemplateLibrary {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestCommentReporterStatus)
+	out := new(TemplateLibrary)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateLibrary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullRequestRefHolder) DeepCopyInto(out *PullRequestRefHolder) {
+func (in *TemplateLibraryList) DeepCopyInto(out *TemplateLibraryList) {
 	*out = *in
-	if in.Gitlab != nil {
-		in, out := &in.Gitlab, &out.Gitlab
-		*out = new(GitlabMergeRequestRef)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Github != nil {
-		in, out := &in.Github, &out.Github
-		*out = new(GithubPullRequestRef)
-		(*in).DeepCopyInto(*out)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TemplateLibrary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestRefHolder.
-func (in *PullRequestRefHolder) DeepCopy() *PullRequestRefHolder {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateLibraryList.
+func (in *TemplateLibraryList) DeepCopy() *TemplateLibraryList {
 	if in == nil {
 		return nil
 	}
-	out := new(PullRequestRefHolder)
+	out := new(TemplateLibraryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretRef) DeepCopyInto(out *SecretRef) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRef.
-func (in *SecretRef) DeepCopy() *SecretRef {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TemplateLibraryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(SecretRef)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Template) DeepCopyInto(out *Template) {
+func (in *TemplateLibrarySpec) DeepCopyInto(out *TemplateLibrarySpec) {
 	*out = *in
-	if in.Object != nil {
-		in, out := &in.Object, &out.Object
-		*out = (*in).DeepCopy()
-	}
-	if in.Raw != nil {
-		in, out := &in.Raw, &out.Raw
-		*out = new(string)
-		**out = **in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]LibraryTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Template.
-func (in *Template) DeepCopy() *Template {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateLibrarySpec.
+func (in *TemplateLibrarySpec) DeepCopy() *TemplateLibrarySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Template)
+	out := new(TemplateLibrarySpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1496,6 +4219,46 @@ func (in *TemplateRefConfigMap) DeepCopy() *TemplateRefConfigMap {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateVarsObjectRef) DeepCopyInto(out *TemplateVarsObjectRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateVarsObjectRef.
+func (in *TemplateVarsObjectRef) DeepCopy() *TemplateVarsObjectRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateVarsObjectRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateVarsSource) DeepCopyInto(out *TemplateVarsSource) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(TemplateVarsObjectRef)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(TemplateVarsObjectRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateVarsSource.
+func (in *TemplateVarsSource) DeepCopy() *TemplateVarsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateVarsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TextTemplate) DeepCopyInto(out *TextTemplate) {
 	*out = *in
@@ -1620,6 +4383,11 @@ func (in *TextTemplateSpec) DeepCopyInto(out *TextTemplateSpec) {
 		*out = new(TemplateRef)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(TextTemplateTarget)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TextTemplateSpec.
@@ -1653,3 +4421,60 @@ func (in *TextTemplateStatus) DeepCopy() *TextTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TextTemplateTarget) DeepCopyInto(out *TextTemplateTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TextTemplateTarget.
+func (in *TextTemplateTarget) DeepCopy() *TextTemplateTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(TextTemplateTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultKubernetesAuth) DeepCopyInto(out *VaultKubernetesAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultKubernetesAuth.
+func (in *VaultKubernetesAuth) DeepCopy() *VaultKubernetesAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultKubernetesAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDestination) DeepCopyInto(out *WebhookDestination) {
+	*out = *in
+	if in.UrlRef != nil {
+		in, out := &in.UrlRef, &out.UrlRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDestination.
+func (in *WebhookDestination) DeepCopy() *WebhookDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDestination)
+	in.DeepCopyInto(out)
+	return out
+}
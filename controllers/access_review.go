@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+)
+
+type accessReviewKey struct {
+	serviceAccount string
+	verb           string
+	gvr            schema.GroupVersionResource
+	namespace      string
+}
+
+// accessReviews caches the results of SelfSubjectAccessReviews for the duration of a single reconciliation, so that
+// templates rendering many objects of the same kind do not cause one review per object. Only reviews without a
+// resource name are cached, as these are valid for all objects of a kind and namespace.
+type accessReviews struct {
+	mutex   sync.Mutex
+	results map[accessReviewKey]bool
+}
+
+func newAccessReviews() *accessReviews {
+	return &accessReviews{
+		results: map[accessReviewKey]bool{},
+	}
+}
+
+// isAllowed returns true if objClient may perform verb on the given object. Access to all objects of the resource in
+// the namespace is reviewed first and cached. If that is denied, the object is reviewed by name, as the service
+// account might only have been granted access to specific resourceNames.
+func (a *accessReviews) isAllowed(ctx context.Context, objClient client.Client, key accessReviewKey, name string) (bool, error) {
+	a.mutex.Lock()
+	allowed, ok := a.results[key]
+	a.mutex.Unlock()
+
+	if !ok {
+		var err error
+		allowed, err = reviewAccess(ctx, objClient, key, "")
+		if err != nil {
+			return false, err
+		}
+		a.mutex.Lock()
+		a.results[key] = allowed
+		a.mutex.Unlock()
+	}
+	if allowed || name == "" {
+		return allowed, nil
+	}
+	return reviewAccess(ctx, objClient, key, name)
+}
+
+func reviewAccess(ctx context.Context, objClient client.Client, key accessReviewKey, name string) (bool, error) {
+	sar := authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: key.namespace,
+				Verb:      key.verb,
+				Group:     key.gvr.Group,
+				Version:   key.gvr.Version,
+				Resource:  key.gvr.Resource,
+				Name:      name,
+			},
+		},
+	}
+	err := objClient.Create(ctx, &sar)
+	if err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// checkAccess performs a SelfSubjectAccessReview for the rendered object with the impersonated client, so that missing
+// permissions of the service account are reported with a clear error message instead of a raw apply failure.
+func (r *ObjectTemplateReconciler) checkAccess(ctx context.Context, objClient client.Client, reviews *accessReviews, rt *templatesv1alpha1.ObjectTemplate, rendered *unstructured.Unstructured, verb string) error {
+	gvk := rendered.GroupVersionKind()
+	rm, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+
+	saName := rt.Spec.ServiceAccountName
+	if saName == "" {
+		saName = "default"
+	}
+	key := accessReviewKey{
+		serviceAccount: saName,
+		verb:           verb,
+		gvr:            rm.Resource,
+		namespace:      rendered.GetNamespace(),
+	}
+	allowed, err := reviews.isAllowed(ctx, objClient, key, rendered.GetName())
+	if err != nil {
+		ref := templatesv1alpha1.ObjectRefFromObject(rendered)
+		return fmt.Errorf("failed to review access for %s: %w", ref.String(), err)
+	}
+	if allowed {
+		return nil
+	}
+
+	resource := rm.Resource.GroupResource().String()
+	if rendered.GetNamespace() != "" {
+		return fmt.Errorf("forbidden: service account %s cannot %s %s in namespace %s", saName, verb, resource, rendered.GetNamespace())
+	}
+	return fmt.Errorf("forbidden: service account %s cannot %s %s at cluster scope", saName, verb, resource)
+}
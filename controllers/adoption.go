@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// checkAdoption verifies that rt may apply rendered on top of the already existing object. Objects that carry the
+// template annotation of rt or that are part of its applied resources are already managed by rt. All other objects
+// are only adopted if spec.adoptExisting is set and the object is not being deleted, not managed by another template
+// and not controlled by another object. Returns true if the object gets adopted.
+func checkAdoption(rt *templatesv1alpha1.ObjectTemplate, rendered *unstructured.Unstructured, existing metav1.Object) (bool, error) {
+	ref := templatesv1alpha1.ObjectRefFromObject(rendered)
+	owner := rendered.GetAnnotations()[templatesv1alpha1.TemplateAnnotation]
+	existingOwner := existing.GetAnnotations()[templatesv1alpha1.TemplateAnnotation]
+
+	if existingOwner != "" {
+		if existingOwner != owner {
+			return false, fmt.Errorf("%s already exists and is managed by %s", ref.String(), existingOwner)
+		}
+		return false, nil
+	}
+
+	refWithoutVersion := ref.WithoutVersion()
+	for _, ari := range rt.Status.AppliedResources {
+		if ari.Ref.WithoutVersion() == refWithoutVersion {
+			return false, nil
+		}
+	}
+
+	if !rt.Spec.AdoptExisting {
+		return false, fmt.Errorf("%s already exists and is not managed by this template, set spec.adoptExisting to adopt it", ref.String())
+	}
+	if existing.GetDeletionTimestamp() != nil {
+		return false, fmt.Errorf("can not adopt %s as it is being deleted", ref.String())
+	}
+	if c := metav1.GetControllerOfNoCopy(existing); c != nil {
+		return false, fmt.Errorf("can not adopt %s as it is controlled by %s %s", ref.String(), c.Kind, c.Name)
+	}
+	return true, nil
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCheckAdoption(t *testing.T) {
+	owner := "ObjectTemplate/ns1/t1"
+	rendered := newTestObject("v1", "ConfigMap", "ns1", "cm1", map[string]string{templatesv1alpha1.TemplateAnnotation: owner})
+	now := metav1.Now()
+	isController := true
+
+	tests := []struct {
+		name          string
+		adoptExisting bool
+		applied       bool
+		existing      func(o *unstructured.Unstructured)
+		want          bool
+		err           string
+	}{
+		{
+			name: "managed by this template",
+			existing: func(o *unstructured.Unstructured) {
+				o.SetAnnotations(map[string]string{templatesv1alpha1.TemplateAnnotation: owner})
+			},
+		},
+		{
+			name: "managed by other template",
+			existing: func(o *unstructured.Unstructured) {
+				o.SetAnnotations(map[string]string{templatesv1alpha1.TemplateAnnotation: "ObjectTemplate/ns1/t2"})
+			},
+			adoptExisting: true,
+			err:           "already exists and is managed by ObjectTemplate/ns1/t2",
+		},
+		{
+			name:    "previously applied",
+			applied: true,
+		},
+		{
+			name: "unmanaged",
+			err:  "set spec.adoptExisting to adopt it",
+		},
+		{
+			name:          "adopt",
+			adoptExisting: true,
+			want:          true,
+		},
+		{
+			name:          "being deleted",
+			adoptExisting: true,
+			existing: func(o *unstructured.Unstructured) {
+				o.SetDeletionTimestamp(&now)
+			},
+			err: "as it is being deleted",
+		},
+		{
+			name:          "controlled by other object",
+			adoptExisting: true,
+			existing: func(o *unstructured.Unstructured) {
+				o.SetOwnerReferences([]metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+					Controller: &isController,
+				}})
+			},
+			err: "as it is controlled by Deployment app",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			rt := &templatesv1alpha1.ObjectTemplate{}
+			rt.Spec.AdoptExisting = tc.adoptExisting
+			if tc.applied {
+				ref := templatesv1alpha1.ObjectRefFromObject(rendered)
+				// the version is ignored when looking up previously applied objects
+				ref.APIVersion = "v2"
+				rt.Status.AppliedResources = []templatesv1alpha1.AppliedResourceInfo{{Ref: ref, Success: true}}
+			}
+			existing := newTestObject("v1", "ConfigMap", "ns1", "cm1", nil)
+			if tc.existing != nil {
+				tc.existing(existing)
+			}
+
+			adopted, err := checkAdoption(rt, rendered, existing)
+			if tc.err != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(adopted).To(Equal(tc.want))
+		})
+	}
+}
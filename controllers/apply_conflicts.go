@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"errors"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
+)
+
+var conflictManagerRegex = regexp.MustCompile(`^conflict with "([^"]*)"`)
+
+// getApplyConflicts returns the conflicting fields of a failed server-side apply. nil is returned if err is not caused
+// by field manager conflicts, e.g. for optimistic locking conflicts.
+func getApplyConflicts(err error) []templatesv1alpha1.FieldConflict {
+	var statusErr apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &statusErr) {
+		return nil
+	}
+	details := statusErr.Status().Details
+	if details == nil {
+		return nil
+	}
+
+	var ret []templatesv1alpha1.FieldConflict
+	for _, c := range details.Causes {
+		if c.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		fc := templatesv1alpha1.FieldConflict{
+			Field: c.Field,
+		}
+		if m := conflictManagerRegex.FindStringSubmatch(c.Message); m != nil {
+			fc.Manager = m[1]
+		}
+		ret = append(ret, fc)
+	}
+	return ret
+}
+
+// countConflicts returns the number of applied resources that have field manager conflicts
+func countConflicts(appliedResources []templatesv1alpha1.AppliedResourceInfo) int {
+	n := 0
+	for _, ari := range appliedResources {
+		if len(ari.Conflicts) != 0 {
+			n++
+		}
+	}
+	return n
+}
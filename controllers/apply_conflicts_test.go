@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGetApplyConflicts(t *testing.T) {
+	g := NewWithT(t)
+
+	err := apierrors.NewApplyConflict([]metav1.StatusCause{
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kubectl-edit" using v1: .data.a`,
+			Field:   ".data.a",
+		},
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "helm" using v1 at 2023-01-01T00:00:00Z: .data.b`,
+			Field:   ".data.b",
+		},
+		{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: "unrelated cause",
+			Field:   ".data.c",
+		},
+	}, "Apply failed with 2 conflicts")
+
+	g.Expect(getApplyConflicts(err)).To(Equal([]templatesv1alpha1.FieldConflict{
+		{Field: ".data.a", Manager: "kubectl-edit"},
+		{Field: ".data.b", Manager: "helm"},
+	}))
+
+	// wrapped errors are supported as well
+	g.Expect(getApplyConflicts(fmt.Errorf("failed to apply: %w", err))).To(HaveLen(2))
+}
+
+func TestGetApplyConflictsOtherErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(getApplyConflicts(nil)).To(BeNil())
+	g.Expect(getApplyConflicts(fmt.Errorf("some error"))).To(BeNil())
+	// optimistic locking conflicts have no field manager conflict causes
+	g.Expect(getApplyConflicts(apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "cm1", fmt.Errorf("the object has been modified")))).To(BeNil())
+	g.Expect(getApplyConflicts(apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "cm1"))).To(BeNil())
+}
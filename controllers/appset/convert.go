@@ -0,0 +1,806 @@
+package appset
+
+import (
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConvertOptions controls how ApplicationSets are converted
+type ConvertOptions struct {
+	// ServiceAccountName is set as spec.serviceAccountName of the resulting ObjectTemplates
+	ServiceAccountName string
+
+	// Interval is set as spec.interval of the resulting objects, unless the generator specifies requeueAfterSeconds
+	Interval time.Duration
+}
+
+var (
+	fastTemplateRegex = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*}}`)
+	goTemplateRegex   = regexp.MustCompile(`^\.([A-Za-z0-9_.]+)$`)
+	pathIndexRegex    = regexp.MustCompile(`^path\[([0-9]+)]$`)
+)
+
+// Convert converts an Argo CD ApplicationSet into equivalent template-controller objects. Each generator of the
+// ApplicationSet results in its own ObjectTemplate, as ApplicationSets generate Applications per generator while the
+// matrix of an ObjectTemplate is the cartesian product of all its entries. Pull request and Git generators result in
+// additional objects (e.g. ListGithubPullRequests or GitProjector) that are referenced by the ObjectTemplates.
+func Convert(u *unstructured.Unstructured, opts ConvertOptions) ([]client.Object, error) {
+	if u.GroupVersionKind().GroupKind().String() != "ApplicationSet.argoproj.io" {
+		return nil, fmt.Errorf("%s is not an ApplicationSet", u.GroupVersionKind().String())
+	}
+
+	var as applicationSet
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &as)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ApplicationSet %s: %w", u.GetName(), err)
+	}
+	if len(as.Spec.Generators) == 0 {
+		return nil, fmt.Errorf("ApplicationSet %s has no generators", u.GetName())
+	}
+	if as.Spec.TemplatePatch != nil {
+		return nil, fmt.Errorf("ApplicationSet %s uses templatePatch, which is not supported", u.GetName())
+	}
+
+	c := converter{
+		appSet: u,
+		as:     &as,
+		opts:   opts,
+	}
+
+	var ret []client.Object
+	for i, g := range as.Spec.Generators {
+		name := u.GetName()
+		if len(as.Spec.Generators) > 1 {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		objs, err := c.convertGenerator(name, g)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert generator %d of ApplicationSet %s: %w", i, u.GetName(), err)
+		}
+		ret = append(ret, objs...)
+	}
+	return ret, nil
+}
+
+type converter struct {
+	appSet *unstructured.Unstructured
+	as     *applicationSet
+	opts   ConvertOptions
+}
+
+// paramMapper maps ApplicationSet template parameters to Jinja2 expressions
+type paramMapper func(param string) (string, error)
+
+func (c *converter) convertGenerator(name string, g generator) ([]client.Object, error) {
+	var objs []client.Object
+	var entries []*templatesv1alpha1.MatrixEntry
+	var mapper paramMapper
+	var err error
+
+	if g.Matrix != nil {
+		if g.Template != nil {
+			return nil, fmt.Errorf("generator level templates are not supported")
+		}
+		if g.Selector != nil {
+			return nil, fmt.Errorf("generator selectors are not supported")
+		}
+		objs, entries, mapper, err = c.convertMatrixGenerator(name, g.Matrix)
+	} else {
+		var entry *templatesv1alpha1.MatrixEntry
+		objs, entry, mapper, err = c.convertSingleGenerator(name, "input", g)
+		entries = append(entries, entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := c.convertTemplate(mapper)
+	if err != nil {
+		return nil, err
+	}
+
+	ot := &templatesv1alpha1.ObjectTemplate{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: templatesv1alpha1.GroupVersion.String(),
+			Kind:       "ObjectTemplate",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.appSet.GetNamespace(),
+		},
+		Spec: templatesv1alpha1.ObjectTemplateSpec{
+			Interval:           metav1.Duration{Duration: c.interval(nil)},
+			ServiceAccountName: c.opts.ServiceAccountName,
+			Prune:              c.as.Spec.SyncPolicy == nil || !c.as.Spec.SyncPolicy.PreserveResourcesOnDeletion,
+			Matrix:             entries,
+			Templates: []templatesv1alpha1.Template{
+				{Object: tmpl},
+			},
+		},
+	}
+	objs = append([]client.Object{ot}, objs...)
+	return objs, nil
+}
+
+// convertSingleGenerator converts a generator that is not a matrix generator into a matrix entry with the given name
+// and the objects referenced by the matrix entry
+func (c *converter) convertSingleGenerator(name string, matrixName string, g generator) ([]client.Object, *templatesv1alpha1.MatrixEntry, paramMapper, error) {
+	var objs []client.Object
+	var entry *templatesv1alpha1.MatrixEntry
+	var mapper paramMapper
+	var err error
+
+	if g.Template != nil {
+		return nil, nil, nil, fmt.Errorf("generator level templates are not supported")
+	}
+	if g.Selector != nil {
+		return nil, nil, nil, fmt.Errorf("generator selectors are not supported")
+	}
+
+	switch {
+	case g.List != nil:
+		entry, mapper, err = c.convertListGenerator(matrixName, g.List)
+	case g.Git != nil:
+		var gp *templatesv1alpha1.GitProjector
+		gp, entry, mapper, err = c.convertGitGenerator(name, matrixName, g.Git)
+		if gp != nil {
+			objs = append(objs, gp)
+		}
+	case g.PullRequest != nil:
+		var lo client.Object
+		lo, entry, mapper, err = c.convertPullRequestGenerator(name, matrixName, g.PullRequest)
+		if lo != nil {
+			objs = append(objs, lo)
+		}
+	case g.Matrix != nil:
+		return nil, nil, nil, fmt.Errorf("nested matrix generators are not supported")
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported generator, only list, git, pullRequest and matrix generators can be converted")
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return objs, entry, mapper, nil
+}
+
+// convertMatrixGenerator converts a matrix generator into one matrix entry per child generator, as the matrix of an
+// ObjectTemplate is already the cartesian product of all its entries. Parameters are taken from the first child
+// generator that provides them. Git file generators accept any parameter and are thus only considered last.
+func (c *converter) convertMatrixGenerator(name string, g *matrixGenerator) ([]client.Object, []*templatesv1alpha1.MatrixEntry, paramMapper, error) {
+	if len(g.Generators) < 2 {
+		return nil, nil, nil, fmt.Errorf("matrix generator must have at least two child generators")
+	}
+
+	var objs []client.Object
+	var entries []*templatesv1alpha1.MatrixEntry
+	var mappers []paramMapper
+	var fallbackMappers []paramMapper
+	for i, child := range g.Generators {
+		childObjs, entry, mapper, err := c.convertSingleGenerator(fmt.Sprintf("%s-%d", name, i), fmt.Sprintf("input%d", i), child)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to convert child generator %d: %w", i, err)
+		}
+		objs = append(objs, childObjs...)
+		entries = append(entries, entry)
+
+		switch {
+		case child.List != nil:
+			// list generators accept any parameter, so restrict them to the keys that actually exist in the elements
+			mapper, err = restrictListMapper(child.List, mapper)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to convert child generator %d: %w", i, err)
+			}
+			mappers = append(mappers, mapper)
+		case child.Git != nil && len(child.Git.Files) != 0:
+			// git file generators accept all fields of the parsed files, which are unknown at conversion time
+			fallbackMappers = append(fallbackMappers, mapper)
+		default:
+			mappers = append(mappers, mapper)
+		}
+	}
+
+	mapper := func(param string) (string, error) {
+		for _, m := range append(mappers, fallbackMappers...) {
+			e, err := m(param)
+			if err == nil {
+				return e, nil
+			}
+		}
+		return "", fmt.Errorf("unsupported parameter %s", param)
+	}
+	return objs, entries, mapper, nil
+}
+
+// restrictListMapper wraps the mapper of a list generator so that it only accepts parameters that refer to keys of
+// at least one element
+func restrictListMapper(g *listGenerator, mapper paramMapper) (paramMapper, error) {
+	keys := map[string]bool{}
+	for _, e := range g.Elements {
+		var m map[string]any
+		err := json.Unmarshal(e.Raw, &m)
+		if err != nil {
+			return nil, fmt.Errorf("list elements must be objects: %w", err)
+		}
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	return func(param string) (string, error) {
+		if !keys[strings.SplitN(param, ".", 2)[0]] {
+			return "", fmt.Errorf("unsupported parameter %s", param)
+		}
+		return mapper(param)
+	}, nil
+}
+
+func (c *converter) interval(requeueAfterSeconds *int64) time.Duration {
+	if requeueAfterSeconds != nil {
+		return time.Duration(*requeueAfterSeconds) * time.Second
+	}
+	if c.opts.Interval != 0 {
+		return c.opts.Interval
+	}
+	return time.Minute
+}
+
+func (c *converter) convertListGenerator(matrixName string, g *listGenerator) (*templatesv1alpha1.MatrixEntry, paramMapper, error) {
+	if g.ElementsYaml != "" {
+		return nil, nil, fmt.Errorf("elementsYaml is not supported")
+	}
+	entry := &templatesv1alpha1.MatrixEntry{
+		Name: matrixName,
+		List: g.Elements,
+	}
+	mapper := func(param string) (string, error) {
+		return fmt.Sprintf("matrix.%s.%s", matrixName, param), nil
+	}
+	return entry, mapper, nil
+}
+
+func (c *converter) convertGitGenerator(name string, matrixName string, g *gitGenerator) (*templatesv1alpha1.GitProjector, *templatesv1alpha1.MatrixEntry, paramMapper, error) {
+	if len(g.Directories) != 0 && len(g.Files) != 0 {
+		return nil, nil, nil, fmt.Errorf("git generator can not have both directories and files")
+	}
+	if len(g.Directories) == 0 && len(g.Files) == 0 {
+		return nil, nil, nil, fmt.Errorf("git generator has no directories or files")
+	}
+	if g.PathParamPrefix != "" {
+		return nil, nil, nil, fmt.Errorf("pathParamPrefix is not supported")
+	}
+
+	gp := &templatesv1alpha1.GitProjector{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: templatesv1alpha1.GroupVersion.String(),
+			Kind:       "GitProjector",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.appSet.GetNamespace(),
+		},
+		Spec: templatesv1alpha1.GitProjectorSpec{
+			Interval: metav1.Duration{Duration: c.interval(g.RequeueAfterSeconds)},
+			URL:      g.RepoURL,
+		},
+	}
+	if g.Revision != "" && g.Revision != "HEAD" {
+		gp.Spec.Reference = &templatesv1alpha1.GitRef{
+			Branch: regexp.QuoteMeta(g.Revision),
+		}
+	}
+	for _, d := range g.Directories {
+		gp.Spec.Directories = append(gp.Spec.Directories, templatesv1alpha1.GitDirectory{
+			Glob:    d.Path,
+			Exclude: d.Exclude,
+		})
+	}
+	for _, f := range g.Files {
+		gp.Spec.Files = append(gp.Spec.Files, templatesv1alpha1.GitFile{
+			Glob:      f.Path,
+			ParseYaml: !f.Exclude,
+			Exclude:   f.Exclude,
+		})
+	}
+
+	jsonPath := "status.result[0].files"
+	if len(g.Directories) != 0 {
+		jsonPath = "status.result[0].directories"
+	}
+	entry := &templatesv1alpha1.MatrixEntry{
+		Name: matrixName,
+		Object: &templatesv1alpha1.MatrixEntryObject{
+			Ref:         templatesv1alpha1.ObjectRefFromObject(gp),
+			JsonPath:    &jsonPath,
+			ExpandLists: true,
+		},
+	}
+
+	file := "matrix." + matrixName
+	if len(g.Directories) != 0 {
+		mapper := func(param string) (string, error) {
+			switch param {
+			case "path", "path.path":
+				return fmt.Sprintf(`%s.path`, file), nil
+			case "path.basename":
+				return fmt.Sprintf(`%s.path.rsplit("/", 1)[-1]`, file), nil
+			case "path.basenameNormalized":
+				return fmt.Sprintf(`%s.path.rsplit("/", 1)[-1] | slugify`, file), nil
+			}
+			if m := pathIndexRegex.FindStringSubmatch(param); m != nil {
+				return fmt.Sprintf(`%s.path.split("/")[%s]`, file, m[1]), nil
+			}
+			return "", fmt.Errorf("unsupported parameter %s", param)
+		}
+		return gp, entry, mapper, nil
+	}
+
+	mapper := func(param string) (string, error) {
+		switch param {
+		case "path", "path.path":
+			return fmt.Sprintf(`%s.path.rsplit("/", 1)[0]`, file), nil
+		case "path.basename":
+			return fmt.Sprintf(`%s.path.rsplit("/", 1)[0].rsplit("/", 1)[-1]`, file), nil
+		case "path.filename":
+			return fmt.Sprintf(`%s.path.rsplit("/", 1)[-1]`, file), nil
+		case "path.basenameNormalized":
+			return fmt.Sprintf(`%s.path.rsplit("/", 1)[0].rsplit("/", 1)[-1] | slugify`, file), nil
+		case "path.filenameNormalized":
+			return fmt.Sprintf(`%s.path.rsplit("/", 1)[-1] | slugify`, file), nil
+		}
+		if m := pathIndexRegex.FindStringSubmatch(param); m != nil {
+			return fmt.Sprintf(`%s.path.split("/")[%s]`, file, m[1]), nil
+		}
+		if strings.HasPrefix(param, "path.") {
+			return "", fmt.Errorf("unsupported parameter %s", param)
+		}
+		return fmt.Sprintf("%s.parsed[0].%s", file, param), nil
+	}
+	return gp, entry, mapper, nil
+}
+
+func (c *converter) convertPullRequestGenerator(name string, matrixName string, g *pullRequestGenerator) (client.Object, *templatesv1alpha1.MatrixEntry, paramMapper, error) {
+	branchMatch, targetBranchMatch, err := convertPullRequestFilters(g.Filters)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	interval := metav1.Duration{Duration: c.interval(g.RequeueAfterSeconds)}
+	objectMeta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.appSet.GetNamespace(),
+	}
+	pr := "matrix." + matrixName
+
+	var obj client.Object
+	var jsonPath string
+	var params map[string]string
+	switch {
+	case g.Github != nil:
+		if g.Github.AppSecretName != "" {
+			return nil, nil, nil, fmt.Errorf("appSecretName is not supported, configure spec.app of the ListGithubPullRequests instead")
+		}
+		o := &templatesv1alpha1.ListGithubPullRequests{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: templatesv1alpha1.GroupVersion.String(),
+				Kind:       "ListGithubPullRequests",
+			},
+			ObjectMeta: objectMeta,
+			Spec: templatesv1alpha1.ListGithubPullRequestsSpec{
+				Interval: interval,
+				GithubProject: templatesv1alpha1.GithubProject{
+					Owner:    g.Github.Owner,
+					Repo:     g.Github.Repo,
+					TokenRef: g.Github.TokenRef.convert(),
+				},
+				Labels: g.Github.Labels,
+				State:  "open",
+				Limit:  100,
+			},
+		}
+		if g.Github.API != "" {
+			o.Spec.API = &g.Github.API
+		}
+		if branchMatch != nil {
+			// head is matched against the PR label, which has the form "owner:branch"
+			head := "[^:]+:" + *branchMatch
+			o.Spec.Head = &head
+		}
+		o.Spec.Base = targetBranchMatch
+		obj = o
+		jsonPath = "status.pullRequests"
+		params = map[string]string{
+			"number":         pr + ".number",
+			"title":          pr + ".title",
+			"author":         pr + ".user.login",
+			"branch":         pr + ".head.ref",
+			"branch_slug":    pr + ".head.ref | slugify",
+			"target_branch":  pr + ".base.ref",
+			"head_sha":       pr + ".head.sha",
+			"head_short_sha": pr + ".head.sha[:8]",
+			"labels":         pr + `.labels | map(attribute="name") | list`,
+		}
+	case g.Gitlab != nil:
+		project := intstr.Parse(g.Gitlab.Project)
+		o := &templatesv1alpha1.ListGitlabMergeRequests{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: templatesv1alpha1.GroupVersion.String(),
+				Kind:       "ListGitlabMergeRequests",
+			},
+			ObjectMeta: objectMeta,
+			Spec: templatesv1alpha1.ListGitlabMergeRequestsSpec{
+				Interval: interval,
+				GitlabProjectOrGroup: templatesv1alpha1.GitlabProjectOrGroup{
+					Project: &project,
+					GitlabAuth: templatesv1alpha1.GitlabAuth{
+						TokenRef: g.Gitlab.TokenRef.convert(),
+					},
+				},
+				SourceBranch: branchMatch,
+				TargetBranch: targetBranchMatch,
+				Labels:       g.Gitlab.Labels,
+				Limit:        100,
+			},
+		}
+		if g.Gitlab.API != "" {
+			o.Spec.API = &g.Gitlab.API
+		}
+		state := "opened"
+		if g.Gitlab.PullRequestState != "" {
+			state = g.Gitlab.PullRequestState
+		}
+		o.Spec.State = &state
+		obj = o
+		jsonPath = "status.mergeRequests"
+		params = map[string]string{
+			"number":         pr + ".iid",
+			"title":          pr + ".title",
+			"author":         pr + ".author.username",
+			"branch":         pr + ".source_branch",
+			"branch_slug":    pr + ".source_branch | slugify",
+			"target_branch":  pr + ".target_branch",
+			"head_sha":       pr + ".sha",
+			"head_short_sha": pr + ".sha[:8]",
+			"labels":         pr + ".labels",
+		}
+	case g.Gitea != nil:
+		if g.Gitea.Insecure {
+			return nil, nil, nil, fmt.Errorf("insecure Gitea connections are not supported")
+		}
+		o := &templatesv1alpha1.ListGiteaPullRequests{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: templatesv1alpha1.GroupVersion.String(),
+				Kind:       "ListGiteaPullRequests",
+			},
+			ObjectMeta: objectMeta,
+			Spec: templatesv1alpha1.ListGiteaPullRequestsSpec{
+				Interval: interval,
+				GiteaProject: templatesv1alpha1.GiteaProject{
+					Owner:    g.Gitea.Owner,
+					Repo:     g.Gitea.Repo,
+					TokenRef: g.Gitea.TokenRef.convert(),
+				},
+				Head:   branchMatch,
+				Base:   targetBranchMatch,
+				Labels: g.Gitea.Labels,
+				State:  "open",
+				Limit:  100,
+			},
+		}
+		if g.Gitea.API != "" {
+			o.Spec.API = &g.Gitea.API
+		}
+		obj = o
+		jsonPath = "status.pullRequests"
+		params = map[string]string{
+			"number":         pr + ".number",
+			"title":          pr + ".title",
+			"author":         pr + ".user.login",
+			"branch":         pr + ".head.ref",
+			"branch_slug":    pr + ".head.ref | slugify",
+			"target_branch":  pr + ".base.ref",
+			"head_sha":       pr + ".head.sha",
+			"head_short_sha": pr + ".head.sha[:8]",
+			"labels":         pr + `.labels | map(attribute="name") | list`,
+		}
+	case g.BitbucketServer != nil:
+		if g.BitbucketServer.Insecure || g.BitbucketServer.CARef != nil {
+			return nil, nil, nil, fmt.Errorf("insecure and caRef are not supported for Bitbucket Server")
+		}
+		o := &templatesv1alpha1.ListBitbucketServerPullRequests{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: templatesv1alpha1.GroupVersion.String(),
+				Kind:       "ListBitbucketServerPullRequests",
+			},
+			ObjectMeta: objectMeta,
+			Spec: templatesv1alpha1.ListBitbucketServerPullRequestsSpec{
+				Interval: interval,
+				BitbucketServerProject: templatesv1alpha1.BitbucketServerProject{
+					API:     g.BitbucketServer.API,
+					Project: g.BitbucketServer.Project,
+					Repo:    g.BitbucketServer.Repo,
+				},
+				SourceBranch: branchMatch,
+				TargetBranch: targetBranchMatch,
+				State:        "OPEN",
+				Limit:        100,
+			},
+		}
+		if ba := g.BitbucketServer.BasicAuth; ba != nil {
+			if ba.PasswordRef == nil {
+				return nil, nil, nil, fmt.Errorf("basicAuth without passwordRef is not supported")
+			}
+			o.Spec.BasicAuth = &templatesv1alpha1.BasicAuth{
+				Username:    ba.Username,
+				PasswordRef: *ba.PasswordRef.convert(),
+			}
+		} else if g.BitbucketServer.BearerToken != nil {
+			o.Spec.TokenRef = g.BitbucketServer.BearerToken.TokenRef.convert()
+		}
+		obj = o
+		jsonPath = "status.pullRequests"
+		params = map[string]string{
+			"number":         pr + ".id",
+			"title":          pr + ".title",
+			"author":         pr + ".author.user.name",
+			"branch":         pr + ".fromRef.displayId",
+			"branch_slug":    pr + ".fromRef.displayId | slugify",
+			"target_branch":  pr + ".toRef.displayId",
+			"head_sha":       pr + ".fromRef.latestCommit",
+			"head_short_sha": pr + ".fromRef.latestCommit[:8]",
+		}
+	case g.Bitbucket != nil:
+		if g.Bitbucket.API != "" && strings.TrimSuffix(g.Bitbucket.API, "/") != "https://api.bitbucket.org/2.0" {
+			return nil, nil, nil, fmt.Errorf("custom api URLs are not supported for Bitbucket Cloud")
+		}
+		o := &templatesv1alpha1.ListBitbucketCloudPullRequests{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: templatesv1alpha1.GroupVersion.String(),
+				Kind:       "ListBitbucketCloudPullRequests",
+			},
+			ObjectMeta: objectMeta,
+			Spec: templatesv1alpha1.ListBitbucketCloudPullRequestsSpec{
+				Interval: interval,
+				BitbucketCloudProject: templatesv1alpha1.BitbucketCloudProject{
+					Workspace: g.Bitbucket.Owner,
+					Repo:      g.Bitbucket.Repo,
+				},
+				SourceBranch: branchMatch,
+				TargetBranch: targetBranchMatch,
+				State:        "OPEN",
+				Limit:        100,
+			},
+		}
+		if ba := g.Bitbucket.BasicAuth; ba != nil {
+			if ba.PasswordRef == nil {
+				return nil, nil, nil, fmt.Errorf("basicAuth without passwordRef is not supported")
+			}
+			o.Spec.BasicAuth = &templatesv1alpha1.BasicAuth{
+				Username:    ba.Username,
+				PasswordRef: *ba.PasswordRef.convert(),
+			}
+		} else if g.Bitbucket.BearerToken != nil {
+			o.Spec.TokenRef = g.Bitbucket.BearerToken.TokenRef.convert()
+		}
+		obj = o
+		jsonPath = "status.pullRequests"
+		params = map[string]string{
+			"number":         pr + ".id",
+			"title":          pr + ".title",
+			"author":         pr + ".author.display_name",
+			"branch":         pr + ".source.branch.name",
+			"branch_slug":    pr + ".source.branch.name | slugify",
+			"target_branch":  pr + ".destination.branch.name",
+			"head_sha":       pr + ".source.commit.hash",
+			"head_short_sha": pr + ".source.commit.hash[:8]",
+		}
+	case g.AzureDevOps != nil:
+		o := &templatesv1alpha1.ListAzureDevOpsPullRequests{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: templatesv1alpha1.GroupVersion.String(),
+				Kind:       "ListAzureDevOpsPullRequests",
+			},
+			ObjectMeta: objectMeta,
+			Spec: templatesv1alpha1.ListAzureDevOpsPullRequestsSpec{
+				Interval: interval,
+				AzureDevOpsProject: templatesv1alpha1.AzureDevOpsProject{
+					Organization: g.AzureDevOps.Organization,
+					Project:      g.AzureDevOps.Project,
+					Repo:         g.AzureDevOps.Repo,
+					TokenRef:     g.AzureDevOps.TokenRef.convert(),
+				},
+				SourceBranch: branchMatch,
+				TargetBranch: targetBranchMatch,
+				Labels:       g.AzureDevOps.Labels,
+				State:        "active",
+				Limit:        100,
+			},
+		}
+		if g.AzureDevOps.API != "" {
+			o.Spec.API = &g.AzureDevOps.API
+		}
+		obj = o
+		jsonPath = "status.pullRequests"
+		params = map[string]string{
+			"number":         pr + ".pullRequestId",
+			"title":          pr + ".title",
+			"author":         pr + ".createdBy.uniqueName",
+			"branch":         pr + ".sourceBranch",
+			"branch_slug":    pr + ".sourceBranch | slugify",
+			"target_branch":  pr + ".targetBranch",
+			"head_sha":       pr + ".lastMergeSourceCommit.commitId",
+			"head_short_sha": pr + ".lastMergeSourceCommit.commitId[:8]",
+			"labels":         pr + `.labels | selectattr("active") | map(attribute="name") | list`,
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported pull request generator, only github, gitlab, gitea, bitbucketServer, bitbucket and azuredevops can be converted")
+	}
+
+	entry := &templatesv1alpha1.MatrixEntry{
+		Name: matrixName,
+		Object: &templatesv1alpha1.MatrixEntryObject{
+			Ref:         templatesv1alpha1.ObjectRefFromObject(obj),
+			JsonPath:    &jsonPath,
+			ExpandLists: true,
+		},
+	}
+	mapper := func(param string) (string, error) {
+		e, ok := params[param]
+		if !ok {
+			return "", fmt.Errorf("unsupported parameter %s", param)
+		}
+		return e, nil
+	}
+	return obj, entry, mapper, nil
+}
+
+// convertPullRequestFilters converts the branchMatch and targetBranchMatch pull request filters into regular expressions
+// that can be used for the branch filters of the List* objects. As the filters of an ApplicationSet are ORed, only a
+// single filter is supported.
+func convertPullRequestFilters(filters []map[string]any) (*string, *string, error) {
+	if len(filters) == 0 {
+		return nil, nil, nil
+	}
+	if len(filters) > 1 {
+		return nil, nil, fmt.Errorf("only a single pull request filter is supported")
+	}
+
+	var branchMatch, targetBranchMatch *string
+	for k, v := range filters[0] {
+		s, ok := v.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("pull request filter %s must be a string", k)
+		}
+		_, err := regexp.Compile(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid regex in pull request filter %s: %w", k, err)
+		}
+		s = anchorRegex(s)
+		switch k {
+		case "branchMatch":
+			branchMatch = &s
+		case "targetBranchMatch":
+			targetBranchMatch = &s
+		default:
+			return nil, nil, fmt.Errorf("unsupported pull request filter %s", k)
+		}
+	}
+	return branchMatch, targetBranchMatch, nil
+}
+
+// anchorRegex converts an unanchored regex (as used by ApplicationSet filters) into one that is wrapped with "^" and
+// "$" by the List* controllers while keeping the same semantics
+func anchorRegex(s string) string {
+	prefix, suffix := ".*", ".*"
+	if strings.HasPrefix(s, "^") {
+		s = s[1:]
+		prefix = ""
+	}
+	if strings.HasSuffix(s, "$") && !strings.HasSuffix(s, `\$`) {
+		s = s[:len(s)-1]
+		suffix = ""
+	}
+	return prefix + "(?:" + s + ")" + suffix
+}
+
+// convertTemplate converts the Application template of the ApplicationSet into an Application object with all
+// parameters replaced by Jinja2 expressions
+func (c *converter) convertTemplate(mapper paramMapper) (*unstructured.Unstructured, error) {
+	t := c.as.Spec.Template
+
+	app := map[string]any{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   t.Metadata,
+		"spec":       t.Spec,
+	}
+	// roundtrip through JSON to get rid of typed values
+	b, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := c.convertValue(m, mapper)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: converted.(map[string]any)}
+	if u.GetNamespace() == "" {
+		u.SetNamespace(c.appSet.GetNamespace())
+	}
+	return u, nil
+}
+
+func (c *converter) convertValue(v any, mapper paramMapper) (any, error) {
+	switch x := v.(type) {
+	case map[string]any:
+		ret := map[string]any{}
+		for k, v2 := range x {
+			k2, err := c.convertString(k, mapper)
+			if err != nil {
+				return nil, err
+			}
+			v3, err := c.convertValue(v2, mapper)
+			if err != nil {
+				return nil, err
+			}
+			ret[k2] = v3
+		}
+		return ret, nil
+	case []any:
+		ret := make([]any, 0, len(x))
+		for _, v2 := range x {
+			v3, err := c.convertValue(v2, mapper)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v3)
+		}
+		return ret, nil
+	case string:
+		return c.convertString(x, mapper)
+	default:
+		return v, nil
+	}
+}
+
+func (c *converter) convertString(s string, mapper paramMapper) (string, error) {
+	var err error
+	ret := fastTemplateRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		param := fastTemplateRegex.FindStringSubmatch(m)[1]
+		if c.as.Spec.GoTemplate {
+			gm := goTemplateRegex.FindStringSubmatch(param)
+			if gm == nil {
+				err = fmt.Errorf("unsupported Go template expression %s", strconv.Quote(m))
+				return m
+			}
+			param = gm[1]
+		}
+		var e string
+		e, err = mapper(param)
+		if err != nil {
+			return m
+		}
+		return "{{ " + e + " }}"
+	})
+	if err != nil {
+		return "", err
+	}
+	return ret, nil
+}
@@ -0,0 +1,83 @@
+package appset
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestAnchorRegex(t *testing.T) {
+	tests := []struct {
+		regex   string
+		want    string
+		matches []string
+		misses  []string
+	}{
+		{regex: "feature", want: ".*(?:feature).*", matches: []string{"feature", "my-feature-1"}},
+		{regex: "^feature", want: "(?:feature).*", matches: []string{"feature-1"}, misses: []string{"my-feature"}},
+		{regex: "feature$", want: ".*(?:feature)", matches: []string{"my-feature"}, misses: []string{"feature-1"}},
+		{regex: "^feature$", want: "(?:feature)", matches: []string{"feature"}, misses: []string{"feature-1", "my-feature"}},
+		{regex: `price\$`, want: `.*(?:price\$).*`, matches: []string{"price$", "the-price$-x"}, misses: []string{"price"}},
+		{regex: "a|b", want: ".*(?:a|b).*", matches: []string{"xa", "bx"}, misses: []string{"c"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.regex, func(t *testing.T) {
+			g := NewWithT(t)
+			s := anchorRegex(tc.regex)
+			g.Expect(s).To(Equal(tc.want))
+
+			// the List* controllers wrap branch filters with ^ and $
+			r := regexp.MustCompile("^" + s + "$")
+			for _, m := range tc.matches {
+				g.Expect(r.MatchString(m)).To(BeTrue(), m)
+			}
+			for _, m := range tc.misses {
+				g.Expect(r.MatchString(m)).To(BeFalse(), m)
+			}
+		})
+	}
+}
+
+func TestConvertPullRequestFilters(t *testing.T) {
+	g := NewWithT(t)
+
+	branchMatch, targetBranchMatch, err := convertPullRequestFilters(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(branchMatch).To(BeNil())
+	g.Expect(targetBranchMatch).To(BeNil())
+
+	branchMatch, targetBranchMatch, err = convertPullRequestFilters([]map[string]any{
+		{"branchMatch": "^feature-", "targetBranchMatch": "main$"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(branchMatch).To(HaveValue(Equal("(?:feature-).*")))
+	g.Expect(targetBranchMatch).To(HaveValue(Equal(".*(?:main)")))
+
+	branchMatch, targetBranchMatch, err = convertPullRequestFilters([]map[string]any{
+		{"branchMatch": "feature"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(branchMatch).To(HaveValue(Equal(".*(?:feature).*")))
+	g.Expect(targetBranchMatch).To(BeNil())
+}
+
+func TestConvertPullRequestFiltersErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []map[string]any
+		err     string
+	}{
+		{name: "multiple filters", filters: []map[string]any{{"branchMatch": "a"}, {"branchMatch": "b"}}, err: "only a single pull request filter is supported"},
+		{name: "not a string", filters: []map[string]any{{"branchMatch": 1}}, err: "pull request filter branchMatch must be a string"},
+		{name: "invalid regex", filters: []map[string]any{{"branchMatch": "("}}, err: "invalid regex in pull request filter branchMatch"},
+		{name: "unsupported", filters: []map[string]any{{"titleMatch": "a"}}, err: "unsupported pull request filter titleMatch"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			_, _, err := convertPullRequestFilters(tc.filters)
+			g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package appset
+
+import (
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The types in this file mirror the subset of the Argo CD ApplicationSet API that can be converted. They are
+// intentionally kept minimal to avoid depending on Argo CD.
+
+type applicationSet struct {
+	Spec applicationSetSpec `json:"spec"`
+}
+
+type applicationSetSpec struct {
+	GoTemplate    bool                      `json:"goTemplate,omitempty"`
+	Generators    []generator               `json:"generators"`
+	Template      applicationTemplate       `json:"template"`
+	TemplatePatch *string                   `json:"templatePatch,omitempty"`
+	SyncPolicy    *applicationSetSyncPolicy `json:"syncPolicy,omitempty"`
+}
+
+type applicationSetSyncPolicy struct {
+	PreserveResourcesOnDeletion bool `json:"preserveResourcesOnDeletion,omitempty"`
+}
+
+type applicationTemplate struct {
+	Metadata map[string]any `json:"metadata"`
+	Spec     map[string]any `json:"spec"`
+}
+
+type generator struct {
+	List        *listGenerator        `json:"list,omitempty"`
+	Git         *gitGenerator         `json:"git,omitempty"`
+	PullRequest *pullRequestGenerator `json:"pullRequest,omitempty"`
+	Matrix      *matrixGenerator      `json:"matrix,omitempty"`
+
+	Template *applicationTemplate `json:"template,omitempty"`
+	Selector map[string]any       `json:"selector,omitempty"`
+}
+
+type matrixGenerator struct {
+	Generators []generator `json:"generators"`
+}
+
+type listGenerator struct {
+	Elements     []runtime.RawExtension `json:"elements,omitempty"`
+	ElementsYaml string                 `json:"elementsYaml,omitempty"`
+}
+
+type gitGenerator struct {
+	RepoURL             string                      `json:"repoURL"`
+	Revision            string                      `json:"revision,omitempty"`
+	Directories         []gitDirectoryGeneratorItem `json:"directories,omitempty"`
+	Files               []gitFileGeneratorItem      `json:"files,omitempty"`
+	PathParamPrefix     string                      `json:"pathParamPrefix,omitempty"`
+	RequeueAfterSeconds *int64                      `json:"requeueAfterSeconds,omitempty"`
+}
+
+type gitDirectoryGeneratorItem struct {
+	Path    string `json:"path"`
+	Exclude bool   `json:"exclude,omitempty"`
+}
+
+type gitFileGeneratorItem struct {
+	Path    string `json:"path"`
+	Exclude bool   `json:"exclude,omitempty"`
+}
+
+type pullRequestGenerator struct {
+	Github              *pullRequestGeneratorGithub          `json:"github,omitempty"`
+	Gitlab              *pullRequestGeneratorGitlab          `json:"gitlab,omitempty"`
+	Gitea               *pullRequestGeneratorGitea           `json:"gitea,omitempty"`
+	BitbucketServer     *pullRequestGeneratorBitbucketServer `json:"bitbucketServer,omitempty"`
+	Bitbucket           *pullRequestGeneratorBitbucket       `json:"bitbucket,omitempty"`
+	AzureDevOps         *pullRequestGeneratorAzureDevOps     `json:"azuredevops,omitempty"`
+	Filters             []map[string]any                     `json:"filters,omitempty"`
+	RequeueAfterSeconds *int64                               `json:"requeueAfterSeconds,omitempty"`
+}
+
+type pullRequestGeneratorGithub struct {
+	Owner         string     `json:"owner"`
+	Repo          string     `json:"repo"`
+	API           string     `json:"api,omitempty"`
+	TokenRef      *secretRef `json:"tokenRef,omitempty"`
+	AppSecretName string     `json:"appSecretName,omitempty"`
+	Labels        []string   `json:"labels,omitempty"`
+}
+
+type pullRequestGeneratorGitlab struct {
+	Project          string     `json:"project"`
+	API              string     `json:"api,omitempty"`
+	TokenRef         *secretRef `json:"tokenRef,omitempty"`
+	Labels           []string   `json:"labels,omitempty"`
+	PullRequestState string     `json:"pullRequestState,omitempty"`
+}
+
+type pullRequestGeneratorGitea struct {
+	Owner    string     `json:"owner"`
+	Repo     string     `json:"repo"`
+	API      string     `json:"api"`
+	TokenRef *secretRef `json:"tokenRef,omitempty"`
+	Insecure bool       `json:"insecure,omitempty"`
+	Labels   []string   `json:"labels,omitempty"`
+}
+
+type pullRequestGeneratorBitbucketServer struct {
+	Project     string          `json:"project"`
+	Repo        string          `json:"repo"`
+	API         string          `json:"api"`
+	BasicAuth   *basicAuth      `json:"basicAuth,omitempty"`
+	BearerToken *bearerToken    `json:"bearerToken,omitempty"`
+	Insecure    bool            `json:"insecure,omitempty"`
+	CARef       *map[string]any `json:"caRef,omitempty"`
+}
+
+type pullRequestGeneratorBitbucket struct {
+	Owner       string       `json:"owner"`
+	Repo        string       `json:"repo"`
+	API         string       `json:"api,omitempty"`
+	BasicAuth   *basicAuth   `json:"basicAuth,omitempty"`
+	BearerToken *bearerToken `json:"bearerToken,omitempty"`
+}
+
+type pullRequestGeneratorAzureDevOps struct {
+	Organization string     `json:"organization"`
+	Project      string     `json:"project"`
+	Repo         string     `json:"repo"`
+	API          string     `json:"api,omitempty"`
+	TokenRef     *secretRef `json:"tokenRef,omitempty"`
+	Labels       []string   `json:"labels,omitempty"`
+}
+
+type basicAuth struct {
+	Username    string     `json:"username"`
+	PasswordRef *secretRef `json:"passwordRef,omitempty"`
+}
+
+type bearerToken struct {
+	TokenRef *secretRef `json:"tokenRef,omitempty"`
+}
+
+type secretRef struct {
+	SecretName string `json:"secretName"`
+	Key        string `json:"key"`
+}
+
+func (r *secretRef) convert() *templatesv1alpha1.SecretRef {
+	if r == nil {
+		return nil
+	}
+	return &templatesv1alpha1.SecretRef{
+		SecretName: r.SecretName,
+		Key:        r.Key,
+	}
+}
@@ -2,14 +2,21 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
 	"github.com/ohler55/ojg/jp"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -18,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sync"
+	"time"
 )
 
 type BaseTemplateReconciler struct {
@@ -26,16 +34,45 @@ type BaseTemplateReconciler struct {
 	Manager      manager.Manager
 	Scheme       *runtime.Scheme
 	FieldManager string
+	Policy       *policy.Policy
+
+	// ShutdownTimeout is the graceful shutdown timeout of the manager. In-flight reconciliations are allowed to finish
+	// within this timeout when the manager shuts down, see drainContext.
+	ShutdownTimeout time.Duration
 
 	controller   controller.Controller
 	watchedKinds map[schema.GroupVersionKind]bool
 	mutex        sync.Mutex
+
+	compiledTemplates compiledTemplateCache
+}
+
+// eventRecorder returns an event recorder that redacts secret values from event messages, or nil if no manager is set
+func (r *BaseTemplateReconciler) eventRecorder(ctx context.Context) record.EventRecorder {
+	if r.Manager == nil {
+		return nil
+	}
+	return redact.EventRecorder(ctx, r.Manager.GetEventRecorderFor("template-controller"))
 }
 
+// getClientForObjects returns a client that impersonates the given service account. All object reads, creations,
+// updates and deletions performed on behalf of a template must go through this client so that the controller's own
+// permissions are never available to templates.
 func (r *BaseTemplateReconciler) getClientForObjects(serviceAccountName string, objNamespace string) (client.Client, error) {
-	restConfig, err := config.GetConfig()
-	if err != nil {
-		return nil, err
+	return newImpersonatingClient(r.Manager, r.RESTMapper(), serviceAccountName, objNamespace)
+}
+
+// newImpersonatingClient returns a client that impersonates the given service account, see getClientForObjects
+func newImpersonatingClient(mgr manager.Manager, mapper apimeta.RESTMapper, serviceAccountName string, objNamespace string) (client.Client, error) {
+	var restConfig *rest.Config
+	if mgr != nil {
+		restConfig = rest.CopyConfig(mgr.GetConfig())
+	} else {
+		var err error
+		restConfig, err = config.GetConfig()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	name := "default"
@@ -46,15 +83,79 @@ func (r *BaseTemplateReconciler) getClientForObjects(serviceAccountName string,
 		return nil, fmt.Errorf("empty serviceAccountName not allowed")
 	}
 	username := fmt.Sprintf("system:serviceaccount:%s:%s", objNamespace, name)
-	restConfig.Impersonate = rest.ImpersonationConfig{UserName: username}
+	restConfig.Impersonate = rest.ImpersonationConfig{
+		UserName: username,
+		// these are the groups the API server would assign to the service account if it authenticated itself, which
+		// allows RBAC bindings to the service account groups to work as expected
+		Groups: []string{
+			"system:serviceaccounts",
+			fmt.Sprintf("system:serviceaccounts:%s", objNamespace),
+			"system:authenticated",
+		},
+	}
 
-	c, err := client.New(restConfig, client.Options{Mapper: r.RESTMapper()})
+	c, err := client.New(restConfig, client.Options{Mapper: mapper})
 	if err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// finishTemplateReconcile is called by the ObjectTemplate and ClusterObjectTemplate reconcilers after doReconcile
+// returned reconcileErr. It reports drift, updates the Ready and Healthy conditions of obj, patches its status and
+// computes when the next reconciliation is due. spec and status must point into obj, lastDriftCheckTime is the last
+// drift check time from before doReconcile.
+func (r *BaseTemplateReconciler) finishTemplateReconcile(ctx context.Context, obj client.Object, patch client.Patch, spec *templatesv1alpha1.ObjectTemplateSpec, status *templatesv1alpha1.ObjectTemplateStatus, lastDriftCheckTime *metav1.Time, reconcileErr error) (ctrl.Result, error) {
+	if isNewDriftCheck(lastDriftCheckTime, status.LastDriftCheckTime) {
+		r.reportDrift(ctx, obj, spec.DriftDetection, status.AppliedResources)
+	}
+
+	c := metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             "Success",
+		Message:            "Success",
+	}
+	if reconcileErr != nil {
+		c.Status = metav1.ConditionFalse
+		c.Reason, c.Message = r.reportReconcileError(ctx, obj, reconcileErr)
+	} else if status.PendingApply != nil {
+		c.Status = metav1.ConditionFalse
+		c.Reason = "Progressing"
+		c.Message = fmt.Sprintf("Waiting for %d objects to become ready, see status.pendingApply", len(status.PendingApply.NotReady))
+	} else if status.Schedule != nil && !status.Schedule.Active {
+		c.Reason = "Inactive"
+		c.Message = "Template is inactive according to its schedule"
+	} else if status.DryRun != nil {
+		c.Reason = "DryRun"
+		c.Message = "Dry-run succeeded, see status.dryRun for the changes that would be applied"
+	} else if n := countConflicts(status.AppliedResources); n != 0 {
+		c.Status = metav1.ConditionFalse
+		c.Reason = "Conflict"
+		c.Message = fmt.Sprintf("%d objects have fields owned by other field managers, see status.appliedResources", n)
+	}
+	apimeta.SetStatusCondition(&status.Conditions, c)
+	setHealthyCondition(&status.Conditions, obj.GetGeneration(), spec, status.AppliedResources)
+
+	// use a separate context so that the inventory of applied resources is persisted even when draining timed out
+	flushCtx, flushCancel := r.flushContext(ctx)
+	defer flushCancel()
+	err := r.Status().Patch(flushCtx, obj, patch, SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var result ctrl.Result
+	now := time.Now()
+	result.RequeueAfter = scheduleRequeueAfter(spec.Interval.Duration, status.Schedule, now)
+	result.RequeueAfter = matrixScheduleRequeueAfter(result.RequeueAfter, spec.Matrix, now)
+	result.RequeueAfter = rolloutRequeueAfter(result.RequeueAfter, spec.Rollout, status.Rollout, now)
+	result.RequeueAfter = driftRequeueAfter(result.RequeueAfter, spec, status.LastDriftCheckTime, now)
+	result.RequeueAfter = pendingApplyRequeueAfter(result.RequeueAfter, status)
+	return result, nil
+}
+
 func (r *BaseTemplateReconciler) addWatchForKind(ctx context.Context, gvk schema.GroupVersionKind, key string, eventHandler handler.EventHandler) error {
 	logger := log.FromContext(ctx)
 
@@ -97,6 +198,22 @@ func (r *BaseTemplateReconciler) buildBaseVars(templateObj runtime.Object, objVa
 	return vars, nil
 }
 
+// registerSecretValues registers all values of the given secret for redaction, as these might end up in error messages
+// when used in templates.
+func registerSecretValues(ctx context.Context, o *unstructured.Unstructured) {
+	data, _, _ := unstructured.NestedStringMap(o.Object, "data")
+	for _, v := range data {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err == nil {
+			redact.RegisterBytes(ctx, b)
+		}
+	}
+	stringData, _, _ := unstructured.NestedStringMap(o.Object, "stringData")
+	for _, v := range stringData {
+		redact.Register(ctx, v)
+	}
+}
+
 func (r *BaseTemplateReconciler) buildObjectInput(ctx context.Context, client client.Client, objNamespace string, ref templatesv1alpha1.ObjectRef, jsonPath *string, expandLists bool, expectOne bool) ([]any, error) {
 	gvk, err := ref.GroupVersionKind()
 	if err != nil {
@@ -106,6 +223,16 @@ func (r *BaseTemplateReconciler) buildObjectInput(ctx context.Context, client cl
 	if ref.Namespace != "" {
 		namespace = ref.Namespace
 	}
+	err = r.Policy.CheckRefNamespace(objNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if gvk.Group == "" && gvk.Kind == "Secret" {
+		err = r.Policy.CheckSecretNamespace(objNamespace, namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	var o unstructured.Unstructured
 	o.SetGroupVersionKind(gvk)
@@ -114,6 +241,9 @@ func (r *BaseTemplateReconciler) buildObjectInput(ctx context.Context, client cl
 	if err != nil {
 		return nil, err
 	}
+	if gvk.Group == "" && gvk.Kind == "Secret" {
+		registerSecretValues(ctx, &o)
+	}
 
 	var results []any
 
@@ -0,0 +1,194 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ClusterObjectTemplateReconciler reconciles a ClusterObjectTemplate object. Rendering and applying is shared with
+// the ObjectTemplateReconciler, the Policy should be derived via policy.Policy.ForClusterTemplates.
+type ClusterObjectTemplateReconciler struct {
+	ObjectTemplateReconciler
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=clusterobjecttemplates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=clusterobjecttemplates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=clusterobjecttemplates/finalizers,verbs=update
+
+// Reconcile a resource
+func (r *ClusterObjectTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := r.drainContext(ctx)
+	defer cancel()
+
+	logger := log.FromContext(ctx)
+
+	logger.V(1).Info("Starting reconcile")
+	defer logger.V(1).Info("Finished reconcile", "err", err)
+
+	var crt templatesv1alpha1.ClusterObjectTemplate
+	err = r.Get(ctx, req.NamespacedName, &crt)
+	if err != nil {
+		logger.Error(err, "Get failed")
+		if errors.IsNotFound(err) {
+			r.compiledTemplates.remove(fmt.Sprintf("ClusterObjectTemplate/%s", req.Name))
+		}
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	// Add our finalizer if it does not exist
+	if !controllerutil.ContainsFinalizer(&crt, templatesv1alpha1.ObjectTemplateFinalizer) {
+		patch := client.MergeFrom(crt.DeepCopy())
+		controllerutil.AddFinalizer(&crt, templatesv1alpha1.ObjectTemplateFinalizer)
+		if err := r.Patch(ctx, &crt, patch, client.FieldOwner(r.FieldManager)); err != nil {
+			logger.Error(err, "unable to register finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	rt := r.toObjectTemplate(&crt)
+
+	// Examine if the object is under deletion
+	if !crt.GetDeletionTimestamp().IsZero() {
+		err := r.finalizeAppliedObjects(ctx, &crt, rt, fmt.Sprintf("ClusterObjectTemplate/%s", crt.GetName()))
+		if err != nil {
+			// keep the finalizer so that deletion is retried
+			return ctrl.Result{}, err
+		}
+
+		// Remove our finalizer from the list and update it
+		controllerutil.RemoveFinalizer(&crt, templatesv1alpha1.ObjectTemplateFinalizer)
+		if err := r.Update(ctx, &crt, client.FieldOwner(r.FieldManager)); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Stop reconciliation as the object is being deleted
+		return ctrl.Result{}, nil
+	}
+
+	// Return early if the object is suspended.
+	if crt.Spec.Suspend {
+		logger.Info("Reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
+	}
+
+	err = r.addWatchesForSpec(ctx, &crt.Spec.ObjectTemplateSpec, r.buildClusterWatchEventHandler)
+	if err != nil {
+		return
+	}
+
+	patch := client.MergeFrom(crt.DeepCopy())
+	lastDriftCheckTime := crt.Status.LastDriftCheckTime
+	err = r.doReconcile(ctx, rt, fmt.Sprintf("ClusterObjectTemplate/%s", crt.GetName()))
+	// doReconcile only works on the converted ObjectTemplate, which carries a copy of the whole status
+	crt.Status = rt.Status
+	return r.finishTemplateReconcile(ctx, &crt, patch, &crt.Spec.ObjectTemplateSpec, &crt.Status, lastDriftCheckTime, err)
+}
+
+// toObjectTemplate converts the ClusterObjectTemplate into an ObjectTemplate that lives in the service account's
+// namespace, so that all rendering and applying logic can be shared with ObjectTemplate.
+func (r *ClusterObjectTemplateReconciler) toObjectTemplate(crt *templatesv1alpha1.ClusterObjectTemplate) *templatesv1alpha1.ObjectTemplate {
+	rt := &templatesv1alpha1.ObjectTemplate{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: templatesv1alpha1.GroupVersion.String(),
+			Kind:       "ClusterObjectTemplate",
+		},
+		ObjectMeta: *crt.ObjectMeta.DeepCopy(),
+		Spec:       *crt.Spec.ObjectTemplateSpec.DeepCopy(),
+		Status:     *crt.Status.DeepCopy(),
+	}
+	rt.SetNamespace(crt.Spec.ServiceAccountNamespace)
+	return rt
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterObjectTemplateReconciler) SetupWithManager(mgr ctrl.Manager, concurrent int) error {
+	r.Manager = mgr
+
+	// Index the ClusterObjectTemplates by the objects they use as matrix inputs or include.
+	if err := mgr.GetCache().IndexField(context.TODO(), &templatesv1alpha1.ClusterObjectTemplate{}, forMatrixObjectKey,
+		func(object client.Object) []string {
+			o := object.(*templatesv1alpha1.ClusterObjectTemplate)
+			var ret []string
+			for _, ref := range specObjectRefs(&o.Spec.ObjectTemplateSpec) {
+				ret = append(ret, BuildRefIndexValue(ref, o.Spec.ServiceAccountNamespace))
+			}
+			return ret
+		}); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+	if err := mgr.GetCache().IndexField(context.TODO(), &templatesv1alpha1.ClusterObjectTemplate{}, forLibraryKey,
+		func(object client.Object) []string {
+			o := object.(*templatesv1alpha1.ClusterObjectTemplate)
+			return buildLibraryIndexValues(o.Spec.Templates, o.Spec.ServiceAccountNamespace)
+		}); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.ClusterObjectTemplate{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, ReconcileRequestedPredicate{}),
+		)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrent,
+		}).
+		Build(redact.Reconciler(r))
+	if err != nil {
+		return err
+	}
+	r.controller = c
+
+	return nil
+}
+
+func (r *ClusterObjectTemplateReconciler) buildClusterWatchEventHandler(indexField string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		var list templatesv1alpha1.ClusterObjectTemplateList
+
+		err := r.List(context.Background(), &list, client.MatchingFields{
+			indexField: BuildObjectIndexValue(object),
+		})
+		if err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, x := range list.Items {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: x.GetName(),
+				},
+			})
+		}
+		return reqs
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
 	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
 	"github.com/kluctl/template-controller/controllers/webgit"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -15,6 +17,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -25,6 +28,7 @@ type BaseCommentReconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
 	FieldManager string
+	Policy       *policy.Policy
 }
 
 type GetCommentSourceSpec interface {
@@ -77,6 +81,11 @@ func (r *BaseCommentReconciler) getText(ctx context.Context, spec templatesv1alp
 }
 
 func (r *BaseCommentReconciler) reconcileComment(ctx context.Context, mr webgit.MergeRequestInterface, tag string, commentId *string, obj client.Object, noteId *string, lastPostedBodyHash *string) error {
+	err := mr.CheckTokenScopes(ctx, "comments")
+	if err != nil {
+		return err
+	}
+
 	clusterId, err := r.getClusterId(ctx)
 	if err != nil {
 		return err
@@ -135,6 +144,50 @@ func (r *BaseCommentReconciler) reconcileComment(ctx context.Context, mr webgit.
 	return nil
 }
 
+// reconcileFinalizer adds or removes the finalizer depending on prune. If the object is being deleted, the comment is
+// deleted and the finalizer removed. It returns true if the object is being deleted.
+func (r *BaseCommentReconciler) reconcileFinalizer(ctx context.Context, obj client.Object, spec templatesv1alpha1.CommentSpec, suspend bool, noteId string, buildMr func() (webgit.MergeRequestInterface, error)) (bool, error) {
+	hasFinalizer := controllerutil.ContainsFinalizer(obj, templatesv1alpha1.CommentFinalizer)
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		if spec.Prune == hasFinalizer {
+			return false, nil
+		}
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		if spec.Prune {
+			controllerutil.AddFinalizer(obj, templatesv1alpha1.CommentFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(obj, templatesv1alpha1.CommentFinalizer)
+		}
+		return false, r.Patch(ctx, obj, patch, client.FieldOwner(r.FieldManager))
+	}
+
+	if !hasFinalizer {
+		return true, nil
+	}
+
+	if spec.Prune && !suspend && noteId != "" {
+		mr, err := buildMr()
+		if err != nil {
+			return true, err
+		}
+		note, err := mr.GetMergeRequestNote(noteId)
+		if err != nil {
+			return true, err
+		}
+		if note != nil {
+			err = note.Delete()
+			if err != nil {
+				return true, err
+			}
+		}
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	controllerutil.RemoveFinalizer(obj, templatesv1alpha1.CommentFinalizer)
+	return true, r.Patch(ctx, obj, patch, client.FieldOwner(r.FieldManager))
+}
+
 func (r *BaseCommentReconciler) findNote(mr webgit.MergeRequestInterface, clusterId string, commentId *string, tag string, obj client.Object) (webgit.Note, error) {
 	notes, err := mr.ListMergeRequestNotes()
 	if err != nil {
@@ -231,5 +284,5 @@ func (r *BaseCommentReconciler) baseSetupWithManager(mgr ctrl.Manager, r2 reconc
 		)).
 		Watches(&corev1.ConfigMap{}, watchHandler).
 		Watches(&templatesv1alpha1.TextTemplate{}, watchHandler).
-		Complete(r2)
+		Complete(redact.Reconciler(r2))
 }
@@ -28,6 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
 )
 
 // GitlabCommentReconciler reconciles a GitlabComment object
@@ -54,6 +55,13 @@ func (r *GitlabCommentReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return
 	}
 
+	deleted, err := r.reconcileFinalizer(ctx, &gc, gc.Spec.CommentSpec, gc.Spec.Suspend, gc.Status.NoteId, func() (webgit.MergeRequestInterface, error) {
+		return webgit.BuildWebgitMergeRequestGitlab(ctx, r.Client, gc.GetNamespace(), r.Policy, gc.Spec.GitlabMergeRequestRef)
+	})
+	if err != nil || deleted {
+		return
+	}
+
 	// Return early if the object is suspended.
 	if gc.Spec.Suspend {
 		logger.Info("Reconciliation is suspended for this object")
@@ -68,7 +76,7 @@ func (r *GitlabCommentReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: gc.GetGeneration(),
 			Reason:             "Error",
-			Message:            err.Error(),
+			Message:            redact.Error(ctx, err),
 		}
 		apimeta.SetStatusCondition(&gc.Status.Conditions, c)
 	} else {
@@ -81,12 +89,13 @@ func (r *GitlabCommentReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 		apimeta.SetStatusCondition(&gc.Status.Conditions, c)
 	}
+	controllers.SetStalledCondition(&gc.Status.Conditions, gc.GetGeneration(), err)
 	err = r.Status().Patch(ctx, &gc, patch, controllers.SubResourceFieldOwner(r.FieldManager))
 	return
 }
 
 func (r *GitlabCommentReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.GitlabComment) error {
-	mr, err := webgit.BuildWebgitMergeRequestGitlab(ctx, r.Client, obj.GetNamespace(), obj.Spec.GitlabMergeRequestRef)
+	mr, err := webgit.BuildWebgitMergeRequestGitlab(ctx, r.Client, obj.GetNamespace(), r.Policy, obj.Spec.GitlabMergeRequestRef)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+)
+
+// objectDependencies maps rendered objects to the rendered objects they depend on
+type objectDependencies map[*unstructured.Unstructured][]*unstructured.Unstructured
+
+// applyState tracks the apply of a single object, so that dependent objects can wait for it. err and ready must be set
+// before done is closed.
+type applyState struct {
+	done  chan struct{}
+	err   error
+	ready bool
+}
+
+type dependencyRef struct {
+	gk        schema.GroupKind
+	namespace string
+	name      string
+}
+
+func parseDependsOn(s string) ([]dependencyRef, error) {
+	var ret []dependencyRef
+	for _, x := range strings.Split(s, ",") {
+		x = strings.TrimSpace(x)
+		if x == "" {
+			continue
+		}
+		var ref dependencyRef
+		parts := strings.Split(x, "/")
+		switch len(parts) {
+		case 2:
+			ref.name = parts[1]
+		case 3:
+			ref.namespace = parts[1]
+			ref.name = parts[2]
+		}
+		ref.gk = schema.ParseGroupKind(parts[0])
+		if ref.gk.Kind == "" || ref.name == "" {
+			return nil, fmt.Errorf("invalid reference %q, must be in the form <kind>[.<group>]/[<namespace>/]<name>", x)
+		}
+		ret = append(ret, ref)
+	}
+	return ret, nil
+}
+
+// resolveDependencies resolves the depends-on annotations of all rendered objects. References without a namespace
+// refer to objects in the namespace of the dependent object or to cluster-scoped objects. Dependencies on objects that
+// are not rendered, on objects of later waves and dependency cycles are rejected.
+func resolveDependencies(waves []*objectWave) (objectDependencies, error) {
+	var all []*unstructured.Unstructured
+	waveOf := map[*unstructured.Unstructured]int{}
+	for _, w := range waves {
+		for _, x := range w.objects {
+			all = append(all, x)
+			waveOf[x] = w.wave
+		}
+	}
+
+	deps := objectDependencies{}
+	for _, x := range all {
+		s, ok := x.GetAnnotations()[templatesv1alpha1.DependsOnAnnotation]
+		if !ok {
+			continue
+		}
+		xRef := templatesv1alpha1.ObjectRefFromObject(x)
+		refs, err := parseDependsOn(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation on %s: %w", templatesv1alpha1.DependsOnAnnotation, xRef.String(), err)
+		}
+		for _, ref := range refs {
+			d := findDependency(all, x, ref)
+			if d == nil {
+				return nil, fmt.Errorf("%s depends on %s/%s, which is not a rendered object", xRef.String(), ref.gk.String(), ref.name)
+			}
+			dRef := templatesv1alpha1.ObjectRefFromObject(d)
+			if waveOf[d] > waveOf[x] {
+				return nil, fmt.Errorf("%s depends on %s, which is part of the later wave %d", xRef.String(), dRef.String(), waveOf[d])
+			}
+			deps[x] = append(deps[x], d)
+		}
+	}
+
+	// detect cycles via depth-first search
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*unstructured.Unstructured]int{}
+	var visit func(x *unstructured.Unstructured) error
+	visit = func(x *unstructured.Unstructured) error {
+		switch state[x] {
+		case visiting:
+			ref := templatesv1alpha1.ObjectRefFromObject(x)
+			return fmt.Errorf("dependency cycle detected involving %s", ref.String())
+		case visited:
+			return nil
+		}
+		state[x] = visiting
+		for _, d := range deps[x] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		state[x] = visited
+		return nil
+	}
+	for _, x := range all {
+		if err := visit(x); err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
+}
+
+func findDependency(all []*unstructured.Unstructured, dependent *unstructured.Unstructured, ref dependencyRef) *unstructured.Unstructured {
+	var clusterScoped *unstructured.Unstructured
+	for _, x := range all {
+		if x.GroupVersionKind().GroupKind() != ref.gk || x.GetName() != ref.name {
+			continue
+		}
+		if ref.namespace != "" {
+			if x.GetNamespace() == ref.namespace {
+				return x
+			}
+			continue
+		}
+		if x.GetNamespace() == dependent.GetNamespace() {
+			return x
+		}
+		if x.GetNamespace() == "" {
+			clusterScoped = x
+		}
+	}
+	return clusterScoped
+}
+
+// waitForDependencies waits until all dependencies of the same wave have been applied and returns the dependencies
+// that are not ready. Dependencies of earlier waves are always ready, as later waves are only applied after all objects
+// of earlier waves became ready.
+func waitForDependencies(ctx context.Context, deps []*unstructured.Unstructured, states map[*unstructured.Unstructured]*applyState) ([]*unstructured.Unstructured, error) {
+	var notReady []*unstructured.Unstructured
+	for _, d := range deps {
+		s, ok := states[d]
+		if !ok {
+			continue
+		}
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if s.err != nil {
+			ref := templatesv1alpha1.ObjectRefFromObject(d)
+			return nil, fmt.Errorf("dependency %s failed to apply", ref.String())
+		}
+		if !s.ready {
+			notReady = append(notReady, d)
+		}
+	}
+	return notReady, nil
+}
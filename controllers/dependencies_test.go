@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newTestObject(apiVersion string, kind string, namespace string, name string, annotations map[string]string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion(apiVersion)
+	o.SetKind(kind)
+	o.SetNamespace(namespace)
+	o.SetName(name)
+	o.SetAnnotations(annotations)
+	return o
+}
+
+func TestParseDependsOn(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []dependencyRef
+		wantErr bool
+	}{
+		{s: "", want: nil},
+		{s: "ConfigMap/cm1", want: []dependencyRef{{gk: schema.GroupKind{Kind: "ConfigMap"}, name: "cm1"}}},
+		{s: "Deployment.apps/ns1/app", want: []dependencyRef{{gk: schema.GroupKind{Group: "apps", Kind: "Deployment"}, namespace: "ns1", name: "app"}}},
+		{s: " ConfigMap/cm1 , Secret/s1,", want: []dependencyRef{
+			{gk: schema.GroupKind{Kind: "ConfigMap"}, name: "cm1"},
+			{gk: schema.GroupKind{Kind: "Secret"}, name: "s1"},
+		}},
+		{s: "ConfigMap", wantErr: true},
+		{s: "ConfigMap/", wantErr: true},
+		{s: "/cm1", wantErr: true},
+		{s: "ConfigMap/a/b/c", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.s, func(t *testing.T) {
+			g := NewWithT(t)
+			refs, err := parseDependsOn(tc.s)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(refs).To(Equal(tc.want))
+		})
+	}
+}
+
+func dependsOn(s string) map[string]string {
+	return map[string]string{templatesv1alpha1.DependsOnAnnotation: s}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	g := NewWithT(t)
+
+	ns := newTestObject("v1", "Namespace", "", "ns1", nil)
+	cm := newTestObject("v1", "ConfigMap", "ns1", "cm1", dependsOn("Namespace/ns1"))
+	deploy := newTestObject("apps/v1", "Deployment", "ns1", "app", dependsOn("ConfigMap/cm1"))
+	other := newTestObject("v1", "ConfigMap", "ns2", "cm1", dependsOn("ConfigMap/ns1/cm1"))
+
+	waves, err := groupWaves([]*unstructured.Unstructured{ns, cm, deploy, other})
+	g.Expect(err).ToNot(HaveOccurred())
+	deps, err := resolveDependencies(waves)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// references without namespace resolve to cluster-scoped objects or objects of the dependent's namespace
+	g.Expect(deps[cm]).To(Equal([]*unstructured.Unstructured{ns}))
+	g.Expect(deps[deploy]).To(Equal([]*unstructured.Unstructured{cm}))
+	g.Expect(deps[other]).To(Equal([]*unstructured.Unstructured{cm}))
+	g.Expect(deps).ToNot(HaveKey(ns))
+}
+
+func TestResolveDependenciesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []*unstructured.Unstructured
+		err     string
+	}{
+		{
+			name: "cycle",
+			objects: []*unstructured.Unstructured{
+				newTestObject("v1", "ConfigMap", "ns1", "a", dependsOn("ConfigMap/b")),
+				newTestObject("v1", "ConfigMap", "ns1", "b", dependsOn("ConfigMap/c")),
+				newTestObject("v1", "ConfigMap", "ns1", "c", dependsOn("ConfigMap/a")),
+			},
+			err: "dependency cycle detected",
+		},
+		{
+			name: "self",
+			objects: []*unstructured.Unstructured{
+				newTestObject("v1", "ConfigMap", "ns1", "a", dependsOn("ConfigMap/a")),
+			},
+			err: "dependency cycle detected",
+		},
+		{
+			name: "not rendered",
+			objects: []*unstructured.Unstructured{
+				newTestObject("v1", "ConfigMap", "ns1", "a", dependsOn("ConfigMap/missing")),
+			},
+			err: "which is not a rendered object",
+		},
+		{
+			name: "other namespace",
+			objects: []*unstructured.Unstructured{
+				newTestObject("v1", "ConfigMap", "ns1", "a", dependsOn("ConfigMap/b")),
+				newTestObject("v1", "ConfigMap", "ns2", "b", nil),
+			},
+			err: "which is not a rendered object",
+		},
+		{
+			name: "later wave",
+			objects: []*unstructured.Unstructured{
+				newTestObject("v1", "ConfigMap", "ns1", "a", dependsOn("ConfigMap/b")),
+				newTestObject("v1", "ConfigMap", "ns1", "b", map[string]string{templatesv1alpha1.WaveAnnotation: "1"}),
+			},
+			err: "which is part of the later wave 1",
+		},
+		{
+			name: "invalid",
+			objects: []*unstructured.Unstructured{
+				newTestObject("v1", "ConfigMap", "ns1", "a", dependsOn("b")),
+			},
+			err: "invalid templates.kluctl.io/depends-on annotation",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			waves, err := groupWaves(tc.objects)
+			g.Expect(err).ToNot(HaveOccurred())
+			_, err = resolveDependencies(waves)
+			g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+		})
+	}
+}
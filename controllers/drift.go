@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"strings"
+	"time"
+)
+
+const (
+	driftDetectedReason  = "DriftDetected"
+	driftCorrectedReason = "DriftCorrected"
+
+	defaultDriftDetectionInterval = 10 * time.Minute
+)
+
+// driftedFieldsTotal only uses bounded labels, the drifted objects and fields are reported via Events and the status
+var driftedFieldsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "template_controller_drifted_fields_total",
+	Help: "Number of fields of applied objects that were detected to have drifted from the rendered state.",
+}, []string{"template", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(driftedFieldsTotal)
+}
+
+func isDriftDetectionEnabled(spec *templatesv1alpha1.ObjectTemplateSpec) bool {
+	return spec.DriftDetection == templatesv1alpha1.DriftDetectionWarn || spec.DriftDetection == templatesv1alpha1.DriftDetectionCorrect
+}
+
+func driftDetectionInterval(spec *templatesv1alpha1.ObjectTemplateSpec) time.Duration {
+	d := defaultDriftDetectionInterval
+	if spec.DriftDetectionInterval != nil {
+		d = spec.DriftDetectionInterval.Duration
+	}
+	if d < time.Second {
+		d = time.Second
+	}
+	return d
+}
+
+// isDriftCheckDue returns true if unchanged objects must be checked for drift in the current reconciliation
+func isDriftCheckDue(rt *templatesv1alpha1.ObjectTemplate, now time.Time) bool {
+	if !isDriftDetectionEnabled(&rt.Spec) {
+		return false
+	}
+	last := rt.Status.LastDriftCheckTime
+	return last == nil || now.Sub(last.Time) >= driftDetectionInterval(&rt.Spec)
+}
+
+// isNewDriftCheck returns true if a drift check was performed, based on the last drift check time before and after
+// the reconciliation
+func isNewDriftCheck(before *metav1.Time, after *metav1.Time) bool {
+	return after != nil && !after.Equal(before)
+}
+
+// driftRequeueAfter returns the time after which the next drift check is due, if it is earlier than requeueAfter
+func driftRequeueAfter(requeueAfter time.Duration, spec *templatesv1alpha1.ObjectTemplateSpec, lastCheck *metav1.Time, now time.Time) time.Duration {
+	if !isDriftDetectionEnabled(spec) || lastCheck == nil {
+		return requeueAfter
+	}
+	d := driftDetectionInterval(spec) - now.Sub(lastCheck.Time)
+	if d < time.Second {
+		d = time.Second
+	}
+	if d < requeueAfter {
+		return d
+	}
+	return requeueAfter
+}
+
+// applyObject applies rendered and returns the resulting applied resource info. With drift detection enabled, objects
+// that did not change since their last successful apply (prev) are not re-applied, so that changes made outside of
+// the template are not silently reverted. Such objects are only compared with the live object when checkDrift is
+// true, and only re-applied if drift was detected in Correct mode.
+func (r *ObjectTemplateReconciler) applyObject(ctx context.Context, objClient client.Client, reviews *accessReviews, rt *templatesv1alpha1.ObjectTemplate, rendered *unstructured.Unstructured, prev *templatesv1alpha1.AppliedResourceInfo, checkDrift bool) (templatesv1alpha1.AppliedResourceInfo, error) {
+	ari := templatesv1alpha1.AppliedResourceInfo{
+		Ref:          templatesv1alpha1.ObjectRefFromObject(rendered),
+		Success:      true,
+		RenderedHash: rendered.GetAnnotations()[templatesv1alpha1.RenderedHashAnnotation],
+	}
+	apply := func(force bool) (templatesv1alpha1.AppliedResourceInfo, error) {
+		err := r.applyRenderedObject(ctx, objClient, reviews, rt, rendered, force)
+		if err != nil {
+			return ari, err
+		}
+		ari.Health, ari.HealthMessage = computeAppliedHealth(ctx, rendered)
+		return ari, nil
+	}
+
+	unchanged := isDriftDetectionEnabled(&rt.Spec) && prev != nil && prev.Success && prev.RenderedHash == ari.RenderedHash
+	if !unchanged {
+		return apply(false)
+	}
+
+	if !checkDrift {
+		ari.DriftedFields = prev.DriftedFields
+		ari.Health, ari.HealthMessage = prev.Health, prev.HealthMessage
+		if prev.Health == templatesv1alpha1.HealthHealthy {
+			return ari, nil
+		}
+		// objects that are not healthy yet are expected to change, so their health is refreshed on every reconciliation
+		var live unstructured.Unstructured
+		live.SetGroupVersionKind(rendered.GroupVersionKind())
+		err := objClient.Get(ctx, client.ObjectKeyFromObject(rendered), &live)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return apply(false)
+			}
+			return ari, err
+		}
+		ari.Health, ari.HealthMessage = computeAppliedHealth(ctx, &live)
+		return ari, nil
+	}
+
+	drifted, live, err := r.detectDrift(ctx, objClient, rt, rendered)
+	if err != nil {
+		return ari, err
+	}
+	if live == nil {
+		// the object got deleted in the meantime
+		return apply(false)
+	}
+	ari.DriftedFields = drifted
+	if drifted != nil && rt.Spec.DriftDetection == templatesv1alpha1.DriftDetectionCorrect {
+		// correcting drift requires to take back ownership of fields changed by other managers
+		return apply(true)
+	}
+	ari.Health, ari.HealthMessage = computeAppliedHealth(ctx, live)
+	return ari, nil
+}
+
+// detectDrift compares the live object with the result of a server-side dry-run apply of rendered and returns the
+// paths of all fields that differ, together with the live object. nil is returned for the live object if it does not
+// exist.
+func (r *ObjectTemplateReconciler) detectDrift(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, rendered *unstructured.Unstructured) ([]string, *unstructured.Unstructured, error) {
+	var live unstructured.Unstructured
+	live.SetGroupVersionKind(rendered.GroupVersionKind())
+	err := objClient.Get(ctx, client.ObjectKeyFromObject(rendered), &live)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if _, err := checkAdoption(rt, rendered, &live); err != nil {
+		return nil, nil, err
+	}
+
+	// ownership is forced, as changes made via update operations (e.g. kubectl edit) transfer the ownership of the
+	// changed fields to another manager
+	applied := rendered.DeepCopy()
+	err = objClient.Patch(ctx, applied, client.Apply, append(r.getApplyOptions(rt, true), client.DryRunAll)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gvk := rendered.GroupVersionKind()
+	isSecret := gvk.Group == "" && gvk.Kind == "Secret"
+	changes := diffObjects(ctx, live.Object, applied.Object, isSecret)
+	if len(changes) == 0 {
+		return nil, &live, nil
+	}
+	paths := make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	return paths, &live, nil
+}
+
+// recordDriftMetrics increments the drift metric by the number of drifted fields of the given object
+func recordDriftMetrics(owner string, kind string, paths []string) {
+	driftedFieldsTotal.WithLabelValues(owner, kind).Add(float64(len(paths)))
+}
+
+// reportDrift emits a warning Event on obj for every applied resource that drifted. It must only be called after a
+// drift check was performed, so that persisting drift is reported once per check.
+func (r *BaseTemplateReconciler) reportDrift(ctx context.Context, obj runtime.Object, driftDetection string, appliedResources []templatesv1alpha1.AppliedResourceInfo) {
+	recorder := r.eventRecorder(ctx)
+	if recorder == nil {
+		return
+	}
+
+	reason := driftDetectedReason
+	if driftDetection == templatesv1alpha1.DriftDetectionCorrect {
+		reason = driftCorrectedReason
+	}
+	for _, ari := range appliedResources {
+		if len(ari.DriftedFields) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("%s has drifted from the rendered state: %s", ari.Ref.String(), strings.Join(ari.DriftedFields, ", "))
+		if reason == driftCorrectedReason {
+			msg = fmt.Sprintf("Corrected drift of %s: %s", ari.Ref.String(), strings.Join(ari.DriftedFields, ", "))
+		}
+		recorder.Event(obj, corev1.EventTypeWarning, reason, msg)
+	}
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const driftTestOwner = "ObjectTemplate/ns1/t1"
+
+func newDriftTemplate(mode string) *templatesv1alpha1.ObjectTemplate {
+	rt := &templatesv1alpha1.ObjectTemplate{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "t1"}}
+	rt.Spec.DriftDetection = mode
+	return rt
+}
+
+func newDriftConfigMap(value string) *unstructured.Unstructured {
+	o := newTestObject("v1", "ConfigMap", "ns1", "cm1", map[string]string{
+		templatesv1alpha1.TemplateAnnotation:     driftTestOwner,
+		templatesv1alpha1.RenderedHashAnnotation: "hash",
+	})
+	_ = unstructured.SetNestedField(o.Object, value, "data", "key")
+	return o
+}
+
+// driftTestPatch records a server-side apply performed via the fake client
+type driftTestPatch struct {
+	dryRun bool
+	force  bool
+}
+
+// newDriftTestReconciler returns a reconciler and an impersonated client containing objs. The fake client does not
+// support server-side apply, so apply patches are only recorded and the dry-run result equals the rendered object.
+func newDriftTestReconciler(patches *[]driftTestPatch, objs ...client.Object) (*ObjectTemplateReconciler, client.Client) {
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, apimeta.RESTScopeNamespace)
+	r := &ObjectTemplateReconciler{}
+	r.FieldManager = "template-controller"
+	r.Client = fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+
+	objClient := fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if sar, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+				sar.Status.Allowed = true
+				return nil
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			var po client.PatchOptions
+			po.ApplyOptions(opts)
+			*patches = append(*patches, driftTestPatch{
+				dryRun: len(po.DryRun) != 0,
+				force:  po.Force != nil && *po.Force,
+			})
+			return nil
+		},
+	}).Build()
+	return r, objClient
+}
+
+func TestIsDriftCheckDue(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	rt := newDriftTemplate(templatesv1alpha1.DriftDetectionOff)
+	g.Expect(isDriftCheckDue(rt, now)).To(BeFalse())
+
+	rt = newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+	g.Expect(isDriftCheckDue(rt, now)).To(BeTrue())
+
+	rt.Status.LastDriftCheckTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	g.Expect(isDriftCheckDue(rt, now)).To(BeFalse())
+	rt.Status.LastDriftCheckTime = &metav1.Time{Time: now.Add(-defaultDriftDetectionInterval)}
+	g.Expect(isDriftCheckDue(rt, now)).To(BeTrue())
+
+	rt.Spec.DriftDetectionInterval = &metav1.Duration{Duration: 30 * time.Second}
+	rt.Status.LastDriftCheckTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	g.Expect(isDriftCheckDue(rt, now)).To(BeTrue())
+}
+
+func TestDriftDetectionInterval(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := &templatesv1alpha1.ObjectTemplateSpec{}
+	g.Expect(driftDetectionInterval(spec)).To(Equal(defaultDriftDetectionInterval))
+	spec.DriftDetectionInterval = &metav1.Duration{Duration: time.Minute}
+	g.Expect(driftDetectionInterval(spec)).To(Equal(time.Minute))
+	spec.DriftDetectionInterval = &metav1.Duration{}
+	g.Expect(driftDetectionInterval(spec)).To(Equal(time.Second))
+}
+
+func TestDriftRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	lastCheck := &metav1.Time{Time: now.Add(-4 * time.Minute)}
+	spec := &templatesv1alpha1.ObjectTemplateSpec{DriftDetection: templatesv1alpha1.DriftDetectionWarn}
+
+	g.Expect(driftRequeueAfter(time.Hour, spec, lastCheck, now)).To(Equal(6 * time.Minute))
+	g.Expect(driftRequeueAfter(time.Minute, spec, lastCheck, now)).To(Equal(time.Minute))
+	g.Expect(driftRequeueAfter(time.Hour, spec, nil, now)).To(Equal(time.Hour))
+	g.Expect(driftRequeueAfter(time.Hour, spec, &metav1.Time{Time: now.Add(-time.Hour)}, now)).To(Equal(time.Second))
+	g.Expect(driftRequeueAfter(time.Hour, &templatesv1alpha1.ObjectTemplateSpec{}, lastCheck, now)).To(Equal(time.Hour))
+}
+
+func TestIsNewDriftCheck(t *testing.T) {
+	g := NewWithT(t)
+
+	t1 := &metav1.Time{Time: time.Now().Truncate(time.Second)}
+	t2 := &metav1.Time{Time: t1.Add(time.Minute)}
+	g.Expect(isNewDriftCheck(nil, nil)).To(BeFalse())
+	g.Expect(isNewDriftCheck(t1, nil)).To(BeFalse())
+	g.Expect(isNewDriftCheck(t1, &metav1.Time{Time: t1.Time})).To(BeFalse())
+	g.Expect(isNewDriftCheck(nil, t1)).To(BeTrue())
+	g.Expect(isNewDriftCheck(t1, t2)).To(BeTrue())
+}
+
+func TestDetectDrift(t *testing.T) {
+	g := NewWithT(t)
+
+	var patches []driftTestPatch
+	rt := newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+	r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+	drifted, live, err := r.detectDrift(context.Background(), objClient, rt, newDriftConfigMap("changed"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(drifted).To(BeNil())
+	g.Expect(live).ToNot(BeNil())
+
+	drifted, _, err = r.detectDrift(context.Background(), objClient, rt, newDriftConfigMap("rendered"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(drifted).To(Equal([]string{"data.key"}))
+
+	// the dry-run apply must force ownership, as fields changed via updates are owned by other managers
+	g.Expect(patches).To(HaveLen(2))
+	g.Expect(patches[1]).To(Equal(driftTestPatch{dryRun: true, force: true}))
+
+	rendered := newDriftConfigMap("rendered")
+	rendered.SetName("missing")
+	drifted, live, err = r.detectDrift(context.Background(), objClient, rt, rendered)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(drifted).To(BeNil())
+	g.Expect(live).To(BeNil())
+
+	// objects taken over by other templates are not reported as drift
+	other := newDriftConfigMap("changed")
+	other.SetAnnotations(map[string]string{templatesv1alpha1.TemplateAnnotation: "ObjectTemplate/ns1/t2"})
+	r, objClient = newDriftTestReconciler(&patches, other)
+	_, _, err = r.detectDrift(context.Background(), objClient, rt, newDriftConfigMap("rendered"))
+	g.Expect(err).To(MatchError(ContainSubstring("is managed by ObjectTemplate/ns1/t2")))
+}
+
+func TestApplyObjectDrift(t *testing.T) {
+	prev := &templatesv1alpha1.AppliedResourceInfo{
+		Success:      true,
+		RenderedHash: "hash",
+		Health:       templatesv1alpha1.HealthHealthy,
+	}
+
+	t.Run("changed", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+		r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+		changedPrev := prev.DeepCopy()
+		changedPrev.RenderedHash = "other"
+		ari, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), changedPrev, false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ari.Success).To(BeTrue())
+		g.Expect(patches).To(Equal([]driftTestPatch{{}}))
+	})
+
+	t.Run("drift detection off", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionOff)
+		r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+		_, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), prev, false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(patches).To(Equal([]driftTestPatch{{}}))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+		r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+		prevDrifted := prev.DeepCopy()
+		prevDrifted.DriftedFields = []string{"data.key"}
+		ari, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), prevDrifted, false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ari.Health).To(Equal(templatesv1alpha1.HealthHealthy))
+		g.Expect(ari.DriftedFields).To(Equal([]string{"data.key"}))
+		g.Expect(patches).To(BeEmpty())
+	})
+
+	t.Run("unchanged and not healthy", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+		r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+		progressing := prev.DeepCopy()
+		progressing.Health = templatesv1alpha1.HealthProgressing
+		ari, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), progressing, false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ari.Health).To(Equal(templatesv1alpha1.HealthHealthy))
+		g.Expect(patches).To(BeEmpty())
+	})
+
+	t.Run("unchanged and deleted", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+		r, objClient := newDriftTestReconciler(&patches)
+
+		progressing := prev.DeepCopy()
+		progressing.Health = templatesv1alpha1.HealthProgressing
+		_, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), progressing, false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(patches).To(Equal([]driftTestPatch{{}}))
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionWarn)
+		r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+		ari, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), prev, true)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ari.DriftedFields).To(Equal([]string{"data.key"}))
+		g.Expect(ari.Health).To(Equal(templatesv1alpha1.HealthHealthy))
+		// drift is only reported, the changed object is not re-applied
+		g.Expect(patches).To(Equal([]driftTestPatch{{dryRun: true, force: true}}))
+	})
+
+	t.Run("correct", func(t *testing.T) {
+		g := NewWithT(t)
+		var patches []driftTestPatch
+		rt := newDriftTemplate(templatesv1alpha1.DriftDetectionCorrect)
+		r, objClient := newDriftTestReconciler(&patches, newDriftConfigMap("changed"))
+
+		ari, err := r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), prev, true)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ari.DriftedFields).To(Equal([]string{"data.key"}))
+		g.Expect(patches).To(Equal([]driftTestPatch{{dryRun: true, force: true}, {force: true}}))
+
+		// objects without drift are not re-applied
+		patches = nil
+		r, objClient = newDriftTestReconciler(&patches, newDriftConfigMap("rendered"))
+		ari, err = r.applyObject(context.Background(), objClient, newAccessReviews(), rt, newDriftConfigMap("rendered"), prev, true)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ari.DriftedFields).To(BeNil())
+		g.Expect(patches).To(Equal([]driftTestPatch{{dryRun: true, force: true}}))
+	})
+}
@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/go-multierror"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxDryRunValueLength limits the length of values recorded in status.dryRun, so that large fields (e.g. the data of
+// ConfigMaps) don't blow up the size of the status
+const maxDryRunValueLength = 256
+
+// dryRunIgnoredFields are not compared when computing changes, as these are either changed by every write or not
+// controlled by the rendered objects
+var dryRunIgnoredFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"status"},
+}
+
+// dryRun performs a server-side dry-run apply of all rendered objects and records the would-be changes in
+// status.dryRun. Objects that would be pruned are recorded as well, but nothing is deleted. check is called for every
+// rendered object before dry-running it.
+func (r *ObjectTemplateReconciler) dryRun(ctx context.Context, objClient client.Client, reviews *accessReviews, rt *templatesv1alpha1.ObjectTemplate, owner string, allResources []*unstructured.Unstructured, check func(resource *unstructured.Unstructured) error) error {
+	var errs *multierror.Error
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	objects := make([]templatesv1alpha1.DryRunObjectInfo, len(allResources))
+	wg.Add(len(allResources))
+	for i, resource := range allResources {
+		i := i
+		resource := resource
+
+		go func() {
+			defer wg.Done()
+			info := templatesv1alpha1.DryRunObjectInfo{
+				Ref: templatesv1alpha1.ObjectRefFromObject(resource),
+			}
+			err := check(resource)
+			if err == nil {
+				err = r.dryRunRenderedObject(ctx, objClient, reviews, rt, resource, &info)
+			}
+			if err != nil {
+				info.Error = redact.Error(ctx, err)
+				mutex.Lock()
+				errs = multierror.Append(errs, err)
+				mutex.Unlock()
+			}
+			objects[i] = info
+		}()
+	}
+	wg.Wait()
+
+	if rt.Spec.Prune {
+		pruned, err := r.dryRunPrune(ctx, objClient, owner, allResources, rt.Status.AppliedResources)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+		objects = append(objects, pruned...)
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Ref.String() < objects[j].Ref.String()
+	})
+	rt.Status.DryRun = &templatesv1alpha1.DryRunStatus{
+		Objects: objects,
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// dryRunRenderedObject performs a server-side dry-run apply of rendered and fills info with the action and changes
+// that a real apply would perform
+func (r *ObjectTemplateReconciler) dryRunRenderedObject(ctx context.Context, objClient client.Client, reviews *accessReviews, rt *templatesv1alpha1.ObjectTemplate, rendered *unstructured.Unstructured, info *templatesv1alpha1.DryRunObjectInfo) error {
+	var orig unstructured.Unstructured
+	orig.SetGroupVersionKind(rendered.GroupVersionKind())
+	origObjFound := true
+	err := objClient.Get(ctx, client.ObjectKeyFromObject(rendered), &orig)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		origObjFound = false
+	}
+	if origObjFound {
+		_, err = checkAdoption(rt, rendered, &orig)
+		if err != nil {
+			return err
+		}
+	}
+
+	verb := "patch"
+	if !origObjFound {
+		verb = "create"
+	}
+	err = r.checkAccess(ctx, objClient, reviews, rt, rendered, verb)
+	if err != nil {
+		return err
+	}
+
+	applied := rendered.DeepCopy()
+	err = objClient.Patch(ctx, applied, client.Apply, append(r.getApplyOptions(rt, false), client.DryRunAll)...)
+	if err != nil {
+		return err
+	}
+
+	if !origObjFound {
+		info.Action = templatesv1alpha1.DryRunActionCreate
+		return nil
+	}
+
+	gvk := rendered.GroupVersionKind()
+	isSecret := gvk.Group == "" && gvk.Kind == "Secret"
+	info.Changes = diffObjects(ctx, orig.Object, applied.Object, isSecret)
+	if len(info.Changes) == 0 {
+		info.Action = templatesv1alpha1.DryRunActionUnchanged
+	} else {
+		info.Action = templatesv1alpha1.DryRunActionUpdate
+	}
+	return nil
+}
+
+// dryRunPrune returns the objects that prune would delete, without deleting them
+func (r *ObjectTemplateReconciler) dryRunPrune(ctx context.Context, objClient client.Client, owner string, allResources []*unstructured.Unstructured, appliedResources []templatesv1alpha1.AppliedResourceInfo) ([]templatesv1alpha1.DryRunObjectInfo, error) {
+	existingRefs := map[templatesv1alpha1.ObjectRef]bool{}
+	for _, resource := range allResources {
+		ref := templatesv1alpha1.ObjectRefFromObject(resource)
+		existingRefs[ref.WithoutVersion()] = true
+	}
+
+	var errs *multierror.Error
+	var ret []templatesv1alpha1.DryRunObjectInfo
+	for _, ari := range appliedResources {
+		if existingRefs[ari.Ref.WithoutVersion()] {
+			continue
+		}
+
+		gvk, err := ari.Ref.GroupVersionKind()
+		if err != nil {
+			return nil, err
+		}
+		m := metav1.PartialObjectMetadata{}
+		m.SetGroupVersionKind(gvk)
+		err = objClient.Get(ctx, client.ObjectKey{Namespace: ari.Ref.Namespace, Name: ari.Ref.Name}, &m)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				errs = multierror.Append(errs, err)
+			}
+			continue
+		}
+		if a, ok := m.GetAnnotations()[templatesv1alpha1.TemplateAnnotation]; ok && a != owner {
+			continue
+		}
+		if isPruneDisabled(&m) {
+			continue
+		}
+		ret = append(ret, templatesv1alpha1.DryRunObjectInfo{
+			Ref:    ari.Ref,
+			Action: templatesv1alpha1.DryRunActionDelete,
+		})
+	}
+	return ret, errs.ErrorOrNil()
+}
+
+// diffObjects returns the fields that differ between orig and applied, sorted by path. Lists are compared as a whole.
+// If redactData is true, the values of data and stringData are not recorded, which is required for Secrets.
+func diffObjects(ctx context.Context, orig map[string]any, applied map[string]any, redactData bool) []templatesv1alpha1.DryRunChange {
+	orig = runtime.DeepCopyJSON(orig)
+	applied = runtime.DeepCopyJSON(applied)
+	for _, f := range dryRunIgnoredFields {
+		unstructured.RemoveNestedField(orig, f...)
+		unstructured.RemoveNestedField(applied, f...)
+	}
+
+	var changes []templatesv1alpha1.DryRunChange
+	var walk func(path []string, a any, b any)
+	walk = func(path []string, a any, b any) {
+		am, aIsMap := a.(map[string]any)
+		bm, bIsMap := b.(map[string]any)
+		if aIsMap && bIsMap {
+			keys := make([]string, 0, len(am)+len(bm))
+			for k := range am {
+				keys = append(keys, k)
+			}
+			for k := range bm {
+				if _, ok := am[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(append(path[:len(path):len(path)], k), am[k], bm[k])
+			}
+			return
+		}
+		if reflect.DeepEqual(a, b) {
+			return
+		}
+		redactValue := redactData && len(path) != 0 && (path[0] == "data" || path[0] == "stringData")
+		changes = append(changes, templatesv1alpha1.DryRunChange{
+			Path: strings.Join(path, "."),
+			Old:  dryRunValue(ctx, a, redactValue),
+			New:  dryRunValue(ctx, b, redactValue),
+		})
+	}
+	walk(nil, orig, applied)
+	return changes
+}
+
+func dryRunValue(ctx context.Context, v any, redactValue bool) string {
+	if v == nil {
+		return ""
+	}
+	if redactValue {
+		return redact.Placeholder
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	s := redact.String(ctx, string(b))
+	if len(s) > maxDryRunValueLength {
+		s = s[:maxDryRunValueLength] + "..."
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"github.com/hashicorp/go-multierror"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ownerLabelValue returns the value of the owner label for the given owner. Label values are limited to 63 characters,
+// so a shortened hash of the owner is used instead of the owner itself.
+func ownerLabelValue(owner string) string {
+	return Sha256String(owner)[:32]
+}
+
+// collectOwnedObjects finds all objects that carry the owner label of owner and adds them to appliedResources, so that
+// they are pruned if not rendered anymore, even if the inventory in the status got lost. Only kinds that are part of
+// resources or appliedResources are searched, in the namespaces of these objects and in objNamespace. The owner label
+// alone is not trusted, the provenance annotation must match as well.
+func (r *ObjectTemplateReconciler) collectOwnedObjects(ctx context.Context, objClient client.Client, objNamespace string, owner string, resources []*unstructured.Unstructured, appliedResources map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo) error {
+	gvks := map[schema.GroupVersionKind]bool{}
+	namespaces := map[string]bool{
+		objNamespace: true,
+	}
+	for _, x := range resources {
+		gvks[x.GroupVersionKind()] = true
+		if x.GetNamespace() != "" {
+			namespaces[x.GetNamespace()] = true
+		}
+	}
+	for _, ari := range appliedResources {
+		gvk, err := ari.Ref.GroupVersionKind()
+		if err != nil {
+			return err
+		}
+		gvks[gvk] = true
+		if ari.Ref.Namespace != "" {
+			namespaces[ari.Ref.Namespace] = true
+		}
+	}
+
+	var errs *multierror.Error
+	for gvk := range gvks {
+		rm, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+
+		listNamespaces := []string{""}
+		if rm.Scope.Name() == apimeta.RESTScopeNameNamespace {
+			listNamespaces = listNamespaces[:0]
+			for ns := range namespaces {
+				listNamespaces = append(listNamespaces, ns)
+			}
+		}
+
+		for _, ns := range listNamespaces {
+			var l metav1.PartialObjectMetadataList
+			l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			err = objClient.List(ctx, &l, client.InNamespace(ns), client.MatchingLabels{
+				templatesv1alpha1.OwnerLabel: ownerLabelValue(owner),
+			})
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			for _, x := range l.Items {
+				if x.GetAnnotations()[templatesv1alpha1.TemplateAnnotation] != owner {
+					continue
+				}
+				ref := templatesv1alpha1.ObjectRef{
+					APIVersion: gvk.GroupVersion().String(),
+					Kind:       gvk.Kind,
+					Namespace:  x.GetNamespace(),
+					Name:       x.GetName(),
+				}
+				if _, ok := appliedResources[ref.WithoutVersion()]; !ok {
+					appliedResources[ref.WithoutVersion()] = templatesv1alpha1.AppliedResourceInfo{
+						Ref:     ref,
+						Success: true,
+					}
+				}
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	types2 "github.com/kluctl/kluctl/v2/pkg/types"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"path"
+	"path/filepath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	gitOutputAuthorName  = "template-controller"
+	gitOutputAuthorEmail = "template-controller@kluctl.io"
+)
+
+// writeGitOutput commits the rendered objects to the Git repository specified in spec.gitOutput instead of applying
+// them. The objects of each matrix entry are written into their own sub-directory of spec.gitOutput.path. The whole
+// path is replaced on each write, so that objects of removed matrix entries disappear from the repository as well.
+func (r *ObjectTemplateReconciler) writeGitOutput(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, entries []renderedMatrixEntry) error {
+	logger := log.FromContext(ctx)
+	spec := rt.Spec.GitOutput
+
+	err := r.checkOutputPolicy(rt, entries)
+	if err != nil {
+		return err
+	}
+
+	u, err := types2.ParseGitUrl(spec.URL)
+	if err != nil {
+		return err
+	}
+	err = r.Policy.CheckHost(u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	ga, err := buildGitAuthProviders(ctx, objClient, rt.GetNamespace(), spec.SecretRef)
+	if err != nil {
+		return err
+	}
+	auth, err := ga.BuildAuth(ctx, *u)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "template-controller-git-output-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	repo, err := git.PlainCloneContext(ctx, tmpDir, false, &git.CloneOptions{
+		URL:      u.String(),
+		Auth:     auth.AuthMethod,
+		CABundle: auth.CABundle,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clone %s: %w", spec.URL, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = checkoutGitOutputBranch(repo, wt, spec.Branch)
+	if err != nil {
+		return err
+	}
+
+	basePath, err := cleanGitOutputPath(spec.Path)
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(filepath.Join(tmpDir, basePath))
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		elementPath := e.outputName
+		if elementPath == "" {
+			elementPath = e.matrixKey
+		}
+		p, err := cleanGitOutputPath(path.Join(basePath, elementPath))
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(p, basePath+"/") {
+			return fmt.Errorf("element path %s is outside of %s", elementPath, spec.Path)
+		}
+		err = writeGitOutputObjects(filepath.Join(tmpDir, filepath.FromSlash(p)), e.objects)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	err = wt.AddWithOptions(&git.AddOptions{All: true})
+	if err != nil {
+		return err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+
+	var commit plumbing.Hash
+	if status.IsClean() {
+		head, err := repo.Head()
+		if err != nil {
+			return err
+		}
+		commit = head.Hash()
+	} else {
+		commitMessage := spec.CommitMessage
+		if commitMessage == "" {
+			commitMessage = "Update rendered objects"
+		}
+		commit, err = wt.Commit(commitMessage, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  gitOutputAuthorName,
+				Email: gitOutputAuthorEmail,
+				When:  time.Now(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		refName := plumbing.NewBranchReferenceName(spec.Branch)
+		err = repo.PushContext(ctx, &git.PushOptions{
+			RemoteName: "origin",
+			RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", refName, refName))},
+			Auth:       auth.AuthMethod,
+			CABundle:   auth.CABundle,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to push to %s: %w", spec.URL, err)
+		}
+		logger.Info("Pushed rendered objects", "url", spec.URL, "branch", spec.Branch, "commit", commit.String())
+	}
+
+	rt.Status.GitOutput = &templatesv1alpha1.GitOutputStatus{
+		Commit: commit.String(),
+		Paths:  paths,
+	}
+	return nil
+}
+
+// checkoutGitOutputBranch checks out the given branch, creating it from the default branch if it does not exist yet
+func checkoutGitOutputBranch(repo *git.Repository, wt *git.Worktree, branch string) error {
+	refName := plumbing.NewBranchReferenceName(branch)
+
+	_, err := repo.Reference(refName, true)
+	if err == nil {
+		return wt.Checkout(&git.CheckoutOptions{Branch: refName})
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	co := &git.CheckoutOptions{
+		Branch: refName,
+		Create: true,
+	}
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err == nil {
+		co.Hash = remoteRef.Hash()
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+	return wt.Checkout(co)
+}
+
+// cleanGitOutputPath cleans the given repository relative path and ensures that it does not point to the repository
+// root or outside of the repository
+func cleanGitOutputPath(p string) (string, error) {
+	c := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if c == "" || c == ".git" || strings.HasPrefix(c, ".git/") {
+		return "", fmt.Errorf("invalid path %s", p)
+	}
+	return c, nil
+}
+
+// checkOutputPolicy verifies that the rendered objects are allowed by the policy. It is used instead of the checks
+// performed while applying when the objects are written to an external output.
+func (r *ObjectTemplateReconciler) checkOutputPolicy(rt *templatesv1alpha1.ObjectTemplate, entries []renderedMatrixEntry) error {
+	for _, e := range entries {
+		for _, x := range e.objects {
+			err := r.Policy.CheckTargetNamespace(rt.GetNamespace(), x.GetNamespace())
+			if err != nil {
+				return err
+			}
+			err = r.Policy.CheckKind(x.GroupVersionKind().GroupKind())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildOutputFileName returns the file name used for the given object when written to an external output
+func buildOutputFileName(x *unstructured.Unstructured) string {
+	name := fmt.Sprintf("%s_%s", x.GetKind(), x.GetName())
+	if x.GetNamespace() != "" {
+		name = x.GetNamespace() + "_" + name
+	}
+	return strings.ToLower(name) + ".yaml"
+}
+
+func writeGitOutputObjects(dir string, objects []*unstructured.Unstructured) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+	for _, x := range objects {
+		b, err := yaml.Marshal(x.Object)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(filepath.Join(dir, buildOutputFileName(x)), b, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/google/go-github/v47/github"
+	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"golang.org/x/oauth2"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"time"
+)
+
+var githubAppTokens shortLivedTokenCache
+
+// GetGithubToken returns the token to use for the given GitHub project. If the project specifies a GitHub App, a
+// short-lived installation token is requested (and cached until shortly before it expires). Otherwise, the token is
+// loaded from tokenRef. An empty token is returned if neither is specified.
+func GetGithubToken(ctx context.Context, client client.Client, namespace string, p *policy.Policy, project v1alpha1.GithubProject) (string, error) {
+	if project.App != nil {
+		return getGithubAppToken(ctx, client, namespace, p, project.API, *project.App)
+	}
+	if project.TokenRef != nil {
+		return GetSecretToken(ctx, client, namespace, p, *project.TokenRef)
+	}
+	return "", nil
+}
+
+func getGithubAppToken(ctx context.Context, client client.Client, namespace string, p *policy.Policy, api *string, app v1alpha1.GithubApp) (string, error) {
+	privateKeyPem, err := GetSecretToken(ctx, client, namespace, p, app.PrivateKeyRef)
+	if err != nil {
+		return "", err
+	}
+
+	apiUrl := ""
+	if api != nil {
+		apiUrl = *api
+	}
+	key := fmt.Sprintf("%s/%d/%d/%s", apiUrl, app.AppId, app.InstallationId, Sha256String(privateKeyPem))
+
+	return githubAppTokens.get(key, func() (string, time.Time, error) {
+		privateKey, err := parseGithubAppPrivateKey([]byte(privateKeyPem))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		jwt, err := buildGithubAppJWT(app.AppId, privateKey)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwt})
+		gh, err := NewGithubClient(oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, p.HTTPClient()), ts), api)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		it, _, err := gh.Apps.CreateInstallationToken(ctx, app.InstallationId, nil)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to create installation token for GitHub App %d: %w", app.AppId, err)
+		}
+		redact.Register(ctx, it.GetToken())
+		return it.GetToken(), it.GetExpiresAt(), nil
+	})
+}
+
+func parseGithubAppPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode GitHub App private key: no PEM block found")
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	rk, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not a RSA key")
+	}
+	return rk, nil
+}
+
+// buildGithubAppJWT builds the RS256 signed JWT that GitHub requires to authenticate as the app itself.
+func buildGithubAppJWT(appId int64, key *rsa.PrivateKey) (string, error) {
+	now := time.Now()
+
+	header, err := json.Marshal(map[string]any{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		// allow for some clock drift
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(appId, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	h := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// BuildGithubHttpClient builds the http client used for all GitHub API requests of the given project.
+func BuildGithubHttpClient(ctx context.Context, client client.Client, namespace string, p *policy.Policy, project v1alpha1.GithubProject) (*http.Client, error) {
+	token, err := GetGithubToken(ctx, client, namespace, p, project)
+	if err != nil {
+		return nil, err
+	}
+	return NewGithubHttpClient(ctx, p, token), nil
+}
+
+// NewGithubClient builds a GitHub API client that uses the given http client. If api is set, the client talks to the
+// given GitHub Enterprise Server API instead of https://api.github.com/.
+func NewGithubClient(hc *http.Client, api *string) (*github.Client, error) {
+	if api == nil || *api == "" {
+		return github.NewClient(hc), nil
+	}
+	return github.NewEnterpriseClient(*api, *api, hc)
+}
+
+// NewGithubHttpClient builds the http client used for GitHub API requests authenticated with the given token.
+func NewGithubHttpClient(ctx context.Context, p *policy.Policy, token string) *http.Client {
+	hc := p.HTTPClient()
+	if token == "" {
+		return hc
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	return oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, hc), ts)
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"github.com/xanzy/go-gitlab"
+	"io"
+	"net/http"
+	"net/url"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+var gitlabOIDCTokens shortLivedTokenCache
+
+// BuildGitlabClient builds a GitLab API client for the given API URL that authenticates via auth. The returned token is
+// the access token loaded from tokenRef, or empty for all other authentication methods, as only stored access tokens
+// can be checked for their scopes, see CheckGitlabTokenScopes.
+func BuildGitlabClient(ctx context.Context, client client.Client, namespace string, p *policy.Policy, api *string, auth v1alpha1.GitlabAuth) (*gitlab.Client, string, error) {
+	opts := []gitlab.ClientOptionFunc{
+		gitlab.WithHTTPClient(p.HTTPClient()),
+	}
+	if api != nil {
+		opts = append(opts, gitlab.WithBaseURL(*api))
+	}
+
+	switch {
+	case auth.OIDC != nil:
+		// the service account token is requested with the identity of the service account itself, so that the
+		// controller's own permissions can not be used to request tokens for other service accounts
+		tokenClient, err := newImpersonatingClient(nil, client.RESTMapper(), auth.OIDC.ServiceAccountName, namespace)
+		if err != nil {
+			return nil, "", err
+		}
+		token, err := getGitlabOIDCToken(ctx, tokenClient, namespace, p, *auth.OIDC)
+		if err != nil {
+			return nil, "", err
+		}
+		gl, err := gitlab.NewOAuthClient(token, opts...)
+		return gl, "", err
+	case auth.JobTokenRef != nil:
+		token, err := GetSecretToken(ctx, client, namespace, p, *auth.JobTokenRef)
+		if err != nil {
+			return nil, "", err
+		}
+		gl, err := gitlab.NewJobClient(token, opts...)
+		return gl, "", err
+	default:
+		var token string
+		if auth.TokenRef != nil {
+			var err error
+			token, err = GetSecretToken(ctx, client, namespace, p, *auth.TokenRef)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		gl, err := gitlab.NewClient(token, opts...)
+		return gl, token, err
+	}
+}
+
+type tokenExchangeResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// getGitlabOIDCToken exchanges a service account token for a GitLab OAuth token via OAuth 2.0 Token Exchange. The
+// service account token is requested with objClient, which must impersonate the service account. Its audience is always
+// the token URL, so that it can not be used to authenticate against other services, e.g. the Kubernetes API server.
+// Tokens are cached until shortly before they expire.
+func getGitlabOIDCToken(ctx context.Context, objClient client.Client, namespace string, p *policy.Policy, oidc v1alpha1.GitlabOIDC) (string, error) {
+	if oidc.TokenURL == "" {
+		return "", fmt.Errorf("missing oidc tokenURL")
+	}
+	audience := oidc.TokenURL
+	serviceAccountName := oidc.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = "default"
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s", oidc.TokenURL, namespace, serviceAccountName, oidc.Scope)
+	return gitlabOIDCTokens.get(key, func() (string, time.Time, error) {
+		idToken, err := requestServiceAccountToken(ctx, objClient, namespace, serviceAccountName, audience)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		form := url.Values{
+			"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+			"subject_token":        {idToken},
+			"subject_token_type":   {"urn:ietf:params:oauth:token-type:id_token"},
+			"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		}
+		if oidc.Scope != "" {
+			form.Set("scope", oidc.Scope)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, oidc.TokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+
+		now := time.Now()
+		resp, err := p.HTTPClient().Do(req)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to exchange service account token: %w", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		var tr tokenExchangeResponse
+		_ = json.Unmarshal(body, &tr)
+		if resp.StatusCode != http.StatusOK {
+			if tr.Error != "" {
+				return "", time.Time{}, fmt.Errorf("failed to exchange service account token: %s: %s", tr.Error, tr.ErrorDescription)
+			}
+			return "", time.Time{}, fmt.Errorf("failed to exchange service account token: %s", resp.Status)
+		}
+		if tr.AccessToken == "" {
+			return "", time.Time{}, fmt.Errorf("failed to exchange service account token: response contains no access_token")
+		}
+		redact.Register(ctx, tr.AccessToken)
+
+		// tokens without expiry information are not cached
+		return tr.AccessToken, now.Add(time.Duration(tr.ExpiresIn) * time.Second), nil
+	})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type tokenRequest struct {
+	namespace string
+	name      string
+	audiences []string
+}
+
+// newTokenClient returns a fake client that answers TokenRequests with "id-token" and records them in requests
+func newTokenClient(requests *[]tokenRequest) client.Client {
+	return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+			sa := obj.(*corev1.ServiceAccount)
+			tr := subResource.(*authenticationv1.TokenRequest)
+			*requests = append(*requests, tokenRequest{namespace: sa.Namespace, name: sa.Name, audiences: tr.Spec.Audiences})
+			tr.Status.Token = "id-token"
+			return nil
+		},
+	}).Build()
+}
+
+func TestGetGitlabOIDCToken(t *testing.T) {
+	g := NewWithT(t)
+
+	exchanges := 0
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		g.Expect(r.ParseForm()).To(Succeed())
+		g.Expect(r.PostForm.Get("grant_type")).To(Equal("urn:ietf:params:oauth:grant-type:token-exchange"))
+		g.Expect(r.PostForm.Get("subject_token")).To(Equal("id-token"))
+		g.Expect(r.PostForm.Get("scope")).To(Equal("read_api"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"gitlab-token","expires_in":3600}`))
+	}))
+	defer sts.Close()
+
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+	oidc := templatesv1alpha1.GitlabOIDC{
+		TokenURL:           sts.URL + "/token",
+		Scope:              "read_api",
+		ServiceAccountName: "gitlab-reader",
+	}
+
+	token, err := getGitlabOIDCToken(context.Background(), objClient, "team-a", nil, oidc)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(token).To(Equal("gitlab-token"))
+
+	// the token must be requested for the configured service account and always with the token URL as audience
+	g.Expect(requests).To(Equal([]tokenRequest{{namespace: "team-a", name: "gitlab-reader", audiences: []string{sts.URL + "/token"}}}))
+
+	// exchanged tokens are cached
+	token, err = getGitlabOIDCToken(context.Background(), objClient, "team-a", nil, oidc)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(token).To(Equal("gitlab-token"))
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(exchanges).To(Equal(1))
+
+	// but not shared between namespaces
+	_, err = getGitlabOIDCToken(context.Background(), objClient, "team-b", nil, oidc)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(requests).To(HaveLen(2))
+	g.Expect(requests[1].namespace).To(Equal("team-b"))
+}
+
+func TestGetGitlabOIDCTokenErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"unknown subject"}`))
+	}))
+	defer sts.Close()
+
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+
+	_, err := getGitlabOIDCToken(context.Background(), objClient, "team-a", nil, templatesv1alpha1.GitlabOIDC{})
+	g.Expect(err).To(MatchError("missing oidc tokenURL"))
+	g.Expect(requests).To(BeEmpty())
+
+	_, err = getGitlabOIDCToken(context.Background(), objClient, "team-a", nil, templatesv1alpha1.GitlabOIDC{TokenURL: sts.URL})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid_grant: unknown subject")))
+	// the default service account is used if none is specified
+	g.Expect(requests).To(ConsistOf(tokenRequest{namespace: "team-a", name: "default", audiences: []string{sts.URL}}))
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/kluctl/kluctl/v2/pkg/git/messages"
 	types2 "github.com/kluctl/kluctl/v2/pkg/types"
@@ -30,12 +31,15 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gobwas/glob"
 	"github.com/kluctl/kluctl/v2/pkg/git"
 	"github.com/kluctl/kluctl/v2/pkg/git/auth"
 	ssh_pool "github.com/kluctl/kluctl/v2/pkg/git/ssh-pool"
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
 	yaml3 "gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
@@ -46,15 +50,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 // GitProjectorReconciler reconciles a GitProjector object
 type GitProjectorReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Manager manager.Manager
+	Scheme  *runtime.Scheme
 
 	FieldManager string
 	TmpBaseDir   string
+	Policy       *policy.Policy
 
 	sshPool ssh_pool.SshPool
 }
@@ -106,7 +113,7 @@ func (r *GitProjectorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: obj.GetGeneration(),
 			Reason:             "Error",
-			Message:            err.Error(),
+			Message:            redact.Error(ctx, err),
 		}
 		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
 	} else {
@@ -132,7 +139,7 @@ func (r *GitProjectorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 func (r *GitProjectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&templatesv1alpha1.GitProjector{}).
-		Complete(r)
+		Complete(redact.Reconciler(r))
 }
 
 func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.GitProjector) error {
@@ -140,6 +147,10 @@ func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templates
 	if err != nil {
 		return err
 	}
+	err = r.Policy.CheckHost(url.Hostname())
+	if err != nil {
+		return err
+	}
 
 	auth, err := r.buildGitAuth(ctx, obj)
 	if err != nil {
@@ -166,18 +177,34 @@ func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templates
 	if err != nil {
 		return err
 	}
-	sortedMatchedRefNames := make([]string, 0, len(matchingRefs))
-	for name, _ := range matchingRefs {
-		sortedMatchedRefNames = append(sortedMatchedRefNames, name)
+	sortedMatchedRefNames, err := r.sortRefs(obj, mr, matchingRefs)
+	if err != nil {
+		return err
+	}
+
+	verifyKeys, err := r.loadVerifyKeys(ctx, obj)
+	if err != nil {
+		return err
 	}
-	sort.Strings(sortedMatchedRefNames)
 
 	allRefsHash := sha256.New()
 	for _, name := range sortedMatchedRefNames {
 		_, _ = fmt.Fprintf(allRefsHash, "%s=%s\n", name, matchingRefs[name])
 	}
+	for _, k := range verifyKeys {
+		_, _ = fmt.Fprintf(allRefsHash, "key=%s\n", Sha256String(k))
+	}
+	// the projection also depends on the spec, e.g. on the file and directory globs
+	_, _ = fmt.Fprintf(allRefsHash, "generation=%d\n", obj.GetGeneration())
 	allRefsHashStr := hex.EncodeToString(allRefsHash.Sum(nil))
-	if allRefsHashStr == obj.Status.AllRefsHash {
+	upToDate := allRefsHashStr == obj.Status.AllRefsHash
+	for _, res := range obj.Status.Result {
+		if res.Commit == "" || res.CommitInfo == nil {
+			// written by an older version of the controller which did not include the commit details
+			upToDate = false
+		}
+	}
+	if upToDate {
 		// nothing to do
 		return nil
 	}
@@ -190,30 +217,57 @@ func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templates
 		}
 		globs = append(globs, g)
 	}
+	var dirGlobs []glob.Glob
+	for _, d := range obj.Spec.Directories {
+		g, err := glob.Compile(d.Glob, '/')
+		if err != nil {
+			return err
+		}
+		dirGlobs = append(dirGlobs, g)
+	}
 
 	type matchedFile struct {
 		gitFile templatesv1alpha1.GitFile
 		file    object.File
 	}
 
-	newResults := make([]templatesv1alpha1.GitProjectorResult, 0, len(matchingRefs))
-	for name, hash := range matchingRefs {
-		t, err := mr.GetGitTreeByCommit(hash)
+	if verifyKeys != nil {
+		for _, name := range sortedMatchedRefNames {
+			err = r.verifyRef(mr, name, matchingRefs[name], verifyKeys)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	newResults := make([]templatesv1alpha1.GitProjectorResult, 0, len(sortedMatchedRefNames))
+	for _, name := range sortedMatchedRefNames {
+		hash := matchingRefs[name]
+		commit, err := r.resolveCommit(mr, name, hash)
+		if err != nil {
+			return err
+		}
+		t, err := commit.Tree()
 		if err != nil {
 			return err
 		}
 
 		var matchedFiles []matchedFile
+		dirs := map[string]bool{}
 
 		err = t.Files().ForEach(func(file *object.File) error {
-			for i, g := range globs {
-				if g.Match(file.Name) {
-					matchedFiles = append(matchedFiles, matchedFile{
-						gitFile: obj.Spec.Files[i],
-						file:    *file,
-					})
-					break
-				}
+			for dir := filepath.ToSlash(filepath.Dir(file.Name)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+				dirs[dir] = true
+			}
+
+			i := matchGlobs(file.Name, globs, func(i int) bool {
+				return obj.Spec.Files[i].Exclude
+			})
+			if i != -1 {
+				matchedFiles = append(matchedFiles, matchedFile{
+					gitFile: obj.Spec.Files[i],
+					file:    *file,
+				})
 			}
 			return nil
 		})
@@ -221,6 +275,23 @@ func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templates
 			return err
 		}
 
+		var matchedDirs []templatesv1alpha1.GitProjectorResultDirectory
+		if len(dirGlobs) != 0 {
+			sortedDirs := make([]string, 0, len(dirs))
+			for dir := range dirs {
+				sortedDirs = append(sortedDirs, dir)
+			}
+			sort.Strings(sortedDirs)
+			for _, dir := range sortedDirs {
+				i := matchGlobs(dir, dirGlobs, func(i int) bool {
+					return obj.Spec.Directories[i].Exclude
+				})
+				if i != -1 {
+					matchedDirs = append(matchedDirs, templatesv1alpha1.GitProjectorResultDirectory{Path: dir})
+				}
+			}
+		}
+
 		var ref templatesv1alpha1.GitRef
 		if strings.HasPrefix(name, "refs/heads/") {
 			ref.Branch = strings.TrimPrefix(name, "refs/heads/")
@@ -231,8 +302,11 @@ func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templates
 		}
 
 		result := templatesv1alpha1.GitProjectorResult{
-			Reference: ref,
-			Files:     make([]templatesv1alpha1.GitProjectorResultFile, 0, len(matchedFiles)),
+			Reference:   ref,
+			Commit:      commit.Hash.String(),
+			CommitInfo:  buildCommitInfo(commit),
+			Files:       make([]templatesv1alpha1.GitProjectorResultFile, 0, len(matchedFiles)),
+			Directories: matchedDirs,
 		}
 
 		for _, mf := range matchedFiles {
@@ -268,16 +342,30 @@ func (r *GitProjectorReconciler) doReconcile(ctx context.Context, obj *templates
 		newResults = append(newResults, result)
 	}
 
-	sort.Slice(newResults, func(i, j int) bool {
-		return newResults[i].Reference.Less(newResults[j].Reference)
-	})
-
 	obj.Status.Result = newResults
 	obj.Status.AllRefsHash = allRefsHashStr
 
 	return nil
 }
 
+// matchGlobs returns the index of the first non-excluding glob that matches the given path. If any of the excluding
+// globs matches the path, -1 is returned.
+func matchGlobs(path string, globs []glob.Glob, isExclude func(i int) bool) int {
+	match := -1
+	for i, g := range globs {
+		if !g.Match(path) {
+			continue
+		}
+		if isExclude(i) {
+			return -1
+		}
+		if match == -1 {
+			match = i
+		}
+	}
+	return match
+}
+
 func (r *GitProjectorReconciler) filterRefs(obj *templatesv1alpha1.GitProjector, mr *git.MirroredGitRepo) (map[string]string, error) {
 	refs, err := mr.RemoteRefHashesMap()
 	if err != nil {
@@ -338,7 +426,121 @@ func (r *GitProjectorReconciler) filterRefs(obj *templatesv1alpha1.GitProjector,
 	return matchingRefs, nil
 }
 
+// sortRefs returns the names of the matching refs, sorted as specified by sortBy and limited to latestN entries
+func (r *GitProjectorReconciler) sortRefs(obj *templatesv1alpha1.GitProjector, mr *git.MirroredGitRepo, matchingRefs map[string]string) ([]string, error) {
+	names := make([]string, 0, len(matchingRefs))
+	for name, _ := range matchingRefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if obj.Spec.SortBy == "semver" {
+		versions := map[string]*semver.Version{}
+		semverNames := make([]string, 0, len(names))
+		for _, name := range names {
+			shortName := strings.TrimPrefix(strings.TrimPrefix(name, "refs/heads/"), "refs/tags/")
+			v, err := semver.NewVersion(shortName)
+			if err != nil {
+				continue
+			}
+			versions[name] = v
+			semverNames = append(semverNames, name)
+		}
+		// stable sort, so that equal versions (e.g. v1.0 and 1.0.0) stay sorted by name
+		sort.SliceStable(semverNames, func(i, j int) bool {
+			return versions[semverNames[i]].GreaterThan(versions[semverNames[j]])
+		})
+		names = semverNames
+	} else if obj.Spec.SortBy == "commitTime" {
+		commitTimes := map[string]time.Time{}
+		for _, name := range names {
+			commit, err := r.resolveCommit(mr, name, matchingRefs[name])
+			if err != nil {
+				return nil, err
+			}
+			commitTimes[name] = commit.Committer.When
+		}
+		// stable sort, so that refs pointing to commits with equal timestamps stay sorted by name
+		sort.SliceStable(names, func(i, j int) bool {
+			return commitTimes[names[i]].After(commitTimes[names[j]])
+		})
+	}
+
+	if obj.Spec.LatestN != nil && len(names) > *obj.Spec.LatestN {
+		names = names[:*obj.Spec.LatestN]
+	}
+	return names, nil
+}
+
+// loadVerifyKeys loads the trusted PGP keys. Unless the policy defines a trusted keys namespace, the Secret is read by
+// impersonating verify.serviceAccountName, so that the controller's own permissions can not be used to read arbitrary
+// secrets.
+func (r *GitProjectorReconciler) loadVerifyKeys(ctx context.Context, obj *templatesv1alpha1.GitProjector) ([]string, error) {
+	if obj.Spec.Verify == nil {
+		return nil, nil
+	}
+
+	objClient, err := newImpersonatingClient(r.Manager, r.RESTMapper(), obj.Spec.Verify.ServiceAccountName, obj.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return loadPublicKeys(ctx, r.Client, objClient, r.Policy, obj.Namespace, obj.Spec.Verify.PublicKeysRef)
+}
+
+// verifyRef verifies that the commit the ref points to is signed by one of the given keys. Annotated tags are
+// resolved to the commit they point to.
+func (r *GitProjectorReconciler) verifyRef(mr *git.MirroredGitRepo, name string, hash string, keys []string) error {
+	commit, err := r.resolveCommit(mr, name, hash)
+	if err != nil {
+		return err
+	}
+
+	if commit.PGPSignature == "" {
+		return fmt.Errorf("commit %s of ref %s is not signed", commit.Hash.String(), name)
+	}
+	for _, k := range keys {
+		_, err = commit.Verify(k)
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to verify signature of commit %s of ref %s: %w", commit.Hash.String(), name, err)
+}
+
+// resolveCommit returns the commit the given ref points to. Annotated tags are resolved to the commit they point to.
+func (r *GitProjectorReconciler) resolveCommit(mr *git.MirroredGitRepo, name string, hash string) (*object.Commit, error) {
+	o, err := mr.GetObjectByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := o.(type) {
+	case *object.Commit:
+		return x, nil
+	case *object.Tag:
+		return x.Commit()
+	default:
+		return nil, fmt.Errorf("ref %s does not point to a commit", name)
+	}
+}
+
+func buildCommitInfo(commit *object.Commit) *templatesv1alpha1.GitCommitInfo {
+	return &templatesv1alpha1.GitCommitInfo{
+		ShortCommit: commit.Hash.String()[:7],
+		AuthorName:  commit.Author.Name,
+		AuthorEmail: commit.Author.Email,
+		Message:     commit.Message,
+		Timestamp:   metav1.NewTime(commit.Committer.When),
+	}
+}
+
 func (r *GitProjectorReconciler) buildGitAuth(ctx context.Context, obj *templatesv1alpha1.GitProjector) (*auth.GitAuthProviders, error) {
+	return buildGitAuthProviders(ctx, r.Client, obj.GetNamespace(), obj.Spec.SecretRef)
+}
+
+// buildGitAuthProviders builds the Git auth providers from the given secret. The contents of the secret must conform
+// to https://kluctl.io/docs/flux/spec/v1alpha1/kluctldeployment/#git-authentication
+func buildGitAuthProviders(ctx context.Context, c client.Client, namespace string, secretRef *templatesv1alpha1.LocalObjectReference) (*auth.GitAuthProviders, error) {
 	logger := log.FromContext(ctx)
 
 	ga := auth.NewDefaultAuthProviders("GIT", &messages.MessageCallbacks{
@@ -350,12 +552,12 @@ func (r *GitProjectorReconciler) buildGitAuth(ctx context.Context, obj *template
 		},
 	})
 
-	if obj.Spec.SecretRef == nil {
+	if secretRef == nil {
 		return ga, nil
 	}
 
 	var gitSecret corev1.Secret
-	err := r.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.SecretRef.Name}, &gitSecret)
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretRef.Name}, &gitSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -370,6 +572,7 @@ func (r *GitProjectorReconciler) buildGitAuth(ctx context.Context, obj *template
 	}
 	if x, ok := gitSecret.Data["password"]; ok {
 		e.Password = string(x)
+		redact.RegisterBytes(ctx, x)
 	}
 	if x, ok := gitSecret.Data["caFile"]; ok {
 		e.CABundle = x
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"fmt"
+	"github.com/kluctl/template-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// nativeKinds contains the kinds that follow the observedGeneration + Ready condition semantics of Flux and the
+// flux-kluctl-controller. These are understood natively instead of relying on kstatus.
+var nativeKinds = map[schema.GroupKind]bool{
+	{Group: "flux.kluctl.io", Kind: "KluctlDeployment"}:           true,
+	{Group: "kustomize.toolkit.fluxcd.io", Kind: "Kustomization"}: true,
+	{Group: "helm.toolkit.fluxcd.io", Kind: "HelmRelease"}:        true,
+}
+
+// Order defines which health status wins when combining multiple health statuses
+var Order = map[string]int{
+	v1alpha1.HealthHealthy:     0,
+	v1alpha1.HealthUnknown:     1,
+	v1alpha1.HealthProgressing: 2,
+	v1alpha1.HealthDegraded:    3,
+}
+
+// IsNativeKind returns true if the health of the given kind is computed via ComputeNative
+func IsNativeKind(gk schema.GroupKind) bool {
+	return nativeKinds[gk]
+}
+
+// Compute computes the health status and message of obj. Kinds that follow the Flux conventions are understood
+// natively, all other objects are evaluated via kstatus.
+func Compute(obj *unstructured.Unstructured) (string, string, error) {
+	if IsNativeKind(obj.GroupVersionKind().GroupKind()) {
+		s, message := ComputeNative(obj)
+		return s, message, nil
+	}
+
+	res, err := status.Compute(obj)
+	if err != nil {
+		return "", "", err
+	}
+	switch res.Status {
+	case status.CurrentStatus:
+		return v1alpha1.HealthHealthy, res.Message, nil
+	case status.InProgressStatus:
+		return v1alpha1.HealthProgressing, res.Message, nil
+	case status.FailedStatus, status.TerminatingStatus, status.NotFoundStatus:
+		return v1alpha1.HealthDegraded, res.Message, nil
+	default:
+		return v1alpha1.HealthUnknown, res.Message, nil
+	}
+}
+
+// ComputeNative computes the health of objects that follow the Flux conventions. The object is only considered
+// healthy if the controller has observed the current generation and reports the Ready condition as True.
+func ComputeNative(obj *unstructured.Unstructured) (string, string) {
+	suspend, _, _ := unstructured.NestedBool(obj.Object, "spec", "suspend")
+	if suspend {
+		return v1alpha1.HealthUnknown, "Reconciliation is suspended"
+	}
+
+	observedGeneration, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if !found || observedGeneration != obj.GetGeneration() {
+		return v1alpha1.HealthProgressing, fmt.Sprintf("Waiting for generation %d to be observed", obj.GetGeneration())
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	var ready, reconciling map[string]any
+	for _, x := range conditions {
+		c, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch c["type"] {
+		case "Ready":
+			ready = c
+		case "Reconciling":
+			reconciling = c
+		}
+	}
+
+	if reconciling != nil && reconciling["status"] == "True" {
+		return v1alpha1.HealthProgressing, fmt.Sprint(reconciling["message"])
+	}
+	if ready == nil {
+		return v1alpha1.HealthProgressing, "Waiting for Ready condition"
+	}
+
+	message := fmt.Sprint(ready["message"])
+	switch ready["status"] {
+	case "True":
+		return v1alpha1.HealthHealthy, message
+	case "False":
+		if ready["reason"] == "Progressing" {
+			return v1alpha1.HealthProgressing, message
+		}
+		return v1alpha1.HealthDegraded, message
+	default:
+		return v1alpha1.HealthProgressing, message
+	}
+}
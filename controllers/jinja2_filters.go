@@ -0,0 +1,59 @@
+package controllers
+
+import "github.com/kluctl/go-jinja2"
+
+// jinja2Filters contains additional filters on top of the ones provided by the Kluctl extension. Hashing and encoding
+// filters are named after the Sprig functions available to Go templates, while from_json complements the to_json,
+// to_yaml and from_yaml filters of the Kluctl extension.
+var jinja2Filters = map[string]string{
+	"sha256sum": `
+def sha256sum(s):
+    import hashlib
+    if not isinstance(s, bytes):
+        s = str(s).encode("utf-8")
+    return hashlib.sha256(s).hexdigest()
+`,
+	"adler32": `
+def adler32(s):
+    import zlib
+    if not isinstance(s, bytes):
+        s = str(s).encode("utf-8")
+    return str(zlib.adler32(s))
+`,
+	"b64enc": `
+def b64enc(s):
+    import base64
+    if not isinstance(s, bytes):
+        s = str(s).encode("utf-8")
+    return base64.b64encode(s).decode()
+`,
+	"b64dec": `
+def b64dec(s):
+    import base64
+    return base64.b64decode(str(s).encode()).decode()
+`,
+	"from_json": `
+def from_json(s):
+    import json
+    return json.loads(s)
+`,
+	// htpasswd uses bcrypt via the crypt module, which relies on the bcrypt support of the system's libcrypt
+	"htpasswd": `
+def htpasswd(username, password):
+    import crypt
+    if ":" in username:
+        raise ValueError("invalid username for htpasswd: must not contain ':'")
+    h = crypt.crypt(str(password), crypt.mksalt(crypt.METHOD_BLOWFISH, rounds=1 << 10))
+    if not h or not h.startswith("$2"):
+        raise ValueError("htpasswd requires bcrypt support of the system's crypt library")
+    return "%s:%s" % (username, h)
+`,
+}
+
+func jinja2FilterOpts() []jinja2.Jinja2Opt {
+	var ret []jinja2.Jinja2Opt
+	for name, code := range jinja2Filters {
+		ret = append(ret, jinja2.WithFilter(name, code))
+	}
+	return ret
+}
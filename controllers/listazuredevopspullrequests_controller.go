@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/url"
+	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAzureDevOpsAPI = "https://dev.azure.com"
+
+const azureDevOpsPageSize = 100
+
+// ListAzureDevOpsPullRequestsReconciler reconciles a ListAzureDevOpsPullRequests object
+type ListAzureDevOpsPullRequestsReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+}
+
+type azureDevOpsList[T any] struct {
+	Value []T `json:"value"`
+	Count int `json:"count"`
+}
+
+// azureDevOpsPullRequest is the subset of the Azure DevOps pull request API object that is written into the status.
+// SourceBranch and TargetBranch are not part of the API object and contain the branch names without the refs/heads/
+// prefix.
+type azureDevOpsPullRequest struct {
+	PullRequestID         int64                  `json:"pullRequestId"`
+	Title                 string                 `json:"title"`
+	Description           string                 `json:"description,omitempty"`
+	Status                string                 `json:"status"`
+	IsDraft               bool                   `json:"isDraft"`
+	MergeStatus           string                 `json:"mergeStatus,omitempty"`
+	CreatedBy             *azureDevOpsIdentity   `json:"createdBy,omitempty"`
+	CreationDate          *time.Time             `json:"creationDate,omitempty"`
+	ClosedDate            *time.Time             `json:"closedDate,omitempty"`
+	SourceRefName         string                 `json:"sourceRefName"`
+	TargetRefName         string                 `json:"targetRefName"`
+	SourceBranch          string                 `json:"sourceBranch"`
+	TargetBranch          string                 `json:"targetBranch"`
+	LastMergeSourceCommit *azureDevOpsCommitRef  `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeTargetCommit *azureDevOpsCommitRef  `json:"lastMergeTargetCommit,omitempty"`
+	LastMergeCommit       *azureDevOpsCommitRef  `json:"lastMergeCommit,omitempty"`
+	Labels                []azureDevOpsLabel     `json:"labels,omitempty"`
+	Repository            *azureDevOpsRepository `json:"repository,omitempty"`
+	URL                   string                 `json:"url"`
+}
+
+type azureDevOpsIdentity struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+type azureDevOpsCommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+type azureDevOpsLabel struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+type azureDevOpsRepository struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Project *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"project,omitempty"`
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listazuredevopspullrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listazuredevopspullrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listazuredevopspullrequests/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *ListAzureDevOpsPullRequestsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	var obj templatesv1alpha1.ListAzureDevOpsPullRequests
+	err := r.Get(ctx, req.NamespacedName, &obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.doReconcile(ctx, &obj)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Success",
+			Message:            "Success",
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
+
+	err = r.Status().Update(ctx, &obj, SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{
+		RequeueAfter: obj.Spec.Interval.Duration,
+	}, nil
+}
+
+func (r *ListAzureDevOpsPullRequestsReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListAzureDevOpsPullRequests) error {
+	var err error
+	headers := map[string]string{}
+	if obj.Spec.TokenRef != nil {
+		token, err := GetSecretToken(ctx, r.Client, obj.Namespace, r.Policy, *obj.Spec.TokenRef)
+		if err != nil {
+			return err
+		}
+		// PATs are passed as password with an empty username
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+token))
+	}
+
+	sourceBranchRegex := regexp.MustCompile(".*")
+	targetBranchRegex := regexp.MustCompile(".*")
+
+	if obj.Spec.SourceBranch != nil {
+		sourceBranchRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.SourceBranch))
+		if err != nil {
+			return err
+		}
+	}
+	if obj.Spec.TargetBranch != nil {
+		targetBranchRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.TargetBranch))
+		if err != nil {
+			return err
+		}
+	}
+
+	prFilter, err := newPullRequestFilter(obj.Spec.PullRequestFilters)
+	if err != nil {
+		return err
+	}
+
+	api := defaultAzureDevOpsAPI
+	if obj.Spec.API != nil && *obj.Spec.API != "" {
+		api = *obj.Spec.API
+	}
+	baseUrl := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests", strings.TrimSuffix(api, "/"),
+		url.PathEscape(obj.Spec.Organization), url.PathEscape(obj.Spec.Project), url.PathEscape(obj.Spec.Repo))
+	webBaseUrl := fmt.Sprintf("%s/%s/%s/_git/%s/pullrequest", strings.TrimSuffix(api, "/"),
+		url.PathEscape(obj.Spec.Organization), url.PathEscape(obj.Spec.Project), url.PathEscape(obj.Spec.Repo))
+
+	hc := r.Policy.HTTPClient()
+
+	state := obj.Spec.State
+	if state == "" {
+		state = "active"
+	}
+
+	var result []*azureDevOpsPullRequest
+	for len(result) < obj.Spec.Limit {
+		perPage := azureDevOpsPageSize
+		if len(result)+perPage > obj.Spec.Limit {
+			perPage = obj.Spec.Limit - len(result)
+		}
+
+		q := url.Values{}
+		q.Set("api-version", "7.0")
+		q.Set("searchCriteria.status", state)
+		q.Set("$skip", strconv.Itoa(len(result)))
+		q.Set("$top", strconv.Itoa(perPage))
+
+		var l azureDevOpsList[*azureDevOpsPullRequest]
+		_, err = getJson(ctx, hc, baseUrl+"?"+q.Encode(), headers, &l)
+		if err != nil {
+			return err
+		}
+		result = append(result, l.Value...)
+		if len(l.Value) < perPage {
+			break
+		}
+	}
+
+	// the API does not support sorting and always returns the newest pull requests first, so sorting can only be
+	// applied to the fetched pull requests
+	sortPullRequests(result, obj.Spec.PullRequestFilters, func(pr *azureDevOpsPullRequest) (int64, *time.Time, *time.Time) {
+		return pr.PullRequestID, pr.CreationDate, pr.CreationDate
+	})
+
+	newPullRequests := make([]runtime.RawExtension, 0, len(result))
+
+	for _, pr := range result {
+		pr.SourceBranch = strings.TrimPrefix(pr.SourceRefName, "refs/heads/")
+		pr.TargetBranch = strings.TrimPrefix(pr.TargetRefName, "refs/heads/")
+
+		if !sourceBranchRegex.MatchString(pr.SourceBranch) || !targetBranchRegex.MatchString(pr.TargetBranch) {
+			continue
+		}
+		allLabelsFound := true
+		for _, l := range obj.Spec.Labels {
+			found := false
+			for _, l2 := range pr.Labels {
+				if l2.Active && l == l2.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				allLabelsFound = false
+				break
+			}
+		}
+		if !allLabelsFound {
+			continue
+		}
+
+		var labels []string
+		for _, l := range pr.Labels {
+			if l.Active {
+				labels = append(labels, l.Name)
+			}
+		}
+		info := pullRequestInfo{
+			Number:       pr.PullRequestID,
+			SourceBranch: pr.SourceBranch,
+			TargetBranch: pr.TargetBranch,
+			Labels:       labels,
+			Description:  pr.Description,
+			WebUrl:       fmt.Sprintf("%s/%d", webBaseUrl, pr.PullRequestID),
+			Title:        pr.Title,
+			Draft:        pr.IsDraft,
+			UpdatedAt:    pr.CreationDate, // Azure DevOps does not return the time of the last update
+			ChangedFiles: func() ([]string, error) {
+				return r.listChangedFiles(ctx, hc, baseUrl, headers, pr.PullRequestID)
+			},
+		}
+		if pr.LastMergeSourceCommit != nil {
+			info.HeadSha = pr.LastMergeSourceCommit.CommitID
+		}
+		if pr.CreatedBy != nil {
+			info.Author = pr.CreatedBy.UniqueName
+		}
+		ok, err := prFilter.match(info)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		j, err := json.Marshal(pr)
+		if err != nil {
+			return err
+		}
+		j, err = addPullRequestSummary(j, info)
+		if err != nil {
+			return err
+		}
+		j, err = HashFields(j, obj.Spec.HashFields)
+		if err != nil {
+			return err
+		}
+
+		newPullRequests = append(newPullRequests, runtime.RawExtension{Raw: j})
+	}
+
+	obj.Status.PullRequests = newPullRequests
+
+	return nil
+}
+
+// listChangedFiles returns the paths of all files changed by the latest iteration of the given pull request, compared
+// to the target branch
+func (r *ListAzureDevOpsPullRequestsReconciler) listChangedFiles(ctx context.Context, hc *http.Client, baseUrl string, headers map[string]string, id int64) ([]string, error) {
+	q := url.Values{}
+	q.Set("api-version", "7.0")
+
+	var iterations azureDevOpsList[struct {
+		ID int64 `json:"id"`
+	}]
+	_, err := getJson(ctx, hc, fmt.Sprintf("%s/%d/iterations?%s", baseUrl, id, q.Encode()), headers, &iterations)
+	if err != nil {
+		return nil, err
+	}
+	var latestIteration int64
+	for _, it := range iterations.Value {
+		if it.ID > latestIteration {
+			latestIteration = it.ID
+		}
+	}
+	if latestIteration == 0 {
+		return nil, nil
+	}
+
+	var files []string
+	skip := 0
+	for {
+		q.Set("$compareTo", "0")
+		q.Set("$top", strconv.Itoa(azureDevOpsPageSize))
+		q.Set("$skip", strconv.Itoa(skip))
+
+		var changes struct {
+			ChangeEntries []struct {
+				OriginalPath string `json:"originalPath,omitempty"`
+				Item         struct {
+					Path string `json:"path"`
+				} `json:"item"`
+			} `json:"changeEntries"`
+			NextSkip int `json:"nextSkip"`
+		}
+		_, err = getJson(ctx, hc, fmt.Sprintf("%s/%d/iterations/%d/changes?%s", baseUrl, id, latestIteration, q.Encode()), headers, &changes)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range changes.ChangeEntries {
+			// paths are returned with a leading slash
+			files = append(files, strings.TrimPrefix(c.Item.Path, "/"))
+			if c.OriginalPath != "" {
+				files = append(files, strings.TrimPrefix(c.OriginalPath, "/"))
+			}
+		}
+		if changes.NextSkip == 0 || len(changes.ChangeEntries) == 0 {
+			break
+		}
+		skip = changes.NextSkip
+	}
+	return files, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ListAzureDevOpsPullRequestsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.ListAzureDevOpsPullRequests{}).
+		Complete(redact.Reconciler(r))
+}
@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/url"
+	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"time"
+)
+
+const bitbucketCloudAPI = "https://api.bitbucket.org/2.0"
+
+// bitbucketCloudPageSize is the maximum page size allowed by the Bitbucket Cloud API when listing pull requests
+const bitbucketCloudPageSize = 50
+
+// bitbucketCloudDiffStatPageSize is the maximum page size allowed by the Bitbucket Cloud API when listing changed files
+const bitbucketCloudDiffStatPageSize = 500
+
+// ListBitbucketCloudPullRequestsReconciler reconciles a ListBitbucketCloudPullRequests object
+type ListBitbucketCloudPullRequestsReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+}
+
+type bitbucketCloudPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next,omitempty"`
+}
+
+// bitbucketCloudPullRequest is the subset of the Bitbucket Cloud pull request API object that is written into the
+// status
+type bitbucketCloudPullRequest struct {
+	ID           int64                  `json:"id"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description,omitempty"`
+	State        string                 `json:"state"`
+	Draft        bool                   `json:"draft"`
+	Author       *bitbucketCloudUser    `json:"author,omitempty"`
+	Source       bitbucketCloudEndpoint `json:"source"`
+	Destination  bitbucketCloudEndpoint `json:"destination"`
+	MergeCommit  *bitbucketCloudCommit  `json:"merge_commit,omitempty"`
+	CommentCount int                    `json:"comment_count"`
+	TaskCount    int                    `json:"task_count"`
+	CreatedOn    *time.Time             `json:"created_on,omitempty"`
+	UpdatedOn    *time.Time             `json:"updated_on,omitempty"`
+	Links        struct {
+		HTML *struct {
+			Href string `json:"href"`
+		} `json:"html,omitempty"`
+	} `json:"links"`
+}
+
+type bitbucketCloudUser struct {
+	UUID        string `json:"uuid"`
+	AccountID   string `json:"account_id,omitempty"`
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+type bitbucketCloudEndpoint struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit     *bitbucketCloudCommit `json:"commit,omitempty"`
+	Repository *struct {
+		UUID     string `json:"uuid"`
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository,omitempty"`
+}
+
+type bitbucketCloudCommit struct {
+	Hash string `json:"hash"`
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listbitbucketcloudpullrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listbitbucketcloudpullrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listbitbucketcloudpullrequests/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *ListBitbucketCloudPullRequestsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	var obj templatesv1alpha1.ListBitbucketCloudPullRequests
+	err := r.Get(ctx, req.NamespacedName, &obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.doReconcile(ctx, &obj)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Success",
+			Message:            "Success",
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
+
+	err = r.Status().Update(ctx, &obj, SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{
+		RequeueAfter: obj.Spec.Interval.Duration,
+	}, nil
+}
+
+func (r *ListBitbucketCloudPullRequestsReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListBitbucketCloudPullRequests) error {
+	var err error
+	headers := map[string]string{}
+	if obj.Spec.BasicAuth != nil {
+		headers["Authorization"], err = getBasicAuthHeader(ctx, r.Client, obj.Namespace, r.Policy, *obj.Spec.BasicAuth)
+		if err != nil {
+			return err
+		}
+	} else if obj.Spec.TokenRef != nil {
+		token, err := GetSecretToken(ctx, r.Client, obj.Namespace, r.Policy, *obj.Spec.TokenRef)
+		if err != nil {
+			return err
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	sourceBranchRegex := regexp.MustCompile(".*")
+	targetBranchRegex := regexp.MustCompile(".*")
+
+	if obj.Spec.SourceBranch != nil {
+		sourceBranchRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.SourceBranch))
+		if err != nil {
+			return err
+		}
+	}
+	if obj.Spec.TargetBranch != nil {
+		targetBranchRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.TargetBranch))
+		if err != nil {
+			return err
+		}
+	}
+
+	prFilter, err := newPullRequestFilter(obj.Spec.PullRequestFilters)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	if obj.Spec.State == "" || obj.Spec.State == "ALL" {
+		// the API only returns open pull requests if no state is specified
+		for _, s := range []string{"OPEN", "MERGED", "DECLINED", "SUPERSEDED"} {
+			q.Add("state", s)
+		}
+	} else {
+		q.Set("state", obj.Spec.State)
+	}
+	perPage := bitbucketCloudPageSize
+	if obj.Spec.Limit < perPage {
+		perPage = obj.Spec.Limit
+	}
+	q.Set("pagelen", strconv.Itoa(perPage))
+	if obj.Spec.SortBy != "" {
+		sortField := obj.Spec.SortBy + "_on"
+		if obj.Spec.SortDirection != "asc" {
+			sortField = "-" + sortField
+		}
+		q.Set("sort", sortField)
+	}
+	baseUrl := fmt.Sprintf("%s/repositories/%s/%s/pullrequests", bitbucketCloudAPI,
+		url.PathEscape(obj.Spec.Workspace), url.PathEscape(obj.Spec.Repo))
+	nextUrl := baseUrl + "?" + q.Encode()
+
+	hc := r.Policy.HTTPClient()
+
+	var result []*bitbucketCloudPullRequest
+	for nextUrl != "" && len(result) < obj.Spec.Limit {
+		var page bitbucketCloudPage[*bitbucketCloudPullRequest]
+		_, err = getJson(ctx, hc, nextUrl, headers, &page)
+		if err != nil {
+			return err
+		}
+		result = append(result, page.Values...)
+		nextUrl = page.Next
+	}
+	if len(result) > obj.Spec.Limit {
+		result = result[:obj.Spec.Limit]
+	}
+
+	sortPullRequests(result, obj.Spec.PullRequestFilters, func(pr *bitbucketCloudPullRequest) (int64, *time.Time, *time.Time) {
+		return pr.ID, pr.CreatedOn, pr.UpdatedOn
+	})
+
+	newPullRequests := make([]runtime.RawExtension, 0, len(result))
+
+	for _, pr := range result {
+		if !sourceBranchRegex.MatchString(pr.Source.Branch.Name) || !targetBranchRegex.MatchString(pr.Destination.Branch.Name) {
+			continue
+		}
+
+		info := pullRequestInfo{
+			Number:       pr.ID,
+			SourceBranch: pr.Source.Branch.Name,
+			TargetBranch: pr.Destination.Branch.Name,
+			Description:  pr.Description,
+			Title:        pr.Title,
+			Draft:        pr.Draft,
+			UpdatedAt:    pr.UpdatedOn,
+			ChangedFiles: func() ([]string, error) {
+				return r.listChangedFiles(ctx, hc, baseUrl, headers, pr.ID)
+			},
+		}
+		if pr.Source.Commit != nil {
+			info.HeadSha = pr.Source.Commit.Hash
+		}
+		if pr.Links.HTML != nil {
+			info.WebUrl = pr.Links.HTML.Href
+		}
+		if pr.Author != nil {
+			info.Author = pr.Author.Nickname
+			info.IsBot = pr.Author.Type == "app_user"
+		}
+		ok, err := prFilter.match(info)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		j, err := json.Marshal(pr)
+		if err != nil {
+			return err
+		}
+		j, err = addPullRequestSummary(j, info)
+		if err != nil {
+			return err
+		}
+		j, err = HashFields(j, obj.Spec.HashFields)
+		if err != nil {
+			return err
+		}
+
+		newPullRequests = append(newPullRequests, runtime.RawExtension{Raw: j})
+	}
+
+	obj.Status.PullRequests = newPullRequests
+
+	return nil
+}
+
+// listChangedFiles returns the paths of all files changed by the given pull request
+func (r *ListBitbucketCloudPullRequestsReconciler) listChangedFiles(ctx context.Context, hc *http.Client, baseUrl string, headers map[string]string, id int64) ([]string, error) {
+	type bitbucketCloudDiffStatFile struct {
+		Path string `json:"path"`
+	}
+	type bitbucketCloudDiffStat struct {
+		Old *bitbucketCloudDiffStatFile `json:"old,omitempty"`
+		New *bitbucketCloudDiffStatFile `json:"new,omitempty"`
+	}
+
+	var files []string
+	nextUrl := fmt.Sprintf("%s/%d/diffstat?pagelen=%d", baseUrl, id, bitbucketCloudDiffStatPageSize)
+	for nextUrl != "" {
+		var page bitbucketCloudPage[bitbucketCloudDiffStat]
+		_, err := getJson(ctx, hc, nextUrl, headers, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, ds := range page.Values {
+			if ds.New != nil {
+				files = append(files, ds.New.Path)
+			}
+			if ds.Old != nil && (ds.New == nil || ds.Old.Path != ds.New.Path) {
+				files = append(files, ds.Old.Path)
+			}
+		}
+		nextUrl = page.Next
+	}
+	return files, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ListBitbucketCloudPullRequestsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.ListBitbucketCloudPullRequests{}).
+		Complete(redact.Reconciler(r))
+}
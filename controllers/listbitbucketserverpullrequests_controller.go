@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/url"
+	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const bitbucketServerPageSize = 100
+
+// ListBitbucketServerPullRequestsReconciler reconciles a ListBitbucketServerPullRequests object
+type ListBitbucketServerPullRequestsReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+}
+
+type bitbucketServerPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// bitbucketServerPullRequest is the subset of the Bitbucket Server pull request API object that is written into the
+// status
+type bitbucketServerPullRequest struct {
+	ID          int64                        `json:"id"`
+	Version     int64                        `json:"version"`
+	Title       string                       `json:"title"`
+	Description string                       `json:"description,omitempty"`
+	State       string                       `json:"state"`
+	Open        bool                         `json:"open"`
+	Closed      bool                         `json:"closed"`
+	Draft       bool                         `json:"draft"`
+	CreatedDate int64                        `json:"createdDate"`
+	UpdatedDate int64                        `json:"updatedDate"`
+	FromRef     bitbucketServerRef           `json:"fromRef"`
+	ToRef       bitbucketServerRef           `json:"toRef"`
+	Author      *bitbucketServerParticipant  `json:"author,omitempty"`
+	Reviewers   []bitbucketServerParticipant `json:"reviewers,omitempty"`
+	Links       bitbucketServerLinks         `json:"links"`
+}
+
+type bitbucketServerRef struct {
+	ID           string                     `json:"id"`
+	DisplayID    string                     `json:"displayId"`
+	LatestCommit string                     `json:"latestCommit"`
+	Repository   *bitbucketServerRepository `json:"repository,omitempty"`
+}
+
+type bitbucketServerRepository struct {
+	ID      int64  `json:"id"`
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Project *struct {
+		ID  int64  `json:"id"`
+		Key string `json:"key"`
+	} `json:"project,omitempty"`
+}
+
+type bitbucketServerParticipant struct {
+	User     bitbucketServerUser `json:"user"`
+	Role     string              `json:"role"`
+	Approved bool                `json:"approved"`
+	Status   string              `json:"status"`
+}
+
+type bitbucketServerUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+type bitbucketServerLinks struct {
+	Self []struct {
+		Href string `json:"href"`
+	} `json:"self,omitempty"`
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listbitbucketserverpullrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listbitbucketserverpullrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listbitbucketserverpullrequests/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *ListBitbucketServerPullRequestsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	var obj templatesv1alpha1.ListBitbucketServerPullRequests
+	err := r.Get(ctx, req.NamespacedName, &obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.doReconcile(ctx, &obj)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Success",
+			Message:            "Success",
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
+
+	err = r.Status().Update(ctx, &obj, SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{
+		RequeueAfter: obj.Spec.Interval.Duration,
+	}, nil
+}
+
+func (r *ListBitbucketServerPullRequestsReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListBitbucketServerPullRequests) error {
+	var err error
+	headers := map[string]string{}
+	if obj.Spec.BasicAuth != nil {
+		headers["Authorization"], err = getBasicAuthHeader(ctx, r.Client, obj.Namespace, r.Policy, *obj.Spec.BasicAuth)
+		if err != nil {
+			return err
+		}
+	} else if obj.Spec.TokenRef != nil {
+		token, err := GetSecretToken(ctx, r.Client, obj.Namespace, r.Policy, *obj.Spec.TokenRef)
+		if err != nil {
+			return err
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	sourceBranchRegex := regexp.MustCompile(".*")
+	targetBranchRegex := regexp.MustCompile(".*")
+
+	if obj.Spec.SourceBranch != nil {
+		sourceBranchRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.SourceBranch))
+		if err != nil {
+			return err
+		}
+	}
+	if obj.Spec.TargetBranch != nil {
+		targetBranchRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.TargetBranch))
+		if err != nil {
+			return err
+		}
+	}
+
+	prFilter, err := newPullRequestFilter(obj.Spec.PullRequestFilters)
+	if err != nil {
+		return err
+	}
+
+	baseUrl := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests", strings.TrimSuffix(obj.Spec.API, "/"),
+		url.PathEscape(obj.Spec.Project), url.PathEscape(obj.Spec.Repo))
+
+	hc := r.Policy.HTTPClient()
+
+	state := obj.Spec.State
+	if state == "" {
+		state = "ALL"
+	}
+
+	var result []*bitbucketServerPullRequest
+	start := 0
+	for len(result) < obj.Spec.Limit {
+		perPage := bitbucketServerPageSize
+		if len(result)+perPage > obj.Spec.Limit {
+			perPage = obj.Spec.Limit - len(result)
+		}
+
+		q := url.Values{}
+		q.Set("state", state)
+		q.Set("start", strconv.Itoa(start))
+		q.Set("limit", strconv.Itoa(perPage))
+		if obj.Spec.SortBy != "" {
+			// the API only allows to specify the direction
+			if obj.Spec.SortDirection == "asc" {
+				q.Set("order", "OLDEST")
+			} else {
+				q.Set("order", "NEWEST")
+			}
+		}
+
+		var page bitbucketServerPage[*bitbucketServerPullRequest]
+		_, err = getJson(ctx, hc, baseUrl+"?"+q.Encode(), headers, &page)
+		if err != nil {
+			return err
+		}
+		result = append(result, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	sortPullRequests(result, obj.Spec.PullRequestFilters, func(pr *bitbucketServerPullRequest) (int64, *time.Time, *time.Time) {
+		createdAt, updatedAt := time.UnixMilli(pr.CreatedDate), time.UnixMilli(pr.UpdatedDate)
+		return pr.ID, &createdAt, &updatedAt
+	})
+
+	newPullRequests := make([]runtime.RawExtension, 0, len(result))
+
+	for _, pr := range result {
+		if !sourceBranchRegex.MatchString(pr.FromRef.DisplayID) || !targetBranchRegex.MatchString(pr.ToRef.DisplayID) {
+			continue
+		}
+
+		info := pullRequestInfo{
+			Number:       pr.ID,
+			SourceBranch: pr.FromRef.DisplayID,
+			TargetBranch: pr.ToRef.DisplayID,
+			HeadSha:      pr.FromRef.LatestCommit,
+			Description:  pr.Description,
+			Title:        pr.Title,
+			Draft:        pr.Draft,
+			ChangedFiles: func() ([]string, error) {
+				return r.listChangedFiles(ctx, hc, baseUrl, headers, pr.ID)
+			},
+		}
+		if len(pr.Links.Self) != 0 {
+			info.WebUrl = pr.Links.Self[0].Href
+		}
+		if pr.UpdatedDate != 0 {
+			updatedAt := time.UnixMilli(pr.UpdatedDate)
+			info.UpdatedAt = &updatedAt
+		}
+		if pr.Author != nil {
+			info.Author = pr.Author.User.Name
+			info.IsBot = pr.Author.User.Type == "SERVICE"
+		}
+		ok, err := prFilter.match(info)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		j, err := json.Marshal(pr)
+		if err != nil {
+			return err
+		}
+		j, err = addPullRequestSummary(j, info)
+		if err != nil {
+			return err
+		}
+		j, err = HashFields(j, obj.Spec.HashFields)
+		if err != nil {
+			return err
+		}
+
+		newPullRequests = append(newPullRequests, runtime.RawExtension{Raw: j})
+	}
+
+	obj.Status.PullRequests = newPullRequests
+
+	return nil
+}
+
+// listChangedFiles returns the paths of all files changed by the given pull request
+func (r *ListBitbucketServerPullRequestsReconciler) listChangedFiles(ctx context.Context, hc *http.Client, baseUrl string, headers map[string]string, id int64) ([]string, error) {
+	type bitbucketServerPath struct {
+		ToString string `json:"toString"`
+	}
+	type bitbucketServerChange struct {
+		Path    bitbucketServerPath  `json:"path"`
+		SrcPath *bitbucketServerPath `json:"srcPath,omitempty"`
+	}
+
+	var files []string
+	start := 0
+	for {
+		q := url.Values{}
+		q.Set("start", strconv.Itoa(start))
+		q.Set("limit", strconv.Itoa(bitbucketServerPageSize))
+
+		var page bitbucketServerPage[bitbucketServerChange]
+		_, err := getJson(ctx, hc, fmt.Sprintf("%s/%d/changes?%s", baseUrl, id, q.Encode()), headers, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range page.Values {
+			files = append(files, c.Path.ToString)
+			if c.SrcPath != nil && c.SrcPath.ToString != "" {
+				files = append(files, c.SrcPath.ToString)
+			}
+		}
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+		start = page.NextPageStart
+	}
+	return files, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ListBitbucketServerPullRequestsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.ListBitbucketServerPullRequests{}).
+		Complete(redact.Reconciler(r))
+}
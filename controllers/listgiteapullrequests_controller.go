@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/url"
+	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultGiteaAPI = "https://gitea.com"
+
+// giteaPageSize is the page size used when listing pull requests. Gitea limits the page size to MAX_RESPONSE_ITEMS,
+// which defaults to 50.
+const giteaPageSize = 50
+
+// ListGiteaPullRequestsReconciler reconciles a ListGiteaPullRequests object
+type ListGiteaPullRequestsReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+}
+
+// giteaPullRequest is the subset of the Gitea pull request API object that is written into the status. Users,
+// repositories and labels are reduced in the same way as done for GitHub pull requests.
+type giteaPullRequest struct {
+	ID             int64         `json:"id"`
+	Number         int64         `json:"number"`
+	Title          string        `json:"title"`
+	Body           string        `json:"body"`
+	State          string        `json:"state"`
+	Draft          bool          `json:"draft"`
+	HTMLURL        string        `json:"html_url"`
+	User           *giteaUser    `json:"user,omitempty"`
+	Labels         []giteaLabel  `json:"labels"`
+	Head           giteaPRBranch `json:"head"`
+	Base           giteaPRBranch `json:"base"`
+	Mergeable      bool          `json:"mergeable"`
+	Merged         bool          `json:"merged"`
+	MergeCommitSHA *string       `json:"merge_commit_sha,omitempty"`
+	CreatedAt      *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time    `json:"updated_at,omitempty"`
+	ClosedAt       *time.Time    `json:"closed_at,omitempty"`
+	MergedAt       *time.Time    `json:"merged_at,omitempty"`
+}
+
+type giteaUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type giteaLabel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type giteaPRBranch struct {
+	Label string           `json:"label"`
+	Ref   string           `json:"ref"`
+	Sha   string           `json:"sha"`
+	Repo  *giteaRepository `json:"repo,omitempty"`
+}
+
+type giteaRepository struct {
+	ID       int64      `json:"id"`
+	Owner    *giteaUser `json:"owner,omitempty"`
+	Name     string     `json:"name"`
+	FullName string     `json:"full_name"`
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listgiteapullrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listgiteapullrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listgiteapullrequests/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *ListGiteaPullRequestsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	var obj templatesv1alpha1.ListGiteaPullRequests
+	err := r.Get(ctx, req.NamespacedName, &obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.doReconcile(ctx, &obj)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Success",
+			Message:            "Success",
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
+
+	err = r.Status().Update(ctx, &obj, SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{
+		RequeueAfter: obj.Spec.Interval.Duration,
+	}, nil
+}
+
+func (r *ListGiteaPullRequestsReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListGiteaPullRequests) error {
+	var err error
+	headers := map[string]string{}
+	if obj.Spec.TokenRef != nil {
+		token, err := GetSecretToken(ctx, r.Client, obj.Namespace, r.Policy, *obj.Spec.TokenRef)
+		if err != nil {
+			return err
+		}
+		headers["Authorization"] = "token " + token
+	}
+
+	allRegex := regexp.MustCompile(".*")
+	headRegex := allRegex
+	baseRegex := allRegex
+
+	if obj.Spec.Head != nil {
+		headRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.Head))
+		if err != nil {
+			return err
+		}
+	}
+	if obj.Spec.Base != nil {
+		baseRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.Base))
+		if err != nil {
+			return err
+		}
+	}
+
+	prFilter, err := newPullRequestFilter(obj.Spec.PullRequestFilters)
+	if err != nil {
+		return err
+	}
+
+	api := defaultGiteaAPI
+	if obj.Spec.API != nil && *obj.Spec.API != "" {
+		api = *obj.Spec.API
+	}
+	baseUrl := fmt.Sprintf("%s/api/v1/repos/%s/%s/pulls", strings.TrimSuffix(api, "/"), url.PathEscape(obj.Spec.Owner), url.PathEscape(obj.Spec.Repo))
+
+	hc := r.Policy.HTTPClient()
+
+	state := obj.Spec.State
+	if state == "" {
+		state = "all"
+	}
+
+	var result []*giteaPullRequest
+	for page := 1; len(result) < obj.Spec.Limit; page++ {
+		perPage := giteaPageSize
+		if len(result)+perPage > obj.Spec.Limit {
+			perPage = obj.Spec.Limit - len(result)
+		}
+
+		q := url.Values{}
+		q.Set("state", state)
+		q.Set("page", strconv.Itoa(page))
+		q.Set("limit", strconv.Itoa(perPage))
+		if s := giteaSortType(obj.Spec.PullRequestFilters); s != "" {
+			q.Set("sort", s)
+		}
+
+		var l []*giteaPullRequest
+		_, err = getJson(ctx, hc, baseUrl+"?"+q.Encode(), headers, &l)
+		if err != nil {
+			return err
+		}
+		result = append(result, l...)
+		if len(l) < perPage {
+			break
+		}
+	}
+
+	sortPullRequests(result, obj.Spec.PullRequestFilters, func(pr *giteaPullRequest) (int64, *time.Time, *time.Time) {
+		return pr.ID, pr.CreatedAt, pr.UpdatedAt
+	})
+
+	newPullRequests := make([]runtime.RawExtension, 0, len(result))
+
+	for _, pr := range result {
+		if !headRegex.MatchString(pr.Head.Ref) {
+			continue
+		}
+		if !baseRegex.MatchString(pr.Base.Ref) {
+			continue
+		}
+		allLabelsFound := true
+		for _, l := range obj.Spec.Labels {
+			found := false
+			for _, l2 := range pr.Labels {
+				if l == l2.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				allLabelsFound = false
+				break
+			}
+		}
+		if !allLabelsFound {
+			continue
+		}
+
+		labels := make([]string, 0, len(pr.Labels))
+		for _, l := range pr.Labels {
+			labels = append(labels, l.Name)
+		}
+		var author string
+		if pr.User != nil {
+			author = pr.User.Login
+		}
+		info := pullRequestInfo{
+			Number:       pr.Number,
+			SourceBranch: pr.Head.Ref,
+			TargetBranch: pr.Base.Ref,
+			HeadSha:      pr.Head.Sha,
+			Labels:       labels,
+			Author:       author,
+			Description:  pr.Body,
+			WebUrl:       pr.HTMLURL,
+			Title:        pr.Title,
+			Draft:        pr.Draft,
+			UpdatedAt:    pr.UpdatedAt,
+			ChangedFiles: func() ([]string, error) {
+				return r.listChangedFiles(ctx, hc, baseUrl, headers, pr.Number)
+			},
+		}
+		ok, err := prFilter.match(info)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		j, err := json.Marshal(pr)
+		if err != nil {
+			return err
+		}
+		j, err = addPullRequestSummary(j, info)
+		if err != nil {
+			return err
+		}
+		j, err = HashFields(j, obj.Spec.HashFields)
+		if err != nil {
+			return err
+		}
+
+		newPullRequests = append(newPullRequests, runtime.RawExtension{Raw: j})
+	}
+
+	obj.Status.PullRequests = newPullRequests
+
+	return nil
+}
+
+// giteaSortType returns the value of the "sort" query parameter matching SortBy and SortDirection. Gitea orders by
+// creation time with the newest pull requests first if no sort type is specified.
+func giteaSortType(spec templatesv1alpha1.PullRequestFilters) string {
+	switch {
+	case spec.SortBy == "created" && spec.SortDirection == "asc":
+		return "oldest"
+	case spec.SortBy == "updated" && spec.SortDirection == "asc":
+		return "leastupdate"
+	case spec.SortBy == "updated":
+		return "recentupdate"
+	default:
+		return ""
+	}
+}
+
+// listChangedFiles returns the paths of all files changed by the given pull request
+func (r *ListGiteaPullRequestsReconciler) listChangedFiles(ctx context.Context, hc *http.Client, baseUrl string, headers map[string]string, number int64) ([]string, error) {
+	var files []string
+	for page := 1; ; page++ {
+		q := url.Values{}
+		q.Set("page", strconv.Itoa(page))
+		q.Set("limit", strconv.Itoa(giteaPageSize))
+
+		var l []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename,omitempty"`
+		}
+		_, err := getJson(ctx, hc, fmt.Sprintf("%s/%d/files?%s", baseUrl, number, q.Encode()), headers, &l)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range l {
+			files = append(files, f.Filename)
+			if f.PreviousFilename != "" {
+				files = append(files, f.PreviousFilename)
+			}
+		}
+		if len(l) < giteaPageSize {
+			break
+		}
+	}
+	return files, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ListGiteaPullRequestsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.ListGiteaPullRequests{}).
+		Complete(redact.Reconciler(r))
+}
@@ -21,19 +21,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/google/go-github/v47/github"
-	"golang.org/x/oauth2"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"net/http"
 	"reflect"
 	"regexp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sort"
+	"time"
 
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
 )
 
 // ListGithubPullRequestsReconciler reconciles a ListGithubPullRequests object
@@ -41,6 +41,7 @@ type ListGithubPullRequestsReconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
 	FieldManager string
+	Policy       *policy.Policy
 }
 
 //+kubebuilder:rbac:groups=templates.kluctl.io,resources=listgithubpullrequests,verbs=get;list;watch;create;update;patch;delete
@@ -64,7 +65,7 @@ func (r *ListGithubPullRequestsReconciler) Reconcile(ctx context.Context, req ct
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: obj.GetGeneration(),
 			Reason:             "Error",
-			Message:            err.Error(),
+			Message:            redact.Error(ctx, err),
 		}
 		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
 	} else {
@@ -77,6 +78,7 @@ func (r *ListGithubPullRequestsReconciler) Reconcile(ctx context.Context, req ct
 		}
 		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
 	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
 
 	// TODO optimize the update as it currently causes to update all merge requests on every call
 	// patching is not working very well as causes nulls to be pruned and full array replacement for every single change
@@ -91,14 +93,9 @@ func (r *ListGithubPullRequestsReconciler) Reconcile(ctx context.Context, req ct
 }
 
 func (r *ListGithubPullRequestsReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListGithubPullRequests) error {
-	var token string
-	var err error
-
-	if obj.Spec.TokenRef != nil {
-		token, err = GetSecretToken(ctx, r.Client, obj.Namespace, *obj.Spec.TokenRef)
-		if err != nil {
-			return err
-		}
+	tc, err := BuildGithubHttpClient(ctx, r.Client, obj.Namespace, r.Policy, obj.Spec.GithubProject)
+	if err != nil {
+		return err
 	}
 
 	allRegex := regexp.MustCompile(".*")
@@ -118,19 +115,24 @@ func (r *ListGithubPullRequestsReconciler) doReconcile(ctx context.Context, obj
 		}
 	}
 
-	var tc *http.Client
-	if token != "" {
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		tc = oauth2.NewClient(ctx, ts)
+	prFilter, err := newPullRequestFilter(obj.Spec.PullRequestFilters)
+	if err != nil {
+		return err
+	}
+
+	gh, err := NewGithubClient(tc, obj.Spec.API)
+	if err != nil {
+		return err
 	}
-	gh := github.NewClient(tc)
 
 	listOpts := &github.PullRequestListOptions{}
 	listOpts.State = obj.Spec.State
 	listOpts.Page = 1
 	listOpts.PerPage = 100
+	if obj.Spec.SortBy != "" {
+		listOpts.Sort = obj.Spec.SortBy
+		listOpts.Direction = obj.Spec.SortDirection
+	}
 
 	var result []*github.PullRequest
 	for true {
@@ -149,8 +151,8 @@ func (r *ListGithubPullRequestsReconciler) doReconcile(ctx context.Context, obj
 		listOpts.Page += 1
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return *result[i].ID < *result[j].ID
+	sortPullRequests(result, obj.Spec.PullRequestFilters, func(pr *github.PullRequest) (int64, *time.Time, *time.Time) {
+		return pr.GetID(), pr.CreatedAt, pr.UpdatedAt
 	})
 
 	newPullRequests := make([]runtime.RawExtension, 0, len(result))
@@ -180,7 +182,36 @@ func (r *ListGithubPullRequestsReconciler) doReconcile(ctx context.Context, obj
 			continue
 		}
 
-		err := r.simplifyObject(reflect.ValueOf(pr))
+		labels := make([]string, 0, len(pr.Labels))
+		for _, l := range pr.Labels {
+			labels = append(labels, l.GetName())
+		}
+		info := pullRequestInfo{
+			Number:       int64(pr.GetNumber()),
+			SourceBranch: pr.Head.GetRef(),
+			TargetBranch: pr.Base.GetRef(),
+			HeadSha:      pr.Head.GetSHA(),
+			Labels:       labels,
+			Author:       pr.GetUser().GetLogin(),
+			Description:  pr.GetBody(),
+			WebUrl:       pr.GetHTMLURL(),
+			IsBot:        pr.GetUser().GetType() == "Bot",
+			Title:        pr.GetTitle(),
+			Draft:        pr.GetDraft(),
+			UpdatedAt:    pr.UpdatedAt,
+			ChangedFiles: func() ([]string, error) {
+				return r.listChangedFiles(ctx, gh, obj, pr.GetNumber())
+			},
+		}
+		ok, err := prFilter.match(info)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		err = r.simplifyObject(reflect.ValueOf(pr))
 		if err != nil {
 			return err
 		}
@@ -189,6 +220,14 @@ func (r *ListGithubPullRequestsReconciler) doReconcile(ctx context.Context, obj
 		if err != nil {
 			return err
 		}
+		j, err = addPullRequestSummary(j, info)
+		if err != nil {
+			return err
+		}
+		j, err = HashFields(j, obj.Spec.HashFields)
+		if err != nil {
+			return err
+		}
 
 		newPullRequests = append(newPullRequests, runtime.RawExtension{Raw: j})
 	}
@@ -198,6 +237,33 @@ func (r *ListGithubPullRequestsReconciler) doReconcile(ctx context.Context, obj
 	return nil
 }
 
+// listChangedFiles returns the paths of all files changed by the given pull request. The GitHub API returns at most
+// 3000 files per pull request.
+func (r *ListGithubPullRequestsReconciler) listChangedFiles(ctx context.Context, gh *github.Client, obj *templatesv1alpha1.ListGithubPullRequests, number int) ([]string, error) {
+	opts := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+	var files []string
+	for {
+		page, resp, err := gh.PullRequests.ListFiles(ctx, obj.Spec.Owner, obj.Spec.Repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range page {
+			files = append(files, f.GetFilename())
+			if f.GetPreviousFilename() != "" {
+				files = append(files, f.GetPreviousFilename())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return files, nil
+}
+
 func (r *ListGithubPullRequestsReconciler) simplifyObject(v reflect.Value) error {
 	switch v.Kind() {
 	case reflect.Pointer:
@@ -302,5 +368,5 @@ func (r *ListGithubPullRequestsReconciler) simplifyLabel(x *github.Label) error
 func (r *ListGithubPullRequestsReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&templatesv1alpha1.ListGithubPullRequests{}).
-		Complete(r)
+		Complete(redact.Reconciler(r))
 }
@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
 	"github.com/xanzy/go-gitlab"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sort"
+	"time"
 )
 
 // ListGitlabMergeRequestsReconciler reconciles a ListGitlabMergeRequests object
@@ -37,6 +40,7 @@ type ListGitlabMergeRequestsReconciler struct {
 	client.Client
 	Scheme       *runtime.Scheme
 	FieldManager string
+	Policy       *policy.Policy
 }
 
 //+kubebuilder:rbac:groups=templates.kluctl.io,resources=listgitlabmergerequests,verbs=get;list;watch;create;update;patch;delete
@@ -60,7 +64,7 @@ func (r *ListGitlabMergeRequestsReconciler) Reconcile(ctx context.Context, req c
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: obj.GetGeneration(),
 			Reason:             "Error",
-			Message:            err.Error(),
+			Message:            redact.Error(ctx, err),
 		}
 		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
 	} else {
@@ -73,6 +77,7 @@ func (r *ListGitlabMergeRequestsReconciler) Reconcile(ctx context.Context, req c
 		}
 		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
 	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
 
 	// TODO optimize the update as it currently causes to update all merge requests on every call
 	// patching is not working very well as causes nulls to be pruned and full array replacement for every single change
@@ -87,14 +92,13 @@ func (r *ListGitlabMergeRequestsReconciler) Reconcile(ctx context.Context, req c
 }
 
 func (r *ListGitlabMergeRequestsReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListGitlabMergeRequests) error {
-	var token string
-	var err error
-
-	if obj.Spec.TokenRef != nil {
-		token, err = GetSecretToken(ctx, r.Client, obj.Namespace, *obj.Spec.TokenRef)
-		if err != nil {
-			return err
-		}
+	gl, token, err := BuildGitlabClient(ctx, r.Client, obj.Namespace, r.Policy, obj.Spec.API, obj.Spec.GitlabAuth)
+	if err != nil {
+		return err
+	}
+	err = CheckGitlabTokenScopes(ctx, gl, token, "listing merge requests", "read_api", "api")
+	if err != nil {
+		return err
 	}
 
 	sourceBranchRegex := regexp.MustCompile(".*")
@@ -113,46 +117,69 @@ func (r *ListGitlabMergeRequestsReconciler) doReconcile(ctx context.Context, obj
 		}
 	}
 
-	var opts []gitlab.ClientOptionFunc
-	if obj.Spec.API != nil {
-		opts = append(opts, gitlab.WithBaseURL(*obj.Spec.API))
-	}
-	gl, err := gitlab.NewClient(token, opts...)
+	prFilter, err := newPullRequestFilter(obj.Spec.PullRequestFilters)
 	if err != nil {
 		return err
 	}
 
+	var projects []string
+	if obj.Spec.Group != nil {
+		if obj.Spec.Project != nil {
+			return fmt.Errorf("project and group are mutually exclusive")
+		}
+		projects, err = r.listGroupProjects(ctx, gl, obj.Spec.Group)
+		if err != nil {
+			return err
+		}
+	} else if obj.Spec.Project != nil {
+		projects = []string{obj.Spec.Project.String()}
+	} else {
+		return fmt.Errorf("either project or group must be specified")
+	}
+
 	labels := gitlab.LabelOptions(obj.Spec.Labels)
 	if len(labels) == 0 {
 		labels = nil
 	}
 
-	listOpts := &gitlab.ListProjectMergeRequestsOptions{
-		Labels: &labels,
-		State:  obj.Spec.State,
-	}
-	listOpts.Page = 1
-	listOpts.PerPage = 100
-
 	var result []*gitlab.MergeRequest
-	for true {
-		if len(result)+listOpts.PerPage > obj.Spec.Limit {
-			listOpts.PerPage = obj.Spec.Limit - len(result)
+	for _, project := range projects {
+		if len(result) >= obj.Spec.Limit {
+			break
 		}
 
-		page, _, err := gl.MergeRequests.ListProjectMergeRequests(obj.Spec.Project, listOpts, gitlab.WithContext(ctx))
-		if err != nil {
-			return err
+		listOpts := &gitlab.ListProjectMergeRequestsOptions{
+			Labels: &labels,
+			State:  obj.Spec.State,
 		}
-		result = append(result, page...)
-		if len(page) != listOpts.PerPage || len(result) >= obj.Spec.Limit {
-			break
+		listOpts.Page = 1
+		listOpts.PerPage = 100
+		if obj.Spec.SortBy != "" {
+			listOpts.OrderBy = gitlab.String(obj.Spec.SortBy + "_at")
+			if obj.Spec.SortDirection != "" {
+				listOpts.Sort = gitlab.String(obj.Spec.SortDirection)
+			}
+		}
+
+		for true {
+			if len(result)+listOpts.PerPage > obj.Spec.Limit {
+				listOpts.PerPage = obj.Spec.Limit - len(result)
+			}
+
+			page, _, err := gl.MergeRequests.ListProjectMergeRequests(project, listOpts, gitlab.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			result = append(result, page...)
+			if len(page) != listOpts.PerPage || len(result) >= obj.Spec.Limit {
+				break
+			}
+			listOpts.Page += 1
 		}
-		listOpts.Page += 1
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].ID < result[j].ID
+	sortPullRequests(result, obj.Spec.PullRequestFilters, func(mr *gitlab.MergeRequest) (int64, *time.Time, *time.Time) {
+		return int64(mr.ID), mr.CreatedAt, mr.UpdatedAt
 	})
 
 	newMergeRequests := make([]runtime.RawExtension, 0, len(result))
@@ -178,10 +205,46 @@ func (r *ListGitlabMergeRequestsReconciler) doReconcile(ctx context.Context, obj
 		if !allLabelsFound {
 			continue
 		}
+		var author string
+		if mr.Author != nil {
+			author = mr.Author.Username
+		}
+		info := pullRequestInfo{
+			Number:       int64(mr.IID),
+			SourceBranch: mr.SourceBranch,
+			TargetBranch: mr.TargetBranch,
+			HeadSha:      mr.SHA,
+			Labels:       mr.Labels,
+			Author:       author,
+			Description:  mr.Description,
+			WebUrl:       mr.WebURL,
+			Title:        mr.Title,
+			Draft:        mr.Draft || mr.WorkInProgress,
+			UpdatedAt:    mr.UpdatedAt,
+			ChangedFiles: func() ([]string, error) {
+				return r.listChangedFiles(ctx, gl, mr)
+			},
+		}
+		ok, err := prFilter.match(info)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
 		j, err := json.Marshal(mr)
 		if err != nil {
 			return err
 		}
+		j, err = addPullRequestSummary(j, info)
+		if err != nil {
+			return err
+		}
+		j, err = HashFields(j, obj.Spec.HashFields)
+		if err != nil {
+			return err
+		}
 		newMergeRequests = append(newMergeRequests, runtime.RawExtension{Raw: j})
 	}
 
@@ -190,9 +253,91 @@ func (r *ListGitlabMergeRequestsReconciler) doReconcile(ctx context.Context, obj
 	return nil
 }
 
+// listChangedFiles returns the paths of all files changed by the given merge request
+func (r *ListGitlabMergeRequestsReconciler) listChangedFiles(ctx context.Context, gl *gitlab.Client, mr *gitlab.MergeRequest) ([]string, error) {
+	opts := &gitlab.ListMergeRequestDiffsOptions{}
+	opts.Page = 1
+	opts.PerPage = 100
+
+	var files []string
+	for {
+		page, resp, err := gl.MergeRequests.ListMergeRequestDiffs(mr.ProjectID, mr.IID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page {
+			files = append(files, d.NewPath)
+			if d.OldPath != d.NewPath {
+				files = append(files, d.OldPath)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return files, nil
+}
+
+// listGroupProjects returns the paths of all projects inside the given group that match the include/exclude filters.
+// Projects with disabled merge requests are skipped.
+func (r *ListGitlabMergeRequestsReconciler) listGroupProjects(ctx context.Context, gl *gitlab.Client, group *templatesv1alpha1.GitlabGroup) ([]string, error) {
+	var err error
+	includeRegex := regexp.MustCompile(".*")
+	var excludeRegex *regexp.Regexp
+
+	if group.IncludeProjects != nil {
+		includeRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *group.IncludeProjects))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if group.ExcludeProjects != nil {
+		excludeRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *group.ExcludeProjects))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listOpts := &gitlab.ListGroupProjectsOptions{
+		IncludeSubGroups:         gitlab.Bool(group.IncludeSubgroups),
+		WithMergeRequestsEnabled: gitlab.Bool(true),
+	}
+	if !group.IncludeArchived {
+		listOpts.Archived = gitlab.Bool(false)
+	}
+	listOpts.Page = 1
+	listOpts.PerPage = 100
+
+	var result []string
+	for true {
+		page, resp, err := gl.Groups.ListGroupProjects(group.Path, listOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			if !includeRegex.MatchString(p.PathWithNamespace) {
+				continue
+			}
+			if excludeRegex != nil && excludeRegex.MatchString(p.PathWithNamespace) {
+				continue
+			}
+			result = append(result, p.PathWithNamespace)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ListGitlabMergeRequestsReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&templatesv1alpha1.ListGitlabMergeRequests{}).
-		Complete(r)
+		Complete(redact.Reconciler(r))
 }
@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/go-github/v47/github"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"github.com/xanzy/go-gitlab"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+)
+
+// ListRepositoriesReconciler reconciles a ListRepositories object
+type ListRepositoriesReconciler struct {
+	client.Client
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+}
+
+// repositoryFilter applies the filters of a ListRepositories object to the repositories returned by the SCM API
+type repositoryFilter struct {
+	pathRegex        *regexp.Regexp
+	excludePathRegex *regexp.Regexp
+	topics           []string
+	includeArchived  bool
+}
+
+func (f *repositoryFilter) match(ri *templatesv1alpha1.RepositoryInfo) bool {
+	if ri.Archived && !f.includeArchived {
+		return false
+	}
+	if !f.pathRegex.MatchString(ri.FullName) {
+		return false
+	}
+	if f.excludePathRegex != nil && f.excludePathRegex.MatchString(ri.FullName) {
+		return false
+	}
+	for _, t := range f.topics {
+		found := false
+		for _, t2 := range ri.Topics {
+			if t == t2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listrepositories,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listrepositories/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=listrepositories/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *ListRepositoriesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+
+	var obj templatesv1alpha1.ListRepositories
+	err := r.Get(ctx, req.NamespacedName, &obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.doReconcile(ctx, &obj)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             "Success",
+			Message:            "Success",
+		}
+		apimeta.SetStatusCondition(&obj.Status.Conditions, c)
+	}
+	SetStalledCondition(&obj.Status.Conditions, obj.GetGeneration(), err)
+
+	err = r.Status().Update(ctx, &obj, SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{
+		RequeueAfter: obj.Spec.Interval.Duration,
+	}, nil
+}
+
+func (r *ListRepositoriesReconciler) doReconcile(ctx context.Context, obj *templatesv1alpha1.ListRepositories) error {
+	var err error
+	filter := &repositoryFilter{
+		pathRegex:       regexp.MustCompile(".*"),
+		topics:          obj.Spec.Topics,
+		includeArchived: obj.Spec.IncludeArchived,
+	}
+	if obj.Spec.Path != nil {
+		filter.pathRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.Path))
+		if err != nil {
+			return err
+		}
+	}
+	if obj.Spec.ExcludePath != nil {
+		filter.excludePathRegex, err = regexp.Compile(fmt.Sprintf("^%s$", *obj.Spec.ExcludePath))
+		if err != nil {
+			return err
+		}
+	}
+
+	var result []templatesv1alpha1.RepositoryInfo
+	switch {
+	case obj.Spec.Github != nil && obj.Spec.Gitlab != nil:
+		return fmt.Errorf("github and gitlab are mutually exclusive")
+	case obj.Spec.Github != nil:
+		result, err = r.listGithubRepositories(ctx, obj, filter)
+	case obj.Spec.Gitlab != nil:
+		result, err = r.listGitlabProjects(ctx, obj, filter)
+	default:
+		return fmt.Errorf("either github or gitlab must be specified")
+	}
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FullName < result[j].FullName
+	})
+
+	obj.Status.Repositories = result
+
+	return nil
+}
+
+func (r *ListRepositoriesReconciler) listGithubRepositories(ctx context.Context, obj *templatesv1alpha1.ListRepositories, filter *repositoryFilter) ([]templatesv1alpha1.RepositoryInfo, error) {
+	spec := obj.Spec.Github
+	tc, err := BuildGithubHttpClient(ctx, r.Client, obj.Namespace, r.Policy, templatesv1alpha1.GithubProject{
+		Owner:    spec.Owner,
+		API:      spec.API,
+		TokenRef: spec.TokenRef,
+		App:      spec.App,
+	})
+	if err != nil {
+		return nil, err
+	}
+	gh, err := NewGithubClient(tc, spec.API)
+	if err != nil {
+		return nil, err
+	}
+
+	// organisations and users are listed via different endpoints
+	owner, _, err := gh.Users.Get(ctx, spec.Owner)
+	if err != nil {
+		return nil, err
+	}
+	isOrg := owner.GetType() == "Organization"
+
+	listOpts := github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+
+	var result []templatesv1alpha1.RepositoryInfo
+	for true {
+		var page []*github.Repository
+		var resp *github.Response
+		if isOrg {
+			page, resp, err = gh.Repositories.ListByOrg(ctx, spec.Owner, &github.RepositoryListByOrgOptions{
+				ListOptions: listOpts,
+			})
+		} else {
+			page, resp, err = gh.Repositories.List(ctx, spec.Owner, &github.RepositoryListOptions{
+				ListOptions: listOpts,
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range page {
+			ri := templatesv1alpha1.RepositoryInfo{
+				ID:            repo.GetID(),
+				Owner:         repo.GetOwner().GetLogin(),
+				Name:          repo.GetName(),
+				FullName:      repo.GetFullName(),
+				Description:   repo.GetDescription(),
+				DefaultBranch: repo.GetDefaultBranch(),
+				Topics:        repo.Topics,
+				Archived:      repo.GetArchived(),
+				Private:       repo.GetPrivate(),
+				CloneUrl:      repo.GetCloneURL(),
+				SshUrl:        repo.GetSSHURL(),
+				WebUrl:        repo.GetHTMLURL(),
+			}
+			if !filter.match(&ri) {
+				continue
+			}
+			result = append(result, ri)
+			if len(result) >= obj.Spec.Limit {
+				return result, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+func (r *ListRepositoriesReconciler) listGitlabProjects(ctx context.Context, obj *templatesv1alpha1.ListRepositories, filter *repositoryFilter) ([]templatesv1alpha1.RepositoryInfo, error) {
+	spec := obj.Spec.Gitlab
+
+	gl, token, err := BuildGitlabClient(ctx, r.Client, obj.Namespace, r.Policy, spec.API, spec.GitlabAuth)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckGitlabTokenScopes(ctx, gl, token, "listing projects", "read_api", "api")
+	if err != nil {
+		return nil, err
+	}
+
+	listOpts := &gitlab.ListGroupProjectsOptions{
+		IncludeSubGroups: gitlab.Bool(spec.IncludeSubgroups),
+	}
+	if !obj.Spec.IncludeArchived {
+		listOpts.Archived = gitlab.Bool(false)
+	}
+	listOpts.Page = 1
+	listOpts.PerPage = 100
+
+	var result []templatesv1alpha1.RepositoryInfo
+	for true {
+		page, resp, err := gl.Groups.ListGroupProjects(spec.Group, listOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			ri := templatesv1alpha1.RepositoryInfo{
+				ID:            int64(p.ID),
+				Name:          p.Path,
+				FullName:      p.PathWithNamespace,
+				Description:   p.Description,
+				DefaultBranch: p.DefaultBranch,
+				Topics:        p.Topics,
+				Archived:      p.Archived,
+				Private:       p.Visibility == gitlab.PrivateVisibility,
+				CloneUrl:      p.HTTPURLToRepo,
+				SshUrl:        p.SSHURLToRepo,
+				WebUrl:        p.WebURL,
+			}
+			if p.Namespace != nil {
+				ri.Owner = p.Namespace.FullPath
+			}
+			if !filter.match(&ri) {
+				continue
+			}
+			result = append(result, ri)
+			if len(result) >= obj.Spec.Limit {
+				return result, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return result, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ListRepositoriesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.ListRepositories{}).
+		Complete(redact.Reconciler(r))
+}
@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"io"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+const defaultAWSAudience = "sts.amazonaws.com"
+
+// awsIdentityToken implements stscreds.IdentityTokenRetriever with an already requested service account token
+type awsIdentityToken string
+
+func (t awsIdentityToken) GetIdentityToken() ([]byte, error) {
+	return []byte(t), nil
+}
+
+// awsClient sends signed requests to AWS APIs that use the JSON protocol. It is used instead of the service specific
+// SDK clients, which are not needed for the few calls performed here.
+type awsClient struct {
+	hc     *http.Client
+	region string
+	creds  aws.Credentials
+	signer *v4.Signer
+}
+
+// buildAWSInput assumes the IAM role specified by spec with a token of the given service account and returns one
+// matrix input per secret or parameter.
+func (r *BaseTemplateReconciler) buildAWSInput(ctx context.Context, objClient client.Client, objNamespace string, serviceAccountName string, spec *templatesv1alpha1.MatrixEntryAWS) ([]any, error) {
+	if (spec.SecretsManager == nil) == (spec.ParameterStore == nil) {
+		return nil, fmt.Errorf("exactly one of secretsManager and parameterStore must be specified")
+	}
+
+	audience := spec.Audience
+	if audience == "" {
+		audience = defaultAWSAudience
+	}
+	token, err := requestServiceAccountToken(ctx, objClient, objNamespace, serviceAccountName, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	hc := r.Policy.HTTPClient()
+	stsClient := sts.New(sts.Options{
+		Region:     spec.Region,
+		HTTPClient: hc,
+	})
+	creds, err := stscreds.NewWebIdentityRoleProvider(stsClient, spec.RoleArn, awsIdentityToken(token)).Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %w", spec.RoleArn, err)
+	}
+	redact.Register(ctx, creds.SecretAccessKey)
+	redact.Register(ctx, creds.SessionToken)
+
+	c := &awsClient{
+		hc:     hc,
+		region: spec.Region,
+		creds:  creds,
+		signer: v4.NewSigner(),
+	}
+	if spec.SecretsManager != nil {
+		return c.listSecrets(ctx, spec.SecretsManager)
+	}
+	return c.listParameters(ctx, spec.ParameterStore)
+}
+
+// listSecrets returns one matrix input per secret whose name starts with the given prefix
+func (c *awsClient) listSecrets(ctx context.Context, spec *templatesv1alpha1.AWSSecretsManagerSource) ([]any, error) {
+	type secretEntry struct {
+		ARN  string `json:"ARN"`
+		Name string `json:"Name"`
+		Tags []struct {
+			Key   string `json:"Key"`
+			Value string `json:"Value"`
+		} `json:"Tags"`
+	}
+
+	var secrets []secretEntry
+	var nextToken string
+	for {
+		req := map[string]any{
+			"Filters": []map[string]any{
+				{"Key": "name", "Values": []string{spec.Prefix}},
+			},
+			"SortOrder": "asc",
+		}
+		if nextToken != "" {
+			req["NextToken"] = nextToken
+		}
+		var resp struct {
+			SecretList []secretEntry `json:"SecretList"`
+			NextToken  string        `json:"NextToken"`
+		}
+		err := c.call(ctx, "secretsmanager", "secretsmanager.ListSecrets", req, &resp)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, resp.SecretList...)
+		nextToken = resp.NextToken
+		if nextToken == "" {
+			break
+		}
+	}
+
+	var ret []any
+	for _, s := range secrets {
+		// the name filter matches prefixes of any word of the name, so we need to check for the actual prefix
+		if !strings.HasPrefix(s.Name, spec.Prefix) {
+			continue
+		}
+
+		var resp struct {
+			SecretString string `json:"SecretString"`
+		}
+		err := c.call(ctx, "secretsmanager", "secretsmanager.GetSecretValue", map[string]any{"SecretId": s.ARN}, &resp)
+		if err != nil {
+			return nil, err
+		}
+		redact.Register(ctx, resp.SecretString)
+
+		tags := map[string]any{}
+		for _, t := range s.Tags {
+			tags[t.Key] = t.Value
+		}
+		e := map[string]any{
+			"name":  s.Name,
+			"arn":   s.ARN,
+			"value": resp.SecretString,
+			"tags":  tags,
+		}
+		var data map[string]any
+		if json.Unmarshal([]byte(resp.SecretString), &data) == nil {
+			for _, v := range data {
+				if s, ok := v.(string); ok {
+					redact.Register(ctx, s)
+				}
+			}
+			e["data"] = data
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// listParameters returns one matrix input per parameter found below the given path
+func (c *awsClient) listParameters(ctx context.Context, spec *templatesv1alpha1.AWSParameterStoreSource) ([]any, error) {
+	var ret []any
+	var nextToken string
+	for {
+		req := map[string]any{
+			"Path":           spec.Path,
+			"Recursive":      spec.Recursive,
+			"WithDecryption": true,
+		}
+		if nextToken != "" {
+			req["NextToken"] = nextToken
+		}
+		var resp struct {
+			Parameters []struct {
+				Name    string `json:"Name"`
+				Type    string `json:"Type"`
+				Value   string `json:"Value"`
+				Version int64  `json:"Version"`
+			} `json:"Parameters"`
+			NextToken string `json:"NextToken"`
+		}
+		err := c.call(ctx, "ssm", "AmazonSSM.GetParametersByPath", req, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resp.Parameters {
+			if p.Type == "SecureString" {
+				redact.Register(ctx, p.Value)
+			}
+			ret = append(ret, map[string]any{
+				"name":    p.Name,
+				"key":     strings.TrimPrefix(strings.TrimPrefix(p.Name, spec.Path), "/"),
+				"type":    p.Type,
+				"value":   p.Value,
+				"version": p.Version,
+			})
+		}
+		nextToken = resp.NextToken
+		if nextToken == "" {
+			break
+		}
+	}
+	return ret, nil
+}
+
+// call sends a signed request to the given target of an AWS API that uses the JSON protocol
+func (c *awsClient) call(ctx context.Context, service string, target string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://%s.%s.amazonaws.com/", service, c.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+
+	payloadHash := sha256.Sum256(body)
+	err = c.signer.SignHTTP(ctx, c.creds, req, hex.EncodeToString(payloadHash[:]), service, c.region, time.Now())
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned unexpected status code %d: %s", target, resp.StatusCode, string(rb))
+	}
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", target, err)
+	}
+	return nil
+}
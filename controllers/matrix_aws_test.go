@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	. "github.com/onsi/gomega"
+)
+
+const fakeAWSCredentials = `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDTEST</AccessKeyId>
+      <SecretAccessKey>aws-secret-access-key</SecretAccessKey>
+      <SessionToken>aws-session-token</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`
+
+// fakeAWS implements the few calls of STS, Secrets Manager and Parameter Store used by the aws matrix entries. All
+// requests are redirected to it by its transport, independent of the AWS host they are sent to.
+type fakeAWS struct {
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	hosts    []string
+	requests map[string][]map[string]any
+}
+
+func newFakeAWS(t *testing.T) *fakeAWS {
+	f := &fakeAWS{requests: map[string][]map[string]any{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAWS) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, err := url.Parse(f.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	f.mutex.Lock()
+	f.hosts = append(f.hosts, req.URL.Host)
+	f.mutex.Unlock()
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = u.Scheme
+	req.URL.Host = u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (f *fakeAWS) handle(w http.ResponseWriter, r *http.Request) {
+	target := r.Header.Get("X-Amz-Target")
+	if target == "" {
+		_ = r.ParseForm()
+		if r.PostForm.Get("Action") != "AssumeRoleWithWebIdentity" || r.PostForm.Get("WebIdentityToken") != "id-token" ||
+			r.PostForm.Get("RoleArn") != "arn:aws:iam::123456789012:role/reader" {
+			http.Error(w, "<ErrorResponse><Error><Code>AccessDenied</Code><Message>denied</Message></Error></ErrorResponse>", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(fakeAWSCredentials))
+		return
+	}
+
+	// all other requests must be signed with the assumed credentials
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential=AKIDTEST/") || r.Header.Get("X-Amz-Security-Token") != "aws-session-token" {
+		http.Error(w, `{"message":"unsigned request"}`, http.StatusForbidden)
+		return
+	}
+	var in map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&in)
+	f.mutex.Lock()
+	f.requests[target] = append(f.requests[target], in)
+	f.mutex.Unlock()
+
+	var resp string
+	switch target {
+	case "secretsmanager.ListSecrets":
+		if in["NextToken"] == nil {
+			resp = `{"SecretList":[{"ARN":"arn:app-a","Name":"app/a","Tags":[{"Key":"env","Value":"prod"}]},{"ARN":"arn:other","Name":"other/app/x"}],"NextToken":"page2"}`
+		} else {
+			resp = `{"SecretList":[{"ARN":"arn:app-b","Name":"app/b"}]}`
+		}
+	case "secretsmanager.GetSecretValue":
+		switch in["SecretId"] {
+		case "arn:app-a":
+			resp = `{"SecretString":"{\"password\":\"a-secret-value\"}"}`
+		case "arn:app-b":
+			resp = `{"SecretString":"b-secret-value"}`
+		}
+	case "AmazonSSM.GetParametersByPath":
+		if in["NextToken"] == nil {
+			resp = `{"Parameters":[{"Name":"/app/db/host","Type":"String","Value":"db.example.com","Version":1}],"NextToken":"page2"}`
+		} else {
+			resp = `{"Parameters":[{"Name":"/app/db/password","Type":"SecureString","Value":"db-secret-value","Version":4}]}`
+		}
+	}
+	if resp == "" {
+		http.Error(w, `{"message":"not found"}`, http.StatusBadRequest)
+		return
+	}
+	_, _ = w.Write([]byte(resp))
+}
+
+func newAWSTestReconciler(t *testing.T, f *fakeAWS) *BaseTemplateReconciler {
+	p, err := policy.New(policy.Policy{Transport: f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &BaseTemplateReconciler{Policy: p}
+}
+
+func TestBuildAWSInputSecretsManager(t *testing.T) {
+	g := NewWithT(t)
+
+	f := newFakeAWS(t)
+	r := newAWSTestReconciler(t, f)
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+
+	ctx := redact.NewContext(context.Background())
+	inputs, err := r.buildAWSInput(ctx, objClient, "team-a", "aws-reader", &templatesv1alpha1.MatrixEntryAWS{
+		Region:         "eu-central-1",
+		RoleArn:        "arn:aws:iam::123456789012:role/reader",
+		SecretsManager: &templatesv1alpha1.AWSSecretsManagerSource{Prefix: "app/"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	// secrets of all pages are returned, except for those that only match the prefix inside of their name
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{
+			"name":  "app/a",
+			"arn":   "arn:app-a",
+			"value": `{"password":"a-secret-value"}`,
+			"data":  map[string]any{"password": "a-secret-value"},
+			"tags":  map[string]any{"env": "prod"},
+		},
+		map[string]any{
+			"name":  "app/b",
+			"arn":   "arn:app-b",
+			"value": "b-secret-value",
+			"tags":  map[string]any{},
+		},
+	}))
+	g.Expect(requests).To(Equal([]tokenRequest{{namespace: "team-a", name: "aws-reader", audiences: []string{defaultAWSAudience}}}))
+	g.Expect(f.requests["secretsmanager.ListSecrets"]).To(HaveLen(2))
+	g.Expect(f.requests["secretsmanager.ListSecrets"][0]["Filters"]).To(Equal([]any{map[string]any{"Key": "name", "Values": []any{"app/"}}}))
+	g.Expect(f.hosts).To(ContainElement("secretsmanager.eu-central-1.amazonaws.com"))
+
+	// secret values and credentials are redacted from messages of the current reconciliation
+	g.Expect(redact.String(ctx, "a-secret-value b-secret-value aws-secret-access-key aws-session-token")).ToNot(MatchRegexp("secret-value|aws-"))
+}
+
+func TestBuildAWSInputParameterStore(t *testing.T) {
+	g := NewWithT(t)
+
+	f := newFakeAWS(t)
+	r := newAWSTestReconciler(t, f)
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+
+	ctx := redact.NewContext(context.Background())
+	inputs, err := r.buildAWSInput(ctx, objClient, "team-a", "", &templatesv1alpha1.MatrixEntryAWS{
+		Region:         "eu-central-1",
+		RoleArn:        "arn:aws:iam::123456789012:role/reader",
+		Audience:       "aws",
+		ParameterStore: &templatesv1alpha1.AWSParameterStoreSource{Path: "/app", Recursive: true},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"name": "/app/db/host", "key": "db/host", "type": "String", "value": "db.example.com", "version": int64(1)},
+		map[string]any{"name": "/app/db/password", "key": "db/password", "type": "SecureString", "value": "db-secret-value", "version": int64(4)},
+	}))
+	g.Expect(requests).To(Equal([]tokenRequest{{namespace: "team-a", name: "default", audiences: []string{"aws"}}}))
+	g.Expect(f.requests["AmazonSSM.GetParametersByPath"][0]).To(Equal(map[string]any{"Path": "/app", "Recursive": true, "WithDecryption": true}))
+	g.Expect(f.hosts).To(ContainElement("ssm.eu-central-1.amazonaws.com"))
+
+	// only SecureString values are redacted
+	g.Expect(redact.String(ctx, "db.example.com db-secret-value")).To(HavePrefix("db.example.com "))
+	g.Expect(redact.String(ctx, "db.example.com db-secret-value")).ToNot(ContainSubstring("db-secret-value"))
+}
+
+func TestBuildAWSInputErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	f := newFakeAWS(t)
+	r := newAWSTestReconciler(t, f)
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+
+	_, err := r.buildAWSInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryAWS{
+		Region:  "eu-central-1",
+		RoleArn: "arn:aws:iam::123456789012:role/reader",
+	})
+	g.Expect(err).To(MatchError("exactly one of secretsManager and parameterStore must be specified"))
+	_, err = r.buildAWSInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryAWS{
+		Region:         "eu-central-1",
+		RoleArn:        "arn:aws:iam::123456789012:role/reader",
+		SecretsManager: &templatesv1alpha1.AWSSecretsManagerSource{Prefix: "app/"},
+		ParameterStore: &templatesv1alpha1.AWSParameterStoreSource{Path: "/app"},
+	})
+	g.Expect(err).To(MatchError("exactly one of secretsManager and parameterStore must be specified"))
+	g.Expect(requests).To(BeEmpty())
+
+	_, err = r.buildAWSInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryAWS{
+		Region:         "eu-central-1",
+		RoleArn:        "arn:aws:iam::123456789012:role/writer",
+		SecretsManager: &templatesv1alpha1.AWSSecretsManagerSource{Prefix: "app/"},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to assume role arn:aws:iam::123456789012:role/writer")))
+}
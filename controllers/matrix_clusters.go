@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+const (
+	argoCDSecretTypeLabel = "argocd.argoproj.io/secret-type"
+	clusterAPINameLabel   = "cluster.x-k8s.io/cluster-name"
+)
+
+// buildClustersInput discovers registered clusters from their secrets and returns one matrix input per cluster. Only
+// the name, server and metadata of the clusters are made available, credentials are never passed to the templates.
+func (r *BaseTemplateReconciler) buildClustersInput(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.MatrixEntryClusters) ([]any, error) {
+	namespace := objNamespace
+	if spec.Namespace != "" {
+		namespace = spec.Namespace
+	}
+	err := r.Policy.CheckRefNamespace(objNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Policy.CheckSecretNamespace(objNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.Everything()
+	if spec.Selector != nil {
+		selector, err = metav1.LabelSelectorAsSelector(spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clusterType := spec.Type
+	if clusterType == "" {
+		clusterType = templatesv1alpha1.ClusterSecretTypeArgoCD
+	}
+
+	var req *labels.Requirement
+	switch clusterType {
+	case templatesv1alpha1.ClusterSecretTypeArgoCD:
+		req, err = labels.NewRequirement(argoCDSecretTypeLabel, selection.Equals, []string{"cluster"})
+	case templatesv1alpha1.ClusterSecretTypeClusterAPI:
+		req, err = labels.NewRequirement(clusterAPINameLabel, selection.Exists, nil)
+	default:
+		return nil, fmt.Errorf("unsupported cluster secret type %s", clusterType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	selector = selector.Add(*req)
+
+	var secrets corev1.SecretList
+	err = objClient.List(ctx, &secrets, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []map[string]any
+	for _, s := range secrets.Items {
+		var name, server string
+		switch clusterType {
+		case templatesv1alpha1.ClusterSecretTypeArgoCD:
+			name, server = string(s.Data["name"]), string(s.Data["server"])
+		case templatesv1alpha1.ClusterSecretTypeClusterAPI:
+			name = s.Labels[clusterAPINameLabel]
+			// Cluster API writes other secrets (e.g. CA and etcd certificates) with the same label
+			if s.Name != name+"-kubeconfig" {
+				continue
+			}
+			server, err = getKubeconfigServer(s.Data["value"])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read kubeconfig from secret %s/%s: %w", s.Namespace, s.Name, err)
+			}
+		}
+		if name == "" {
+			name = s.Name
+		}
+
+		clusterLabels := map[string]any{}
+		for k, v := range s.Labels {
+			clusterLabels[k] = v
+		}
+		clusterAnnotations := map[string]any{}
+		for k, v := range s.Annotations {
+			clusterAnnotations[k] = v
+		}
+		clusters = append(clusters, map[string]any{
+			"name":        name,
+			"server":      server,
+			"namespace":   s.Namespace,
+			"secretName":  s.Name,
+			"labels":      clusterLabels,
+			"annotations": clusterAnnotations,
+		})
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i]["name"].(string) < clusters[j]["name"].(string)
+	})
+
+	ret := make([]any, 0, len(clusters))
+	for _, c := range clusters {
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+// getKubeconfigServer returns the API server url of the current context of the given kubeconfig
+func getKubeconfigServer(kubeconfig []byte) (string, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	kc, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return "", fmt.Errorf("current context %s not found", cfg.CurrentContext)
+	}
+	cluster, ok := cfg.Clusters[kc.Cluster]
+	if !ok {
+		return "", fmt.Errorf("cluster %s not found", kc.Cluster)
+	}
+	return cluster.Server, nil
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: c1
+  cluster:
+    server: https://c1.example.com:6443
+contexts:
+- name: c1
+  context:
+    cluster: c1
+    user: admin
+current-context: c1
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+func newClusterSecret(namespace string, name string, labels map[string]string, data map[string]string) *corev1.Secret {
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+func TestBuildClustersInputArgoCD(t *testing.T) {
+	g := NewWithT(t)
+
+	argoLabels := func(env string) map[string]string {
+		return map[string]string{argoCDSecretTypeLabel: "cluster", "env": env}
+	}
+	objClient := fake.NewClientBuilder().WithObjects(
+		newClusterSecret("argocd", "cluster-b", argoLabels("prod"), map[string]string{"name": "b", "server": "https://b.example.com", "config": `{"bearerToken":"secret"}`}),
+		newClusterSecret("argocd", "cluster-a", argoLabels("dev"), map[string]string{"server": "https://a.example.com"}),
+		newClusterSecret("argocd", "repo", map[string]string{argoCDSecretTypeLabel: "repository"}, map[string]string{"url": "https://git.example.com"}),
+		newClusterSecret("other", "cluster-c", argoLabels("prod"), map[string]string{"name": "c"}),
+	).Build()
+	r := &BaseTemplateReconciler{}
+
+	inputs, err := r.buildClustersInput(context.Background(), objClient, "argocd", &templatesv1alpha1.MatrixEntryClusters{})
+	g.Expect(err).ToNot(HaveOccurred())
+	// credentials are not passed to the templates and the secret name is used if the cluster has no name
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{
+			"name":        "b",
+			"server":      "https://b.example.com",
+			"namespace":   "argocd",
+			"secretName":  "cluster-b",
+			"labels":      map[string]any{argoCDSecretTypeLabel: "cluster", "env": "prod"},
+			"annotations": map[string]any{},
+		},
+		map[string]any{
+			"name":        "cluster-a",
+			"server":      "https://a.example.com",
+			"namespace":   "argocd",
+			"secretName":  "cluster-a",
+			"labels":      map[string]any{argoCDSecretTypeLabel: "cluster", "env": "dev"},
+			"annotations": map[string]any{},
+		},
+	}))
+
+	inputs, err = r.buildClustersInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryClusters{
+		Namespace: "argocd",
+		Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(1))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("name", "b"))
+}
+
+func TestBuildClustersInputClusterAPI(t *testing.T) {
+	g := NewWithT(t)
+
+	capiLabels := map[string]string{clusterAPINameLabel: "c1"}
+	objClient := fake.NewClientBuilder().WithObjects(
+		newClusterSecret("capi", "c1-kubeconfig", capiLabels, map[string]string{"value": testKubeconfig}),
+		newClusterSecret("capi", "c1-ca", capiLabels, map[string]string{"tls.crt": "ca"}),
+		newClusterSecret("capi", "c2-kubeconfig", map[string]string{clusterAPINameLabel: "c2"}, map[string]string{"value": "invalid"}),
+	).Build()
+	r := &BaseTemplateReconciler{}
+
+	inputs, err := r.buildClustersInput(context.Background(), objClient, "capi", &templatesv1alpha1.MatrixEntryClusters{
+		Type:     templatesv1alpha1.ClusterSecretTypeClusterAPI,
+		Selector: &metav1.LabelSelector{MatchLabels: capiLabels},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(1))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("name", "c1"))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("server", "https://c1.example.com:6443"))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("secretName", "c1-kubeconfig"))
+
+	_, err = r.buildClustersInput(context.Background(), objClient, "capi", &templatesv1alpha1.MatrixEntryClusters{
+		Type: templatesv1alpha1.ClusterSecretTypeClusterAPI,
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to read kubeconfig from secret capi/c2-kubeconfig")))
+}
+
+func TestBuildClustersInputErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	objClient := fake.NewClientBuilder().Build()
+	r := &BaseTemplateReconciler{}
+
+	_, err := r.buildClustersInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryClusters{Type: "Rancher"})
+	g.Expect(err).To(MatchError("unsupported cluster secret type Rancher"))
+
+	p, err := policy.New(policy.Policy{SecretNamespaces: []string{"argocd"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	r.Policy = p
+	_, err = r.buildClustersInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryClusters{Namespace: "argocd"})
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = r.buildClustersInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryClusters{Namespace: "kube-system"})
+	g.Expect(err).To(MatchError("reading secrets from namespace kube-system is not allowed by policy"))
+}
+
+func TestGetKubeconfigServer(t *testing.T) {
+	g := NewWithT(t)
+
+	server, err := getKubeconfigServer([]byte(testKubeconfig))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(server).To(Equal("https://c1.example.com:6443"))
+
+	_, err = getKubeconfigServer([]byte("apiVersion: v1\nkind: Config\ncurrent-context: missing\n"))
+	g.Expect(err).To(MatchError("current context missing not found"))
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// buildDataInput reads the data of the ConfigMap or Secret specified by spec and returns the matrix inputs for it.
+// kind must either be "ConfigMap" or "Secret".
+func (r *BaseTemplateReconciler) buildDataInput(ctx context.Context, objClient client.Client, objNamespace string, kind string, spec *templatesv1alpha1.MatrixEntryData) ([]any, error) {
+	namespace := objNamespace
+	if spec.Namespace != "" {
+		namespace = spec.Namespace
+	}
+	err := r.Policy.CheckRefNamespace(objNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	key := types.NamespacedName{Namespace: namespace, Name: spec.Name}
+	data := map[string]string{}
+	switch kind {
+	case "ConfigMap":
+		var cm corev1.ConfigMap
+		err = objClient.Get(ctx, key, &cm)
+		if err != nil {
+			return nil, err
+		}
+		data = cm.Data
+	case "Secret":
+		err = r.Policy.CheckSecretNamespace(objNamespace, namespace)
+		if err != nil {
+			return nil, err
+		}
+		var secret corev1.Secret
+		err = objClient.Get(ctx, key, &secret)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range secret.Data {
+			redact.RegisterBytes(ctx, v)
+			data[k] = string(v)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", kind)
+	}
+
+	if spec.Key != "" {
+		v, ok := data[spec.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in %s %s", spec.Key, kind, key.String())
+		}
+		var parsed any
+		err = yaml.Unmarshal([]byte(v), &parsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s of %s %s: %w", spec.Key, kind, key.String(), err)
+		}
+		if l, ok := parsed.([]any); ok {
+			return l, nil
+		}
+		return []any{parsed}, nil
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ret := make([]any, 0, len(keys))
+	for _, k := range keys {
+		ret = append(ret, map[string]any{
+			"key":   k,
+			"value": data[k],
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBuildDataInput(t *testing.T) {
+	g := NewWithT(t)
+
+	objClient := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"},
+			Data: map[string]string{
+				"b":     "2",
+				"a":     "1",
+				"list":  "- x: 1\n- x: 2\n",
+				"map":   "x: 1",
+				"plain": "value",
+				"bad":   "a: [",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "secret"},
+			Data:       map[string][]byte{"password": []byte("s3cr3t-value")},
+		},
+	).Build()
+	r := &BaseTemplateReconciler{}
+
+	inputs, err := r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(6))
+	g.Expect(inputs[0]).To(Equal(map[string]any{"key": "a", "value": "1"}))
+	g.Expect(inputs[1]).To(Equal(map[string]any{"key": "b", "value": "2"}))
+
+	// lists are expanded, other values result in a single input
+	inputs, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Key: "list"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{map[string]any{"x": int64(1)}, map[string]any{"x": int64(2)}}))
+	inputs, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Key: "map"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{map[string]any{"x": int64(1)}}))
+	inputs, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Key: "plain"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{"value"}))
+
+	_, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Key: "missing"})
+	g.Expect(err).To(MatchError("key missing not found in ConfigMap default/cm"))
+	_, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Key: "bad"})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to parse key bad of ConfigMap default/cm")))
+	_, err = r.buildDataInput(context.Background(), objClient, "default", "Pod", &templatesv1alpha1.MatrixEntryData{Name: "cm"})
+	g.Expect(err).To(MatchError("unsupported kind Pod"))
+
+	// secret values are redacted from messages of the current reconciliation
+	ctx := redact.NewContext(context.Background())
+	inputs, err = r.buildDataInput(ctx, objClient, "default", "Secret", &templatesv1alpha1.MatrixEntryData{Name: "secret", Namespace: "other"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{map[string]any{"key": "password", "value": "s3cr3t-value"}}))
+	g.Expect(redact.String(ctx, "failed: s3cr3t-value")).ToNot(ContainSubstring("s3cr3t-value"))
+}
+
+func TestBuildDataInputPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	objClient := fake.NewClientBuilder().Build()
+	p, err := policy.New(policy.Policy{SecretNamespaces: []string{"shared"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	r := &BaseTemplateReconciler{Policy: p}
+
+	// ConfigMaps are not restricted by the secret namespaces
+	_, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Namespace: "other"})
+	g.Expect(err).To(MatchError(ContainSubstring("not found")))
+	_, err = r.buildDataInput(context.Background(), objClient, "default", "Secret", &templatesv1alpha1.MatrixEntryData{Name: "secret", Namespace: "other"})
+	g.Expect(err).To(MatchError("reading secrets from namespace other is not allowed by policy"))
+
+	p, err = policy.New(policy.Policy{NoCrossNamespaceRefs: true})
+	g.Expect(err).ToNot(HaveOccurred())
+	r.Policy = p
+	_, err = r.buildDataInput(context.Background(), objClient, "default", "ConfigMap", &templatesv1alpha1.MatrixEntryData{Name: "cm", Namespace: "other"})
+	g.Expect(err).To(MatchError("referencing objects in namespace other is not allowed as cross-namespace references are disabled"))
+}
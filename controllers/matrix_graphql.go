@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/ohler55/ojg/jp"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+type graphQLRequest struct {
+	Query     string `json:"query"`
+	Variables any    `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   any `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// buildGraphQLInput executes the GraphQL query specified by spec and returns one matrix input per element of the list
+// extracted from the response data.
+func (r *BaseTemplateReconciler) buildGraphQLInput(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.MatrixEntryGraphQL) ([]any, error) {
+	path, err := jp.ParseString(spec.JsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	gr := graphQLRequest{
+		Query: spec.Query,
+	}
+	if spec.Variables != nil && len(spec.Variables.Raw) != 0 {
+		err = json.Unmarshal(spec.Variables.Raw, &gr.Variables)
+		if err != nil {
+			return nil, err
+		}
+	}
+	body, err := json.Marshal(gr)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+	for k, v := range spec.Headers {
+		headers[k] = v
+	}
+	err = getSecretHeaders(ctx, objClient, objNamespace, r.Policy, spec.SecretHeaders, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultRequestTimeout
+	if spec.Timeout != nil {
+		timeout = spec.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var resp graphQLResponse
+	err = doJsonRequest(ctx, r.Policy.HTTPClient(), http.MethodPost, spec.URL, body, headers, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) != 0 {
+		var msgs []string
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return nil, fmt.Errorf("GraphQL query failed: %s", strings.Join(msgs, ", "))
+	}
+
+	return expandJsonPathResults(resp.Data, path), nil
+}
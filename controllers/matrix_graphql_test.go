@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBuildGraphQLInput(t *testing.T) {
+	g := NewWithT(t)
+
+	var gotRequest map[string]any
+	var gotAuth string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotRequest = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotRequest)
+		if gotRequest["query"] == "invalid" {
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"syntax error"},{"message":"unknown field"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"repository":{"branches":{"nodes":[{"name":"main"},{"name":"dev"}]}}}}`))
+	}))
+	defer s.Close()
+
+	objClient := fake.NewClientBuilder().WithObjects(newTokenSecret("default", "api", "token", "Bearer api-token")).Build()
+	r := &BaseTemplateReconciler{}
+
+	query := `query($owner: String!) { repository(owner: $owner) { branches { nodes { name } } } }`
+	inputs, err := r.buildGraphQLInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryGraphQL{
+		URL:       s.URL,
+		Query:     query,
+		Variables: &runtime.RawExtension{Raw: []byte(`{"owner":"kluctl"}`)},
+		JsonPath:  "$.repository.branches.nodes",
+		SecretHeaders: []templatesv1alpha1.HTTPSecretHeader{
+			{Name: "Authorization", ValueRef: templatesv1alpha1.SecretRef{SecretName: "api", Key: "token"}},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{map[string]any{"name": "main"}, map[string]any{"name": "dev"}}))
+	g.Expect(gotRequest).To(Equal(map[string]any{"query": query, "variables": map[string]any{"owner": "kluctl"}}))
+	g.Expect(gotAuth).To(Equal("Bearer api-token"))
+
+	// variables are omitted if not specified
+	_, err = r.buildGraphQLInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryGraphQL{
+		URL:      s.URL,
+		Query:    "{ viewer { login } }",
+		JsonPath: "$.viewer",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(gotRequest).To(Equal(map[string]any{"query": "{ viewer { login } }"}))
+
+	_, err = r.buildGraphQLInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryGraphQL{
+		URL:      s.URL,
+		Query:    "invalid",
+		JsonPath: "$",
+	})
+	g.Expect(err).To(MatchError("GraphQL query failed: syntax error, unknown field"))
+
+	_, err = r.buildGraphQLInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryGraphQL{
+		URL:      s.URL,
+		Query:    query,
+		JsonPath: "$.[",
+	})
+	g.Expect(err).To(HaveOccurred())
+}
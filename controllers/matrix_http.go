@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/ohler55/ojg/jp"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildHTTPInput queries the endpoint specified by spec and returns one matrix input per element of the returned (or
+// extracted) list.
+func (r *BaseTemplateReconciler) buildHTTPInput(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.MatrixEntryHTTP) ([]any, error) {
+	var path jp.Expr
+	var err error
+	if spec.JsonPath != nil {
+		path, err = jp.ParseString(*spec.JsonPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	headers := map[string]string{}
+	for k, v := range spec.Headers {
+		headers[k] = v
+	}
+	err = getSecretHeaders(ctx, objClient, objNamespace, r.Policy, spec.SecretHeaders, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body []byte
+	if spec.Body != "" {
+		body = []byte(spec.Body)
+	}
+
+	timeout := defaultRequestTimeout
+	if spec.Timeout != nil {
+		timeout = spec.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var resp any
+	err = doJsonRequest(ctx, r.Policy.HTTPClient(), method, spec.URL, body, headers, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return expandJsonPathResults(resp, path), nil
+}
+
+// expandJsonPathResults applies path to v (if specified) and returns all results, with lists expanded into their
+// elements.
+func expandJsonPathResults(v any, path jp.Expr) []any {
+	results := []any{v}
+	if path != nil {
+		results = path.Get(v)
+	}
+	var ret []any
+	for _, x := range results {
+		if l, ok := x.([]any); ok {
+			ret = append(ret, l...)
+		} else {
+			ret = append(ret, x)
+		}
+	}
+	return ret
+}
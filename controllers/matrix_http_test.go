@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBuildHTTPInput(t *testing.T) {
+	g := NewWithT(t)
+
+	var gotMethod, gotBody string
+	var gotHeader http.Header
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Clone()
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		_, _ = w.Write([]byte(`{"items":[{"name":"a"},{"name":"b"}],"count":2}`))
+	}))
+	defer s.Close()
+
+	objClient := fake.NewClientBuilder().WithObjects(newTokenSecret("default", "api", "token", "api-token")).Build()
+	r := &BaseTemplateReconciler{}
+	itemsPath := "$.items"
+	namesPath := "$.items[*].name"
+
+	inputs, err := r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{
+		URL:      s.URL,
+		JsonPath: &itemsPath,
+		Headers:  map[string]string{"X-Env": "prod"},
+		SecretHeaders: []templatesv1alpha1.HTTPSecretHeader{
+			{Name: "Authorization", ValueRef: templatesv1alpha1.SecretRef{SecretName: "api", Key: "token"}},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	// lists are expanded into their elements
+	g.Expect(inputs).To(Equal([]any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}))
+	g.Expect(gotMethod).To(Equal(http.MethodGet))
+	g.Expect(gotBody).To(BeEmpty())
+	g.Expect(gotHeader.Get("X-Env")).To(Equal("prod"))
+	g.Expect(gotHeader.Get("Authorization")).To(Equal("api-token"))
+
+	inputs, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{
+		URL:      s.URL,
+		Method:   http.MethodPost,
+		Body:     `{"query":"x"}`,
+		JsonPath: &namesPath,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{"a", "b"}))
+	g.Expect(gotMethod).To(Equal(http.MethodPost))
+	g.Expect(gotBody).To(Equal(`{"query":"x"}`))
+	g.Expect(gotHeader.Get("Content-Type")).To(Equal("application/json"))
+
+	// without a json path, the whole response is a single input
+	inputs, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{URL: s.URL})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(1))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("count", float64(2)))
+}
+
+func TestBuildHTTPInputErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invalid":
+			_, _ = w.Write([]byte(`not json`))
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	objClient := fake.NewClientBuilder().Build()
+	r := &BaseTemplateReconciler{}
+	invalidPath := "$.["
+
+	_, err := r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{URL: s.URL + "/missing"})
+	g.Expect(err).To(MatchError(ContainSubstring("returned unexpected status code 404")))
+	_, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{URL: s.URL + "/invalid"})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to decode response of GET")))
+	_, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{URL: s.URL, JsonPath: &invalidPath})
+	g.Expect(err).To(HaveOccurred())
+	_, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{
+		URL:     s.URL + "/slow",
+		Timeout: &metav1.Duration{Duration: 50 * time.Millisecond},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
+	_, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{
+		URL: s.URL,
+		SecretHeaders: []templatesv1alpha1.HTTPSecretHeader{
+			{Name: "Authorization", ValueRef: templatesv1alpha1.SecretRef{SecretName: "missing", Key: "token"}},
+		},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("not found")))
+
+	u, err := url.Parse(s.URL)
+	g.Expect(err).ToNot(HaveOccurred())
+	p, err := policy.New(policy.Policy{AllowedHosts: []string{"api.example.com"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	r.Policy = p
+	_, err = r.buildHTTPInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryHTTP{URL: s.URL})
+	g.Expect(err).To(MatchError(ContainSubstring("requests to host " + u.Hostname() + " are not allowed by policy")))
+}
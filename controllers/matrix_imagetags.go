@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// buildImageTagsInput lists the tags of the image repository specified by spec and returns one matrix input per
+// matching tag.
+func (r *BaseTemplateReconciler) buildImageTagsInput(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.MatrixEntryImageTags) ([]any, error) {
+	repo, err := name.NewRepository(spec.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image repository %s: %w", spec.Repository, err)
+	}
+	err = r.Policy.CheckRegistry(repo.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+
+	var re *regexp.Regexp
+	if spec.Regex != nil {
+		re, err = regexp.Compile(fmt.Sprintf("^%s$", *spec.Regex))
+		if err != nil {
+			return nil, err
+		}
+	}
+	var constraint *semver.Constraints
+	if spec.SemVer != nil {
+		constraint, err = semver.NewConstraint(*spec.SemVer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver constraint %s: %w", *spec.SemVer, err)
+		}
+	}
+
+	auth, err := buildOCIAuth(ctx, objClient, objNamespace, spec.SecretRef, repo.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+	tags, err := remote.List(repo, remote.WithContext(ctx), remote.WithAuth(auth), remote.WithTransport(r.Policy.HTTPClient().Transport))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", repo.String(), err)
+	}
+	sort.Strings(tags)
+
+	versions := map[string]*semver.Version{}
+	var matchingTags []string
+	for _, tag := range tags {
+		if re != nil && !re.MatchString(tag) {
+			continue
+		}
+		if constraint != nil {
+			v, err := semver.NewVersion(tag)
+			if err != nil || !constraint.Check(v) {
+				continue
+			}
+			versions[tag] = v
+		}
+		matchingTags = append(matchingTags, tag)
+	}
+	if constraint != nil {
+		// stable sort, so that equal versions (e.g. v1.0 and 1.0.0) stay sorted by name
+		sort.SliceStable(matchingTags, func(i, j int) bool {
+			return versions[matchingTags[i]].GreaterThan(versions[matchingTags[j]])
+		})
+	}
+	if spec.LatestN != nil && len(matchingTags) > *spec.LatestN {
+		matchingTags = matchingTags[:*spec.LatestN]
+	}
+
+	ret := make([]any, 0, len(matchingTags))
+	for _, tag := range matchingTags {
+		e := map[string]any{
+			"tag":   tag,
+			"image": repo.String() + ":" + tag,
+		}
+		if v, ok := versions[tag]; ok {
+			e["version"] = v.String()
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// pushTestTags pushes a test artifact with the given tags (in addition to v1) to a new in-memory registry
+func pushTestTags(t *testing.T, tags ...string) name.Repository {
+	repo, _ := pushTestArtifact(t)
+	img, err := random.Image(128, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		err = remote.Write(repo.Tag(tag), img)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func imageTagsOf(inputs []any) []string {
+	var ret []string
+	for _, x := range inputs {
+		ret = append(ret, x.(map[string]any)["tag"].(string))
+	}
+	return ret
+}
+
+func TestBuildImageTagsInput(t *testing.T) {
+	g := NewWithT(t)
+
+	repo := pushTestTags(t, "1.0.0", "1.2.0", "v1.10.0", "2.0.0-rc.1", "2.0.0", "latest", "main-abc123", "main-def456")
+	r := &BaseTemplateReconciler{}
+	objClient := fake.NewClientBuilder().Build()
+
+	build := func(spec templatesv1alpha1.MatrixEntryImageTags) []any {
+		spec.Repository = repo.String()
+		inputs, err := r.buildImageTagsInput(context.Background(), objClient, "default", &spec)
+		g.ExpectWithOffset(1, err).ToNot(HaveOccurred())
+		return inputs
+	}
+	regex := "main-.*"
+	partialRegex := "main"
+	semver := "^1.0"
+	prerelease := ">=2.0.0-0"
+	latestN := 2
+
+	inputs := build(templatesv1alpha1.MatrixEntryImageTags{})
+	g.Expect(imageTagsOf(inputs)).To(Equal([]string{"1.0.0", "1.2.0", "2.0.0", "2.0.0-rc.1", "latest", "main-abc123", "main-def456", "v1", "v1.10.0"}))
+	g.Expect(inputs[0]).To(Equal(map[string]any{"tag": "1.0.0", "image": repo.String() + ":1.0.0"}))
+
+	g.Expect(imageTagsOf(build(templatesv1alpha1.MatrixEntryImageTags{Regex: &regex}))).To(Equal([]string{"main-abc123", "main-def456"}))
+	// the regex must match the whole tag
+	g.Expect(build(templatesv1alpha1.MatrixEntryImageTags{Regex: &partialRegex})).To(BeEmpty())
+
+	// semver matches are sorted by version, highest first
+	inputs = build(templatesv1alpha1.MatrixEntryImageTags{SemVer: &semver})
+	g.Expect(imageTagsOf(inputs)).To(Equal([]string{"v1.10.0", "1.2.0", "1.0.0", "v1"}))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("version", "1.10.0"))
+	g.Expect(imageTagsOf(build(templatesv1alpha1.MatrixEntryImageTags{SemVer: &prerelease}))).To(Equal([]string{"2.0.0", "2.0.0-rc.1"}))
+	g.Expect(imageTagsOf(build(templatesv1alpha1.MatrixEntryImageTags{SemVer: &semver, LatestN: &latestN}))).To(Equal([]string{"v1.10.0", "1.2.0"}))
+}
+
+func TestBuildImageTagsInputErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	repo := pushTestTags(t)
+	r := &BaseTemplateReconciler{}
+	objClient := fake.NewClientBuilder().Build()
+	invalidRegex := "("
+	invalidSemVer := "not a version"
+
+	_, err := r.buildImageTagsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryImageTags{Repository: "INVALID"})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid image repository INVALID")))
+	_, err = r.buildImageTagsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryImageTags{Repository: repo.String(), Regex: &invalidRegex})
+	g.Expect(err).To(HaveOccurred())
+	_, err = r.buildImageTagsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryImageTags{Repository: repo.String(), SemVer: &invalidSemVer})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid semver constraint not a version")))
+	_, err = r.buildImageTagsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryImageTags{Repository: repo.RegistryStr() + "/missing"})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to list tags of " + repo.RegistryStr() + "/missing")))
+}
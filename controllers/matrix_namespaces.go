@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// buildNamespacesInput lists the namespaces matching spec and returns one matrix input per namespace. Namespaces that
+// are being deleted are skipped, as no objects can be created inside them.
+func (r *BaseTemplateReconciler) buildNamespacesInput(ctx context.Context, objClient client.Client, spec *templatesv1alpha1.MatrixEntryNamespaces) ([]any, error) {
+	var opts []client.ListOption
+	if spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	var l corev1.NamespaceList
+	err := objClient.List(ctx, &l, opts...)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(l.Items, func(i, j int) bool {
+		return l.Items[i].Name < l.Items[j].Name
+	})
+
+	var ret []any
+	for _, ns := range l.Items {
+		if !ns.DeletionTimestamp.IsZero() {
+			continue
+		}
+		ret = append(ret, map[string]any{
+			"name":        ns.Name,
+			"labels":      stringMapToAny(ns.Labels),
+			"annotations": stringMapToAny(ns.Annotations),
+		})
+	}
+	return ret, nil
+}
+
+// stringMapToAny converts m into a map that can be passed to the templates
+func stringMapToAny(m map[string]string) map[string]any {
+	ret := make(map[string]any, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBuildNamespacesInput(t *testing.T) {
+	g := NewWithT(t)
+
+	now := metav1.Now()
+	objClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-a",
+			Labels:      map[string]string{"tenant": "true"},
+			Annotations: map[string]string{"owner": "a@example.com"},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:              "team-c",
+			Labels:            map[string]string{"tenant": "true"},
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"kubernetes"},
+		}},
+	).Build()
+	r := &BaseTemplateReconciler{}
+
+	// namespaces being deleted are skipped
+	inputs, err := r.buildNamespacesInput(context.Background(), objClient, &templatesv1alpha1.MatrixEntryNamespaces{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{
+			"name":        "team-a",
+			"labels":      map[string]any{"tenant": "true"},
+			"annotations": map[string]any{"owner": "a@example.com"},
+		},
+		map[string]any{
+			"name":        "team-b",
+			"labels":      map[string]any{"tenant": "true"},
+			"annotations": map[string]any{},
+		},
+	}))
+
+	inputs, err = r.buildNamespacesInput(context.Background(), objClient, &templatesv1alpha1.MatrixEntryNamespaces{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(3))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("name", "kube-system"))
+
+	_, err = r.buildNamespacesInput(context.Background(), objClient, &templatesv1alpha1.MatrixEntryNamespaces{
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "Invalid"}}},
+	})
+	g.Expect(err).To(HaveOccurred())
+}
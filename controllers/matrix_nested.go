@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// parseNestedMatrixEntries parses the schemaless nested entries of matrix and merge entries
+func parseNestedMatrixEntries(raw []runtime.RawExtension) ([]*templatesv1alpha1.MatrixEntry, error) {
+	ret := make([]*templatesv1alpha1.MatrixEntry, 0, len(raw))
+	for i, x := range raw {
+		var me templatesv1alpha1.MatrixEntry
+		d := json.NewDecoder(bytes.NewReader(x.Raw))
+		d.DisallowUnknownFields()
+		err := d.Decode(&me)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nested matrix entry at index %d: %w", i, err)
+		}
+		ret = append(ret, &me)
+	}
+	return ret, nil
+}
+
+// nestedMatrixEntries returns the nested entries of the given matrix entry. Invalid entries are ignored here, as these
+// are reported while building the matrix.
+func nestedMatrixEntries(me *templatesv1alpha1.MatrixEntry) []*templatesv1alpha1.MatrixEntry {
+	var raw []runtime.RawExtension
+	if me.Matrix != nil {
+		raw = me.Matrix
+	} else if me.Merge != nil {
+		raw = me.Merge.Entries
+	}
+	var ret []*templatesv1alpha1.MatrixEntry
+	for _, x := range raw {
+		nme, err := parseNestedMatrixEntries([]runtime.RawExtension{x})
+		if err != nil {
+			continue
+		}
+		ret = append(ret, nme...)
+	}
+	return ret
+}
+
+// buildNestedMatrixInput returns one matrix input per combination of the inputs of the given nested entries
+func (r *ObjectTemplateReconciler) buildNestedMatrixInput(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate, client client.Client, raw []runtime.RawExtension) ([]any, error) {
+	entries, err := parseNestedMatrixEntries(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, me := range entries {
+		if me.Name == "" {
+			return nil, fmt.Errorf("nested matrix entries must have a name")
+		}
+	}
+	matrix, err := r.buildMatrix(ctx, rt, client, entries)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]any, 0, len(matrix))
+	for _, m := range matrix {
+		ret = append(ret, m)
+	}
+	return ret, nil
+}
+
+// buildMergeInput returns the inputs of the first nested entry, with the inputs of all following entries merged into
+// them when the values of all merge keys are equal.
+func (r *ObjectTemplateReconciler) buildMergeInput(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate, client client.Client, spec *templatesv1alpha1.MatrixEntryMerge) ([]any, error) {
+	entries, err := parseNestedMatrixEntries(spec.Entries)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	var base []map[string]any
+	baseByKey := map[string][]map[string]any{}
+	for i, me := range entries {
+		elems, err := r.buildMatrixEntryInput(ctx, rt, client, me)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range elems {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("merge requires all inputs to be objects, got %T", e)
+			}
+			key, ok, err := buildMergeKey(m, spec.MergeKeys)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				m = copyMergeValue(m).(map[string]any)
+				base = append(base, m)
+				if ok {
+					baseByKey[key] = append(baseByKey[key], m)
+				}
+				continue
+			}
+			if !ok {
+				continue
+			}
+			for _, b := range baseByKey[key] {
+				MergeMap(b, copyMergeValue(m).(map[string]any))
+			}
+		}
+	}
+
+	ret := make([]any, 0, len(base))
+	for _, b := range base {
+		ret = append(ret, b)
+	}
+	return ret, nil
+}
+
+// buildMergeKey returns the JSON encoded values of all merge keys. It returns false if any of the keys is missing.
+func buildMergeKey(m map[string]any, mergeKeys []string) (string, bool, error) {
+	values := make([]any, 0, len(mergeKeys))
+	for _, k := range mergeKeys {
+		var v any = m
+		for _, p := range strings.Split(k, ".") {
+			vm, ok := v.(map[string]any)
+			if !ok {
+				return "", false, nil
+			}
+			v, ok = vm[p]
+			if !ok {
+				return "", false, nil
+			}
+		}
+		values = append(values, v)
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
+
+// copyMergeValue deep copies nested maps and lists, so that merging never modifies inputs shared between items
+func copyMergeValue(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		ret := make(map[string]any, len(x))
+		for k, v2 := range x {
+			ret[k] = copyMergeValue(v2)
+		}
+		return ret
+	case []any:
+		ret := make([]any, len(x))
+		for i, v2 := range x {
+			ret[i] = copyMergeValue(v2)
+		}
+		return ret
+	default:
+		return v
+	}
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func rawEntries(entries ...string) []runtime.RawExtension {
+	ret := make([]runtime.RawExtension, 0, len(entries))
+	for _, e := range entries {
+		ret = append(ret, runtime.RawExtension{Raw: []byte(e)})
+	}
+	return ret
+}
+
+func newNestedTestTemplate() *templatesv1alpha1.ObjectTemplate {
+	return &templatesv1alpha1.ObjectTemplate{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "t1"}}
+}
+
+func TestBuildNestedMatrixInput(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &ObjectTemplateReconciler{}
+	objClient := fake.NewClientBuilder().Build()
+
+	inputs, err := r.buildNestedMatrixInput(context.Background(), newNestedTestTemplate(), objClient, rawEntries(
+		`{"name":"env","list":["dev","prod"]}`,
+		`{"name":"region","list":[{"name":"eu"},{"name":"us"}]}`,
+	))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"env": "dev", "region": map[string]any{"name": "eu"}},
+		map[string]any{"env": "dev", "region": map[string]any{"name": "us"}},
+		map[string]any{"env": "prod", "region": map[string]any{"name": "eu"}},
+		map[string]any{"env": "prod", "region": map[string]any{"name": "us"}},
+	}))
+
+	_, err = r.buildNestedMatrixInput(context.Background(), newNestedTestTemplate(), objClient, rawEntries(`{"list":["dev"]}`))
+	g.Expect(err).To(MatchError("nested matrix entries must have a name"))
+	_, err = r.buildNestedMatrixInput(context.Background(), newNestedTestTemplate(), objClient, rawEntries(
+		`{"name":"env","list":["dev"]}`,
+		`{"name":"region","lists":["eu"]}`,
+	))
+	g.Expect(err).To(MatchError(ContainSubstring("invalid nested matrix entry at index 1")))
+}
+
+func TestBuildMergeInput(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &ObjectTemplateReconciler{}
+	objClient := fake.NewClientBuilder().Build()
+
+	inputs, err := r.buildMergeInput(context.Background(), newNestedTestTemplate(), objClient, &templatesv1alpha1.MatrixEntryMerge{
+		MergeKeys: []string{"cluster.name"},
+		Entries: rawEntries(
+			`{"list":[{"cluster":{"name":"a"},"replicas":1},{"cluster":{"name":"b"},"replicas":1},{"other":true}]}`,
+			`{"list":[{"cluster":{"name":"b","region":"eu"},"replicas":3},{"cluster":{"name":"c"},"replicas":5},{"replicas":7}]}`,
+		),
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	// inputs of the first entry are kept, with matching inputs of following entries merged into them
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"cluster": map[string]any{"name": "a"}, "replicas": int64(1)},
+		map[string]any{"cluster": map[string]any{"name": "b", "region": "eu"}, "replicas": int64(3)},
+		map[string]any{"other": true},
+	}))
+
+	inputs, err = r.buildMergeInput(context.Background(), newNestedTestTemplate(), objClient, &templatesv1alpha1.MatrixEntryMerge{
+		MergeKeys: []string{"cluster.name"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(BeEmpty())
+
+	_, err = r.buildMergeInput(context.Background(), newNestedTestTemplate(), objClient, &templatesv1alpha1.MatrixEntryMerge{
+		MergeKeys: []string{"name"},
+		Entries:   rawEntries(`{"list":["a"]}`),
+	})
+	g.Expect(err).To(MatchError("merge requires all inputs to be objects, got string"))
+}
+
+func TestBuildMergeKey(t *testing.T) {
+	g := NewWithT(t)
+
+	m := map[string]any{"a": map[string]any{"b": "x"}, "c": float64(1)}
+	key, ok, err := buildMergeKey(m, []string{"a.b", "c"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+	g.Expect(key).To(Equal(`["x",1]`))
+
+	_, ok, err = buildMergeKey(m, []string{"a.missing"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+	_, ok, err = buildMergeKey(m, []string{"c.d"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestMatrixScheduleRequeueAfterNested(t *testing.T) {
+	g := NewWithT(t)
+
+	matrix := []*templatesv1alpha1.MatrixEntry{
+		{Name: "nested", Matrix: rawEntries(
+			`{"name":"window","schedule":{"windows":[{"start":"09:00","end":"12:00"}]}}`,
+			`{"name":"invalid","unknown":true}`,
+		)},
+		{Name: "merged", Merge: &templatesv1alpha1.MatrixEntryMerge{Entries: rawEntries(
+			`{"schedule":{"windows":[{"start":"08:45","end":"12:00"}]}}`,
+		)}},
+	}
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix[:1], mondayAt(8, 30))).To(Equal(30 * time.Minute))
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix, mondayAt(8, 30))).To(Equal(15 * time.Minute))
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// buildNodesInput lists the nodes matching spec and returns one matrix input per node.
+func (r *BaseTemplateReconciler) buildNodesInput(ctx context.Context, objClient client.Client, spec *templatesv1alpha1.MatrixEntryNodes) ([]any, error) {
+	var opts []client.ListOption
+	if spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	var l corev1.NodeList
+	err := objClient.List(ctx, &l, opts...)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(l.Items, func(i, j int) bool {
+		return l.Items[i].Name < l.Items[j].Name
+	})
+
+	ret := make([]any, 0, len(l.Items))
+	for _, n := range l.Items {
+		taints := make([]any, 0, len(n.Spec.Taints))
+		for _, t := range n.Spec.Taints {
+			taints = append(taints, map[string]any{
+				"key":    t.Key,
+				"value":  t.Value,
+				"effect": string(t.Effect),
+			})
+		}
+		ret = append(ret, map[string]any{
+			"name":          n.Name,
+			"labels":        stringMapToAny(n.Labels),
+			"annotations":   stringMapToAny(n.Annotations),
+			"taints":        taints,
+			"unschedulable": n.Spec.Unschedulable,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBuildNodesInput(t *testing.T) {
+	g := NewWithT(t)
+
+	objClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "worker-1",
+				Labels:      map[string]string{"node-role.kubernetes.io/worker": ""},
+				Annotations: map[string]string{"zone": "a"},
+			},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "control-plane", Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""}}},
+	).Build()
+	r := &BaseTemplateReconciler{}
+
+	inputs, err := r.buildNodesInput(context.Background(), objClient, &templatesv1alpha1.MatrixEntryNodes{
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "node-role.kubernetes.io/worker", Operator: metav1.LabelSelectorOpExists},
+		}},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{
+			"name":          "worker-1",
+			"labels":        map[string]any{"node-role.kubernetes.io/worker": ""},
+			"annotations":   map[string]any{"zone": "a"},
+			"taints":        []any{map[string]any{"key": "gpu", "value": "true", "effect": "NoSchedule"}},
+			"unschedulable": false,
+		},
+		map[string]any{
+			"name":          "worker-2",
+			"labels":        map[string]any{"node-role.kubernetes.io/worker": ""},
+			"annotations":   map[string]any{},
+			"taints":        []any{},
+			"unschedulable": true,
+		},
+	}))
+
+	inputs, err = r.buildNodesInput(context.Background(), objClient, &templatesv1alpha1.MatrixEntryNodes{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(3))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("name", "control-plane"))
+}
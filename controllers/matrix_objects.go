@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/ohler55/ojg/jp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// buildObjectsInput lists the objects specified by spec and returns one matrix input per object, or per value matched
+// by the json path if one is specified.
+func (r *BaseTemplateReconciler) buildObjectsInput(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.MatrixEntryObjects) ([]any, error) {
+	gv, err := schema.ParseGroupVersion(spec.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvk := gv.WithKind(spec.Kind)
+
+	var dummy unstructured.Unstructured
+	dummy.SetGroupVersionKind(gvk)
+	namespaced, err := objClient.IsObjectNamespaced(&dummy)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []client.ListOption
+	if namespaced {
+		namespace := objNamespace
+		if spec.Namespace != "" {
+			namespace = spec.Namespace
+		}
+		err = r.Policy.CheckRefNamespace(objNamespace, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if gvk.Group == "" && gvk.Kind == "Secret" {
+			err = r.Policy.CheckSecretNamespace(objNamespace, namespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+	if spec.FieldSelector != "" {
+		selector, err := fields.ParseSelector(spec.FieldSelector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingFieldsSelector{Selector: selector})
+	}
+
+	var l unstructured.UnstructuredList
+	l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	err = objClient.List(ctx, &l, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(l.Items, func(i, j int) bool {
+		if l.Items[i].GetNamespace() != l.Items[j].GetNamespace() {
+			return l.Items[i].GetNamespace() < l.Items[j].GetNamespace()
+		}
+		return l.Items[i].GetName() < l.Items[j].GetName()
+	})
+
+	var path jp.Expr
+	if spec.JsonPath != nil {
+		path, err = jp.ParseString(*spec.JsonPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ret []any
+	for _, o := range l.Items {
+		if gvk.Group == "" && gvk.Kind == "Secret" {
+			registerSecretValues(ctx, &o)
+		}
+		if path != nil {
+			ret = append(ret, path.Get(o.Object)...)
+		} else {
+			ret = append(ret, o.Object)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newObjectsTestClient(objs ...client.Object) client.Client {
+	mapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, apimeta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, apimeta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, apimeta.RESTScopeRoot)
+	return fake.NewClientBuilder().
+		WithRESTMapper(mapper).
+		WithObjects(objs...).
+		WithIndex(&corev1.ConfigMap{}, "metadata.name", func(o client.Object) []string {
+			return []string{o.GetName()}
+		}).
+		Build()
+}
+
+func newObjectsTestConfigMap(namespace string, name string, labels map[string]string, value string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Data:       map[string]string{"value": value},
+	}
+}
+
+func TestBuildObjectsInput(t *testing.T) {
+	g := NewWithT(t)
+
+	objClient := newObjectsTestClient(
+		newObjectsTestConfigMap("default", "b", map[string]string{"app": "x"}, "b"),
+		newObjectsTestConfigMap("default", "a", map[string]string{"app": "x"}, "a"),
+		newObjectsTestConfigMap("default", "c", nil, "c"),
+		newObjectsTestConfigMap("other", "d", map[string]string{"app": "x"}, "d"),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+	)
+	r := &BaseTemplateReconciler{}
+	valuePath, namePath, invalidPath := "$.data.value", "$.metadata.name", "$.["
+
+	// namespaced objects are listed from the namespace of the template by default
+	inputs, err := r.buildObjectsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "x"}},
+		JsonPath:   &valuePath,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{"a", "b"}))
+
+	inputs, err = r.buildObjectsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion:    "v1",
+		Kind:          "ConfigMap",
+		Namespace:     "other",
+		FieldSelector: "metadata.name=d",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(1))
+	g.Expect(inputs[0]).To(HaveKeyWithValue("data", map[string]any{"value": "d"}))
+
+	// the namespace is ignored for cluster-scoped objects
+	inputs, err = r.buildObjectsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		JsonPath:   &namePath,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{"ns1", "ns2"}))
+
+	_, err = r.buildObjectsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "example.com/v1",
+		Kind:       "Widget",
+	})
+	g.Expect(apimeta.IsNoMatchError(err)).To(BeTrue())
+
+	_, err = r.buildObjectsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		JsonPath:   &invalidPath,
+	})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestBuildObjectsInputSecrets(t *testing.T) {
+	g := NewWithT(t)
+
+	objClient := newObjectsTestClient(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "secret"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t-value")},
+	})
+	p, err := policy.New(policy.Policy{SecretNamespaces: []string{"other"}})
+	g.Expect(err).ToNot(HaveOccurred())
+	r := &BaseTemplateReconciler{Policy: p}
+
+	ctx := redact.NewContext(context.Background())
+	inputs, err := r.buildObjectsInput(ctx, objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  "other",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(1))
+	g.Expect(redact.String(ctx, "failed: s3cr3t-value")).ToNot(ContainSubstring("s3cr3t-value"))
+
+	_, err = r.buildObjectsInput(ctx, objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  "kube-system",
+	})
+	g.Expect(err).To(MatchError("reading secrets from namespace kube-system is not allowed by policy"))
+
+	// other kinds are only restricted by cross-namespace references
+	_, err = r.buildObjectsInput(ctx, objClient, "default", &templatesv1alpha1.MatrixEntryObjects{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Namespace:  "kube-system",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pluginRequest and pluginResponse follow the protocol of Argo CD ApplicationSet plugin generators, so that existing
+// plugins can be reused
+type pluginRequest struct {
+	Input pluginParameters `json:"input"`
+}
+
+type pluginParameters struct {
+	Parameters any `json:"parameters"`
+}
+
+type pluginResponse struct {
+	Output struct {
+		Parameters []any `json:"parameters"`
+	} `json:"output"`
+}
+
+// buildPluginInput calls the plugin endpoint specified by spec and returns one matrix input per returned parameter set.
+func (r *BaseTemplateReconciler) buildPluginInput(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.MatrixEntryPlugin) ([]any, error) {
+	var params any = map[string]any{}
+	if spec.Input != nil && len(spec.Input.Raw) != 0 {
+		err := json.Unmarshal(spec.Input.Raw, &params)
+		if err != nil {
+			return nil, err
+		}
+	}
+	body, err := json.Marshal(pluginRequest{Input: pluginParameters{Parameters: params}})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+	if spec.BasicAuth != nil {
+		headers["Authorization"], err = getBasicAuthHeader(ctx, objClient, objNamespace, r.Policy, *spec.BasicAuth)
+		if err != nil {
+			return nil, err
+		}
+	} else if spec.TokenRef != nil {
+		token, err := GetSecretToken(ctx, objClient, objNamespace, r.Policy, *spec.TokenRef)
+		if err != nil {
+			return nil, err
+		}
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	timeout := defaultRequestTimeout
+	if spec.Timeout != nil {
+		timeout = spec.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var pr pluginResponse
+	err = doJsonRequest(ctx, r.Policy.HTTPClient(), http.MethodPost, spec.URL, body, headers, &pr)
+	if err != nil {
+		return nil, err
+	}
+	return pr.Output.Parameters, nil
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTokenSecret(namespace string, name string, key string, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string][]byte{key: []byte(value)},
+	}
+}
+
+func TestBuildPluginInput(t *testing.T) {
+	g := NewWithT(t)
+
+	var gotAuth string
+	var gotRequest map[string]any
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotRequest = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotRequest)
+		_, _ = w.Write([]byte(`{"output":{"parameters":[{"name":"a"},{"name":"b"}]}}`))
+	}))
+	defer s.Close()
+
+	objClient := fake.NewClientBuilder().WithObjects(newTokenSecret("default", "plugin", "token", "plugin-token")).Build()
+	r := &BaseTemplateReconciler{}
+
+	inputs, err := r.buildPluginInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryPlugin{
+		URL:      s.URL,
+		Input:    &runtime.RawExtension{Raw: []byte(`{"env":"prod"}`)},
+		TokenRef: &templatesv1alpha1.SecretRef{SecretName: "plugin", Key: "token"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}))
+	// the request follows the protocol of Argo CD ApplicationSet plugin generators
+	g.Expect(gotRequest).To(Equal(map[string]any{"input": map[string]any{"parameters": map[string]any{"env": "prod"}}}))
+	g.Expect(gotAuth).To(Equal("Bearer plugin-token"))
+
+	_, err = r.buildPluginInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryPlugin{
+		URL: s.URL,
+		BasicAuth: &templatesv1alpha1.BasicAuth{
+			Username:    "user",
+			PasswordRef: templatesv1alpha1.SecretRef{SecretName: "plugin", Key: "token"},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(gotRequest).To(Equal(map[string]any{"input": map[string]any{"parameters": map[string]any{}}}))
+	g.Expect(gotAuth).To(Equal("Basic dXNlcjpwbHVnaW4tdG9rZW4="))
+
+	_, err = r.buildPluginInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryPlugin{
+		URL:      s.URL,
+		TokenRef: &templatesv1alpha1.SecretRef{SecretName: "missing", Key: "token"},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("not found")))
+}
+
+func TestBuildPluginInputErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "plugin failed", http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	r := &BaseTemplateReconciler{}
+	objClient := fake.NewClientBuilder().Build()
+
+	_, err := r.buildPluginInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryPlugin{URL: s.URL})
+	g.Expect(err).To(MatchError(ContainSubstring("returned unexpected status code 500: plugin failed")))
+
+	_, err = r.buildPluginInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryPlugin{
+		URL:   s.URL,
+		Input: &runtime.RawExtension{Raw: []byte(`{`)},
+	})
+	g.Expect(err).To(HaveOccurred())
+}
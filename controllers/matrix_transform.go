@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/kluctl/go-jinja2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// transformMatrixInputs renders the given transform template once per input and returns the parsed results. Inputs
+// for which the template renders to an empty string or null are dropped.
+func transformMatrixInputs(transform string, elems []any) ([]any, error) {
+	if len(elems) == 0 {
+		return elems, nil
+	}
+
+	j2, err := NewJinja2()
+	if err != nil {
+		return nil, err
+	}
+	defer j2.Close()
+
+	ret := make([]any, 0, len(elems))
+	for i, e := range elems {
+		r, err := j2.RenderString(transform, jinja2.WithGlobals(map[string]any{
+			"item": e,
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform matrix input %d: %w", i, err)
+		}
+		var x any
+		err = yaml.Unmarshal([]byte(r), &x)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transformed matrix input %d: %w", i, err)
+		}
+		if x == nil {
+			continue
+		}
+		ret = append(ret, x)
+	}
+	return ret, nil
+}
+
+// mergeMatrixValues merges the given static values into copies of all inputs. Values override existing fields.
+func mergeMatrixValues(values *runtime.RawExtension, elems []any) ([]any, error) {
+	var m map[string]any
+	if len(values.Raw) != 0 {
+		err := yaml.Unmarshal(values.Raw, &m)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := make([]any, 0, len(elems))
+	for _, e := range elems {
+		em, ok := e.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("values can only be merged into object inputs, got %T", e)
+		}
+		em = copyMergeValue(em).(map[string]any)
+		MergeMap(em, copyMergeValue(m).(map[string]any))
+		ret = append(ret, em)
+	}
+	return ret, nil
+}
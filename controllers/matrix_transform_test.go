@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTransformMatrixInputs(t *testing.T) {
+	g := NewWithT(t)
+
+	elems := []any{
+		map[string]any{"name": "a", "env": "prod"},
+		map[string]any{"name": "b", "env": "dev"},
+		map[string]any{"name": "c", "env": "prod"},
+	}
+
+	// inputs rendering to null or an empty string are dropped
+	inputs, err := transformMatrixInputs(`{% if item.env == "prod" %}{"name": "{{ item.name }}-prod", "replicas": 3}{% endif %}`, elems)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"name": "a-prod", "replicas": float64(3)},
+		map[string]any{"name": "c-prod", "replicas": float64(3)},
+	}))
+
+	inputs, err = transformMatrixInputs(`{{ item.name | upper }}`, elems)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{"A", "B", "C"}))
+
+	inputs, err = transformMatrixInputs(`{{ item.name }}`, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(BeEmpty())
+
+	_, err = transformMatrixInputs(`{{ item.name`, elems)
+	g.Expect(err).To(MatchError(ContainSubstring("failed to transform matrix input 0")))
+	_, err = transformMatrixInputs(`a: [`, elems)
+	g.Expect(err).To(MatchError(ContainSubstring("failed to parse transformed matrix input 0")))
+}
+
+func TestMergeMatrixValues(t *testing.T) {
+	g := NewWithT(t)
+
+	shared := map[string]any{"name": "a", "labels": map[string]any{"team": "x"}}
+	elems := []any{shared, map[string]any{"name": "b"}}
+
+	inputs, err := mergeMatrixValues(&runtime.RawExtension{Raw: []byte(`{"labels":{"env":"prod"},"replicas":3}`)}, elems)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"name": "a", "labels": map[string]any{"team": "x", "env": "prod"}, "replicas": float64(3)},
+		map[string]any{"name": "b", "labels": map[string]any{"env": "prod"}, "replicas": float64(3)},
+	}))
+	// inputs may be shared with other matrix entries and must not be modified
+	g.Expect(shared).To(Equal(map[string]any{"name": "a", "labels": map[string]any{"team": "x"}}))
+
+	inputs, err = mergeMatrixValues(&runtime.RawExtension{}, elems)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal(elems))
+
+	_, err = mergeMatrixValues(&runtime.RawExtension{Raw: []byte(`{"a":1}`)}, []any{"a"})
+	g.Expect(err).To(MatchError("values can only be merged into object inputs, got string"))
+}
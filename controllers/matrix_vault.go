@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"io"
+	"net/http"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+)
+
+// buildVaultInput logs into Vault with a token of the given service account and returns one matrix input per secret
+// found directly below the path specified by spec.
+func (r *BaseTemplateReconciler) buildVaultInput(ctx context.Context, objClient client.Client, objNamespace string, serviceAccountName string, spec *templatesv1alpha1.MatrixEntryVault) ([]any, error) {
+	hc := r.Policy.HTTPClient()
+	address := strings.TrimSuffix(spec.Address, "/")
+
+	token, err := vaultKubernetesLogin(ctx, hc, objClient, objNamespace, serviceAccountName, address, spec.KubernetesAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	mount := strings.Trim(spec.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	secretPath := strings.Trim(spec.Path, "/")
+	v2 := spec.KVVersion != 1
+
+	listUrl := fmt.Sprintf("%s/v1/%s/%s?list=true", address, mount, secretPath)
+	if v2 {
+		listUrl = fmt.Sprintf("%s/v1/%s/metadata/%s?list=true", address, mount, secretPath)
+	}
+	var listResp struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	found, err := vaultRequest(ctx, hc, http.MethodGet, listUrl, token, nil, &listResp)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	keys := listResp.Data.Keys
+	sort.Strings(keys)
+
+	var ret []any
+	for _, key := range keys {
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		p := path.Join(secretPath, key)
+
+		readUrl := fmt.Sprintf("%s/v1/%s/%s", address, mount, p)
+		if v2 {
+			readUrl = fmt.Sprintf("%s/v1/%s/data/%s", address, mount, p)
+		}
+		var readResp struct {
+			Data map[string]any `json:"data"`
+		}
+		found, err = vaultRequest(ctx, hc, http.MethodGet, readUrl, token, nil, &readResp)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			// deleted in the meantime
+			continue
+		}
+
+		e := map[string]any{
+			"name": key,
+			"path": p,
+		}
+		data := readResp.Data
+		if v2 {
+			e["metadata"] = data["metadata"]
+			data, _ = data["data"].(map[string]any)
+		}
+		for _, v := range data {
+			if s, ok := v.(string); ok {
+				redact.Register(ctx, s)
+			}
+		}
+		e["data"] = data
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// vaultKubernetesLogin requests a short-lived token for the given service account and uses it to log into Vault via
+// the Kubernetes auth method. The service account must be allowed to create tokens for itself.
+func vaultKubernetesLogin(ctx context.Context, hc *http.Client, objClient client.Client, objNamespace string, serviceAccountName string, address string, auth templatesv1alpha1.VaultKubernetesAuth) (string, error) {
+	jwt, err := requestServiceAccountToken(ctx, objClient, objNamespace, serviceAccountName, auth.Audience)
+	if err != nil {
+		return "", err
+	}
+
+	mount := strings.Trim(auth.Mount, "/")
+	if mount == "" {
+		mount = "kubernetes"
+	}
+	body, err := json.Marshal(map[string]string{
+		"role": auth.Role,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return "", err
+	}
+	var loginResp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	_, err = vaultRequest(ctx, hc, http.MethodPost, fmt.Sprintf("%s/v1/auth/%s/login", address, mount), "", body, &loginResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to log into Vault: %w", err)
+	}
+	if loginResp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("failed to log into Vault: no client token returned")
+	}
+	redact.Register(ctx, loginResp.Auth.ClientToken)
+	return loginResp.Auth.ClientToken, nil
+}
+
+// vaultRequest sends a request to the Vault API and decodes the JSON response into v. It returns false if the path
+// does not exist.
+func vaultRequest(ctx context.Context, hc *http.Client, method string, url string, token string, body []byte, v any) (bool, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("%s %s returned unexpected status code %d: %s", method, req.URL.Redacted(), resp.StatusCode, string(rb))
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode response of %s %s: %w", method, req.URL.Redacted(), err)
+	}
+	return true, nil
+}
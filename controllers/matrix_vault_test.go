@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	. "github.com/onsi/gomega"
+)
+
+// newFakeVault returns a Vault server with the Kubernetes auth method mounted at auth/k8s and the given KV v1 and v2
+// secrets mounted at kv1 and kv2. It only accepts the token returned by newTokenClient.
+func newFakeVault(t *testing.T) *httptest.Server {
+	const vaultToken = "vault-token"
+	responses := map[string]string{
+		"/v1/kv2/metadata/apps?list=true": `{"data":{"keys":["b","a","nested/"]}}`,
+		"/v1/kv2/data/apps/a":             `{"data":{"data":{"password":"a-secret-value"},"metadata":{"version":3}}}`,
+		"/v1/kv2/data/apps/b":             `{"data":{"data":{"password":"b-secret-value"},"metadata":{"version":1}}}`,
+		"/v1/kv1/apps?list=true":          `{"data":{"keys":["a","deleted"]}}`,
+		"/v1/kv1/apps/a":                  `{"data":{"password":"a-secret-value"}}`,
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/k8s/login" {
+			var login map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&login)
+			if login["role"] != "reader" || login["jwt"] != "id-token" {
+				http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"` + vaultToken + `"}}`))
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != vaultToken {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		resp, ok := responses[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestBuildVaultInput(t *testing.T) {
+	g := NewWithT(t)
+
+	vault := newFakeVault(t)
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+	r := &BaseTemplateReconciler{}
+	auth := templatesv1alpha1.VaultKubernetesAuth{Role: "reader", Mount: "k8s", Audience: "vault"}
+
+	ctx := redact.NewContext(context.Background())
+	inputs, err := r.buildVaultInput(ctx, objClient, "team-a", "vault-reader", &templatesv1alpha1.MatrixEntryVault{
+		Address:        vault.URL + "/",
+		Mount:          "kv2",
+		Path:           "/apps/",
+		KubernetesAuth: auth,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	// sub-paths are skipped
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"name": "a", "path": "apps/a", "data": map[string]any{"password": "a-secret-value"}, "metadata": map[string]any{"version": float64(3)}},
+		map[string]any{"name": "b", "path": "apps/b", "data": map[string]any{"password": "b-secret-value"}, "metadata": map[string]any{"version": float64(1)}},
+	}))
+	g.Expect(requests).To(Equal([]tokenRequest{{namespace: "team-a", name: "vault-reader", audiences: []string{"vault"}}}))
+
+	// secret values and tokens are redacted from messages of the current reconciliation
+	g.Expect(redact.String(ctx, "a-secret-value b-secret-value vault-token id-token")).ToNot(MatchRegexp("secret-value|token"))
+
+	// secrets deleted between listing and reading are skipped
+	inputs, err = r.buildVaultInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryVault{
+		Address:        vault.URL,
+		Mount:          "kv1",
+		Path:           "apps",
+		KVVersion:      1,
+		KubernetesAuth: auth,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{
+		map[string]any{"name": "a", "path": "apps/a", "data": map[string]any{"password": "a-secret-value"}},
+	}))
+	g.Expect(requests[1]).To(Equal(tokenRequest{namespace: "team-a", name: "default", audiences: []string{"vault"}}))
+
+	// missing paths result in no inputs
+	inputs, err = r.buildVaultInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryVault{
+		Address:        vault.URL,
+		Mount:          "kv2",
+		Path:           "missing",
+		KubernetesAuth: auth,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(BeEmpty())
+}
+
+func TestBuildVaultInputLoginFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	vault := newFakeVault(t)
+	var requests []tokenRequest
+	objClient := newTokenClient(&requests)
+	r := &BaseTemplateReconciler{}
+
+	_, err := r.buildVaultInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryVault{
+		Address:        vault.URL,
+		Path:           "apps",
+		KubernetesAuth: templatesv1alpha1.VaultKubernetesAuth{Role: "writer", Mount: "k8s"},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to log into Vault: POST " + vault.URL + "/v1/auth/k8s/login returned unexpected status code 403")))
+
+	// the auth method is mounted at kubernetes by default
+	_, err = r.buildVaultInput(context.Background(), objClient, "team-a", "", &templatesv1alpha1.MatrixEntryVault{
+		Address:        vault.URL,
+		Path:           "apps",
+		KubernetesAuth: templatesv1alpha1.VaultKubernetesAuth{Role: "reader"},
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("failed to log into Vault: POST " + vault.URL + "/v1/auth/kubernetes/login")))
+}
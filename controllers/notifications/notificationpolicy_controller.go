@@ -0,0 +1,431 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
+	"sync"
+	"time"
+)
+
+const forSourceKindIndexKey = "spec.sources.kind"
+
+// NotificationPolicyReconciler reconciles a NotificationPolicy object
+type NotificationPolicyReconciler struct {
+	client.Client
+	Manager manager.Manager
+
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+
+	controller   controller.Controller
+	watchedKinds map[schema.GroupVersionKind]bool
+	mutex        sync.Mutex
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=notificationpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=notificationpolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=notificationpolicies/finalizers,verbs=update
+
+// Reconcile a resource
+func (r *NotificationPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	logger.V(1).Info("Starting reconcile")
+	defer logger.V(1).Info("Finished reconcile", "err", err)
+
+	var np templatesv1alpha1.NotificationPolicy
+	err = r.Get(ctx, req.NamespacedName, &np)
+	if err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	// Return early if the object is suspended.
+	if np.Spec.Suspend {
+		logger.Info("Reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(np.DeepCopy())
+	err = r.doReconcile(ctx, &np)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: np.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&np.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: np.GetGeneration(),
+			Reason:             "Success",
+			Message:            "Success",
+		}
+		apimeta.SetStatusCondition(&np.Status.Conditions, c)
+	}
+	err = r.Status().Patch(ctx, &np, patch, controllers.SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return
+	}
+
+	result.RequeueAfter = np.Spec.Interval.Duration
+	return
+}
+
+func (r *NotificationPolicyReconciler) doReconcile(ctx context.Context, np *templatesv1alpha1.NotificationPolicy) error {
+	conditionType := np.Spec.ConditionType
+	if conditionType == "" {
+		conditionType = "Ready"
+	}
+
+	objects, err := r.listSourceObjects(ctx, np)
+	if err != nil {
+		return err
+	}
+
+	oldStatuses := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.NotificationObjectStatus{}
+	for _, s := range np.Status.ObservedObjects {
+		oldStatuses[s.Ref.WithoutVersion()] = s
+	}
+
+	var senders []Sender
+	var sendersErr error
+	sendersBuilt := false
+
+	var errs *multierror.Error
+	newStatuses := make([]templatesv1alpha1.NotificationObjectStatus, 0, len(objects))
+	for _, o := range objects {
+		ref := templatesv1alpha1.ObjectRefFromObject(o)
+		c := findCondition(o, conditionType)
+
+		newStatus := templatesv1alpha1.NotificationObjectStatus{
+			Ref: ref,
+		}
+		if c != nil {
+			newStatus.Status = c.Status
+			newStatus.Reason = c.Reason
+		}
+
+		oldStatus, ok := oldStatuses[ref.WithoutVersion()]
+		if !ok {
+			// objects seen for the first time are only recorded, so that (re-)creating a policy does not cause a storm
+			// of notifications
+			newStatuses = append(newStatuses, newStatus)
+			continue
+		}
+		newStatus.LastNotificationTime = oldStatus.LastNotificationTime
+
+		if c == nil || (oldStatus.Status == c.Status && oldStatus.Reason == c.Reason) {
+			newStatuses = append(newStatuses, newStatus)
+			continue
+		}
+
+		severity, matched, err := matchRules(np.Spec.Rules, c)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			newStatuses = append(newStatuses, newStatus)
+			continue
+		}
+		if oldStatus.LastNotificationTime != nil && time.Since(oldStatus.LastNotificationTime.Time) < np.Spec.Throttle.Duration {
+			newStatuses = append(newStatuses, newStatus)
+			continue
+		}
+
+		if !sendersBuilt {
+			senders, sendersErr = r.buildSenders(ctx, np)
+			sendersBuilt = true
+		}
+		if sendersErr != nil {
+			return sendersErr
+		}
+
+		n := &Notification{
+			Policy:   fmt.Sprintf("%s/%s", np.GetNamespace(), np.GetName()),
+			Object:   ref,
+			Severity: severity,
+			Type:     conditionType,
+			Status:   string(c.Status),
+			Reason:   c.Reason,
+			Message:  redact.String(ctx, c.Message),
+			Time:     time.Now(),
+		}
+		err = r.send(ctx, np, senders, n)
+		if err != nil {
+			// keep the old status so that the notification is retried
+			errs = multierror.Append(errs, err)
+			newStatuses = append(newStatuses, oldStatus)
+			continue
+		}
+		now := metav1.Now()
+		newStatus.LastNotificationTime = &now
+		newStatuses = append(newStatuses, newStatus)
+	}
+
+	sort.Slice(newStatuses, func(i, j int) bool {
+		return newStatuses[i].Ref.String() < newStatuses[j].Ref.String()
+	})
+	np.Status.ObservedObjects = newStatuses
+
+	return errs.ErrorOrNil()
+}
+
+// buildSenders builds senders for all destinations. Building is delayed until the first notification is sent, so that
+// secrets are only read when needed. Destinations are filtered by severity when sending.
+func (r *NotificationPolicyReconciler) buildSenders(ctx context.Context, np *templatesv1alpha1.NotificationPolicy) ([]Sender, error) {
+	senders := make([]Sender, len(np.Spec.Destinations))
+	for i, d := range np.Spec.Destinations {
+		s, err := BuildSender(ctx, r.Client, np.GetNamespace(), r.Policy, d)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build destination %s: %w", d.Name, err)
+		}
+		senders[i] = s
+	}
+	return senders, nil
+}
+
+func (r *NotificationPolicyReconciler) send(ctx context.Context, np *templatesv1alpha1.NotificationPolicy, senders []Sender, n *Notification) error {
+	logger := log.FromContext(ctx)
+
+	var errs *multierror.Error
+	for i, d := range np.Spec.Destinations {
+		if !SeverityAtLeast(n.Severity, d.MinSeverity) {
+			continue
+		}
+		logger.Info("Sending notification", "destination", d.Name, "object", n.Object.String(), "status", n.Status)
+		err := senders[i].Send(ctx, n)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to send notification to %s: %w", d.Name, err))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+func matchRules(rules []templatesv1alpha1.NotificationRule, c *metav1.Condition) (string, bool, error) {
+	for _, rule := range rules {
+		if rule.Status != nil && *rule.Status != c.Status {
+			continue
+		}
+		if rule.Reason != nil {
+			re, err := regexp.Compile(fmt.Sprintf("^%s$", *rule.Reason))
+			if err != nil {
+				return "", false, err
+			}
+			if !re.MatchString(c.Reason) {
+				continue
+			}
+		}
+		severity := rule.Severity
+		if severity == "" {
+			severity = templatesv1alpha1.SeverityInfo
+		}
+		return severity, true, nil
+	}
+	return "", false, nil
+}
+
+func findCondition(o *unstructured.Unstructured, conditionType string) *metav1.Condition {
+	conditions, _, _ := unstructured.NestedSlice(o.Object, "status", "conditions")
+	for _, x := range conditions {
+		m, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		var c metav1.Condition
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &c)
+		if err != nil {
+			continue
+		}
+		if c.Type == conditionType {
+			return &c
+		}
+	}
+	return nil
+}
+
+func (r *NotificationPolicyReconciler) listSourceObjects(ctx context.Context, np *templatesv1alpha1.NotificationPolicy) ([]*unstructured.Unstructured, error) {
+	var ret []*unstructured.Unstructured
+	for _, s := range np.Spec.Sources {
+		gvk, err := sourceGroupVersionKind(s)
+		if err != nil {
+			return nil, err
+		}
+		err = r.addWatchForKind(ctx, gvk)
+		if err != nil {
+			return nil, err
+		}
+
+		namespace := np.GetNamespace()
+		if s.Namespace != "" {
+			namespace = s.Namespace
+		}
+		err = r.Policy.CheckRefNamespace(np.GetNamespace(), namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.Name != "" {
+			var o unstructured.Unstructured
+			o.SetGroupVersionKind(gvk)
+			err = r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: s.Name}, &o)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, &o)
+			continue
+		}
+
+		opts := []client.ListOption{client.InNamespace(namespace)}
+		if s.Selector != nil {
+			sel, err := metav1.LabelSelectorAsSelector(s.Selector)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, client.MatchingLabelsSelector{Selector: sel})
+		}
+
+		var l unstructured.UnstructuredList
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err = r.Client.List(ctx, &l, opts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range l.Items {
+			ret = append(ret, &l.Items[i])
+		}
+	}
+	return ret, nil
+}
+
+func sourceGroupVersionKind(s templatesv1alpha1.NotificationSource) (schema.GroupVersionKind, error) {
+	gv, err := schema.ParseGroupVersion(s.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return gv.WithKind(s.Kind), nil
+}
+
+func buildSourceIndexValue(gk schema.GroupKind) string {
+	return gk.String()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NotificationPolicyReconciler) SetupWithManager(mgr ctrl.Manager, concurrent int) error {
+	r.Manager = mgr
+	r.watchedKinds = map[schema.GroupVersionKind]bool{}
+
+	// Index the NotificationPolicies by the kinds they observe.
+	if err := mgr.GetCache().IndexField(context.TODO(), &templatesv1alpha1.NotificationPolicy{}, forSourceKindIndexKey,
+		func(object client.Object) []string {
+			np := object.(*templatesv1alpha1.NotificationPolicy)
+			var ret []string
+			for _, s := range np.Spec.Sources {
+				gvk, err := sourceGroupVersionKind(s)
+				if err != nil {
+					continue
+				}
+				ret = append(ret, buildSourceIndexValue(gvk.GroupKind()))
+			}
+			return ret
+		}); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.NotificationPolicy{}, builder.WithPredicates(
+			predicate.GenerationChangedPredicate{},
+		)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: concurrent,
+		}).
+		Build(redact.Reconciler(r))
+	if err != nil {
+		return err
+	}
+	r.controller = c
+
+	return nil
+}
+
+func (r *NotificationPolicyReconciler) addWatchForKind(ctx context.Context, gvk schema.GroupVersionKind) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if x, ok := r.watchedKinds[gvk]; ok && x {
+		return nil
+	}
+
+	var dummy unstructured.Unstructured
+	dummy.SetGroupVersionKind(gvk)
+
+	err := r.controller.Watch(source.Kind(r.Manager.GetCache(), &dummy), handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		var list templatesv1alpha1.NotificationPolicyList
+		err := r.List(ctx, &list, client.MatchingFields{
+			forSourceKindIndexKey: buildSourceIndexValue(object.GetObjectKind().GroupVersionKind().GroupKind()),
+		})
+		if err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, x := range list.Items {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: x.Namespace,
+					Name:      x.Name,
+				},
+			})
+		}
+		return reqs
+	}))
+	if err != nil {
+		return err
+	}
+
+	r.watchedKinds[gvk] = true
+	return nil
+}
@@ -0,0 +1,172 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification describes a condition transition of an observed object
+type Notification struct {
+	Policy   string                      `json:"policy"`
+	Object   templatesv1alpha1.ObjectRef `json:"object"`
+	Severity string                      `json:"severity"`
+	Type     string                      `json:"type"`
+	Status   string                      `json:"status"`
+	Reason   string                      `json:"reason"`
+	Message  string                      `json:"message"`
+	Time     time.Time                   `json:"time"`
+}
+
+func (n *Notification) Text() string {
+	return fmt.Sprintf("[%s] %s: %s is %s (%s): %s", strings.ToUpper(n.Severity), n.Object.String(), n.Type, n.Status, n.Reason, n.Message)
+}
+
+type Sender interface {
+	Send(ctx context.Context, n *Notification) error
+}
+
+var severities = map[string]int{
+	templatesv1alpha1.SeverityInfo:    0,
+	templatesv1alpha1.SeverityWarning: 1,
+	templatesv1alpha1.SeverityError:   2,
+}
+
+// SeverityAtLeast returns true if severity is at least minSeverity. Empty severities are treated as info.
+func SeverityAtLeast(severity string, minSeverity string) bool {
+	return severities[severity] >= severities[minSeverity]
+}
+
+func BuildSender(ctx context.Context, c client.Client, namespace string, p *policy.Policy, d templatesv1alpha1.NotificationDestination) (Sender, error) {
+	if d.Slack != nil {
+		u, err := controllers.GetSecretToken(ctx, c, namespace, p, d.Slack.WebhookUrlRef)
+		if err != nil {
+			return nil, err
+		}
+		return &slackSender{url: u, channel: d.Slack.Channel, hc: p.HTTPClient()}, nil
+	} else if d.Webhook != nil {
+		u := d.Webhook.Url
+		if d.Webhook.UrlRef != nil {
+			var err error
+			u, err = controllers.GetSecretToken(ctx, c, namespace, p, *d.Webhook.UrlRef)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if u == "" {
+			return nil, fmt.Errorf("webhook destination %s has no url", d.Name)
+		}
+		return &webhookSender{url: u, headers: d.Webhook.Headers, hc: p.HTTPClient()}, nil
+	} else if d.Email != nil {
+		err := p.CheckHost(d.Email.Host)
+		if err != nil {
+			return nil, err
+		}
+		s := &emailSender{spec: *d.Email}
+		if d.Email.UsernameRef != nil {
+			s.username, err = controllers.GetSecretToken(ctx, c, namespace, p, *d.Email.UsernameRef)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if d.Email.PasswordRef != nil {
+			s.password, err = controllers.GetSecretToken(ctx, c, namespace, p, *d.Email.PasswordRef)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	} else {
+		return nil, fmt.Errorf("destination %s has no type specified", d.Name)
+	}
+}
+
+func postJson(ctx context.Context, hc *http.Client, url string, headers map[string]string, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(rb))
+	}
+	return nil
+}
+
+type slackSender struct {
+	url     string
+	channel string
+	hc      *http.Client
+}
+
+func (s *slackSender) Send(ctx context.Context, n *Notification) error {
+	body := map[string]any{
+		"text": n.Text(),
+	}
+	if s.channel != "" {
+		body["channel"] = s.channel
+	}
+	return postJson(ctx, s.hc, s.url, nil, body)
+}
+
+type webhookSender struct {
+	url     string
+	headers map[string]string
+	hc      *http.Client
+}
+
+func (s *webhookSender) Send(ctx context.Context, n *Notification) error {
+	return postJson(ctx, s.hc, s.url, s.headers, n)
+}
+
+type emailSender struct {
+	spec     templatesv1alpha1.EmailDestination
+	username string
+	password string
+}
+
+func (s *emailSender) Send(ctx context.Context, n *Notification) error {
+	port := s.spec.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(s.spec.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if s.username != "" || s.password != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.spec.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.spec.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.spec.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [%s] %s %s is %s\r\n", strings.ToUpper(n.Severity), n.Object.String(), n.Type, n.Status)
+	fmt.Fprintf(&msg, "\r\n%s\r\n", n.Text())
+
+	return smtp.SendMail(addr, auth, s.spec.From, s.spec.To, msg.Bytes())
+}
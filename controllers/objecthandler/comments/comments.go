@@ -2,13 +2,14 @@ package comments
 
 import (
 	"context"
+	"github.com/kluctl/template-controller/api/v1alpha1"
 	"github.com/kluctl/template-controller/controllers/objecthandler/comments/templates"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type CommentGenerator interface {
-	GenerateComment(ctx context.Context, obj client.Object) (string, error)
+	GenerateComment(ctx context.Context, obj client.Object, health *v1alpha1.ObjectHealth) (string, error)
 }
 
 var byGroupKind = map[schema.GroupKind]CommentGenerator{
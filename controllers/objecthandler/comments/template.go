@@ -3,6 +3,7 @@ package comments
 import (
 	"context"
 	"github.com/kluctl/go-jinja2"
+	"github.com/kluctl/template-controller/api/v1alpha1"
 	"github.com/kluctl/template-controller/controllers"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -12,7 +13,7 @@ type TemplateComment struct {
 	template string
 }
 
-func (c *TemplateComment) GenerateComment(ctx context.Context, obj client.Object) (string, error) {
+func (c *TemplateComment) GenerateComment(ctx context.Context, obj client.Object, health *v1alpha1.ObjectHealth) (string, error) {
 	j2, err := controllers.NewJinja2()
 	if err != nil {
 		return "", err
@@ -28,6 +29,14 @@ func (c *TemplateComment) GenerateComment(ctx context.Context, obj client.Object
 
 	vars["object"] = u
 
+	if health != nil {
+		h, err := runtime.DefaultUnstructuredConverter.ToUnstructured(health)
+		if err != nil {
+			return "", err
+		}
+		vars["health"] = h
+	}
+
 	rendered, err := j2.RenderString(c.template, jinja2.WithGlobals(vars))
 	if err != nil {
 		return "", err
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/health"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// templateKinds contains the kinds for which the health of all applied resources is taken into account
+var templateKinds = map[schema.GroupKind]bool{
+	v1alpha1.GroupVersion.WithKind("ObjectTemplate").GroupKind():        true,
+	v1alpha1.GroupVersion.WithKind("ClusterObjectTemplate").GroupKind(): true,
+}
+
+// ComputeHealth computes the combined health of the given object. If the object is an ObjectTemplate or
+// ClusterObjectTemplate, the health of all applied resources is combined with the health of the object itself.
+func (sc *StatusCalculator) ComputeHealth(ctx context.Context, obj *unstructured.Unstructured) (*v1alpha1.ObjectHealth, error) {
+	rh, err := sc.computeResourceHealth(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	objHealth := &v1alpha1.ObjectHealth{
+		Status:    rh.Status,
+		Message:   rh.Message,
+		Resources: []v1alpha1.ResourceHealth{rh},
+	}
+
+	if !templateKinds[obj.GroupVersionKind().GroupKind()] {
+		return objHealth, nil
+	}
+
+	appliedResources, _, err := unstructured.NestedSlice(obj.Object, "status", "appliedResources")
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range appliedResources {
+		m, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		ref, _, _ := unstructured.NestedStringMap(m, "ref")
+		rh, err := sc.computeAppliedResourceHealth(ctx, v1alpha1.ObjectRef{
+			APIVersion: ref["apiVersion"],
+			Kind:       ref["kind"],
+			Namespace:  ref["namespace"],
+			Name:       ref["name"],
+		})
+		if err != nil {
+			return nil, err
+		}
+		objHealth.Resources = append(objHealth.Resources, rh)
+		if health.Order[rh.Status] > health.Order[objHealth.Status] {
+			objHealth.Status = rh.Status
+			objHealth.Message = fmt.Sprintf("%s/%s: %s", rh.Ref.Kind, rh.Ref.Name, rh.Message)
+		}
+	}
+
+	return objHealth, nil
+}
+
+func (sc *StatusCalculator) computeAppliedResourceHealth(ctx context.Context, ref v1alpha1.ObjectRef) (v1alpha1.ResourceHealth, error) {
+	gvk, err := ref.GroupVersionKind()
+	if err != nil {
+		return v1alpha1.ResourceHealth{}, err
+	}
+
+	var obj unstructured.Unstructured
+	obj.SetGroupVersionKind(gvk)
+	err = sc.Client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &obj)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return v1alpha1.ResourceHealth{
+				Ref:     ref,
+				Status:  v1alpha1.HealthDegraded,
+				Message: "Object not found",
+			}, nil
+		}
+		return v1alpha1.ResourceHealth{}, err
+	}
+	return sc.computeResourceHealth(ctx, &obj)
+}
+
+func (sc *StatusCalculator) computeResourceHealth(ctx context.Context, obj *unstructured.Unstructured) (v1alpha1.ResourceHealth, error) {
+	rh := v1alpha1.ResourceHealth{
+		Ref: v1alpha1.ObjectRefFromObject(obj),
+	}
+
+	if !health.IsNativeKind(obj.GroupVersionKind().GroupKind()) && sc.hasStatus(ctx, obj) {
+		if _, ok := obj.Object["status"]; !ok {
+			rh.Status = v1alpha1.HealthProgressing
+			rh.Message = "Waiting for status"
+			return rh, nil
+		}
+	}
+
+	var err error
+	rh.Status, rh.Message, err = health.Compute(obj)
+	if err != nil {
+		return rh, err
+	}
+	return rh, nil
+}
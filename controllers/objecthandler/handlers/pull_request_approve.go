@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
 	"github.com/kluctl/template-controller/controllers/webgit"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -13,8 +14,12 @@ type PullRequestApproveReporter struct {
 	spec v1alpha1.PullRequestApproveReporter
 }
 
-func BuildPullRequestApproveReporter(ctx context.Context, client client.Client, namespace string, spec v1alpha1.PullRequestApproveReporter) (Handler, error) {
-	mr, err := webgit.BuildWebgitMergeRequest(ctx, client, namespace, spec.PullRequestRefHolder)
+func BuildPullRequestApproveReporter(ctx context.Context, client client.Client, namespace string, p *policy.Policy, spec v1alpha1.PullRequestApproveReporter) (Handler, error) {
+	mr, err := webgit.BuildWebgitMergeRequest(ctx, client, namespace, p, spec.PullRequestRefHolder)
+	if err != nil {
+		return nil, err
+	}
+	err = mr.CheckTokenScopes(ctx, "approvals")
 	if err != nil {
 		return nil, err
 	}
@@ -22,7 +27,7 @@ func BuildPullRequestApproveReporter(ctx context.Context, client client.Client,
 	return &PullRequestApproveReporter{mr: mr, spec: spec}, nil
 }
 
-func (p *PullRequestApproveReporter) Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, status *v1alpha1.HandlerStatus) error {
+func (p *PullRequestApproveReporter) Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, health *v1alpha1.ObjectHealth, status *v1alpha1.HandlerStatus) error {
 	if status.PullRequestApprove == nil {
 		status.PullRequestApprove = &v1alpha1.PullRequestApproveReporterStatus{}
 	}
@@ -37,6 +42,9 @@ func (p *PullRequestApproveReporter) Handle(ctx context.Context, client client.C
 	if err != nil {
 		return err
 	}
+	if health != nil && health.Status != v1alpha1.HealthHealthy {
+		ready = false
+	}
 
 	if ready && !approved {
 		err = p.mr.Approve()
@@ -9,6 +9,8 @@ import (
 	"github.com/kluctl/template-controller/api/v1alpha1"
 	"github.com/kluctl/template-controller/controllers"
 	"github.com/kluctl/template-controller/controllers/objecthandler/comments/templates"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
 	"github.com/kluctl/template-controller/controllers/webgit"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -24,8 +26,12 @@ type PullRequestCommandHandler struct {
 	clusterId string
 }
 
-func BuildPullRequestCommandHandler(ctx context.Context, client client.Client, namespace string, spec v1alpha1.PullRequestCommandHandler) (Handler, error) {
-	mr, err := webgit.BuildWebgitMergeRequest(ctx, client, namespace, spec.PullRequestRefHolder)
+func BuildPullRequestCommandHandler(ctx context.Context, client client.Client, namespace string, p *policy.Policy, spec v1alpha1.PullRequestCommandHandler) (Handler, error) {
+	mr, err := webgit.BuildWebgitMergeRequest(ctx, client, namespace, p, spec.PullRequestRefHolder)
+	if err != nil {
+		return nil, err
+	}
+	err = mr.CheckTokenScopes(ctx, "comments")
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +44,7 @@ func BuildPullRequestCommandHandler(ctx context.Context, client client.Client, n
 	return &PullRequestCommandHandler{mr: mr, spec: spec, clusterId: clusterId}, nil
 }
 
-func (p *PullRequestCommandHandler) Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, status *v1alpha1.HandlerStatus) error {
+func (p *PullRequestCommandHandler) Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, health *v1alpha1.ObjectHealth, status *v1alpha1.HandlerStatus) error {
 	j2, err := controllers.NewJinja2()
 	if err != nil {
 		return err
@@ -146,7 +152,7 @@ func (p *PullRequestCommandHandler) processCommand(ctx context.Context, j2 *jinj
 	newBody := body
 	newBody += fmt.Sprintf("\n\n:robot: Command has been processed at %s\n", time.Now().Format(time.RFC3339))
 	if err != nil {
-		newBody += fmt.Sprintf("<br>:boom: Command failed with error: %s\n", err.Error())
+		newBody += fmt.Sprintf("<br>:boom: Command failed with error: %s\n", redact.Error(ctx, err))
 	}
 	newBody += generateMarkerComment("pull-request-command-processed", p.clusterId, obj.GetNamespace(), obj.GetName())
 
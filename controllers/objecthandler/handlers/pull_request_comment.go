@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/kluctl/template-controller/api/v1alpha1"
 	"github.com/kluctl/template-controller/controllers/objecthandler/comments"
+	"github.com/kluctl/template-controller/controllers/policy"
 	"github.com/kluctl/template-controller/controllers/webgit"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -14,8 +15,12 @@ type PullRequestCommentReporter struct {
 	clusterId string
 }
 
-func BuildPullRequestCommentReporter(ctx context.Context, client client.Client, namespace string, spec v1alpha1.PullRequestCommentReporter) (Handler, error) {
-	mr, err := webgit.BuildWebgitMergeRequest(ctx, client, namespace, spec.PullRequestRefHolder)
+func BuildPullRequestCommentReporter(ctx context.Context, client client.Client, namespace string, p *policy.Policy, spec v1alpha1.PullRequestCommentReporter) (Handler, error) {
+	mr, err := webgit.BuildWebgitMergeRequest(ctx, client, namespace, p, spec.PullRequestRefHolder)
+	if err != nil {
+		return nil, err
+	}
+	err = mr.CheckTokenScopes(ctx, "comments")
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +36,7 @@ func BuildPullRequestCommentReporter(ctx context.Context, client client.Client,
 	}, nil
 }
 
-func (p *PullRequestCommentReporter) Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, status *v1alpha1.HandlerStatus) error {
+func (p *PullRequestCommentReporter) Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, health *v1alpha1.ObjectHealth, status *v1alpha1.HandlerStatus) error {
 	if status.PullRequestComment == nil {
 		status.PullRequestComment = &v1alpha1.PullRequestCommentReporterStatus{}
 	}
@@ -41,7 +46,7 @@ func (p *PullRequestCommentReporter) Handle(ctx context.Context, client client.C
 		return err
 	}
 
-	comment, err := generator.GenerateComment(ctx, obj)
+	comment, err := generator.GenerateComment(ctx, obj, health)
 	if err != nil {
 		return err
 	}
@@ -8,5 +8,5 @@ import (
 )
 
 type Handler interface {
-	Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, status *v1alpha1.HandlerStatus) error
+	Handle(ctx context.Context, client client.Client, obj *unstructured.Unstructured, health *v1alpha1.ObjectHealth, status *v1alpha1.HandlerStatus) error
 }
@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/health"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -48,6 +50,11 @@ func (sc *StatusCalculator) ComputeReady(ctx context.Context, obj client.Object,
 		}
 	}
 
+	if health.IsNativeKind(u.GroupVersionKind().GroupKind()) {
+		h, _ := health.ComputeNative(u)
+		return h == v1alpha1.HealthHealthy, nil
+	}
+
 	res, err := status.Compute(u)
 	if err != nil {
 		return false, err
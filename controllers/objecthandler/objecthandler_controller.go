@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/kluctl/template-controller/controllers"
 	"github.com/kluctl/template-controller/controllers/objecthandler/handlers"
+	"github.com/kluctl/template-controller/controllers/policy"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -35,6 +36,7 @@ import (
 	"sync"
 
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,6 +51,7 @@ type ObjectHandlerReconciler struct {
 
 	Scheme       *runtime.Scheme
 	FieldManager string
+	Policy       *policy.Policy
 
 	controller   controller.Controller
 	watchedKinds map[schema.GroupVersionKind]bool
@@ -75,7 +78,7 @@ func (r *ObjectHandlerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: sr.GetGeneration(),
 			Reason:             "Error",
-			Message:            err.Error(),
+			Message:            redact.Error(ctx, err),
 		}
 		apimeta.SetStatusCondition(&sr.Status.Conditions, c)
 	} else {
@@ -88,6 +91,7 @@ func (r *ObjectHandlerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 		apimeta.SetStatusCondition(&sr.Status.Conditions, c)
 	}
+	controllers.SetStalledCondition(&sr.Status.Conditions, sr.GetGeneration(), err)
 	err = r.Status().Patch(ctx, &sr, patch, controllers.SubResourceFieldOwner(r.FieldManager))
 	if err != nil {
 		return ctrl.Result{}, err
@@ -119,7 +123,7 @@ func (r *ObjectHandlerReconciler) SetupWithManager(mgr ctrl.Manager, concurrent
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: concurrent,
 		}).
-		Build(r)
+		Build(redact.Reconciler(r))
 	if err != nil {
 		return err
 	}
@@ -165,6 +169,10 @@ func (r *ObjectHandlerReconciler) doReconcile(ctx context.Context, sr *templates
 	if sr.Spec.ForObject.Namespace != "" {
 		name.Namespace = sr.Spec.ForObject.Namespace
 	}
+	err = r.Policy.CheckRefNamespace(sr.GetNamespace(), name.Namespace)
+	if err != nil {
+		return err
+	}
 
 	var obj unstructured.Unstructured
 	obj.SetGroupVersionKind(gvk)
@@ -176,17 +184,24 @@ func (r *ObjectHandlerReconciler) doReconcile(ctx context.Context, sr *templates
 
 	origObj := obj.DeepCopy()
 
+	sc := handlers.StatusCalculator{Client: r.Client}
+	health, err := sc.ComputeHealth(ctx, &obj)
+	if err != nil {
+		return err
+	}
+	sr.Status.Health = health
+
 	existingStatuses := map[string]bool{}
 
 	var errs *multierror.Error
 	for _, spec := range sr.Spec.Handlers {
 		var reporter handlers.Handler
 		if spec.PullRequestComment != nil {
-			reporter, err = handlers.BuildPullRequestCommentReporter(ctx, r.Client, sr.GetNamespace(), *spec.PullRequestComment)
+			reporter, err = handlers.BuildPullRequestCommentReporter(ctx, r.Client, sr.GetNamespace(), r.Policy, *spec.PullRequestComment)
 		} else if spec.PullRequestApprove != nil {
-			reporter, err = handlers.BuildPullRequestApproveReporter(ctx, r.Client, sr.GetNamespace(), *spec.PullRequestApprove)
+			reporter, err = handlers.BuildPullRequestApproveReporter(ctx, r.Client, sr.GetNamespace(), r.Policy, *spec.PullRequestApprove)
 		} else if spec.PullRequestCommand != nil {
-			reporter, err = handlers.BuildPullRequestCommandHandler(ctx, r.Client, sr.GetNamespace(), *spec.PullRequestCommand)
+			reporter, err = handlers.BuildPullRequestCommandHandler(ctx, r.Client, sr.GetNamespace(), r.Policy, *spec.PullRequestCommand)
 		} else {
 			return fmt.Errorf("no reporter specified")
 		}
@@ -211,10 +226,10 @@ func (r *ObjectHandlerReconciler) doReconcile(ctx context.Context, sr *templates
 			sr.Status.HandlerStatus = append(sr.Status.HandlerStatus, status)
 		}
 
-		err = reporter.Handle(ctx, r.Client, &obj, status)
+		err = reporter.Handle(ctx, r.Client, &obj, health, status)
 		if err != nil {
 			errs = multierror.Append(errs, err)
-			status.Error = err.Error()
+			status.Error = redact.Error(ctx, err)
 		} else {
 			status.Error = ""
 		}
@@ -18,11 +18,14 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/go-multierror"
-	"github.com/kluctl/go-jinja2"
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"github.com/ohler55/ojg/jp"
 	"io"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +45,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 const forMatrixObjectKey = "spec.matrix.object.ref"
@@ -61,6 +65,9 @@ type ObjectTemplateReconciler struct {
 
 // Reconcile a resource
 func (r *ObjectTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := r.drainContext(ctx)
+	defer cancel()
+
 	logger := log.FromContext(ctx)
 
 	logger.V(1).Info("Starting reconcile")
@@ -70,6 +77,9 @@ func (r *ObjectTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	err = r.Get(ctx, req.NamespacedName, &rt)
 	if err != nil {
 		logger.Error(err, "Get failed")
+		if errors.IsNotFound(err) {
+			r.compiledTemplates.remove(fmt.Sprintf("ObjectTemplate/%s/%s", req.Namespace, req.Name))
+		}
 		err = client.IgnoreNotFound(err)
 		return
 	}
@@ -95,48 +105,66 @@ func (r *ObjectTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, nil
 	}
 
-	for _, me := range rt.Spec.Matrix {
-		if me.Object != nil {
-			gvk, err2 := me.Object.Ref.GroupVersionKind()
-			if err2 != nil {
-				err = err2
-				return
-			}
-			err = r.addWatchForKind(ctx, gvk, forMatrixObjectKey, r.buildWatchEventHandler(forMatrixObjectKey))
-			if err != nil {
-				return
-			}
-		}
+	err = r.addWatchesForSpec(ctx, &rt.Spec, r.buildWatchEventHandler)
+	if err != nil {
+		return
 	}
 
 	patch := client.MergeFrom(rt.DeepCopy())
-	err = r.doReconcile(ctx, &rt)
-	if err != nil {
-		c := metav1.Condition{
-			Type:               "Ready",
-			Status:             metav1.ConditionFalse,
-			ObservedGeneration: rt.GetGeneration(),
-			Reason:             "Error",
-			Message:            err.Error(),
-		}
-		apimeta.SetStatusCondition(&rt.Status.Conditions, c)
-	} else {
-		c := metav1.Condition{
-			Type:               "Ready",
-			Status:             metav1.ConditionTrue,
-			ObservedGeneration: rt.GetGeneration(),
-			Reason:             "Success",
-			Message:            "Success",
+	lastDriftCheckTime := rt.Status.LastDriftCheckTime
+	err = r.doReconcile(ctx, &rt, fmt.Sprintf("ObjectTemplate/%s/%s", rt.GetNamespace(), rt.GetName()))
+	return r.finishTemplateReconcile(ctx, &rt, patch, &rt.Spec, &rt.Status, lastDriftCheckTime, err)
+}
+
+// addWatchesForSpec starts watches for all kinds used as matrix inputs, for included ConfigMaps, for template sources
+// and for TemplateLibraries if referenced.
+func (r *ObjectTemplateReconciler) addWatchesForSpec(ctx context.Context, spec *templatesv1alpha1.ObjectTemplateSpec, buildHandler func(indexField string) handler.EventHandler) error {
+	for _, ref := range specObjectRefs(spec) {
+		gvk, err := ref.GroupVersionKind()
+		if err != nil {
+			return err
+		}
+		err = r.addWatchForKind(ctx, gvk, forMatrixObjectKey, buildHandler(forMatrixObjectKey))
+		if err != nil {
+			return err
 		}
-		apimeta.SetStatusCondition(&rt.Status.Conditions, c)
 	}
-	err = r.Status().Patch(ctx, &rt, patch, SubResourceFieldOwner(r.FieldManager))
-	if err != nil {
-		return
+	for _, t := range spec.Templates {
+		if t.Library != nil {
+			return r.addWatchForKind(ctx, templatesv1alpha1.GroupVersion.WithKind("TemplateLibrary"), forLibraryKey, buildHandler(forLibraryKey))
+		}
+	}
+	return nil
+}
+
+// specObjectRefs returns references to all single objects that are read by the matrix entries, included by the
+// templates or that the templates are loaded from
+func specObjectRefs(spec *templatesv1alpha1.ObjectTemplateSpec) []templatesv1alpha1.ObjectRef {
+	var ret []templatesv1alpha1.ObjectRef
+	for _, me := range spec.Matrix {
+		ret = append(ret, matrixEntryRefs(me)...)
 	}
+	ret = append(ret, templateIncludeRefs(spec.Includes)...)
+	ret = append(ret, templateVarsRefs(spec.Vars)...)
+	return append(ret, templateSourceRefs(spec.Templates)...)
+}
 
-	result.RequeueAfter = rt.Spec.Interval.Duration
-	return
+// matrixEntryRefs returns references to all single objects that are read by the given matrix entry
+func matrixEntryRefs(me *templatesv1alpha1.MatrixEntry) []templatesv1alpha1.ObjectRef {
+	var ret []templatesv1alpha1.ObjectRef
+	if me.Object != nil {
+		ret = append(ret, me.Object.Ref)
+	}
+	if me.ConfigMap != nil {
+		ret = append(ret, templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: me.ConfigMap.Namespace, Name: me.ConfigMap.Name})
+	}
+	if me.Secret != nil {
+		ret = append(ret, templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "Secret", Namespace: me.Secret.Namespace, Name: me.Secret.Name})
+	}
+	for _, nme := range nestedMatrixEntries(me) {
+		ret = append(ret, matrixEntryRefs(nme)...)
+	}
+	return ret
 }
 
 func (r *ObjectTemplateReconciler) multiplyMatrix(matrix []map[string]any, key string, newElems []any) []map[string]any {
@@ -157,156 +185,620 @@ func (r *ObjectTemplateReconciler) multiplyMatrix(matrix []map[string]any, key s
 }
 
 func (r *ObjectTemplateReconciler) buildMatrixEntries(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate, client client.Client) ([]map[string]any, error) {
-	var err error
+	return r.buildMatrix(ctx, rt, client, rt.Spec.Matrix)
+}
+
+// buildMatrix returns the cartesian product of the inputs of the given matrix entries
+func (r *ObjectTemplateReconciler) buildMatrix(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate, client client.Client, matrix []*templatesv1alpha1.MatrixEntry) ([]map[string]any, error) {
 	var matrixEntries []map[string]any
 	matrixEntries = append(matrixEntries, map[string]any{})
 
-	for _, me := range rt.Spec.Matrix {
-		var elems []any
-		if me.Object != nil {
-			elems, err = r.buildObjectInput(ctx, client, rt.GetNamespace(), me.Object.Ref, me.Object.JsonPath, me.Object.ExpandLists, false)
+	for _, me := range matrix {
+		elems, err := r.buildMatrixEntryInput(ctx, rt, client, me)
+		if err != nil {
+			return nil, err
+		}
+		matrixEntries = r.multiplyMatrix(matrixEntries, me.Name, elems)
+	}
+	return matrixEntries, nil
+}
+
+// buildMatrixEntryInput returns the list of inputs of a single matrix entry
+func (r *ObjectTemplateReconciler) buildMatrixEntryInput(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate, client client.Client, me *templatesv1alpha1.MatrixEntry) ([]any, error) {
+	var err error
+	var elems []any
+	if me.Object != nil {
+		elems, err = r.buildObjectInput(ctx, client, rt.GetNamespace(), me.Object.Ref, me.Object.JsonPath, me.Object.ExpandLists, false)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.List != nil {
+		for _, le := range me.List {
+			var e any
+			err := yaml.Unmarshal(le.Raw, &e)
 			if err != nil {
 				return nil, err
 			}
-		} else if me.List != nil {
-			for _, le := range me.List {
-				var e any
-				err := yaml.Unmarshal(le.Raw, &e)
-				if err != nil {
-					return nil, err
-				}
-				elems = append(elems, e)
-			}
-		} else {
-			return nil, fmt.Errorf("missing matrix value")
+			elems = append(elems, e)
+		}
+	} else if me.Clusters != nil {
+		elems, err = r.buildClustersInput(ctx, client, rt.GetNamespace(), me.Clusters)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.ConfigMap != nil {
+		elems, err = r.buildDataInput(ctx, client, rt.GetNamespace(), "ConfigMap", me.ConfigMap)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Secret != nil {
+		elems, err = r.buildDataInput(ctx, client, rt.GetNamespace(), "Secret", me.Secret)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Objects != nil {
+		elems, err = r.buildObjectsInput(ctx, client, rt.GetNamespace(), me.Objects)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Schedule != nil {
+		elems, err = buildScheduleInput(me.Schedule, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Plugin != nil {
+		elems, err = r.buildPluginInput(ctx, client, rt.GetNamespace(), me.Plugin)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.ImageTags != nil {
+		elems, err = r.buildImageTagsInput(ctx, client, rt.GetNamespace(), me.ImageTags)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.HTTP != nil {
+		elems, err = r.buildHTTPInput(ctx, client, rt.GetNamespace(), me.HTTP)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.GraphQL != nil {
+		elems, err = r.buildGraphQLInput(ctx, client, rt.GetNamespace(), me.GraphQL)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Vault != nil {
+		elems, err = r.buildVaultInput(ctx, client, rt.GetNamespace(), rt.Spec.ServiceAccountName, me.Vault)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.AWS != nil {
+		elems, err = r.buildAWSInput(ctx, client, rt.GetNamespace(), rt.Spec.ServiceAccountName, me.AWS)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Namespaces != nil {
+		elems, err = r.buildNamespacesInput(ctx, client, me.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Nodes != nil {
+		elems, err = r.buildNodesInput(ctx, client, me.Nodes)
+		if err != nil {
+			return nil, err
+		}
+	} else if me.Matrix != nil {
+		elems, err = r.buildNestedMatrixInput(ctx, rt, client, me.Matrix)
+		if err != nil {
+			return nil, err
 		}
+	} else if me.Merge != nil {
+		elems, err = r.buildMergeInput(ctx, rt, client, me.Merge)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("missing matrix value")
+	}
 
-		matrixEntries = r.multiplyMatrix(matrixEntries, me.Name, elems)
+	if me.Values != nil {
+		elems, err = mergeMatrixValues(me.Values, elems)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return matrixEntries, nil
+	if me.Transform != nil {
+		elems, err = transformMatrixInputs(*me.Transform, elems)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return elems, nil
 }
 
-func (r *ObjectTemplateReconciler) doReconcile(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate) error {
-	baseVars, err := r.buildBaseVars(rt, "objectTemplate")
+// doReconcile renders and applies the templates of rt. owner identifies the owning template in the provenance
+// annotations.
+func (r *ObjectTemplateReconciler) doReconcile(ctx context.Context, rt *templatesv1alpha1.ObjectTemplate, owner string) error {
+	objClient, err := r.getClientForObjects(rt.Spec.ServiceAccountName, rt.GetNamespace())
 	if err != nil {
 		return err
 	}
 
-	j2, err := NewJinja2()
-	if err != nil {
+	if !rt.Spec.DryRun {
+		rt.Status.DryRun = nil
+	}
+	rt.Status.PendingApply = nil
+
+	active, err := r.reconcileSchedule(ctx, objClient, rt, owner)
+	if err != nil || !active {
 		return err
 	}
-	defer j2.Close()
 
+	entries, err := r.renderMatrixEntries(ctx, objClient, rt, owner)
+	if err != nil {
+		return err
+	}
 	var allResources []*unstructured.Unstructured
+	for _, e := range entries {
+		allResources = append(allResources, e.objects...)
+	}
+
 	var errs *multierror.Error
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 
-	objClient, err := r.getClientForObjects(rt.Spec.ServiceAccountName, rt.GetNamespace())
+	waves, err := groupWaves(allResources)
 	if err != nil {
 		return err
 	}
 
-	matrixEntries, err := r.buildMatrixEntries(ctx, rt, objClient)
+	clusterScoped := map[*unstructured.Unstructured]bool{}
+	// unmapped contains objects of later waves with kinds that are not known yet, e.g. because the CRD is applied in
+	// an earlier wave. These are resolved right before applying them.
+	unmapped := map[*unstructured.Unstructured]bool{}
+	var unmappedErr error
+	for i, w := range waves {
+		for _, x := range w.objects {
+			cs, err := r.resolveScope(rt, x)
+			if err != nil {
+				if i != 0 && apimeta.IsNoMatchError(err) {
+					unmapped[x] = true
+					unmappedErr = err
+					continue
+				}
+				return err
+			}
+			clusterScoped[x] = cs
+		}
+	}
+
+	if rt.Spec.GitOutput != nil && rt.Spec.OCIOutput != nil {
+		return fmt.Errorf("gitOutput and ociOutput can not be combined")
+	}
+	if rt.Spec.DryRun && (rt.Spec.GitOutput != nil || rt.Spec.OCIOutput != nil) {
+		return fmt.Errorf("dryRun can not be combined with gitOutput or ociOutput")
+	}
+	if rt.Spec.GitOutput == nil {
+		rt.Status.GitOutput = nil
+	}
+	if rt.Spec.OCIOutput == nil {
+		rt.Status.OCIOutput = nil
+	}
+	if unmappedErr != nil && (rt.Spec.GitOutput != nil || rt.Spec.OCIOutput != nil) {
+		return unmappedErr
+	}
+	if rt.Spec.GitOutput != nil {
+		return r.writeGitOutput(ctx, objClient, rt, entries)
+	} else if rt.Spec.OCIOutput != nil {
+		return r.pushOCIOutput(ctx, objClient, rt, entries)
+	}
+
+	allowClusterScoped := true
+	hasClusterScoped := len(unmapped) != 0
+	for _, cs := range clusterScoped {
+		hasClusterScoped = hasClusterScoped || cs
+	}
+	if hasClusterScoped {
+		allowClusterScoped, err = r.isClusterScopedAllowed(ctx, rt.GetNamespace())
+		if err != nil {
+			return err
+		}
+	}
+	checkResource := func(resource *unstructured.Unstructured) error {
+		cs := clusterScoped[resource]
+		if unmapped[resource] {
+			var err error
+			cs, err = r.resolveScope(rt, resource)
+			if err != nil {
+				return err
+			}
+		}
+		return r.checkRenderedObject(rt, resource, cs && !allowClusterScoped)
+	}
+	reviews := newAccessReviews()
+
+	deps, err := resolveDependencies(waves)
 	if err != nil {
 		return err
 	}
 
-	wg.Add(len(matrixEntries))
-	for _, matrix := range matrixEntries {
-		matrix := matrix
-		go func() {
-			defer wg.Done()
-			vars := runtime.DeepCopyJSON(baseVars)
-			MergeMap(vars, map[string]interface{}{
-				"matrix": matrix,
-			})
+	if rt.Spec.DryRun {
+		return r.dryRun(ctx, objClient, reviews, rt, owner, allResources, checkResource)
+	}
 
-			resources, err := r.renderTemplates(j2, rt, vars)
-			mutex.Lock()
-			defer mutex.Unlock()
-			if err != nil {
-				errs = multierror.Append(errs, err)
+	now := time.Now()
+	plan := planRollout(rt, entries, now)
+	limiter := newEntryLimiter(0)
+	if rt.Spec.Rollout != nil {
+		limiter = newEntryLimiter(rt.Spec.Rollout.MaxConcurrent)
+	}
+	applyWaves := filterWaves(waves, plan.deferred)
+
+	checkDrift := isDriftCheckDue(rt, now)
+	prevAppliedResources := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
+	newAppliedResources := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
+	for _, n := range rt.Status.AppliedResources {
+		prevAppliedResources[n.Ref.WithoutVersion()] = n
+		newAppliedResources[n.Ref.WithoutVersion()] = n
+	}
+
+	// objects that wait for earlier waves or dependencies to become ready are not applied in this reconciliation,
+	// instead they are recorded in the status and the template is reconciled again later
+	var pending templatesv1alpha1.PendingApplyStatus
+	pendingRefs := map[templatesv1alpha1.ObjectRef]bool{}
+	addNotReady := func(x *unstructured.Unstructured) {
+		ref := templatesv1alpha1.ObjectRefFromObject(x)
+		s := ref.String()
+		for _, y := range pending.NotReady {
+			if y == s {
 				return
 			}
+		}
+		pending.NotReady = append(pending.NotReady, s)
+	}
 
-			allResources = append(allResources, resources...)
-		}()
+	for i, w := range applyWaves {
+		if i != 0 {
+			// later waves usually depend on objects of earlier waves, e.g. CRDs or Namespaces
+			prev := applyWaves[i-1]
+			for _, x := range prev.objects {
+				ref := templatesv1alpha1.ObjectRefFromObject(x)
+				ari, ok := newAppliedResources[ref.WithoutVersion()]
+				if pendingRefs[ref.WithoutVersion()] || !ok || !isAppliedReady(&ari) {
+					addNotReady(x)
+				}
+			}
+			if len(pending.NotReady) != 0 {
+				wave := w.wave
+				pending.Wave = &wave
+				for _, ws := range applyWaves[i:] {
+					for _, x := range ws.objects {
+						ref := templatesv1alpha1.ObjectRefFromObject(x)
+						pendingRefs[ref.WithoutVersion()] = true
+					}
+				}
+				break
+			}
+		}
+
+		states := make(map[*unstructured.Unstructured]*applyState, len(w.objects))
+		for _, resource := range w.objects {
+			states[resource] = &applyState{done: make(chan struct{})}
+		}
+
+		wg.Add(len(w.objects))
+		for _, resource := range w.objects {
+			resource := resource
+			state := states[resource]
+
+			go func() {
+				defer wg.Done()
+				defer close(state.done)
+				notReady, err := waitForDependencies(ctx, deps[resource], states)
+				ref := templatesv1alpha1.ObjectRefFromObject(resource)
+				if err == nil && len(notReady) != 0 {
+					mutex.Lock()
+					defer mutex.Unlock()
+					pending.Objects = append(pending.Objects, ref.String())
+					pendingRefs[ref.WithoutVersion()] = true
+					for _, d := range notReady {
+						addNotReady(d)
+					}
+					return
+				}
+				if err == nil {
+					err = checkResource(resource)
+				}
+				var ari templatesv1alpha1.AppliedResourceInfo
+				if err == nil {
+					var prev *templatesv1alpha1.AppliedResourceInfo
+					if p, ok := prevAppliedResources[ref.WithoutVersion()]; ok {
+						prev = &p
+					}
+					limiter.acquire(resource)
+					ari, err = r.applyObject(ctx, objClient, reviews, rt, resource, prev, checkDrift)
+					limiter.release(resource)
+				}
+				state.err = err
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if err != nil {
+					ari = templatesv1alpha1.AppliedResourceInfo{
+						Ref:   ref,
+						Error: redact.Error(ctx, err),
+					}
+					// conflicts are only recorded, so that they don't block other objects from being applied and pruned
+					ari.Conflicts = getApplyConflicts(err)
+					if ari.Conflicts == nil {
+						errs = multierror.Append(errs, err)
+					}
+				}
+				if checkDrift && ari.DriftedFields != nil {
+					recordDriftMetrics(owner, resource.GetKind(), ari.DriftedFields)
+				}
+				state.ready = err == nil && isAppliedReady(&ari)
+				newAppliedResources[ari.Ref.WithoutVersion()] = ari
+			}()
+		}
+		wg.Wait()
+
+		if errs != nil {
+			break
+		}
 	}
-	wg.Wait()
+
+	if checkDrift {
+		rt.Status.LastDriftCheckTime = &metav1.Time{Time: now}
+	} else if !isDriftDetectionEnabled(&rt.Spec) {
+		rt.Status.LastDriftCheckTime = nil
+	}
+	if len(pending.NotReady) != 0 {
+		sort.Strings(pending.Objects)
+		sort.Strings(pending.NotReady)
+		rt.Status.PendingApply = &pending
+	}
+	finishRollout(rt, plan, entries, newAppliedResources, pendingRefs)
+	defer setAppliedResources(rt, newAppliedResources)
+
 	if errs != nil {
 		return errs
 	}
+	if rt.Status.PendingApply != nil {
+		// pruning is postponed until all rendered objects were applied
+		return nil
+	}
 
-	for _, x := range allResources {
-		rm, err := r.Client.RESTMapper().RESTMapping(x.GroupVersionKind().GroupKind(), x.GroupVersionKind().Version)
+	if rt.Spec.Prune {
+		err = r.collectOwnedObjects(ctx, objClient, rt.GetNamespace(), owner, allResources, newAppliedResources)
+		if err != nil {
+			// listing requires additional permissions, which not all service accounts have
+			log.FromContext(ctx).Info("Failed to collect owned objects by label", "error", err.Error())
+		}
+		err = r.prune(ctx, objClient, owner, allResources, newAppliedResources)
 		if err != nil {
 			return err
 		}
-		if rm.Scope.Name() == apimeta.RESTScopeNameNamespace && x.GetNamespace() == "" {
-			x.SetNamespace(rt.Namespace)
+	}
+
+	return nil
+}
+
+func setAppliedResources(rt *templatesv1alpha1.ObjectTemplate, appliedResources map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo) {
+	rt.Status.AppliedResources = make([]templatesv1alpha1.AppliedResourceInfo, 0, len(appliedResources))
+	for _, ari := range appliedResources {
+		rt.Status.AppliedResources = append(rt.Status.AppliedResources, ari)
+	}
+	sort.Slice(rt.Status.AppliedResources, func(i, j int) bool {
+		return rt.Status.AppliedResources[i].Ref.String() < rt.Status.AppliedResources[j].Ref.String()
+	})
+}
+
+// renderedMatrixEntry holds the objects rendered for a single matrix entry
+type renderedMatrixEntry struct {
+	matrixKey string
+	// outputName is the rendered spec.gitOutput.elementPath or spec.ociOutput.tag, or empty if not specified
+	outputName string
+	objects    []*unstructured.Unstructured
+}
+
+// renderObjects renders the templates of rt for all matrix entries. Matrix inputs and libraries are read through
+// objClient.
+func (r *ObjectTemplateReconciler) renderObjects(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, owner string) ([]*unstructured.Unstructured, error) {
+	entries, err := r.renderMatrixEntries(ctx, objClient, rt, owner)
+	if err != nil {
+		return nil, err
+	}
+	var allResources []*unstructured.Unstructured
+	for _, e := range entries {
+		allResources = append(allResources, e.objects...)
+	}
+	return allResources, nil
+}
+
+// renderMatrixEntries renders the templates of rt and returns the rendered objects grouped by matrix entry.
+func (r *ObjectTemplateReconciler) renderMatrixEntries(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, owner string) ([]renderedMatrixEntry, error) {
+	baseVars, err := r.buildBaseVars(rt, "objectTemplate")
+	if err != nil {
+		return nil, err
+	}
+
+	decryptor, err := r.buildSOPSDecryptor(ctx, objClient, rt.GetNamespace(), rt.Spec.Decryption)
+	if err != nil {
+		return nil, err
+	}
+
+	templateVars, err := r.loadTemplateVars(ctx, objClient, rt.GetNamespace(), rt.Spec.Vars)
+	if err != nil {
+		return nil, err
+	}
+	if decryptor != nil {
+		x, err := decryptor.decryptValues(ctx, templateVars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt vars: %w", err)
 		}
+		templateVars = x.(map[string]any)
 	}
+	baseVars["vars"] = templateVars
 
-	newAppliedResources := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
-	for _, n := range rt.Status.AppliedResources {
-		newAppliedResources[n.Ref.WithoutVersion()] = n
+	includes, err := r.loadTemplateIncludes(ctx, objClient, rt.GetNamespace(), rt.Spec.Includes)
+	if err != nil {
+		return nil, err
+	}
+	lookup := r.buildTemplateLookupFunc(ctx, objClient, rt.GetNamespace())
+	engine, err := newTemplateEngine(rt.Spec.TemplateEngine, templateEngineOptions{
+		includes:   includes,
+		lookup:     lookup,
+		strict:     rt.Spec.Strict,
+		compiled:   r.compiledTemplates.forObject(owner, rt.GetUID(), rt.GetGeneration(), includes),
+		delimiters: rt.Spec.Delimiters,
+	})
+	if err != nil {
+		return nil, err
 	}
+	defer engine.Close()
 
-	wg.Add(len(allResources))
-	for _, resource := range allResources {
-		resource := resource
+	matrixEntries, err := r.buildMatrixEntries(ctx, rt, objClient)
+	if err != nil {
+		return nil, err
+	}
+	if decryptor != nil {
+		for i, m := range matrixEntries {
+			x, err := decryptor.decryptValues(ctx, m)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt matrix inputs: %w", err)
+			}
+			matrixEntries[i] = x.(map[string]any)
+		}
+	}
+
+	templates, err := r.resolveTemplates(ctx, objClient, rt.GetNamespace(), rt.Spec.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []renderedMatrixEntry
+	var errs *multierror.Error
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
 
+	wg.Add(len(matrixEntries))
+	for _, matrix := range matrixEntries {
+		matrix := matrix
 		go func() {
 			defer wg.Done()
-			err := r.applyRenderedObject(ctx, objClient, resource)
-			mutex.Lock()
-			defer mutex.Unlock()
+			vars := runtime.DeepCopyJSON(baseVars)
+			MergeMap(vars, map[string]interface{}{
+				"matrix": matrix,
+			})
 
-			ari := templatesv1alpha1.AppliedResourceInfo{
-				Ref:     templatesv1alpha1.ObjectRefFromObject(resource),
-				Success: true,
+			var e renderedMatrixEntry
+			var err error
+			e.matrixKey, err = buildMatrixKey(matrix)
+			if err == nil {
+				e.objects, err = r.renderTemplates(engine, templates, vars)
 			}
-
+			if err == nil {
+				err = r.addProvenance(owner, e.matrixKey, e.objects)
+			}
+			if err == nil {
+				e.outputName, err = renderOutputName(engine, rt, vars)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
 			if err != nil {
-				ari.Success = false
-				ari.Error = err.Error()
 				errs = multierror.Append(errs, err)
+				return
 			}
-			newAppliedResources[ari.Ref.WithoutVersion()] = ari
+
+			entries = append(entries, e)
 		}()
 	}
 	wg.Wait()
+	if errs != nil {
+		return nil, errs
+	}
+	return entries, nil
+}
 
-	defer func() {
-		rt.Status.AppliedResources = make([]templatesv1alpha1.AppliedResourceInfo, 0, len(newAppliedResources))
-		for _, ari := range newAppliedResources {
-			rt.Status.AppliedResources = append(rt.Status.AppliedResources, ari)
-		}
-		sort.Slice(rt.Status.AppliedResources, func(i, j int) bool {
-			return rt.Status.AppliedResources[i].Ref.String() < rt.Status.AppliedResources[j].Ref.String()
-		})
-	}()
+// RenderObjectTemplate renders the given ObjectTemplate without applying the results. Matrix inputs and libraries are
+// read through c. This is used by the render command to validate templates outside the cluster.
+func RenderObjectTemplate(ctx context.Context, c client.Client, rt *templatesv1alpha1.ObjectTemplate) ([]*unstructured.Unstructured, error) {
+	r := &ObjectTemplateReconciler{}
+	return r.renderObjects(ctx, c, rt, fmt.Sprintf("ObjectTemplate/%s/%s", rt.GetNamespace(), rt.GetName()))
+}
 
-	if errs != nil {
-		return errs
+// isClusterScopedAllowed checks if templates in the given namespace may apply cluster-scoped objects. This is only
+// the case if allowed via policy or if the namespace is annotated by a cluster admin.
+func (r *ObjectTemplateReconciler) isClusterScopedAllowed(ctx context.Context, namespace string) (bool, error) {
+	if r.Policy == nil || r.Policy.AllowClusterScopedObjects {
+		return true, nil
 	}
 
-	err = r.prune(ctx, objClient, rt, allResources, newAppliedResources)
+	var ns corev1.Namespace
+	err := r.Client.Get(ctx, types.NamespacedName{Name: namespace}, &ns)
 	if err != nil {
-		return err
+		return false, err
 	}
+	return ns.GetAnnotations()[templatesv1alpha1.AllowClusterScopedObjectsAnnotation] == "true", nil
+}
 
-	return nil
+// renderOutputName renders the template that names the output location of a single matrix entry when the rendered
+// objects are written to Git or pushed to an OCI repository
+func renderOutputName(engine templateEngine, rt *templatesv1alpha1.ObjectTemplate, vars map[string]any) (string, error) {
+	var t string
+	if rt.Spec.GitOutput != nil {
+		t = rt.Spec.GitOutput.ElementPath
+	} else if rt.Spec.OCIOutput != nil {
+		t = rt.Spec.OCIOutput.Tag
+	}
+	if t == "" {
+		return "", nil
+	}
+	return engine.RenderString(t, vars)
 }
 
-func (r *ObjectTemplateReconciler) prune(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, allResources []*unstructured.Unstructured, appliedResources map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo) error {
-	logger := log.FromContext(ctx)
+// buildMatrixKey returns a hash that identifies the given matrix entry
+func buildMatrixKey(matrix map[string]any) (string, error) {
+	matrixJson, err := json.Marshal(matrix)
+	if err != nil {
+		return "", err
+	}
+	return Sha256Bytes(matrixJson), nil
+}
 
-	if !rt.Spec.Prune {
-		return nil
+// addProvenance stamps the rendered objects with annotations that allow to trace them back to the owning template
+// and matrix entry. The owner labels additionally allow to find the objects of a template via label selectors.
+func (r *ObjectTemplateReconciler) addProvenance(owner string, matrixKey string, resources []*unstructured.Unstructured) error {
+	for _, x := range resources {
+		renderedJson, err := json.Marshal(x.Object)
+		if err != nil {
+			return err
+		}
+		a := x.GetAnnotations()
+		if a == nil {
+			a = map[string]string{}
+		}
+		a[templatesv1alpha1.TemplateAnnotation] = owner
+		a[templatesv1alpha1.MatrixKeyAnnotation] = matrixKey
+		a[templatesv1alpha1.RenderedHashAnnotation] = Sha256Bytes(renderedJson)
+		x.SetAnnotations(a)
+
+		l := x.GetLabels()
+		if l == nil {
+			l = map[string]string{}
+		}
+		l[templatesv1alpha1.OwnerLabel] = ownerLabelValue(owner)
+		l[templatesv1alpha1.MatrixKeyLabel] = matrixKey[:32]
+		x.SetLabels(l)
 	}
+	return nil
+}
+
+// prune deletes all objects from appliedResources that are not part of allResources anymore. Objects that got adopted
+// by another template in the meantime (as indicated by their provenance annotation) are only removed from
+// appliedResources, but not deleted.
+func (r *ObjectTemplateReconciler) prune(ctx context.Context, objClient client.Client, owner string, allResources []*unstructured.Unstructured, appliedResources map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo) error {
+	logger := log.FromContext(ctx)
 
 	var errs *multierror.Error
 	var wg sync.WaitGroup
@@ -325,8 +817,6 @@ func (r *ObjectTemplateReconciler) prune(ctx context.Context, objClient client.C
 			continue
 		}
 
-		logger.Info("Deleting object", "ref", ari.Ref)
-
 		gvk, err := ari.Ref.GroupVersionKind()
 		if err != nil {
 			return err
@@ -339,7 +829,18 @@ func (r *ObjectTemplateReconciler) prune(ctx context.Context, objClient client.C
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			err := objClient.Delete(ctx, &m)
+			err := objClient.Get(ctx, client.ObjectKeyFromObject(&m), &m)
+			if err == nil {
+				if a, ok := m.GetAnnotations()[templatesv1alpha1.TemplateAnnotation]; ok && a != owner {
+					logger.Info("Not deleting object owned by another template", "ref", ari.Ref, "owner", a)
+				} else if isPruneDisabled(&m) {
+					// the object is orphaned by removing it from the applied resources below
+					logger.Info("Not deleting object with disabled pruning", "ref", ari.Ref)
+				} else {
+					logger.Info("Deleting object", "ref", ari.Ref)
+					err = objClient.Delete(ctx, &m, client.Preconditions{UID: &m.UID})
+				}
+			}
 			mutex.Lock()
 			defer mutex.Unlock()
 			if err != nil {
@@ -363,7 +864,43 @@ func (r *ObjectTemplateReconciler) prune(ctx context.Context, objClient client.C
 	return errs.ErrorOrNil()
 }
 
-func (r *ObjectTemplateReconciler) applyRenderedObject(ctx context.Context, objClient client.Client, rendered *unstructured.Unstructured) error {
+// isPruneDisabled returns true if the object must never be deleted by the controller, see PruneAnnotation
+func isPruneDisabled(o metav1.Object) bool {
+	return o.GetAnnotations()[templatesv1alpha1.PruneAnnotation] == templatesv1alpha1.PruneDisabled
+}
+
+// checkRenderedObject verifies that the policy allows rt to apply the rendered object. denyClusterScoped must be true if
+// the object is cluster-scoped and the namespace of rt is not allowed to apply cluster-scoped objects.
+func (r *ObjectTemplateReconciler) checkRenderedObject(rt *templatesv1alpha1.ObjectTemplate, resource *unstructured.Unstructured, denyClusterScoped bool) error {
+	err := r.Policy.CheckTargetNamespace(rt.GetNamespace(), resource.GetNamespace())
+	if err != nil {
+		return err
+	}
+	if denyClusterScoped {
+		return fmt.Errorf("cluster-scoped objects are not allowed for templates in namespace %s", rt.GetNamespace())
+	}
+	return r.Policy.CheckKind(resource.GroupVersionKind().GroupKind())
+}
+
+// getFieldManager returns the field manager to use when applying the rendered objects of rt
+func (r *ObjectTemplateReconciler) getFieldManager(rt *templatesv1alpha1.ObjectTemplate) string {
+	if rt.Spec.FieldManager != "" {
+		return rt.Spec.FieldManager
+	}
+	return r.FieldManager
+}
+
+// getApplyOptions returns the options for server-side applying rendered objects of rt. Ownership of conflicting fields
+// is taken if either force or spec.force is set.
+func (r *ObjectTemplateReconciler) getApplyOptions(rt *templatesv1alpha1.ObjectTemplate, force bool) []client.PatchOption {
+	opts := []client.PatchOption{client.FieldOwner(r.getFieldManager(rt))}
+	if force || rt.Spec.Force {
+		opts = append(opts, client.ForceOwnership)
+	}
+	return opts
+}
+
+func (r *ObjectTemplateReconciler) applyRenderedObject(ctx context.Context, objClient client.Client, reviews *accessReviews, rt *templatesv1alpha1.ObjectTemplate, rendered *unstructured.Unstructured, force bool) error {
 	logger := log.FromContext(ctx)
 
 	var origMeta metav1.PartialObjectMetadata
@@ -375,13 +912,34 @@ func (r *ObjectTemplateReconciler) applyRenderedObject(ctx context.Context, objC
 		origObjFound = true
 	}
 
-	err = objClient.Patch(ctx, rendered, client.Apply, client.FieldOwner(r.FieldManager))
+	adopted := false
+	if origObjFound {
+		adopted, err = checkAdoption(rt, rendered, &origMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	verb := "patch"
+	if !origObjFound {
+		verb = "create"
+	}
+	err = r.checkAccess(ctx, objClient, reviews, rt, rendered, verb)
+	if err != nil {
+		return err
+	}
+
+	// server-side apply without forcing ownership (unless requested), so that fields managed by other managers are
+	// preserved and conflicting changes are reported instead of being overwritten
+	err = objClient.Patch(ctx, rendered, client.Apply, r.getApplyOptions(rt, force)...)
 	if err != nil {
 		return err
 	}
 
 	if !origObjFound {
 		logger.Info("Created new object", "ref", templatesv1alpha1.ObjectRefFromObject(rendered))
+	} else if adopted {
+		logger.Info("Adopted existing object", "ref", templatesv1alpha1.ObjectRefFromObject(rendered))
 	} else {
 		if origMeta.GetResourceVersion() != rendered.GetResourceVersion() {
 			logger.Info("Updated existing object", "ref", templatesv1alpha1.ObjectRefFromObject(rendered))
@@ -391,36 +949,73 @@ func (r *ObjectTemplateReconciler) applyRenderedObject(ctx context.Context, objC
 	return nil
 }
 
-func (r *ObjectTemplateReconciler) renderTemplates(j2 *jinja2.Jinja2, rt *templatesv1alpha1.ObjectTemplate, vars map[string]any) ([]*unstructured.Unstructured, error) {
+func (r *ObjectTemplateReconciler) renderTemplates(engine templateEngine, templates []resolvedTemplate, vars map[string]any) ([]*unstructured.Unstructured, error) {
 	var ret []*unstructured.Unstructured
-	for _, t := range rt.Spec.Templates {
-		if t.Object != nil {
-			x := t.Object.DeepCopy()
-			_, err := j2.RenderStruct(x, jinja2.WithGlobals(vars))
+	for _, t := range templates {
+		if t.ForEach == nil {
+			objs, err := r.renderTemplate(engine, t.Template, vars)
 			if err != nil {
-				return nil, err
+				return nil, newTemplateError(t.index, &t.Template, t.lineOffset, err)
 			}
-			ret = append(ret, x)
-		} else if t.Raw != nil {
-			r, err := j2.RenderString(*t.Raw, jinja2.WithGlobals(vars))
+			ret = append(ret, objs...)
+			continue
+		}
+
+		path, err := jp.ParseString(*t.ForEach)
+		if err != nil {
+			return nil, newTemplateError(t.index, &t.Template, 0, fmt.Errorf("invalid forEach: %w", err))
+		}
+		for _, each := range expandJsonPathResults(vars, path) {
+			eachVars := make(map[string]any, len(vars)+1)
+			for k, v := range vars {
+				eachVars[k] = v
+			}
+			eachVars["each"] = each
+
+			objs, err := r.renderTemplate(engine, t.Template, eachVars)
 			if err != nil {
-				return nil, err
+				return nil, newTemplateError(t.index, &t.Template, t.lineOffset, err)
 			}
-			d := yaml.NewYAMLToJSONDecoder(strings.NewReader(r))
-			for {
-				var u unstructured.Unstructured
-				err = d.Decode(&u)
-				if err != nil {
-					if err == io.EOF {
-						break
-					}
-					return nil, err
-				}
-				ret = append(ret, &u)
+			ret = append(ret, objs...)
+		}
+	}
+	return ret, nil
+}
+
+// renderTemplate renders a single template
+func (r *ObjectTemplateReconciler) renderTemplate(engine templateEngine, t templatesv1alpha1.Template, vars map[string]any) ([]*unstructured.Unstructured, error) {
+	if t.Object != nil {
+		x := t.Object.DeepCopy()
+		err := engine.RenderObject(x, vars)
+		if err != nil {
+			return nil, err
+		}
+		return []*unstructured.Unstructured{x}, nil
+	}
+	if t.Raw == nil {
+		return nil, fmt.Errorf("no template specified")
+	}
+
+	rendered, err := engine.RenderString(*t.Raw, vars)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*unstructured.Unstructured
+	d := yaml.NewYAMLToJSONDecoder(strings.NewReader(rendered))
+	for {
+		var u unstructured.Unstructured
+		err = d.Decode(&u)
+		if err != nil {
+			if err == io.EOF {
+				break
 			}
-		} else {
-			return nil, fmt.Errorf("no template specified")
+			return nil, fmt.Errorf("failed to parse rendered raw template: %w", err)
 		}
+		if len(u.Object) == 0 {
+			// documents can end up empty when control structures render nothing
+			continue
+		}
+		ret = append(ret, &u)
 	}
 	return ret, nil
 }
@@ -434,24 +1029,29 @@ func (r *ObjectTemplateReconciler) SetupWithManager(mgr ctrl.Manager, concurrent
 		func(object client.Object) []string {
 			o := object.(*templatesv1alpha1.ObjectTemplate)
 			var ret []string
-			for _, me := range o.Spec.Matrix {
-				if me.Object != nil {
-					ret = append(ret, BuildRefIndexValue(me.Object.Ref, o.GetNamespace()))
-				}
+			for _, ref := range specObjectRefs(&o.Spec) {
+				ret = append(ret, BuildRefIndexValue(ref, o.GetNamespace()))
 			}
 			return ret
 		}); err != nil {
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
+	if err := mgr.GetCache().IndexField(context.TODO(), &templatesv1alpha1.ObjectTemplate{}, forLibraryKey,
+		func(object client.Object) []string {
+			o := object.(*templatesv1alpha1.ObjectTemplate)
+			return buildLibraryIndexValues(o.Spec.Templates, o.GetNamespace())
+		}); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
 
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&templatesv1alpha1.ObjectTemplate{}, builder.WithPredicates(
-			predicate.Or(predicate.GenerationChangedPredicate{}),
+			predicate.Or(predicate.GenerationChangedPredicate{}, ReconcileRequestedPredicate{}),
 		)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: concurrent,
 		}).
-		Build(r)
+		Build(redact.Reconciler(r))
 	if err != nil {
 		return err
 	}
@@ -484,7 +1084,11 @@ func (r *ObjectTemplateReconciler) buildWatchEventHandler(indexField string) han
 }
 
 func (r *ObjectTemplateReconciler) finalize(ctx context.Context, obj *templatesv1alpha1.ObjectTemplate) (ctrl.Result, error) {
-	r.doFinalize(ctx, obj)
+	err := r.finalizeAppliedObjects(ctx, obj, obj, fmt.Sprintf("ObjectTemplate/%s/%s", obj.GetNamespace(), obj.GetName()))
+	if err != nil {
+		// keep the finalizer so that deletion is retried
+		return ctrl.Result{}, err
+	}
 
 	// Remove our finalizer from the list and update it
 	controllerutil.RemoveFinalizer(obj, templatesv1alpha1.ObjectTemplateFinalizer)
@@ -496,41 +1100,150 @@ func (r *ObjectTemplateReconciler) finalize(ctx context.Context, obj *templatesv
 	return ctrl.Result{}, nil
 }
 
-func (r *ObjectTemplateReconciler) doFinalize(ctx context.Context, obj *templatesv1alpha1.ObjectTemplate) {
-	log := ctrl.LoggerFrom(ctx)
+// shouldDeleteOnFinalize returns true if the applied objects must be deleted when the template gets deleted. Objects are
+// only deleted with the Delete deletion policy, and never by suspended and dry-run templates.
+func shouldDeleteOnFinalize(spec *templatesv1alpha1.ObjectTemplateSpec) bool {
+	if spec.Suspend || spec.DryRun {
+		return false
+	}
+	return spec.DeletionPolicy == templatesv1alpha1.DeletionPolicyDelete
+}
 
-	if !obj.Spec.Prune || obj.Spec.Suspend {
-		return
+const objectsOrphanedReason = "ObjectsOrphaned"
+
+// orphanedObjectsError is returned by doFinalize if applied objects can not be deleted anymore, e.g. because the
+// service account or its RoleBindings were deleted before the template while the whole namespace is being deleted.
+// Retrying would never succeed, so the remaining objects are orphaned instead.
+type orphanedObjectsError struct {
+	err error
+}
+
+func (e *orphanedObjectsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *orphanedObjectsError) Unwrap() error {
+	return e.err
+}
+
+// isPermanentDeletionError returns true if err indicates that the impersonated service account is not able to delete
+// objects anymore
+func isPermanentDeletionError(err error) bool {
+	return errors.IsForbidden(err) || errors.IsUnauthorized(err)
+}
+
+// finalizeAppliedObjects calls doFinalize and returns an error if the finalizer must be kept so that deletion is
+// retried. If the applied objects can not be deleted anymore, a warning Event is emitted on eventObj and no error is
+// returned, so that the template (and its namespace) does not get stuck in deletion.
+func (r *ObjectTemplateReconciler) finalizeAppliedObjects(ctx context.Context, eventObj client.Object, obj *templatesv1alpha1.ObjectTemplate, owner string) error {
+	err := r.doFinalize(ctx, obj, owner)
+	orphanedErr, ok := err.(*orphanedObjectsError)
+	if !ok {
+		return err
+	}
+
+	ctrl.LoggerFrom(ctx).Info("Orphaning applied objects that can not be deleted", "error", orphanedErr.Error())
+	if recorder := r.eventRecorder(ctx); recorder != nil {
+		recorder.Eventf(eventObj, corev1.EventTypeWarning, objectsOrphanedReason, "Orphaning applied objects that can not be deleted: %s", orphanedErr.Error())
+	}
+	return nil
+}
+
+// doFinalize deletes the applied objects of obj if required by its deletion policy. Objects that were taken over by
+// another template or that have pruning disabled are left untouched. Errors are returned so that the finalizer is only
+// removed after all objects have been deleted, except for errors that can't be resolved by retrying, which are
+// returned as orphanedObjectsError.
+func (r *ObjectTemplateReconciler) doFinalize(ctx context.Context, obj *templatesv1alpha1.ObjectTemplate, owner string) error {
+	if !shouldDeleteOnFinalize(&obj.Spec) {
+		return nil
+	}
+
+	saName := obj.Spec.ServiceAccountName
+	if saName == "" {
+		saName = "default"
+	}
+	var sa metav1.PartialObjectMetadata
+	sa.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ServiceAccount"))
+	err := r.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: saName}, &sa)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &orphanedObjectsError{err: fmt.Errorf("service account %s/%s does not exist anymore", obj.GetNamespace(), saName)}
+		}
+		return err
 	}
 
 	objClient, err := r.getClientForObjects(obj.Spec.ServiceAccountName, obj.GetNamespace())
 	if err != nil {
-		log.Error(err, "Failed to create objClient for deletion")
-		return
+		return &orphanedObjectsError{err: fmt.Errorf("failed to create client for deletion: %w", err)}
 	}
 
-	var wg sync.WaitGroup
+	appliedResources := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
 	for _, ar := range obj.Status.AppliedResources {
+		appliedResources[ar.Ref.WithoutVersion()] = ar
+	}
+	err = r.collectOwnedObjects(ctx, objClient, obj.GetNamespace(), owner, nil, appliedResources)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Info("Failed to collect owned objects by label", "error", err.Error())
+	}
+
+	return deleteAppliedObjects(ctx, objClient, owner, appliedResources)
+}
+
+// deleteAppliedObjects deletes the given applied objects with objClient, see doFinalize
+func deleteAppliedObjects(ctx context.Context, objClient client.Client, owner string, appliedResources map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var errs *multierror.Error
+	var orphaned *multierror.Error
+	for _, ar := range appliedResources {
 		ar := ar
+		gvk, err := ar.Ref.GroupVersionKind()
+		if err != nil {
+			log.Error(err, "Invalid applied object ref, skipping deletion", "ref", ar.Ref)
+			continue
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			gvk, err := ar.Ref.GroupVersionKind()
-			if err != nil {
-				return
-			}
 
-			log.Info("Deleting applied object", "ref", ar.Ref)
-
-			var o unstructured.Unstructured
+			var o metav1.PartialObjectMetadata
 			o.SetGroupVersionKind(gvk)
 			o.SetName(ar.Ref.Name)
 			o.SetNamespace(ar.Ref.Namespace)
-			err = objClient.Delete(ctx, &o)
+			err := objClient.Get(ctx, client.ObjectKeyFromObject(&o), &o)
+			if err == nil {
+				if a, ok := o.GetAnnotations()[templatesv1alpha1.TemplateAnnotation]; ok && a != owner {
+					log.Info("Not deleting applied object owned by another template", "ref", ar.Ref, "owner", a)
+					return
+				}
+				if isPruneDisabled(&o) {
+					log.Info("Not deleting applied object with disabled pruning", "ref", ar.Ref)
+					return
+				}
+				log.Info("Deleting applied object", "ref", ar.Ref)
+				err = objClient.Delete(ctx, &o, client.Preconditions{UID: &o.UID})
+			}
 			if err != nil && !errors.IsNotFound(err) {
-				log.Error(err, "Failed to delete applied object", "ref", ar.Ref)
+				mutex.Lock()
+				defer mutex.Unlock()
+				if isPermanentDeletionError(err) {
+					orphaned = multierror.Append(orphaned, fmt.Errorf("failed to delete %s: %w", ar.Ref.String(), err))
+				} else {
+					errs = multierror.Append(errs, fmt.Errorf("failed to delete %s: %w", ar.Ref.String(), err))
+				}
 			}
 		}()
 	}
 	wg.Wait()
+
+	if errs != nil {
+		return errs.ErrorOrNil()
+	}
+	if orphaned != nil {
+		return &orphanedObjectsError{err: orphaned.ErrorOrNil()}
+	}
+	return nil
 }
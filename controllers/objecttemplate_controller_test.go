@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const testOwner = "ObjectTemplate/default/example"
+
+func newAppliedConfigMap(name string, annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: annotations,
+		},
+	}
+}
+
+func appliedConfigMaps(names ...string) map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo {
+	ret := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
+	for _, n := range names {
+		ref := templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: n}
+		ret[ref.WithoutVersion()] = templatesv1alpha1.AppliedResourceInfo{Ref: ref}
+	}
+	return ret
+}
+
+func TestShouldDeleteOnFinalize(t *testing.T) {
+	tests := []struct {
+		name string
+		spec templatesv1alpha1.ObjectTemplateSpec
+		want bool
+	}{
+		// templates created before deletionPolicy existed must not start deleting their objects
+		{name: "unset", spec: templatesv1alpha1.ObjectTemplateSpec{}},
+		{name: "unset with prune", spec: templatesv1alpha1.ObjectTemplateSpec{Prune: true}},
+		{name: "orphan", spec: templatesv1alpha1.ObjectTemplateSpec{DeletionPolicy: templatesv1alpha1.DeletionPolicyOrphan, Prune: true}},
+		{name: "delete", spec: templatesv1alpha1.ObjectTemplateSpec{DeletionPolicy: templatesv1alpha1.DeletionPolicyDelete}, want: true},
+		{name: "delete suspended", spec: templatesv1alpha1.ObjectTemplateSpec{DeletionPolicy: templatesv1alpha1.DeletionPolicyDelete, Suspend: true}},
+		{name: "delete dry-run", spec: templatesv1alpha1.ObjectTemplateSpec{DeletionPolicy: templatesv1alpha1.DeletionPolicyDelete, DryRun: true}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(shouldDeleteOnFinalize(&tc.spec)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestDeleteAppliedObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	c := fake.NewClientBuilder().WithObjects(
+		newAppliedConfigMap("owned", map[string]string{templatesv1alpha1.TemplateAnnotation: testOwner}),
+		newAppliedConfigMap("unannotated", nil),
+		newAppliedConfigMap("taken-over", map[string]string{templatesv1alpha1.TemplateAnnotation: "ObjectTemplate/default/other"}),
+		newAppliedConfigMap("protected", map[string]string{
+			templatesv1alpha1.TemplateAnnotation: testOwner,
+			templatesv1alpha1.PruneAnnotation:    templatesv1alpha1.PruneDisabled,
+		}),
+	).Build()
+
+	err := deleteAppliedObjects(context.Background(), c, testOwner, appliedConfigMaps("owned", "unannotated", "taken-over", "protected", "missing"))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var list corev1.ConfigMapList
+	g.Expect(c.List(context.Background(), &list)).To(Succeed())
+	var names []string
+	for _, cm := range list.Items {
+		names = append(names, cm.Name)
+	}
+	g.Expect(names).To(ConsistOf("taken-over", "protected"))
+}
+
+func TestDeleteAppliedObjectsErrors(t *testing.T) {
+	gr := schema.GroupResource{Resource: "configmaps"}
+
+	tests := []struct {
+		name     string
+		err      error
+		orphaned bool
+	}{
+		{name: "forbidden", err: apierrors.NewForbidden(gr, "a", nil), orphaned: true},
+		{name: "unauthorized", err: apierrors.NewUnauthorized("expired"), orphaned: true},
+		{name: "transient", err: apierrors.NewInternalError(context.DeadlineExceeded)},
+		{name: "conflict", err: apierrors.NewConflict(gr, "a", nil)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().WithObjects(
+				newAppliedConfigMap("a", nil),
+			).WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					return tc.err
+				},
+			}).Build()
+
+			err := deleteAppliedObjects(context.Background(), c, testOwner, appliedConfigMaps("a"))
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring("failed to delete"))
+			_, orphaned := err.(*orphanedObjectsError)
+			g.Expect(orphaned).To(Equal(tc.orphaned))
+		})
+	}
+}
+
+func TestFinalizeWithoutServiceAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &ObjectTemplateReconciler{}
+	r.Client = fake.NewClientBuilder().Build()
+
+	rt := &templatesv1alpha1.ObjectTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example"},
+		Spec: templatesv1alpha1.ObjectTemplateSpec{
+			ServiceAccountName: "deleted",
+			DeletionPolicy:     templatesv1alpha1.DeletionPolicyDelete,
+		},
+	}
+
+	err := r.doFinalize(context.Background(), rt, testOwner)
+	g.Expect(err).To(BeAssignableToTypeOf(&orphanedObjectsError{}))
+	g.Expect(err.Error()).To(ContainSubstring("service account default/deleted does not exist anymore"))
+
+	// the finalizer must be removed even though nothing could be deleted
+	g.Expect(r.finalizeAppliedObjects(context.Background(), rt, rt, testOwner)).To(Succeed())
+}
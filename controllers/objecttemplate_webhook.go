@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"github.com/kluctl/go-jinja2"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/ohler55/ojg/jp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// ObjectTemplateValidator rejects ObjectTemplates and ClusterObjectTemplates with broken templates at apply time. It
+// performs a dry-run render of all inline templates with synthetic variables, in which every matrix entry, the vars
+// and the forEach item are empty. As the synthetic variables can not satisfy all templates, only syntax errors cause a
+// rejection, while all other rendering errors are left to the controller.
+// Templates from libraries, GitRepositories and OCI artifacts are not validated.
+//
+// If Policy is set, templates that would violate it for sure are rejected as well, see checkPolicy.
+type ObjectTemplateValidator struct {
+	Policy *policy.Policy
+
+	mutex sync.Mutex
+	// the Jinja2 engine is expensive to create, so it is shared between all admission requests
+	jinja2 *jinja2Engine
+}
+
+// SetupWebhookWithManager registers the validating webhooks for ObjectTemplate and ClusterObjectTemplate
+func (v *ObjectTemplateValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&templatesv1alpha1.ObjectTemplate{}).
+		WithValidator(v).
+		Complete()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&templatesv1alpha1.ClusterObjectTemplate{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *ObjectTemplateValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+func (v *ObjectTemplateValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+func (v *ObjectTemplateValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ObjectTemplateValidator) validate(obj runtime.Object) error {
+	var spec *templatesv1alpha1.ObjectTemplateSpec
+	var namespace string
+	p := v.Policy
+	switch x := obj.(type) {
+	case *templatesv1alpha1.ObjectTemplate:
+		spec = &x.Spec
+		namespace = x.Namespace
+	case *templatesv1alpha1.ClusterObjectTemplate:
+		spec = &x.Spec.ObjectTemplateSpec
+		namespace = x.Spec.ServiceAccountNamespace
+		p = p.ForClusterTemplates()
+	default:
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	matrix := map[string]any{}
+	for _, me := range spec.Matrix {
+		matrix[me.Name] = map[string]any{}
+	}
+	vars := map[string]any{
+		// ClusterObjectTemplates are rendered as ObjectTemplates as well, see ClusterObjectTemplateReconciler.toObjectTemplate
+		"objectTemplate": u,
+		"matrix":         matrix,
+		"vars":           map[string]any{},
+	}
+
+	engine, err := v.getEngine(spec.TemplateEngine, spec.Delimiters)
+	if err != nil {
+		return err
+	}
+
+	var errs *multierror.Error
+	for _, ref := range specObjectRefs(spec) {
+		err = checkRefPolicy(p, namespace, ref)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	for i, t := range spec.Templates {
+		tvars := vars
+		if t.ForEach != nil {
+			_, err = jp.ParseString(*t.ForEach)
+			if err != nil {
+				errs = multierror.Append(errs, newTemplateError(i, &t, 0, fmt.Errorf("invalid forEach: %w", err)))
+				continue
+			}
+			tvars = make(map[string]any, len(vars)+1)
+			for k, x := range vars {
+				tvars[k] = x
+			}
+			tvars["each"] = map[string]any{}
+		}
+
+		if t.Object != nil {
+			rendered := t.Object.DeepCopy()
+			err = engine.RenderObject(rendered, tvars)
+			if err == nil {
+				err = checkObjectPolicy(p, namespace, t.Object, rendered)
+				if err != nil {
+					errs = multierror.Append(errs, newTemplateError(i, &t, 0, err))
+				}
+				continue
+			}
+		} else if t.Raw != nil {
+			_, err = engine.RenderString(*t.Raw, tvars)
+		} else {
+			continue
+		}
+		if err != nil && isTemplateSyntaxError(err) {
+			errs = multierror.Append(errs, newTemplateError(i, &t, 0, err))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// checkRefPolicy verifies that the policy allows the template to read the referenced object
+func checkRefPolicy(p *policy.Policy, namespace string, ref templatesv1alpha1.ObjectRef) error {
+	refNamespace := namespace
+	if ref.Namespace != "" {
+		refNamespace = ref.Namespace
+	}
+	err := p.CheckRefNamespace(namespace, refNamespace)
+	if err != nil {
+		return err
+	}
+	gvk, err := ref.GroupVersionKind()
+	if err == nil && gvk.Group == "" && gvk.Kind == "Secret" {
+		return p.CheckSecretNamespace(namespace, refNamespace)
+	}
+	return nil
+}
+
+// checkObjectPolicy rejects inline objects that would violate the policy for sure. Only fields that are not changed
+// by rendering with synthetic variables are considered literal and checked, all other fields depend on the actual
+// matrix inputs and are left to the controller.
+func checkObjectPolicy(p *policy.Policy, namespace string, o *unstructured.Unstructured, rendered *unstructured.Unstructured) error {
+	if o.GetAPIVersion() == rendered.GetAPIVersion() && o.GetKind() == rendered.GetKind() && o.GetKind() != "" {
+		err := p.CheckKind(o.GroupVersionKind().GroupKind())
+		if err != nil {
+			return err
+		}
+	}
+	if o.GetNamespace() == rendered.GetNamespace() && o.GetNamespace() != "" {
+		return p.CheckTargetNamespace(namespace, o.GetNamespace())
+	}
+	return nil
+}
+
+func (v *ObjectTemplateValidator) getEngine(name string, delimiters *templatesv1alpha1.TemplateDelimiters) (templateEngine, error) {
+	switch name {
+	case "", templateEngineJinja2:
+		if delimiters != nil {
+			err := validateDelimiters(name, delimiters)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+		if v.jinja2 == nil {
+			e, err := newTemplateEngine(name, templateEngineOptions{})
+			if err != nil {
+				return nil, err
+			}
+			v.jinja2 = e.(*jinja2Engine)
+		}
+		return &jinja2Engine{j2: v.jinja2.j2, delimiters: delimiters}, nil
+	default:
+		// lookup must be defined, as Go templates fail to parse when unknown functions are used
+		return newTemplateEngine(name, templateEngineOptions{
+			lookup: func(apiVersion string, kind string, namespace string, name string) (map[string]any, error) {
+				return map[string]any{}, nil
+			},
+			delimiters: delimiters,
+		})
+	}
+}
+
+// isTemplateSyntaxError returns true if err (or one of the errors in a multierror) is caused by invalid template
+// syntax instead of failed execution
+func isTemplateSyntaxError(err error) bool {
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		for _, e := range merr.Errors {
+			if isTemplateSyntaxError(e) {
+				return true
+			}
+		}
+		return false
+	}
+	if errors.Is(err, errUnclosedDelimiter) {
+		return true
+	}
+	var execErr template.ExecError
+	if errors.As(err, &execErr) {
+		return false
+	}
+	var j2Err *jinja2.Jinja2Error
+	if errors.As(err, &j2Err) {
+		return strings.Contains(err.Error(), "TemplateSyntaxError")
+	}
+	// parse errors of Go templates are not typed
+	return strings.HasPrefix(err.Error(), "template: ")
+}
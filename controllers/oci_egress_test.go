@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// countingTransport counts the requests sent per host
+type countingTransport struct {
+	mutex sync.Mutex
+	hosts map[string]int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mutex.Lock()
+	if t.hosts == nil {
+		t.hosts = map[string]int{}
+	}
+	t.hosts[req.URL.Host]++
+	t.mutex.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRegistryRequestsUsePolicyTransport(t *testing.T) {
+	g := NewWithT(t)
+
+	repo, _ := pushTestArtifact(t)
+	transport := &countingTransport{}
+	p, err := policy.New(policy.Policy{AllowedHosts: []string{"127.0.0.1"}, Transport: transport})
+	g.Expect(err).ToNot(HaveOccurred())
+	r := &BaseTemplateReconciler{Policy: p}
+	objClient := fake.NewClientBuilder().Build()
+
+	// the registry is specified with a port, which must not prevent matching the allowed hosts
+	inputs, err := r.buildImageTagsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryImageTags{
+		Repository: repo.String(),
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(HaveLen(1))
+	g.Expect(transport.hosts[repo.RegistryStr()]).To(BeNumerically(">", 0))
+
+	requests := transport.hosts[repo.RegistryStr()]
+	_, err = r.resolveOCITemplates(context.Background(), objClient, "default", &templatesv1alpha1.OCITemplateSource{
+		URL:  "oci://" + repo.String(),
+		Tag:  "v1",
+		Path: "templates",
+	})
+	// the random test artifact has no template content, but it must have been pulled through the policy transport
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).ToNot(ContainSubstring("not allowed by policy"))
+	g.Expect(transport.hosts[repo.RegistryStr()]).To(BeNumerically(">", requests))
+
+	p, err = policy.New(policy.Policy{AllowedHosts: []string{"registry.example.com"}, Transport: transport})
+	g.Expect(err).ToNot(HaveOccurred())
+	r.Policy = p
+	transport.hosts = nil
+
+	_, err = r.buildImageTagsInput(context.Background(), objClient, "default", &templatesv1alpha1.MatrixEntryImageTags{
+		Repository: repo.String(),
+	})
+	g.Expect(err).To(MatchError("requests to host 127.0.0.1 are not allowed by policy"))
+	g.Expect(transport.hosts).To(BeEmpty())
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"net/url"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+const (
+	// these are the media types used by Flux, so that pushed artifacts can be consumed by the OCIRepository source
+	ociConfigMediaType  = "application/vnd.cncf.flux.config.v1+json"
+	ociContentMediaType = "application/vnd.cncf.flux.content.v1.tar+gzip"
+
+	ociRevisionAnnotation = "org.opencontainers.image.revision"
+)
+
+// pushOCIOutput pushes the rendered objects of each matrix entry as an OCI artifact to the repository specified in
+// spec.ociOutput instead of applying them. Artifacts are only pushed if their digest has changed.
+func (r *ObjectTemplateReconciler) pushOCIOutput(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, entries []renderedMatrixEntry) error {
+	logger := log.FromContext(ctx)
+	spec := rt.Spec.OCIOutput
+
+	err := r.checkOutputPolicy(rt, entries)
+	if err != nil {
+		return err
+	}
+
+	repo, err := name.NewRepository(strings.TrimPrefix(spec.URL, "oci://"))
+	if err != nil {
+		return fmt.Errorf("invalid OCI url %s: %w", spec.URL, err)
+	}
+	err = r.Policy.CheckRegistry(repo.RegistryStr())
+	if err != nil {
+		return err
+	}
+
+	auth, err := buildOCIAuth(ctx, objClient, rt.GetNamespace(), spec.SecretRef, repo.RegistryStr())
+	if err != nil {
+		return err
+	}
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuth(auth),
+		remote.WithTransport(r.Policy.HTTPClient().Transport),
+	}
+
+	var artifacts []templatesv1alpha1.OCIArtifactInfo
+	for _, e := range entries {
+		tagName := e.outputName
+		if tagName == "" {
+			tagName = e.matrixKey
+		}
+		tag, err := name.NewTag(repo.String()+":"+tagName, name.StrictValidation)
+		if err != nil {
+			return fmt.Errorf("invalid tag %s: %w", tagName, err)
+		}
+
+		img, err := buildOCIArtifact(e)
+		if err != nil {
+			return err
+		}
+		digest, err := img.Digest()
+		if err != nil {
+			return err
+		}
+
+		desc, err := remote.Head(tag, opts...)
+		if err != nil || desc.Digest != digest {
+			err = remote.Write(tag, img, opts...)
+			if err != nil {
+				return fmt.Errorf("failed to push %s: %w", tag.String(), err)
+			}
+			logger.Info("Pushed rendered objects", "tag", tag.String(), "digest", digest.String())
+		}
+
+		artifacts = append(artifacts, templatesv1alpha1.OCIArtifactInfo{
+			Tag:    tag.TagStr(),
+			Digest: digest.String(),
+		})
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Tag < artifacts[j].Tag
+	})
+
+	rt.Status.OCIOutput = &templatesv1alpha1.OCIOutputStatus{
+		Artifacts: artifacts,
+	}
+	return nil
+}
+
+// buildOCIArtifact builds a Flux compatible artifact from the rendered objects of a single matrix entry. The content
+// layer is built reproducibly, so that unchanged objects result in the same digest.
+func buildOCIArtifact(e renderedMatrixEntry) (gcrv1.Image, error) {
+	objects := make([]*unstructured.Unstructured, len(e.objects))
+	copy(objects, e.objects)
+	sort.Slice(objects, func(i, j int) bool {
+		return buildOutputFileName(objects[i]) < buildOutputFileName(objects[j])
+	})
+
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, x := range objects {
+		b, err := yaml.Marshal(x.Object)
+		if err != nil {
+			return nil, err
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name:     buildOutputFileName(x),
+			Mode:     0o644,
+			Size:     int64(len(b)),
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = tw.Write(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, ociConfigMediaType)
+	img = mutate.Annotations(img, map[string]string{
+		ociRevisionAnnotation: e.matrixKey,
+	}).(gcrv1.Image)
+	return mutate.Append(img, mutate.Addendum{
+		Layer: static.NewLayer(buf.Bytes(), ociContentMediaType),
+	})
+}
+
+// buildOCIAuth builds the registry authentication for the given registry from a kubernetes.io/dockerconfigjson
+// secret. Anonymous authentication is used if no secret is specified or if it contains no entry for the registry.
+func buildOCIAuth(ctx context.Context, c client.Client, namespace string, secretRef *templatesv1alpha1.LocalObjectReference, registry string) (authn.Authenticator, error) {
+	if secretRef == nil {
+		return authn.Anonymous, nil
+	}
+
+	var secret corev1.Secret
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretRef.Name}, &secret)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := secret.Data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s does not contain %s", secretRef.Name, corev1.DockerConfigJsonKey)
+	}
+
+	var dockerConfig struct {
+		Auths map[string]authn.AuthConfig `json:"auths"`
+	}
+	err = json.Unmarshal(b, &dockerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s of secret %s: %w", corev1.DockerConfigJsonKey, secretRef.Name, err)
+	}
+
+	for host, ac := range dockerConfig.Auths {
+		if normalizeRegistryHost(host) != registry {
+			continue
+		}
+		redact.Register(ctx, ac.Password)
+		redact.Register(ctx, ac.Auth)
+		redact.Register(ctx, ac.IdentityToken)
+		redact.Register(ctx, ac.RegistryToken)
+		return authn.FromConfig(ac), nil
+	}
+	return authn.Anonymous, nil
+}
+
+// normalizeRegistryHost converts the keys found in docker config files (e.g. https://index.docker.io/v1/) into plain
+// registry hosts
+func normalizeRegistryHost(host string) string {
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err == nil {
+			host = u.Host
+		}
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if host == "docker.io" || host == "index.docker.io" {
+		return name.DefaultRegistry
+	}
+	return host
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// CheckHost verifies that generators and handlers may send requests to the given host.
+func (p *Policy) CheckHost(host string) error {
+	if p == nil {
+		return nil
+	}
+	ok, err := p.matchAny(p.AllowedHosts, host)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("requests to host %s are not allowed by policy", host)
+	}
+	return nil
+}
+
+// CheckRegistry verifies that requests may be sent to the given OCI registry. Registries are specified in the form
+// host[:port], while the allowed hosts only match hostnames, so the port is ignored.
+func (p *Policy) CheckRegistry(registry string) error {
+	host, _, err := net.SplitHostPort(registry)
+	if err != nil {
+		// no port specified
+		host = strings.TrimSuffix(strings.TrimPrefix(registry, "["), "]")
+	}
+	return p.CheckHost(host)
+}
+
+// HTTPClient returns a http client that refuses to send requests to hosts which are not allowed by the policy. As
+// the check is performed for every single request, redirects to forbidden hosts are refused as well.
+func (p *Policy) HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: p.WrapTransport(p.baseTransport()),
+	}
+}
+
+func (p *Policy) baseTransport() http.RoundTripper {
+	if p == nil || p.Transport == nil {
+		return http.DefaultTransport
+	}
+	return p.Transport
+}
+
+// WrapTransport wraps the given transport so that requests to hosts which are not allowed by the policy are refused.
+func (p *Policy) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if p == nil || p.AllowedHosts == nil {
+		return rt
+	}
+	return &egressTransport{
+		policy: p,
+		base:   rt,
+	}
+}
+
+type egressTransport struct {
+	policy *Policy
+	base   http.RoundTripper
+}
+
+func (t *egressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := t.policy.CheckHost(req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCheckHost(t *testing.T) {
+	g := NewWithT(t)
+
+	p := mustNew(t, Policy{AllowedHosts: []string{"api.github.com", "*.example.com"}})
+	g.Expect(p.CheckHost("api.github.com")).To(Succeed())
+	g.Expect(p.CheckHost("gitlab.example.com")).To(Succeed())
+	g.Expect(p.CheckHost("gitlab.com")).To(MatchError("requests to host gitlab.com are not allowed by policy"))
+
+	g.Expect(mustNew(t, Policy{}).CheckHost("gitlab.com")).To(Succeed())
+	g.Expect(mustNew(t, Policy{AllowedHosts: []string{}}).CheckHost("gitlab.com")).ToNot(Succeed())
+}
+
+func TestHTTPClientRefusesForbiddenHosts(t *testing.T) {
+	g := NewWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://forbidden.example.com/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p := mustNew(t, Policy{AllowedHosts: []string{u.Hostname()}})
+	resp, err := p.HTTPClient().Get(server.URL)
+	g.Expect(err).ToNot(HaveOccurred())
+	_ = resp.Body.Close()
+
+	// redirects are checked as well
+	_, err = p.HTTPClient().Get(server.URL + "/redirect")
+	g.Expect(err).To(MatchError(ContainSubstring("requests to host forbidden.example.com are not allowed by policy")))
+}
+
+func TestCheckRegistry(t *testing.T) {
+	g := NewWithT(t)
+
+	p := mustNew(t, Policy{AllowedHosts: []string{"ghcr.io", "*.example.com", "127.0.0.1", "::1"}})
+	g.Expect(p.CheckRegistry("ghcr.io")).To(Succeed())
+	g.Expect(p.CheckRegistry("registry.example.com:5000")).To(Succeed())
+	g.Expect(p.CheckRegistry("127.0.0.1:5000")).To(Succeed())
+	g.Expect(p.CheckRegistry("[::1]:5000")).To(Succeed())
+	g.Expect(p.CheckRegistry("[::1]")).To(Succeed())
+	g.Expect(p.CheckRegistry("index.docker.io")).To(MatchError("requests to host index.docker.io are not allowed by policy"))
+	g.Expect(p.CheckRegistry("evil.com:443")).To(MatchError("requests to host evil.com are not allowed by policy"))
+}
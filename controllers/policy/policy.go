@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/gobwas/glob"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"strings"
+)
+
+// Policy defines cluster-wide restrictions that apply to all templates and handlers, independent of the permissions
+// that the impersonated service accounts have. A zero Policy does not restrict anything.
+type Policy struct {
+	// TargetNamespaces is a list of namespace glob patterns that templates may apply objects to. Templates are always
+	// allowed to apply objects into their own namespace. A nil list means that all namespaces are allowed.
+	TargetNamespaces []string
+
+	// SecretNamespaces is a list of namespace glob patterns that templates and handlers may read secrets from. Secrets
+	// from their own namespace can always be read. A nil list means that all namespaces are allowed.
+	SecretNamespaces []string
+
+	// AllowedKinds is a list of glob patterns of kinds that templates may apply. Patterns are matched against the
+	// kind in the form "Kind.group", e.g. "ClusterRoleBinding.rbac.authorization.k8s.io". Kinds from the core group
+	// have no group suffix, e.g. "ConfigMap". A nil list means that all kinds are allowed.
+	AllowedKinds []string
+
+	// DeniedKinds is a list of glob patterns of kinds that templates may not apply, in the same form as AllowedKinds.
+	// DeniedKinds takes precedence over AllowedKinds.
+	DeniedKinds []string
+
+	// AllowedLookupKinds is a list of glob patterns of kinds that templates may read via the lookup function, in the
+	// same form as AllowedKinds. A nil list means that all kinds are allowed.
+	AllowedLookupKinds []string
+
+	// AllowedHosts is a list of glob patterns of hostnames that generators and handlers may send requests to, e.g.
+	// "gitlab.example.com" or "api.github.com". A nil list means that all hosts are allowed.
+	AllowedHosts []string
+
+	// AllowClusterScopedObjects allows namespaced templates to apply cluster-scoped objects. If false, only templates
+	// in namespaces that are annotated with templates.kluctl.io/allow-cluster-scoped-objects=true may do so.
+	AllowClusterScopedObjects bool
+
+	// NoCrossNamespaceRefs rejects all references to objects, secrets and target namespaces outside the namespace of
+	// the referencing object
+	NoCrossNamespaceRefs bool
+
+	// TrustedKeysNamespace is the namespace that the public keys used for signature verification are read from. If
+	// set, the keys are read by the controller itself instead of the impersonated service account, so that tenants can
+	// not trust keys they control. If empty, the keys are read from the namespace of the verifying object.
+	TrustedKeysNamespace string
+
+	// RequireSignedTemplates rejects all template sources that are not verified against trusted keys, i.e. OCI
+	// sources without verify and GitRepository sources. Requires TrustedKeysNamespace to be set.
+	RequireSignedTemplates bool
+
+	// Transport optionally overrides the transport used for requests to external hosts. It is used by the test
+	// harness to redirect requests to fake servers. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// globs contains the compiled patterns of all lists, see New
+	globs map[string]glob.Glob
+}
+
+// New returns a copy of p with all patterns compiled, so that they are not compiled again on every check. Invalid
+// patterns are reported here instead of failing every check later.
+func New(p Policy) (*Policy, error) {
+	if p.RequireSignedTemplates && p.TrustedKeysNamespace == "" {
+		return nil, fmt.Errorf("requiring signed templates needs a trusted keys namespace")
+	}
+
+	ret := p
+	ret.globs = map[string]glob.Glob{}
+	for _, l := range [][]string{p.TargetNamespaces, p.SecretNamespaces, p.AllowedKinds, p.DeniedKinds, p.AllowedLookupKinds, p.AllowedHosts} {
+		for _, pattern := range l {
+			g, err := glob.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid policy pattern %s: %w", pattern, err)
+			}
+			ret.globs[pattern] = g
+		}
+	}
+	return &ret, nil
+}
+
+// ForClusterTemplates returns a copy of the policy to be used for cluster-scoped templates, which are managed by
+// platform admins and thus not bound to tenant boundaries. Namespace restrictions are removed, while kind, host and
+// signature restrictions still apply.
+func (p *Policy) ForClusterTemplates() *Policy {
+	if p == nil {
+		return nil
+	}
+	return &Policy{
+		AllowedKinds:              p.AllowedKinds,
+		DeniedKinds:               p.DeniedKinds,
+		AllowedLookupKinds:        p.AllowedLookupKinds,
+		AllowedHosts:              p.AllowedHosts,
+		AllowClusterScopedObjects: true,
+		TrustedKeysNamespace:      p.TrustedKeysNamespace,
+		RequireSignedTemplates:    p.RequireSignedTemplates,
+		Transport:                 p.Transport,
+		globs:                     p.globs,
+	}
+}
+
+// CheckTargetNamespace verifies that an object owned by namespace ownerNamespace may be applied into namespace.
+func (p *Policy) CheckTargetNamespace(ownerNamespace string, namespace string) error {
+	if p == nil || namespace == "" || namespace == ownerNamespace {
+		return nil
+	}
+	if p.NoCrossNamespaceRefs {
+		return fmt.Errorf("applying objects into namespace %s is not allowed as cross-namespace references are disabled", namespace)
+	}
+	ok, err := p.matchAny(p.TargetNamespaces, namespace)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("applying objects into namespace %s is not allowed by policy", namespace)
+	}
+	return nil
+}
+
+// CheckSecretNamespace verifies that a secret in namespace may be read on behalf of an object owned by namespace
+// ownerNamespace.
+func (p *Policy) CheckSecretNamespace(ownerNamespace string, namespace string) error {
+	if p == nil || namespace == "" || namespace == ownerNamespace {
+		return nil
+	}
+	if p.NoCrossNamespaceRefs {
+		return fmt.Errorf("reading secrets from namespace %s is not allowed as cross-namespace references are disabled", namespace)
+	}
+	ok, err := p.matchAny(p.SecretNamespaces, namespace)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("reading secrets from namespace %s is not allowed by policy", namespace)
+	}
+	return nil
+}
+
+// CheckRefNamespace verifies that an object owned by namespace ownerNamespace may reference objects in namespace.
+func (p *Policy) CheckRefNamespace(ownerNamespace string, namespace string) error {
+	if p == nil || !p.NoCrossNamespaceRefs || namespace == "" || namespace == ownerNamespace {
+		return nil
+	}
+	return fmt.Errorf("referencing objects in namespace %s is not allowed as cross-namespace references are disabled", namespace)
+}
+
+// CheckUnsignedTemplateSource verifies that templates may be loaded from the given source without signature
+// verification.
+func (p *Policy) CheckUnsignedTemplateSource(source string) error {
+	if p == nil || !p.RequireSignedTemplates {
+		return nil
+	}
+	return fmt.Errorf("loading templates from %s without signature verification is not allowed by policy", source)
+}
+
+// CheckKind verifies that objects of the given kind may be applied.
+func (p *Policy) CheckKind(gk schema.GroupKind) error {
+	if p == nil {
+		return nil
+	}
+	s := gk.String()
+	if p.DeniedKinds != nil {
+		denied, err := p.matchAny(p.DeniedKinds, s)
+		if err != nil {
+			return err
+		}
+		if denied {
+			return fmt.Errorf("applying objects of kind %s is denied by policy", s)
+		}
+	}
+	ok, err := p.matchAny(p.AllowedKinds, s)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("applying objects of kind %s is not allowed by policy", s)
+	}
+	return nil
+}
+
+// CheckLookupKind verifies that objects of the given kind may be read via the lookup template function.
+func (p *Policy) CheckLookupKind(gk schema.GroupKind) error {
+	if p == nil {
+		return nil
+	}
+	s := gk.String()
+	ok, err := p.matchAny(p.AllowedLookupKinds, s)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("looking up objects of kind %s is not allowed by policy", s)
+	}
+	return nil
+}
+
+// matchAny returns true if s matches any of the patterns or if patterns is nil. Patterns that were not compiled by New
+// (e.g. for policies that are constructed directly) are compiled on demand.
+func (p *Policy) matchAny(patterns []string, s string) (bool, error) {
+	if patterns == nil {
+		return true, nil
+	}
+	for _, pattern := range patterns {
+		g, ok := p.globs[pattern]
+		if !ok {
+			var err error
+			g, err = glob.Compile(pattern)
+			if err != nil {
+				return false, fmt.Errorf("invalid policy pattern %s: %w", pattern, err)
+			}
+		}
+		if g.Match(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ParseList parses a comma separated list of patterns as passed via command line flags. An empty string results in
+// an empty but non-nil list, which means that nothing is allowed except for the owner's namespace.
+func ParseList(s string) []string {
+	ret := []string{}
+	for _, x := range strings.Split(s, ",") {
+		x = strings.TrimSpace(x)
+		if x != "" {
+			ret = append(ret, x)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func mustNew(t *testing.T, p Policy) *Policy {
+	ret, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := New(Policy{AllowedKinds: []string{"["}})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid policy pattern [")))
+}
+
+func TestNilPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	var p *Policy
+	g.Expect(p.CheckKind(schema.GroupKind{Kind: "Secret"})).To(Succeed())
+	g.Expect(p.CheckHost("example.com")).To(Succeed())
+	g.Expect(p.CheckRefNamespace("ns1", "ns2")).To(Succeed())
+	g.Expect(p.CheckTargetNamespace("ns1", "ns2")).To(Succeed())
+	g.Expect(p.CheckSecretNamespace("ns1", "ns2")).To(Succeed())
+	g.Expect(p.ForClusterTemplates()).To(BeNil())
+}
+
+func TestCheckKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		gk      schema.GroupKind
+		allowed bool
+	}{
+		{name: "no restrictions", gk: schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}, allowed: true},
+		{name: "core kind allowed", policy: Policy{AllowedKinds: []string{"ConfigMap", "*.apps"}}, gk: schema.GroupKind{Kind: "ConfigMap"}, allowed: true},
+		{name: "group glob allowed", policy: Policy{AllowedKinds: []string{"ConfigMap", "*.apps"}}, gk: schema.GroupKind{Group: "apps", Kind: "Deployment"}, allowed: true},
+		{name: "not allowed", policy: Policy{AllowedKinds: []string{"ConfigMap", "*.apps"}}, gk: schema.GroupKind{Kind: "Secret"}},
+		{name: "empty allow list", policy: Policy{AllowedKinds: []string{}}, gk: schema.GroupKind{Kind: "ConfigMap"}},
+		{name: "denied", policy: Policy{DeniedKinds: []string{"*.rbac.authorization.k8s.io"}}, gk: schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}},
+		{name: "not denied", policy: Policy{DeniedKinds: []string{"*.rbac.authorization.k8s.io"}}, gk: schema.GroupKind{Kind: "ConfigMap"}, allowed: true},
+		{
+			name:   "denied takes precedence",
+			policy: Policy{AllowedKinds: []string{"*"}, DeniedKinds: []string{"Secret"}},
+			gk:     schema.GroupKind{Kind: "Secret"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			// compiled and uncompiled policies must behave the same
+			for _, p := range []*Policy{&tc.policy, mustNew(t, tc.policy)} {
+				err := p.CheckKind(tc.gk)
+				if tc.allowed {
+					g.Expect(err).ToNot(HaveOccurred())
+				} else {
+					g.Expect(err).To(HaveOccurred())
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRefNamespace(t *testing.T) {
+	g := NewWithT(t)
+
+	p := mustNew(t, Policy{})
+	g.Expect(p.CheckRefNamespace("ns1", "ns2")).To(Succeed())
+
+	p = mustNew(t, Policy{NoCrossNamespaceRefs: true})
+	g.Expect(p.CheckRefNamespace("ns1", "ns1")).To(Succeed())
+	g.Expect(p.CheckRefNamespace("ns1", "")).To(Succeed())
+	g.Expect(p.CheckRefNamespace("ns1", "ns2")).To(MatchError(ContainSubstring("cross-namespace references are disabled")))
+
+	// cluster-scoped templates are not bound to namespaces
+	g.Expect(p.ForClusterTemplates().CheckRefNamespace("ns1", "ns2")).To(Succeed())
+}
+
+func TestCheckNamespaces(t *testing.T) {
+	g := NewWithT(t)
+
+	p := mustNew(t, Policy{TargetNamespaces: []string{"preview-*"}, SecretNamespaces: []string{"shared"}})
+	g.Expect(p.CheckTargetNamespace("ns1", "ns1")).To(Succeed())
+	g.Expect(p.CheckTargetNamespace("ns1", "preview-1")).To(Succeed())
+	g.Expect(p.CheckTargetNamespace("ns1", "ns2")).To(MatchError("applying objects into namespace ns2 is not allowed by policy"))
+	g.Expect(p.CheckSecretNamespace("ns1", "ns1")).To(Succeed())
+	g.Expect(p.CheckSecretNamespace("ns1", "shared")).To(Succeed())
+	g.Expect(p.CheckSecretNamespace("ns1", "ns2")).To(MatchError("reading secrets from namespace ns2 is not allowed by policy"))
+
+	cp := p.ForClusterTemplates()
+	g.Expect(cp.CheckTargetNamespace("ns1", "ns2")).To(Succeed())
+	g.Expect(cp.CheckSecretNamespace("ns1", "ns2")).To(Succeed())
+
+	p = mustNew(t, Policy{NoCrossNamespaceRefs: true})
+	g.Expect(p.CheckTargetNamespace("ns1", "ns2")).To(MatchError(ContainSubstring("cross-namespace references are disabled")))
+	g.Expect(p.CheckSecretNamespace("ns1", "ns2")).To(MatchError(ContainSubstring("cross-namespace references are disabled")))
+}
+
+func TestForClusterTemplatesKeepsKindAndHostRestrictions(t *testing.T) {
+	g := NewWithT(t)
+
+	p := mustNew(t, Policy{DeniedKinds: []string{"Secret"}, AllowedHosts: []string{"api.github.com"}}).ForClusterTemplates()
+	g.Expect(p.CheckKind(schema.GroupKind{Kind: "Secret"})).ToNot(Succeed())
+	g.Expect(p.CheckHost("gitlab.com")).ToNot(Succeed())
+}
+
+func TestParseList(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ParseList("")).To(Equal([]string{}))
+	g.Expect(ParseList(" a, b ,,c")).To(Equal([]string{"a", "b", "c"}))
+}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gobwas/glob"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// botNameRegex matches usernames that are typically used by bots, e.g. "dependabot[bot]", "renovate-bot" or the
+// users of Gitlab project and group access tokens ("project_123_bot_...")
+var botNameRegex = regexp.MustCompile(`(?i)(\[bot]|[-_]bot)$|^(project|group)_[0-9]+_bot`)
+
+// draftTitleRegex matches the title prefixes used by Gitea and Gitlab to mark pull requests as work in progress
+var draftTitleRegex = regexp.MustCompile(`(?i)^\s*(wip:|\[wip]|draft:|\[draft]|\(draft\))`)
+
+// pullRequestInfo contains the provider independent properties of a pull request that are required to apply
+// PullRequestFilters and to build the pull request summary
+type pullRequestInfo struct {
+	Number       int64
+	SourceBranch string
+	TargetBranch string
+	HeadSha      string
+	Labels       []string
+	Author       string
+	Description  string
+	WebUrl       string
+
+	// IsBot must be set if the provider marks the author as bot. Authors with typical bot usernames are always treated
+	// as bots.
+	IsBot bool
+
+	Title string
+	Draft bool
+
+	// UpdatedAt is the time of the last update of the pull request. Pull requests without an update time are never
+	// excluded by MaxAge.
+	UpdatedAt *time.Time
+
+	// ChangedFiles is only invoked if ChangedPaths is specified and all other filters passed. It must return the paths
+	// of all files changed by the pull request, including the old paths of renamed files.
+	ChangedFiles func() ([]string, error)
+}
+
+type pullRequestFilter struct {
+	excludeLabels       map[string]bool
+	excludeSourceBranch *regexp.Regexp
+	authors             map[string]bool
+	excludeAuthors      map[string]bool
+	excludeBots         bool
+	excludeDrafts       bool
+	changedPaths        []glob.Glob
+	minUpdatedAt        *time.Time
+}
+
+func newPullRequestFilter(spec templatesv1alpha1.PullRequestFilters) (*pullRequestFilter, error) {
+	f := &pullRequestFilter{
+		excludeLabels:  map[string]bool{},
+		excludeAuthors: map[string]bool{},
+		excludeBots:    spec.ExcludeBots,
+		excludeDrafts:  spec.ExcludeDrafts,
+	}
+	for _, l := range spec.ExcludeLabels {
+		f.excludeLabels[l] = true
+	}
+	if len(spec.Authors) != 0 {
+		f.authors = map[string]bool{}
+		for _, a := range spec.Authors {
+			f.authors[strings.ToLower(a)] = true
+		}
+	}
+	for _, a := range spec.ExcludeAuthors {
+		f.excludeAuthors[strings.ToLower(a)] = true
+	}
+	if spec.MaxAge != nil {
+		t := time.Now().Add(-spec.MaxAge.Duration)
+		f.minUpdatedAt = &t
+	}
+	for _, p := range spec.ChangedPaths {
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid changed paths glob %s: %w", p, err)
+		}
+		f.changedPaths = append(f.changedPaths, g)
+	}
+	if spec.ExcludeSourceBranch != nil {
+		var err error
+		f.excludeSourceBranch, err = regexp.Compile(fmt.Sprintf("^%s$", *spec.ExcludeSourceBranch))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// match returns true if the given pull request passes all filters
+func (f *pullRequestFilter) match(pr pullRequestInfo) (bool, error) {
+	for _, l := range pr.Labels {
+		if f.excludeLabels[l] {
+			return false, nil
+		}
+	}
+	if f.excludeSourceBranch != nil && f.excludeSourceBranch.MatchString(pr.SourceBranch) {
+		return false, nil
+	}
+	author := strings.ToLower(pr.Author)
+	if f.authors != nil && !f.authors[author] {
+		return false, nil
+	}
+	if f.excludeAuthors[author] {
+		return false, nil
+	}
+	if f.excludeBots && (pr.IsBot || botNameRegex.MatchString(pr.Author)) {
+		return false, nil
+	}
+	if f.excludeDrafts && (pr.Draft || draftTitleRegex.MatchString(pr.Title)) {
+		return false, nil
+	}
+	if f.minUpdatedAt != nil && pr.UpdatedAt != nil && pr.UpdatedAt.Before(*f.minUpdatedAt) {
+		return false, nil
+	}
+	if len(f.changedPaths) != 0 {
+		// this is the most expensive filter, so it must always be evaluated last
+		files, err := pr.ChangedFiles()
+		if err != nil {
+			return false, fmt.Errorf("failed to list changed files: %w", err)
+		}
+		for _, file := range files {
+			for _, g := range f.changedPaths {
+				if g.Match(file) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// sortPullRequests orders the given pull requests as specified by SortBy and SortDirection. Pull requests are ordered by
+// ID if SortBy is not specified, or if the times are equal. The key function must return the ID, the creation time and
+// the time of the last update of the given pull request.
+func sortPullRequests[T any](l []T, spec templatesv1alpha1.PullRequestFilters, key func(pr T) (int64, *time.Time, *time.Time)) {
+	getTime := func(pr T) time.Time {
+		_, createdAt, updatedAt := key(pr)
+		t := createdAt
+		if spec.SortBy == "updated" {
+			t = updatedAt
+		}
+		if t == nil {
+			return time.Time{}
+		}
+		return *t
+	}
+	sort.SliceStable(l, func(i, j int) bool {
+		if spec.SortBy != "" {
+			ti, tj := getTime(l[i]), getTime(l[j])
+			if !ti.Equal(tj) {
+				if spec.SortDirection == "asc" {
+					return ti.Before(tj)
+				}
+				return ti.After(tj)
+			}
+		}
+		idI, _, _ := key(l[i])
+		idJ, _, _ := key(l[j])
+		return idI < idJ
+	})
+}
+
+// pullRequestSummary is added to every pull request written into the status, so that templates can access the most
+// important fields independent of the provider
+type pullRequestSummary struct {
+	Number       int64      `json:"number"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Author       string     `json:"author"`
+	Labels       []string   `json:"labels"`
+	SourceBranch string     `json:"sourceBranch"`
+	TargetBranch string     `json:"targetBranch"`
+	HeadSha      string     `json:"headSha"`
+	WebUrl       string     `json:"webUrl"`
+	Draft        bool       `json:"draft"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+}
+
+// addPullRequestSummary adds the "summary" field to the given JSON encoded pull request
+func addPullRequestSummary(j []byte, pr pullRequestInfo) ([]byte, error) {
+	var m map[string]any
+	err := json.Unmarshal(j, &m)
+	if err != nil {
+		return nil, err
+	}
+	labels := pr.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	m["summary"] = pullRequestSummary{
+		Number:       pr.Number,
+		Title:        pr.Title,
+		Description:  pr.Description,
+		Author:       pr.Author,
+		Labels:       labels,
+		SourceBranch: pr.SourceBranch,
+		TargetBranch: pr.TargetBranch,
+		HeadSha:      pr.HeadSha,
+		WebUrl:       pr.WebUrl,
+		Draft:        pr.Draft,
+		UpdatedAt:    pr.UpdatedAt,
+	}
+	return json.Marshal(m)
+}
@@ -0,0 +1,76 @@
+package receiver
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sync"
+	"time"
+)
+
+// TargetKey identifies an object that is triggered by Receivers
+type TargetKey struct {
+	GVK schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// Debouncer coalesces bursts of events for the same target object into a single delayed trigger. Every event received
+// while a trigger is pending is merged into the pending one, so that the trigger function is called at most once per
+// delay window and target, even if the events were received by different Receivers.
+type Debouncer struct {
+	trigger func(ctx context.Context, key TargetKey)
+
+	mutex   sync.Mutex
+	pending map[TargetKey]*time.Timer
+}
+
+func NewDebouncer(trigger func(ctx context.Context, key TargetKey)) *Debouncer {
+	return &Debouncer{
+		trigger: trigger,
+		pending: map[TargetKey]*time.Timer{},
+	}
+}
+
+// Add schedules a trigger for the given target after delay. If a trigger is already pending for the target, the event
+// is merged into the pending trigger and nothing else happens.
+func (d *Debouncer) Add(ctx context.Context, key TargetKey, delay time.Duration) {
+	d.mutex.Lock()
+	if _, ok := d.pending[key]; ok {
+		d.mutex.Unlock()
+		return
+	}
+	if delay <= 0 {
+		d.mutex.Unlock()
+		d.trigger(ctx, key)
+		return
+	}
+
+	// the trigger must not be bound to the (usually short-lived) request context of the event that initiated it
+	ctx = context.WithoutCancel(ctx)
+	d.pending[key] = time.AfterFunc(delay, func() {
+		d.mutex.Lock()
+		delete(d.pending, key)
+		d.mutex.Unlock()
+
+		d.trigger(ctx, key)
+	})
+	d.mutex.Unlock()
+}
+
+// Pending returns the number of targets with a pending trigger.
+func (d *Debouncer) Pending() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.pending)
+}
+
+// Stop cancels all pending triggers.
+func (d *Debouncer) Stop() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for key, t := range d.pending {
+		t.Stop()
+		delete(d.pending, key)
+	}
+}
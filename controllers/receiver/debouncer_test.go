@@ -0,0 +1,90 @@
+package receiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// triggerRecorder records the targets triggered by a Debouncer
+type triggerRecorder struct {
+	mutex     sync.Mutex
+	triggered []TargetKey
+	ctxErrs   []error
+}
+
+func (r *triggerRecorder) trigger(ctx context.Context, key TargetKey) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.triggered = append(r.triggered, key)
+	r.ctxErrs = append(r.ctxErrs, ctx.Err())
+}
+
+func (r *triggerRecorder) get() []TargetKey {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]TargetKey(nil), r.triggered...)
+}
+
+func newTargetKey(name string) TargetKey {
+	return TargetKey{
+		GVK:            schema.GroupVersionKind{Group: "templates.kluctl.io", Version: "v1alpha1", Kind: "ObjectTemplate"},
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: name},
+	}
+}
+
+func TestDebouncerWithoutDelay(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &triggerRecorder{}
+	d := NewDebouncer(r.trigger)
+	d.Add(context.Background(), newTargetKey("a"), 0)
+	d.Add(context.Background(), newTargetKey("a"), 0)
+
+	g.Expect(r.get()).To(Equal([]TargetKey{newTargetKey("a"), newTargetKey("a")}))
+	g.Expect(d.Pending()).To(Equal(0))
+}
+
+func TestDebouncerCoalescesEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &triggerRecorder{}
+	d := NewDebouncer(r.trigger)
+	defer d.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 5; i++ {
+		d.Add(ctx, newTargetKey("a"), 100*time.Millisecond)
+	}
+	d.Add(ctx, newTargetKey("b"), 100*time.Millisecond)
+	// the request context of the event ends before the trigger fires
+	cancel()
+
+	g.Expect(d.Pending()).To(Equal(2))
+	g.Eventually(r.get).Should(ConsistOf(newTargetKey("a"), newTargetKey("b")))
+	g.Consistently(r.get, 300*time.Millisecond).Should(HaveLen(2))
+	g.Expect(d.Pending()).To(Equal(0))
+	g.Expect(r.ctxErrs).To(HaveEach(BeNil()))
+
+	// events after the trigger fired start a new window
+	d.Add(context.Background(), newTargetKey("a"), 10*time.Millisecond)
+	g.Eventually(r.get).Should(HaveLen(3))
+}
+
+func TestDebouncerStop(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &triggerRecorder{}
+	d := NewDebouncer(r.trigger)
+	d.Add(context.Background(), newTargetKey("a"), 50*time.Millisecond)
+	d.Add(context.Background(), newTargetKey("b"), 50*time.Millisecond)
+	d.Stop()
+
+	g.Expect(d.Pending()).To(Equal(0))
+	g.Consistently(r.get, 200*time.Millisecond).Should(BeEmpty())
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package receiver
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const webhookPathIndexKey = "status.webhookPath"
+
+// ReceiverReconciler reconciles a Receiver object. It computes the webhook path of the Receiver, while incoming
+// events are handled by the Server.
+type ReceiverReconciler struct {
+	client.Client
+
+	Scheme       *runtime.Scheme
+	FieldManager string
+	Policy       *policy.Policy
+}
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=receivers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=receivers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=receivers/finalizers,verbs=update
+
+// Reconcile a resource
+func (r *ReceiverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	logger.V(1).Info("Starting reconcile")
+	defer logger.V(1).Info("Finished reconcile", "err", err)
+
+	var rcv templatesv1alpha1.Receiver
+	err = r.Get(ctx, req.NamespacedName, &rcv)
+	if err != nil {
+		err = client.IgnoreNotFound(err)
+		return
+	}
+
+	// Return early if the object is suspended.
+	if rcv.Spec.Suspend {
+		logger.Info("Reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(rcv.DeepCopy())
+	err = r.doReconcile(ctx, &rcv)
+	if err != nil {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: rcv.GetGeneration(),
+			Reason:             "Error",
+			Message:            redact.Error(ctx, err),
+		}
+		apimeta.SetStatusCondition(&rcv.Status.Conditions, c)
+	} else {
+		c := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: rcv.GetGeneration(),
+			Reason:             "Success",
+			Message:            fmt.Sprintf("Receiving events at %s", rcv.Status.WebhookPath),
+		}
+		apimeta.SetStatusCondition(&rcv.Status.Conditions, c)
+	}
+	err = r.Status().Patch(ctx, &rcv, patch, controllers.SubResourceFieldOwner(r.FieldManager))
+	if err != nil {
+		return
+	}
+
+	result.RequeueAfter = rcv.Spec.Interval.Duration
+	return
+}
+
+func (r *ReceiverReconciler) doReconcile(ctx context.Context, rcv *templatesv1alpha1.Receiver) error {
+	for _, res := range rcv.Spec.Resources {
+		_, err := resourceGroupVersionKind(res)
+		if err != nil {
+			return err
+		}
+	}
+
+	token, err := controllers.GetSecretToken(ctx, r.Client, rcv.GetNamespace(), r.Policy, rcv.Spec.SecretRef)
+	if err != nil {
+		return fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	rcv.Status.WebhookPath = BuildWebhookPath(rcv, token)
+	return nil
+}
+
+// BuildWebhookPath returns the path under which the Receiver accepts events. The token is part of the hashed value,
+// so that the path can not be guessed and changes when the token is rotated.
+func BuildWebhookPath(rcv *templatesv1alpha1.Receiver, token string) string {
+	return "/hook/" + controllers.Sha256String(token+rcv.GetName()+rcv.GetNamespace())
+}
+
+func resourceGroupVersionKind(res templatesv1alpha1.ReceiverResource) (schema.GroupVersionKind, error) {
+	apiVersion := res.APIVersion
+	if apiVersion == "" {
+		apiVersion = templatesv1alpha1.GroupVersion.String()
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	if gv.Group != templatesv1alpha1.GroupVersion.Group {
+		return schema.GroupVersionKind{}, fmt.Errorf("resource %s of group %s can not be triggered by receivers", res.Kind, gv.Group)
+	}
+	if res.Name == "" && len(res.MatchLabels) == 0 {
+		return schema.GroupVersionKind{}, fmt.Errorf("resource %s must specify either name or matchLabels", res.Kind)
+	}
+	return gv.WithKind(res.Kind), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReceiverReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index the Receivers by their webhook path, so that the Server can find them.
+	if err := mgr.GetCache().IndexField(context.TODO(), &templatesv1alpha1.Receiver{}, webhookPathIndexKey,
+		func(object client.Object) []string {
+			rcv := object.(*templatesv1alpha1.Receiver)
+			if rcv.Status.WebhookPath == "" {
+				return nil
+			}
+			return []string{rcv.Status.WebhookPath}
+		}); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&templatesv1alpha1.Receiver{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, controllers.ReconcileRequestedPredicate{}),
+		)).
+		Complete(redact.Reconciler(r))
+}
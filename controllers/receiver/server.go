@@ -0,0 +1,285 @@
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"net"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxBodySize = 1024 * 1024
+
+// Server accepts incoming events for all Receivers and triggers the resources of matching Receivers. It runs on all
+// replicas, independent of leader election.
+type Server struct {
+	Client       client.Client
+	FieldManager string
+	Policy       *policy.Policy
+	BindAddress  string
+
+	mutex     sync.Mutex
+	debouncer *Debouncer
+}
+
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("receiver")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook/", s.handleHook)
+
+	srv := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(_ net.Listener) context.Context {
+			return log.IntoContext(ctx, logger)
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+
+		s.getDebouncer().Stop()
+	}()
+
+	logger.Info("Starting receiver server", "addr", s.BindAddress)
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleHook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx).WithValues("path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var list templatesv1alpha1.ReceiverList
+	err := s.Client.List(ctx, &list, client.MatchingFields{
+		webhookPathIndexKey: r.URL.Path,
+	})
+	if err != nil {
+		logger.Error(err, "failed to list receivers")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if len(list.Items) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	accepted := 0
+	for i := range list.Items {
+		rcv := &list.Items[i]
+		if rcv.Spec.Suspend {
+			continue
+		}
+
+		// values read on behalf of a receiver are only redacted from its own logs, see redact.NewContext
+		ctx := redact.NewContext(log.IntoContext(ctx, logger))
+		logger := log.FromContext(ctx)
+
+		token, err := controllers.GetSecretToken(ctx, s.Client, rcv.GetNamespace(), s.Policy, rcv.Spec.SecretRef)
+		if err != nil {
+			logger.Error(err, "failed to get receiver token", "receiver", client.ObjectKeyFromObject(rcv))
+			continue
+		}
+		// the path is re-validated in case the token was rotated and the status is not updated yet
+		if BuildWebhookPath(rcv, token) != r.URL.Path {
+			continue
+		}
+
+		event, err := validateRequest(rcv.Spec.Type, token, r, body)
+		if err != nil {
+			logger.Info("Rejected event", "receiver", client.ObjectKeyFromObject(rcv), "reason", err.Error())
+			continue
+		}
+		accepted++
+
+		if !matchEvent(rcv.Spec.Events, event) {
+			logger.V(1).Info("Ignoring event", "receiver", client.ObjectKeyFromObject(rcv), "event", event)
+			continue
+		}
+
+		logger.Info("Accepted event", "receiver", client.ObjectKeyFromObject(rcv), "event", event)
+		s.handleEvent(ctx, rcv)
+	}
+
+	if accepted == 0 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getDebouncer() *Debouncer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.debouncer == nil {
+		s.debouncer = NewDebouncer(s.triggerTarget)
+	}
+	return s.debouncer
+}
+
+// validateRequest validates the request against the token and returns the event type, if the receiver type provides
+// one.
+func validateRequest(receiverType string, token string, r *http.Request, body []byte) (string, error) {
+	switch receiverType {
+	case templatesv1alpha1.ReceiverTypeGeneric:
+		return "", nil
+	case templatesv1alpha1.ReceiverTypeGenericHmac:
+		return "", verifyHmac(r.Header.Get("X-Signature"), token, body)
+	case templatesv1alpha1.ReceiverTypeGithub:
+		err := verifyHmac(r.Header.Get("X-Hub-Signature-256"), token, body)
+		if err != nil {
+			return "", err
+		}
+		return r.Header.Get("X-GitHub-Event"), nil
+	case templatesv1alpha1.ReceiverTypeGitlab:
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(token)) != 1 {
+			return "", fmt.Errorf("invalid X-Gitlab-Token header")
+		}
+		return r.Header.Get("X-Gitlab-Event"), nil
+	default:
+		return "", fmt.Errorf("unknown receiver type %s", receiverType)
+	}
+}
+
+func verifyHmac(signature string, token string, body []byte) error {
+	sigHex, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return fmt.Errorf("missing or invalid signature")
+	}
+	sig, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func matchEvent(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if strings.EqualFold(e, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleEvent schedules a reconciliation of all resources of the given Receiver. Triggers are debounced per resource,
+// so that resources targeted by multiple Receivers are only reconciled once per debounce window.
+func (s *Server) handleEvent(ctx context.Context, rcv *templatesv1alpha1.Receiver) {
+	logger := log.FromContext(ctx).WithValues("receiver", client.ObjectKeyFromObject(rcv))
+
+	var errs *multierror.Error
+	for _, res := range rcv.Spec.Resources {
+		keys, err := s.resolveTargets(ctx, rcv.GetNamespace(), res)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		for _, key := range keys {
+			s.getDebouncer().Add(ctx, key, rcv.Spec.Debounce.Duration)
+		}
+	}
+	if errs.ErrorOrNil() != nil {
+		logger.Error(errs, "failed to trigger resources")
+	}
+
+	now := metav1.Now()
+	patch := client.MergeFrom(rcv.DeepCopy())
+	rcv.Status.LastEventTime = &now
+	err := s.Client.Status().Patch(ctx, rcv, patch, controllers.SubResourceFieldOwner(s.FieldManager))
+	if err != nil {
+		logger.Error(err, "failed to update receiver status")
+	}
+}
+
+// resolveTargets returns the keys of all objects matching the given resource
+func (s *Server) resolveTargets(ctx context.Context, namespace string, res templatesv1alpha1.ReceiverResource) ([]TargetKey, error) {
+	gvk, err := resourceGroupVersionKind(res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Name != "" {
+		return []TargetKey{{GVK: gvk, NamespacedName: types.NamespacedName{Namespace: namespace, Name: res.Name}}}, nil
+	}
+
+	var list unstructured.UnstructuredList
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	err = s.Client.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels(res.MatchLabels))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+	}
+	keys := make([]TargetKey, 0, len(list.Items))
+	for _, o := range list.Items {
+		keys = append(keys, TargetKey{GVK: gvk, NamespacedName: client.ObjectKeyFromObject(&o)})
+	}
+	return keys, nil
+}
+
+// triggerTarget requests reconciliation of the given object by setting the
+// templates.kluctl.io/reconcile-requested-at annotation.
+func (s *Server) triggerTarget(ctx context.Context, key TargetKey) {
+	logger := log.FromContext(ctx).WithValues("kind", key.GVK.Kind, "name", key.NamespacedName)
+
+	var u unstructured.Unstructured
+	u.SetGroupVersionKind(key.GVK)
+	u.SetNamespace(key.Namespace)
+	u.SetName(key.Name)
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+		templatesv1alpha1.ReconcileRequestedAtAnnotation, time.Now().Format(time.RFC3339Nano)))
+	err := s.Client.Patch(ctx, &u, client.RawPatch(types.MergePatchType, patch), client.FieldOwner(s.FieldManager))
+	if err != nil {
+		logger.Error(err, "failed to trigger resource")
+	}
+}
@@ -0,0 +1,268 @@
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testToken = "secret-token"
+
+func newTestReceiver(name string, receiverType string, resources ...templatesv1alpha1.ReceiverResource) *templatesv1alpha1.Receiver {
+	rcv := &templatesv1alpha1.Receiver{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: templatesv1alpha1.ReceiverSpec{
+			Type:      receiverType,
+			SecretRef: templatesv1alpha1.SecretRef{SecretName: "token", Key: "token"},
+			Resources: resources,
+		},
+	}
+	rcv.Status.WebhookPath = BuildWebhookPath(rcv, testToken)
+	return rcv
+}
+
+func newTestObjectTemplate(name string, labels map[string]string) *templatesv1alpha1.ObjectTemplate {
+	return &templatesv1alpha1.ObjectTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+	}
+}
+
+func newTestServer(t *testing.T, objs ...client.Object) *Server {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = templatesv1alpha1.AddToScheme(scheme)
+
+	objs = append(objs, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "token"},
+		Data:       map[string][]byte{"token": []byte(testToken)},
+	})
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&templatesv1alpha1.Receiver{}).
+		WithIndex(&templatesv1alpha1.Receiver{}, webhookPathIndexKey, func(object client.Object) []string {
+			return []string{object.(*templatesv1alpha1.Receiver).Status.WebhookPath}
+		}).
+		Build()
+	s := &Server{Client: c, FieldManager: "template-controller"}
+	t.Cleanup(func() {
+		s.getDebouncer().Stop()
+	})
+	return s
+}
+
+func sendEvent(s *Server, path string, body string, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	s.handleHook(w, req)
+	return w.Code
+}
+
+func hmacSignature(token string, body string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// requestedAt returns the reconcile-requested-at annotation of the given ObjectTemplate
+func requestedAt(g *WithT, s *Server, name string) string {
+	var ot templatesv1alpha1.ObjectTemplate
+	g.Expect(s.Client.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, &ot)).To(Succeed())
+	return ot.GetAnnotations()[templatesv1alpha1.ReconcileRequestedAtAnnotation]
+}
+
+func TestHandleHook(t *testing.T) {
+	g := NewWithT(t)
+
+	rcv := newTestReceiver("generic", templatesv1alpha1.ReceiverTypeGeneric,
+		templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", Name: "t1"},
+		templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", MatchLabels: map[string]string{"trigger": "true"}},
+	)
+	s := newTestServer(t, rcv,
+		newTestObjectTemplate("t1", nil),
+		newTestObjectTemplate("t2", map[string]string{"trigger": "true"}),
+		newTestObjectTemplate("t3", nil),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, rcv.Status.WebhookPath, nil)
+	w := httptest.NewRecorder()
+	s.handleHook(w, req)
+	g.Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+
+	g.Expect(sendEvent(s, "/hook/unknown", "", nil)).To(Equal(http.StatusNotFound))
+	g.Expect(requestedAt(g, s, "t1")).To(BeEmpty())
+
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, "", nil)).To(Equal(http.StatusOK))
+	g.Expect(requestedAt(g, s, "t1")).ToNot(BeEmpty())
+	g.Expect(requestedAt(g, s, "t2")).ToNot(BeEmpty())
+	g.Expect(requestedAt(g, s, "t3")).To(BeEmpty())
+
+	var updated templatesv1alpha1.Receiver
+	g.Expect(s.Client.Get(context.Background(), client.ObjectKeyFromObject(rcv), &updated)).To(Succeed())
+	g.Expect(updated.Status.LastEventTime).ToNot(BeNil())
+}
+
+func TestHandleHookRotatedToken(t *testing.T) {
+	g := NewWithT(t)
+
+	// the status still contains the path of the previous token
+	rcv := newTestReceiver("generic", templatesv1alpha1.ReceiverTypeGeneric,
+		templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", Name: "t1"})
+	rcv.Status.WebhookPath = BuildWebhookPath(rcv, "old-token")
+	s := newTestServer(t, rcv, newTestObjectTemplate("t1", nil))
+
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, "", nil)).To(Equal(http.StatusUnauthorized))
+	g.Expect(requestedAt(g, s, "t1")).To(BeEmpty())
+}
+
+func TestHandleHookSuspended(t *testing.T) {
+	g := NewWithT(t)
+
+	rcv := newTestReceiver("generic", templatesv1alpha1.ReceiverTypeGeneric,
+		templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", Name: "t1"})
+	rcv.Spec.Suspend = true
+	s := newTestServer(t, rcv, newTestObjectTemplate("t1", nil))
+
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, "", nil)).To(Equal(http.StatusUnauthorized))
+	g.Expect(requestedAt(g, s, "t1")).To(BeEmpty())
+}
+
+func TestHandleHookGithub(t *testing.T) {
+	g := NewWithT(t)
+
+	rcv := newTestReceiver("github", templatesv1alpha1.ReceiverTypeGithub,
+		templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", Name: "t1"})
+	rcv.Spec.Events = []string{"push"}
+	s := newTestServer(t, rcv, newTestObjectTemplate("t1", nil))
+
+	body := `{"ref":"refs/heads/main"}`
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, body, map[string]string{
+		"X-Hub-Signature-256": hmacSignature("wrong", body),
+		"X-GitHub-Event":      "push",
+	})).To(Equal(http.StatusUnauthorized))
+	g.Expect(requestedAt(g, s, "t1")).To(BeEmpty())
+
+	// valid events that do not match the filter are accepted, but do not trigger anything
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, body, map[string]string{
+		"X-Hub-Signature-256": hmacSignature(testToken, body),
+		"X-GitHub-Event":      "ping",
+	})).To(Equal(http.StatusOK))
+	g.Expect(requestedAt(g, s, "t1")).To(BeEmpty())
+
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, body, map[string]string{
+		"X-Hub-Signature-256": hmacSignature(testToken, body),
+		"X-GitHub-Event":      "Push",
+	})).To(Equal(http.StatusOK))
+	g.Expect(requestedAt(g, s, "t1")).ToNot(BeEmpty())
+}
+
+func TestHandleHookDebounce(t *testing.T) {
+	g := NewWithT(t)
+
+	rcv := newTestReceiver("generic", templatesv1alpha1.ReceiverTypeGeneric,
+		templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", Name: "t1"})
+	rcv.Spec.Debounce = metav1.Duration{Duration: time.Hour}
+	s := newTestServer(t, rcv, newTestObjectTemplate("t1", nil))
+
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, "", nil)).To(Equal(http.StatusOK))
+	g.Expect(sendEvent(s, rcv.Status.WebhookPath, "", nil)).To(Equal(http.StatusOK))
+	g.Expect(s.getDebouncer().Pending()).To(Equal(1))
+	g.Expect(requestedAt(g, s, "t1")).To(BeEmpty())
+}
+
+func TestValidateRequest(t *testing.T) {
+	body := []byte(`{"a":"b"}`)
+
+	tests := []struct {
+		name         string
+		receiverType string
+		header       map[string]string
+		event        string
+		err          string
+	}{
+		{name: "generic", receiverType: templatesv1alpha1.ReceiverTypeGeneric},
+		{
+			name:         "generic-hmac",
+			receiverType: templatesv1alpha1.ReceiverTypeGenericHmac,
+			header:       map[string]string{"X-Signature": hmacSignature(testToken, string(body))},
+		},
+		{
+			name:         "generic-hmac missing signature",
+			receiverType: templatesv1alpha1.ReceiverTypeGenericHmac,
+			err:          "missing or invalid signature",
+		},
+		{
+			name:         "generic-hmac invalid signature",
+			receiverType: templatesv1alpha1.ReceiverTypeGenericHmac,
+			header:       map[string]string{"X-Signature": "sha256=zz"},
+			err:          "invalid signature: encoding/hex: invalid byte: U+007A 'z'",
+		},
+		{
+			name:         "generic-hmac signature mismatch",
+			receiverType: templatesv1alpha1.ReceiverTypeGenericHmac,
+			header:       map[string]string{"X-Signature": hmacSignature("wrong", string(body))},
+			err:          "signature mismatch",
+		},
+		{
+			name:         "gitlab",
+			receiverType: templatesv1alpha1.ReceiverTypeGitlab,
+			header:       map[string]string{"X-Gitlab-Token": testToken, "X-Gitlab-Event": "Push Hook"},
+			event:        "Push Hook",
+		},
+		{
+			name:         "gitlab invalid token",
+			receiverType: templatesv1alpha1.ReceiverTypeGitlab,
+			header:       map[string]string{"X-Gitlab-Token": "wrong"},
+			err:          "invalid X-Gitlab-Token header",
+		},
+		{name: "unknown", receiverType: "bitbucket", err: "unknown receiver type bitbucket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			req := httptest.NewRequest(http.MethodPost, "/hook/x", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			event, err := validateRequest(tt.receiverType, testToken, req, body)
+			if tt.err != "" {
+				g.Expect(err).To(MatchError(tt.err))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(event).To(Equal(tt.event))
+		})
+	}
+}
+
+func TestResourceGroupVersionKind(t *testing.T) {
+	g := NewWithT(t)
+
+	gvk, err := resourceGroupVersionKind(templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate", Name: "t1"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(gvk).To(Equal(templatesv1alpha1.GroupVersion.WithKind("ObjectTemplate")))
+
+	_, err = resourceGroupVersionKind(templatesv1alpha1.ReceiverResource{APIVersion: "v1", Kind: "ConfigMap", Name: "cm"})
+	g.Expect(err).To(MatchError("resource ConfigMap of group  can not be triggered by receivers"))
+
+	_, err = resourceGroupVersionKind(templatesv1alpha1.ReceiverResource{Kind: "ObjectTemplate"})
+	g.Expect(err).To(MatchError("resource ObjectTemplate must specify either name or matchLabels"))
+}
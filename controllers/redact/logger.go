@@ -0,0 +1,79 @@
+package redact
+
+import (
+	"errors"
+	"github.com/go-logr/logr"
+)
+
+// Logger wraps l so that all values registered with r are redacted from log messages, string values and errors.
+func Logger(l logr.Logger, r *Registry) logr.Logger {
+	if l.GetSink() == nil {
+		return l
+	}
+	return logr.New(&logSink{sink: l.GetSink(), r: r})
+}
+
+type logSink struct {
+	sink logr.LogSink
+	r    *Registry
+}
+
+func (s *logSink) Init(info logr.RuntimeInfo) {
+	// account for the additional frame of this sink
+	info.CallDepth++
+	s.sink.Init(info)
+}
+
+func (s *logSink) Enabled(level int) bool {
+	return s.sink.Enabled(level)
+}
+
+func (s *logSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.sink.Info(level, s.r.String(msg), redactValues(s.r, keysAndValues)...)
+}
+
+func (s *logSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(redactError(s.r, err), s.r.String(msg), redactValues(s.r, keysAndValues)...)
+}
+
+func (s *logSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logSink{sink: s.sink.WithValues(redactValues(s.r, keysAndValues)...), r: s.r}
+}
+
+func (s *logSink) WithName(name string) logr.LogSink {
+	return &logSink{sink: s.sink.WithName(name), r: s.r}
+}
+
+func (s *logSink) WithCallDepth(depth int) logr.LogSink {
+	if cd, ok := s.sink.(logr.CallDepthLogSink); ok {
+		return &logSink{sink: cd.WithCallDepth(depth), r: s.r}
+	}
+	return s
+}
+
+func redactError(r *Registry, err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	redacted := r.String(msg)
+	if redacted == msg {
+		return err
+	}
+	return errors.New(redacted)
+}
+
+func redactValues(r *Registry, keysAndValues []interface{}) []interface{} {
+	ret := make([]interface{}, len(keysAndValues))
+	for i, v := range keysAndValues {
+		switch x := v.(type) {
+		case string:
+			ret[i] = r.String(x)
+		case error:
+			ret[i] = redactError(r, x)
+		default:
+			ret[i] = v
+		}
+	}
+	return ret
+}
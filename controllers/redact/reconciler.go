@@ -0,0 +1,16 @@
+package redact
+
+import (
+	"context"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconciler wraps r so that every reconciliation gets its own Registry, see NewContext. Values registered while
+// reconciling are also redacted from the returned error, which is logged by controller-runtime.
+func Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		ctx = NewContext(ctx)
+		res, err := r.Reconcile(ctx, req)
+		return res, redactError(FromContext(ctx), err)
+	})
+}
@@ -0,0 +1,31 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// EventRecorder wraps rec so that all values registered with the Registry stored in ctx are redacted from event
+// messages.
+func EventRecorder(ctx context.Context, rec record.EventRecorder) record.EventRecorder {
+	return &eventRecorder{rec: rec, r: FromContext(ctx)}
+}
+
+type eventRecorder struct {
+	rec record.EventRecorder
+	r   *Registry
+}
+
+func (e *eventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	e.rec.Event(object, eventtype, reason, e.r.String(message))
+}
+
+func (e *eventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.rec.Event(object, eventtype, reason, e.r.String(fmt.Sprintf(messageFmt, args...)))
+}
+
+func (e *eventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.rec.AnnotatedEventf(object, annotations, eventtype, reason, "%s", e.r.String(fmt.Sprintf(messageFmt, args...)))
+}
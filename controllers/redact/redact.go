@@ -0,0 +1,139 @@
+package redact
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/go-logr/logr"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Placeholder replaces redacted values
+	Placeholder = "*****"
+
+	// values shorter than this are not redacted, as it would otherwise render most messages unreadable
+	minLength = 6
+)
+
+// Registry keeps track of secret values that must never appear in condition messages, events or logs. A Registry only
+// lives for a single reconciliation (see NewContext), so that values read on behalf of one object are only redacted
+// from the messages, events and logs of the same object. Otherwise, tenants could hide the diagnostics of other
+// objects by putting common words into their secrets. A nil Registry redacts nothing.
+type Registry struct {
+	mutex  sync.RWMutex
+	values map[string]bool
+	// sorted contains all values, longest first, so that values containing other values are replaced as a whole
+	sorted []string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx with a new, empty Registry. The logger stored in ctx is wrapped so that it redacts
+// all values registered with the new Registry.
+func NewContext(ctx context.Context) context.Context {
+	r := &Registry{}
+	ctx = context.WithValue(ctx, contextKey{}, r)
+	return logr.NewContext(ctx, Logger(logr.FromContextOrDiscard(ctx), r))
+}
+
+// FromContext returns the Registry stored in ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Registry {
+	r, _ := ctx.Value(contextKey{}).(*Registry)
+	return r
+}
+
+// Register registers the given secret values with the Registry stored in ctx.
+func Register(ctx context.Context, values ...string) {
+	FromContext(ctx).Register(values...)
+}
+
+// RegisterBytes registers the given secret values with the Registry stored in ctx.
+func RegisterBytes(ctx context.Context, values ...[]byte) {
+	r := FromContext(ctx)
+	for _, v := range values {
+		r.Register(string(v))
+	}
+}
+
+// String redacts all values registered with the Registry stored in ctx from s.
+func String(ctx context.Context, s string) string {
+	return FromContext(ctx).String(s)
+}
+
+// Error returns the redacted error message of err.
+func Error(ctx context.Context, err error) string {
+	if err == nil {
+		return ""
+	}
+	return FromContext(ctx).String(err.Error())
+}
+
+func (r *Registry) Register(values ...string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.values == nil {
+		r.values = map[string]bool{}
+	}
+	changed := false
+	add := func(v string) {
+		if !r.values[v] {
+			r.values[v] = true
+			changed = true
+		}
+	}
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minLength {
+			continue
+		}
+		add(v)
+		if strings.Contains(v, "\n") {
+			// multi-line secrets (e.g. private keys) might appear partially
+			for _, l := range strings.Split(v, "\n") {
+				l = strings.TrimSpace(l)
+				if len(l) >= minLength {
+					add(l)
+				}
+			}
+		}
+		// secrets often appear in base64 encoded form, e.g. when a whole secret object is rendered
+		add(base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+
+	if changed {
+		r.sorted = make([]string, 0, len(r.values))
+		for v := range r.values {
+			r.sorted = append(r.sorted, v)
+		}
+		sort.Slice(r.sorted, func(i, j int) bool {
+			if len(r.sorted[i]) != len(r.sorted[j]) {
+				return len(r.sorted[i]) > len(r.sorted[j])
+			}
+			return r.sorted[i] < r.sorted[j]
+		})
+	}
+}
+
+func (r *Registry) String(s string) string {
+	if r == nil {
+		return s
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, v := range r.sorted {
+		if strings.Contains(s, v) {
+			s = strings.ReplaceAll(s, v, Placeholder)
+		}
+	}
+	return s
+}
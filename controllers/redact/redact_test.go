@@ -0,0 +1,140 @@
+package redact
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRegistry(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &Registry{}
+	r.Register("my-secret-token", " padded-secret \n", "short")
+
+	g.Expect(r.String("token is my-secret-token")).To(Equal("token is " + Placeholder))
+	g.Expect(r.String("padded-secret")).To(Equal(Placeholder))
+	// short values would render most messages unreadable
+	g.Expect(r.String("short")).To(Equal("short"))
+	// secrets often appear base64 encoded, e.g. in rendered Secret objects
+	g.Expect(r.String(base64.StdEncoding.EncodeToString([]byte("my-secret-token")))).To(Equal(Placeholder))
+}
+
+func TestRegistryLongestFirst(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &Registry{}
+	r.Register("secret", "secret-with-suffix")
+
+	// values containing other values must be replaced as a whole, otherwise parts of them would leak
+	g.Expect(r.String("a secret-with-suffix")).To(Equal("a " + Placeholder))
+	g.Expect(r.String("a secret")).To(Equal("a " + Placeholder))
+}
+
+func TestRegistryMultiLine(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &Registry{}
+	r.Register("-----BEGIN KEY-----\nline1line1\nline2line2\n-----END KEY-----")
+
+	g.Expect(r.String("error at line2line2")).To(Equal("error at " + Placeholder))
+}
+
+func TestNilRegistry(t *testing.T) {
+	g := NewWithT(t)
+
+	var r *Registry
+	r.Register("my-secret-token")
+	g.Expect(r.String("my-secret-token")).To(Equal("my-secret-token"))
+
+	// without a registry in the context, nothing is redacted
+	Register(context.Background(), "my-secret-token")
+	g.Expect(String(context.Background(), "my-secret-token")).To(Equal("my-secret-token"))
+}
+
+func TestContextScope(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx1 := NewContext(context.Background())
+	ctx2 := NewContext(context.Background())
+	Register(ctx1, "tenant-a-secret")
+	RegisterBytes(ctx2, []byte("tenant-b-secret"))
+
+	// values are only redacted from messages of the same reconciliation
+	g.Expect(String(ctx1, "tenant-a-secret tenant-b-secret")).To(Equal(Placeholder + " tenant-b-secret"))
+	g.Expect(String(ctx2, "tenant-a-secret tenant-b-secret")).To(Equal("tenant-a-secret " + Placeholder))
+
+	// derived contexts share the registry
+	ctx3, cancel := context.WithCancel(ctx1)
+	defer cancel()
+	Register(ctx3, "another-secret")
+	g.Expect(String(ctx1, "another-secret")).To(Equal(Placeholder))
+}
+
+func TestRedactError(t *testing.T) {
+	g := NewWithT(t)
+
+	ctx := NewContext(context.Background())
+	Register(ctx, "some-secret-value")
+	g.Expect(Error(ctx, errors.New("failed with some-secret-value"))).To(Equal("failed with " + Placeholder))
+	g.Expect(Error(ctx, nil)).To(Equal(""))
+
+	r := FromContext(ctx)
+	err := errors.New("unrelated error")
+	g.Expect(redactError(r, err)).To(BeIdenticalTo(err))
+	g.Expect(redactError(r, errors.New("some-secret-value")).Error()).To(Equal(Placeholder))
+	g.Expect(redactValues(r, []any{"key", "some-secret-value", 1})).To(Equal([]any{"key", Placeholder, 1}))
+}
+
+type recordedLog struct {
+	msg           string
+	keysAndValues []any
+}
+
+type recordingSink struct {
+	logs *[]recordedLog
+}
+
+func (s recordingSink) Init(info logr.RuntimeInfo)   {}
+func (s recordingSink) Enabled(level int) bool       { return true }
+func (s recordingSink) WithName(string) logr.LogSink { return s }
+func (s recordingSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return s
+}
+func (s recordingSink) Info(level int, msg string, keysAndValues ...any) {
+	*s.logs = append(*s.logs, recordedLog{msg: msg, keysAndValues: keysAndValues})
+}
+func (s recordingSink) Error(err error, msg string, keysAndValues ...any) {
+	*s.logs = append(*s.logs, recordedLog{msg: msg, keysAndValues: append([]any{err.Error()}, keysAndValues...)})
+}
+
+func TestReconciler(t *testing.T) {
+	g := NewWithT(t)
+
+	var logs []recordedLog
+	ctx := logr.NewContext(context.Background(), logr.New(recordingSink{logs: &logs}))
+
+	var registries []*Registry
+	r := Reconciler(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		registries = append(registries, FromContext(ctx))
+		Register(ctx, "reconcile-secret")
+		logr.FromContextOrDiscard(ctx).Info("read reconcile-secret", "value", "reconcile-secret")
+		return reconcile.Result{}, fmt.Errorf("failed with reconcile-secret")
+	}))
+
+	_, err := r.Reconcile(ctx, reconcile.Request{})
+	g.Expect(err).To(MatchError("failed with " + Placeholder))
+	g.Expect(logs).To(Equal([]recordedLog{{msg: "read " + Placeholder, keysAndValues: []any{"value", Placeholder}}}))
+
+	// every reconciliation gets its own registry
+	_, _ = r.Reconcile(ctx, reconcile.Request{})
+	g.Expect(registries).To(HaveLen(2))
+	g.Expect(registries[0]).ToNot(BeNil())
+	g.Expect(registries[0]).ToNot(BeIdenticalTo(registries[1]))
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sync"
+	"time"
+)
+
+const defaultRolloutBatchInterval = 30 * time.Second
+
+// rolloutPlan holds the matrix entries to apply in the current reconciliation
+type rolloutPlan struct {
+	// deferred contains the matrix keys of changed entries that are left for later batches
+	deferred map[string]bool
+	// batch contains the matrix keys of changed entries that are applied in this batch
+	batch map[string]bool
+}
+
+// planRollout determines which changed matrix entries are applied in the current batch. Unchanged entries are always
+// applied, so that their objects are kept up-to-date. If no batch size is configured, all entries are applied. Changed
+// entries are held back until BatchInterval has passed since the last batch.
+func planRollout(rt *templatesv1alpha1.ObjectTemplate, entries []renderedMatrixEntry, now time.Time) *rolloutPlan {
+	plan := &rolloutPlan{
+		deferred: map[string]bool{},
+		batch:    map[string]bool{},
+	}
+	if rt.Spec.Rollout == nil || rt.Spec.Rollout.BatchSize == 0 {
+		rt.Status.Rollout = nil
+		return plan
+	}
+
+	status := rt.Status.Rollout
+	if status == nil {
+		status = &templatesv1alpha1.RolloutStatus{}
+	}
+	paused := rt.Spec.Rollout.PauseOnFailure && len(status.FailedEntries) != 0 && status.FailedGeneration == rt.GetGeneration()
+	failed := map[string]bool{}
+	if paused {
+		for _, k := range status.FailedEntries {
+			failed[k] = true
+		}
+	}
+	waiting := status.LastBatchTime != nil && now.Sub(status.LastBatchTime.Time) < rolloutBatchInterval(rt.Spec.Rollout)
+
+	appliedHashes := map[templatesv1alpha1.ObjectRef]string{}
+	for _, ari := range rt.Status.AppliedResources {
+		if ari.Success {
+			appliedHashes[ari.Ref.WithoutVersion()] = ari.RenderedHash
+		}
+	}
+
+	for _, e := range entries {
+		if failed[e.matrixKey] {
+			// failed entries are retried on every reconciliation, so that their health is re-evaluated
+			plan.batch[e.matrixKey] = true
+			continue
+		}
+		if !isEntryChanged(e, appliedHashes) {
+			continue
+		}
+		if !waiting && !paused && len(plan.batch) < rt.Spec.Rollout.BatchSize {
+			plan.batch[e.matrixKey] = true
+		} else {
+			plan.deferred[e.matrixKey] = true
+		}
+	}
+
+	rt.Status.Rollout = &templatesv1alpha1.RolloutStatus{
+		PendingEntries:   len(plan.deferred),
+		Paused:           paused,
+		FailedEntries:    status.FailedEntries,
+		FailedGeneration: status.FailedGeneration,
+		LastBatchTime:    status.LastBatchTime,
+	}
+	if !paused {
+		rt.Status.Rollout.FailedEntries = nil
+		rt.Status.Rollout.FailedGeneration = 0
+	}
+	if len(plan.batch) != 0 && !paused {
+		rt.Status.Rollout.LastBatchTime = &metav1.Time{Time: now}
+	}
+	return plan
+}
+
+// isEntryChanged returns true if one of the rendered objects of the matrix entry was not applied successfully yet or
+// has a different rendered hash than recorded in the applied resources
+func isEntryChanged(e renderedMatrixEntry, appliedHashes map[templatesv1alpha1.ObjectRef]string) bool {
+	for _, x := range e.objects {
+		ref := templatesv1alpha1.ObjectRefFromObject(x)
+		h, ok := appliedHashes[ref.WithoutVersion()]
+		if !ok || h != x.GetAnnotations()[templatesv1alpha1.RenderedHashAnnotation] {
+			return true
+		}
+	}
+	return false
+}
+
+// finishRollout records the matrix entries of the current batch that failed to apply or have degraded objects. With
+// pauseOnFailure, these entries pause the rollout until they succeed or the ObjectTemplate changes. Pending objects
+// that wait for earlier waves or dependencies are not considered.
+func finishRollout(rt *templatesv1alpha1.ObjectTemplate, plan *rolloutPlan, entries []renderedMatrixEntry, appliedResources map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo, pending map[templatesv1alpha1.ObjectRef]bool) {
+	if rt.Status.Rollout == nil || !rt.Spec.Rollout.PauseOnFailure {
+		return
+	}
+
+	var failed []string
+	for _, e := range entries {
+		if !plan.batch[e.matrixKey] {
+			continue
+		}
+		for _, x := range e.objects {
+			ref := templatesv1alpha1.ObjectRefFromObject(x)
+			if pending[ref.WithoutVersion()] {
+				continue
+			}
+			ari, ok := appliedResources[ref.WithoutVersion()]
+			if !ok || !ari.Success || ari.Health == templatesv1alpha1.HealthDegraded {
+				failed = append(failed, e.matrixKey)
+				break
+			}
+		}
+	}
+
+	rt.Status.Rollout.FailedEntries = failed
+	rt.Status.Rollout.FailedGeneration = 0
+	rt.Status.Rollout.Paused = len(failed) != 0
+	if len(failed) != 0 {
+		rt.Status.Rollout.FailedGeneration = rt.GetGeneration()
+	}
+}
+
+func rolloutBatchInterval(spec *templatesv1alpha1.RolloutStrategy) time.Duration {
+	d := defaultRolloutBatchInterval
+	if spec.BatchInterval != nil {
+		d = spec.BatchInterval.Duration
+	}
+	if d < time.Second {
+		d = time.Second
+	}
+	return d
+}
+
+// rolloutRequeueAfter returns the time after which the next batch must be applied, if it is earlier than requeueAfter
+func rolloutRequeueAfter(requeueAfter time.Duration, spec *templatesv1alpha1.RolloutStrategy, status *templatesv1alpha1.RolloutStatus, now time.Time) time.Duration {
+	if spec == nil || status == nil || status.PendingEntries == 0 {
+		return requeueAfter
+	}
+	d := rolloutBatchInterval(spec)
+	if status.LastBatchTime != nil {
+		d -= now.Sub(status.LastBatchTime.Time)
+	}
+	if d < time.Second {
+		d = time.Second
+	}
+	if d < requeueAfter {
+		return d
+	}
+	return requeueAfter
+}
+
+// filterWaves removes the objects of deferred matrix entries from all waves
+func filterWaves(waves []*objectWave, deferred map[string]bool) []*objectWave {
+	if len(deferred) == 0 {
+		return waves
+	}
+	var ret []*objectWave
+	for _, w := range waves {
+		fw := &objectWave{wave: w.wave}
+		for _, x := range w.objects {
+			if !deferred[x.GetAnnotations()[templatesv1alpha1.MatrixKeyAnnotation]] {
+				fw.objects = append(fw.objects, x)
+			}
+		}
+		if len(fw.objects) != 0 {
+			ret = append(ret, fw)
+		}
+	}
+	return ret
+}
+
+// entryLimiter limits the number of matrix entries whose objects are applied concurrently. Objects of matrix entries
+// that are already being applied do not need to wait.
+type entryLimiter struct {
+	max    int
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	active map[string]int
+}
+
+func newEntryLimiter(max int) *entryLimiter {
+	l := &entryLimiter{
+		max:    max,
+		active: map[string]int{},
+	}
+	l.cond = sync.NewCond(&l.mutex)
+	return l
+}
+
+func (l *entryLimiter) acquire(x *unstructured.Unstructured) {
+	if l.max == 0 {
+		return
+	}
+	key := x.GetAnnotations()[templatesv1alpha1.MatrixKeyAnnotation]
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for l.active[key] == 0 && len(l.active) >= l.max {
+		l.cond.Wait()
+	}
+	l.active[key]++
+}
+
+func (l *entryLimiter) release(x *unstructured.Unstructured) {
+	if l.max == 0 {
+		return
+	}
+	key := x.GetAnnotations()[templatesv1alpha1.MatrixKeyAnnotation]
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.active[key]--
+	if l.active[key] == 0 {
+		delete(l.active, key)
+	}
+	l.cond.Broadcast()
+}
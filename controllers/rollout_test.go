@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newRolloutEntry returns a matrix entry with a single ConfigMap rendered with the given hash
+func newRolloutEntry(key string, hash string) renderedMatrixEntry {
+	return renderedMatrixEntry{
+		matrixKey: key,
+		objects: []*unstructured.Unstructured{
+			newTestObject("v1", "ConfigMap", "default", key, map[string]string{
+				templatesv1alpha1.MatrixKeyAnnotation:    key,
+				templatesv1alpha1.RenderedHashAnnotation: hash,
+			}),
+		},
+	}
+}
+
+// appliedEntry returns the applied resource info of the ConfigMap of newRolloutEntry
+func appliedEntry(key string, hash string, success bool, health string) templatesv1alpha1.AppliedResourceInfo {
+	return templatesv1alpha1.AppliedResourceInfo{
+		Ref:          templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: key},
+		Success:      success,
+		Health:       health,
+		RenderedHash: hash,
+	}
+}
+
+func newRolloutTemplate(rollout *templatesv1alpha1.RolloutStrategy, applied ...templatesv1alpha1.AppliedResourceInfo) *templatesv1alpha1.ObjectTemplate {
+	return &templatesv1alpha1.ObjectTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "example", Generation: 1},
+		Spec:       templatesv1alpha1.ObjectTemplateSpec{Rollout: rollout},
+		Status:     templatesv1alpha1.ObjectTemplateStatus{AppliedResources: applied},
+	}
+}
+
+func TestPlanRolloutWithoutBatches(t *testing.T) {
+	g := NewWithT(t)
+
+	rt := newRolloutTemplate(&templatesv1alpha1.RolloutStrategy{MaxConcurrent: 2})
+	rt.Status.Rollout = &templatesv1alpha1.RolloutStatus{PendingEntries: 1}
+
+	plan := planRollout(rt, []renderedMatrixEntry{newRolloutEntry("a", "1"), newRolloutEntry("b", "1")}, time.Now())
+	g.Expect(plan.deferred).To(BeEmpty())
+	g.Expect(rt.Status.Rollout).To(BeNil())
+}
+
+func TestPlanRolloutBatches(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	rollout := &templatesv1alpha1.RolloutStrategy{BatchSize: 2, BatchInterval: &metav1.Duration{Duration: time.Minute}}
+	// a is unchanged, b and c changed and d was never applied
+	rt := newRolloutTemplate(rollout,
+		appliedEntry("a", "1", true, templatesv1alpha1.HealthHealthy),
+		appliedEntry("b", "1", true, templatesv1alpha1.HealthHealthy),
+		appliedEntry("c", "1", true, templatesv1alpha1.HealthHealthy),
+	)
+	entries := []renderedMatrixEntry{newRolloutEntry("a", "1"), newRolloutEntry("b", "2"), newRolloutEntry("c", "2"), newRolloutEntry("d", "1")}
+
+	plan := planRollout(rt, entries, now)
+	g.Expect(plan.batch).To(Equal(map[string]bool{"b": true, "c": true}))
+	g.Expect(plan.deferred).To(Equal(map[string]bool{"d": true}))
+	g.Expect(rt.Status.Rollout.PendingEntries).To(Equal(1))
+	g.Expect(rt.Status.Rollout.LastBatchTime.Time).To(Equal(now))
+
+	// the next batch has to wait for the batch interval
+	rt.Status.AppliedResources = []templatesv1alpha1.AppliedResourceInfo{
+		appliedEntry("a", "1", true, templatesv1alpha1.HealthHealthy),
+		appliedEntry("b", "2", true, templatesv1alpha1.HealthHealthy),
+		appliedEntry("c", "2", true, templatesv1alpha1.HealthHealthy),
+	}
+	plan = planRollout(rt, entries, now.Add(30*time.Second))
+	g.Expect(plan.batch).To(BeEmpty())
+	g.Expect(plan.deferred).To(Equal(map[string]bool{"d": true}))
+	g.Expect(rt.Status.Rollout.LastBatchTime.Time).To(Equal(now))
+
+	plan = planRollout(rt, entries, now.Add(time.Minute))
+	g.Expect(plan.batch).To(Equal(map[string]bool{"d": true}))
+	g.Expect(plan.deferred).To(BeEmpty())
+	g.Expect(rt.Status.Rollout.PendingEntries).To(Equal(0))
+	g.Expect(rt.Status.Rollout.LastBatchTime.Time).To(Equal(now.Add(time.Minute)))
+}
+
+func TestRolloutPauseOnFailure(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	rollout := &templatesv1alpha1.RolloutStrategy{BatchSize: 1, BatchInterval: &metav1.Duration{Duration: time.Second}, PauseOnFailure: true}
+	rt := newRolloutTemplate(rollout)
+	entries := []renderedMatrixEntry{newRolloutEntry("a", "1"), newRolloutEntry("b", "1")}
+
+	plan := planRollout(rt, entries, now)
+	g.Expect(plan.batch).To(Equal(map[string]bool{"a": true}))
+
+	// a degraded object fails the entry and pauses the rollout
+	applied := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
+	ari := appliedEntry("a", "1", true, templatesv1alpha1.HealthDegraded)
+	applied[ari.Ref.WithoutVersion()] = ari
+	finishRollout(rt, plan, entries, applied, nil)
+	g.Expect(rt.Status.Rollout.Paused).To(BeTrue())
+	g.Expect(rt.Status.Rollout.FailedEntries).To(Equal([]string{"a"}))
+	g.Expect(rt.Status.Rollout.FailedGeneration).To(Equal(int64(1)))
+
+	// while paused, only the failed entry is retried
+	rt.Status.AppliedResources = []templatesv1alpha1.AppliedResourceInfo{ari}
+	plan = planRollout(rt, entries, now.Add(time.Minute))
+	g.Expect(plan.batch).To(Equal(map[string]bool{"a": true}))
+	g.Expect(plan.deferred).To(Equal(map[string]bool{"b": true}))
+	g.Expect(rt.Status.Rollout.Paused).To(BeTrue())
+	g.Expect(rt.Status.Rollout.LastBatchTime.Time).To(Equal(now))
+
+	// once the failed entry recovers, the rollout continues
+	ari = appliedEntry("a", "1", true, templatesv1alpha1.HealthHealthy)
+	applied[ari.Ref.WithoutVersion()] = ari
+	finishRollout(rt, plan, entries, applied, nil)
+	g.Expect(rt.Status.Rollout.Paused).To(BeFalse())
+	g.Expect(rt.Status.Rollout.FailedEntries).To(BeEmpty())
+
+	rt.Status.AppliedResources = []templatesv1alpha1.AppliedResourceInfo{ari}
+	plan = planRollout(rt, entries, now.Add(2*time.Minute))
+	g.Expect(plan.batch).To(Equal(map[string]bool{"b": true}))
+}
+
+func TestRolloutResumesOnNewGeneration(t *testing.T) {
+	g := NewWithT(t)
+
+	rollout := &templatesv1alpha1.RolloutStrategy{BatchSize: 1, PauseOnFailure: true}
+	rt := newRolloutTemplate(rollout)
+	rt.Status.Rollout = &templatesv1alpha1.RolloutStatus{Paused: true, FailedEntries: []string{"a"}, FailedGeneration: 1}
+	rt.Generation = 2
+
+	plan := planRollout(rt, []renderedMatrixEntry{newRolloutEntry("a", "2")}, time.Now())
+	g.Expect(plan.batch).To(Equal(map[string]bool{"a": true}))
+	g.Expect(rt.Status.Rollout.Paused).To(BeFalse())
+	g.Expect(rt.Status.Rollout.FailedEntries).To(BeNil())
+}
+
+func TestFinishRolloutIgnoresPendingObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	rt := newRolloutTemplate(&templatesv1alpha1.RolloutStrategy{BatchSize: 1, PauseOnFailure: true})
+	entries := []renderedMatrixEntry{newRolloutEntry("a", "1")}
+	plan := planRollout(rt, entries, time.Now())
+
+	// objects that wait for earlier waves are not applied yet, which is not a failure
+	ref := templatesv1alpha1.ObjectRefFromObject(entries[0].objects[0])
+	finishRollout(rt, plan, entries, nil, map[templatesv1alpha1.ObjectRef]bool{ref.WithoutVersion(): true})
+	g.Expect(rt.Status.Rollout.Paused).To(BeFalse())
+
+	finishRollout(rt, plan, entries, nil, nil)
+	g.Expect(rt.Status.Rollout.Paused).To(BeTrue())
+}
+
+func TestRolloutRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	spec := &templatesv1alpha1.RolloutStrategy{BatchSize: 1, BatchInterval: &metav1.Duration{Duration: time.Minute}}
+	pending := &templatesv1alpha1.RolloutStatus{PendingEntries: 1, LastBatchTime: &metav1.Time{Time: now.Add(-20 * time.Second)}}
+
+	g.Expect(rolloutRequeueAfter(time.Hour, nil, pending, now)).To(Equal(time.Hour))
+	g.Expect(rolloutRequeueAfter(time.Hour, spec, &templatesv1alpha1.RolloutStatus{}, now)).To(Equal(time.Hour))
+	g.Expect(rolloutRequeueAfter(time.Hour, spec, pending, now)).To(Equal(40 * time.Second))
+	g.Expect(rolloutRequeueAfter(10*time.Second, spec, pending, now)).To(Equal(10 * time.Second))
+
+	overdue := &templatesv1alpha1.RolloutStatus{PendingEntries: 1, LastBatchTime: &metav1.Time{Time: now.Add(-time.Hour)}}
+	g.Expect(rolloutRequeueAfter(time.Hour, spec, overdue, now)).To(Equal(time.Second))
+
+	// the batch interval defaults to 30s
+	g.Expect(rolloutRequeueAfter(time.Hour, &templatesv1alpha1.RolloutStrategy{BatchSize: 1}, &templatesv1alpha1.RolloutStatus{PendingEntries: 1}, now)).To(Equal(defaultRolloutBatchInterval))
+}
+
+func TestFilterWaves(t *testing.T) {
+	g := NewWithT(t)
+
+	a := newRolloutEntry("a", "1").objects[0]
+	b := newRolloutEntry("b", "1").objects[0]
+	waves := []*objectWave{{wave: 0, objects: []*unstructured.Unstructured{a, b}}, {wave: 1, objects: []*unstructured.Unstructured{b}}}
+
+	g.Expect(filterWaves(waves, nil)).To(Equal(waves))
+
+	filtered := filterWaves(waves, map[string]bool{"b": true})
+	g.Expect(filtered).To(HaveLen(1))
+	g.Expect(filtered[0].wave).To(Equal(0))
+	g.Expect(filtered[0].objects).To(HaveExactElements(a))
+}
+
+func TestEntryLimiter(t *testing.T) {
+	g := NewWithT(t)
+
+	l := newEntryLimiter(2)
+	var mutex sync.Mutex
+	active := map[string]int{}
+	maxActive := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 3; j++ {
+			x := newRolloutEntry(fmt.Sprintf("e%d", i), "1").objects[0]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				key := x.GetAnnotations()[templatesv1alpha1.MatrixKeyAnnotation]
+				l.acquire(x)
+				mutex.Lock()
+				active[key]++
+				if len(active) > maxActive {
+					maxActive = len(active)
+				}
+				mutex.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				active[key]--
+				if active[key] == 0 {
+					delete(active, key)
+				}
+				mutex.Unlock()
+				l.release(x)
+			}()
+		}
+	}
+	wg.Wait()
+
+	// objects of the same entry share a slot, but no more than 2 entries are applied concurrently
+	g.Expect(maxActive).To(BeNumerically("<=", 2))
+	g.Expect(l.active).To(BeEmpty())
+}
@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"time"
+	// schedules refer to IANA time zones, which might not be available in the container image
+	_ "time/tzdata"
+)
+
+var scheduleWeekdays = map[templatesv1alpha1.ScheduleWeekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// reconcileSchedule updates the schedule status of rt and returns true if the template is currently active. Inactive
+// templates with the Prune action get all applied objects deleted, unless dry-run is enabled.
+func (r *ObjectTemplateReconciler) reconcileSchedule(ctx context.Context, objClient client.Client, rt *templatesv1alpha1.ObjectTemplate, owner string) (bool, error) {
+	if rt.Spec.Schedule == nil {
+		rt.Status.Schedule = nil
+		return true, nil
+	}
+
+	status, err := evaluateSchedule(rt.Spec.Schedule, rt.GetCreationTimestamp().Time, time.Now())
+	if err != nil {
+		return false, err
+	}
+	rt.Status.Schedule = status
+	if status.Active {
+		return true, nil
+	}
+
+	if rt.Spec.Schedule.Action != templatesv1alpha1.ScheduleActionPrune || len(rt.Status.AppliedResources) == 0 {
+		return false, nil
+	}
+	if rt.Spec.DryRun {
+		log.FromContext(ctx).Info("Not pruning objects of inactive template while dry-run is enabled")
+		return false, nil
+	}
+
+	log.FromContext(ctx).Info("Pruning all objects as the template is inactive")
+
+	appliedResources := map[templatesv1alpha1.ObjectRef]templatesv1alpha1.AppliedResourceInfo{}
+	for _, n := range rt.Status.AppliedResources {
+		appliedResources[n.Ref.WithoutVersion()] = n
+	}
+	err = r.prune(ctx, objClient, owner, nil, appliedResources)
+	setAppliedResources(rt, appliedResources)
+	return false, err
+}
+
+type scheduleInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// evaluateSchedule computes the schedule status at the given time. The TTL is relative to the creation time of the
+// template.
+func evaluateSchedule(s *templatesv1alpha1.ObjectTemplateSchedule, created time.Time, now time.Time) (*templatesv1alpha1.ScheduleStatus, error) {
+	action := s.Action
+	if action == "" {
+		action = templatesv1alpha1.ScheduleActionSuspend
+	}
+
+	status := &templatesv1alpha1.ScheduleStatus{
+		Active: true,
+	}
+
+	var expiresAt *time.Time
+	if s.TTL != nil {
+		t := created.Add(s.TTL.Duration)
+		expiresAt = &t
+		status.ExpiresAt = &metav1.Time{Time: t}
+		if !now.Before(t) {
+			// expired templates stay inactive forever
+			status.Active = false
+			return status, nil
+		}
+	}
+
+	var next *time.Time
+	nextAction := action
+	if len(s.ActiveWindows) != 0 {
+		intervals, err := buildScheduleIntervals(s.ActiveWindows, now)
+		if err != nil {
+			return nil, err
+		}
+		status.Active = false
+		for _, iv := range intervals {
+			if !now.Before(iv.start) && now.Before(iv.end) {
+				status.Active = true
+				t := iv.end
+				next = &t
+				break
+			} else if iv.start.After(now) {
+				t := iv.start
+				next = &t
+				nextAction = templatesv1alpha1.ScheduleActionResume
+				break
+			}
+		}
+	}
+
+	if expiresAt != nil && (next == nil || !next.Before(*expiresAt)) {
+		// resuming after expiry is not possible
+		next = expiresAt
+		nextAction = action
+		if !status.Active {
+			next = nil
+		}
+	}
+
+	if next != nil {
+		status.NextAction = nextAction
+		status.NextActionTime = &metav1.Time{Time: *next}
+	}
+	return status, nil
+}
+
+// buildScheduleIntervals returns the sorted and merged intervals of all windows that end after now. Windows are
+// evaluated from yesterday to one week ahead, which is enough to find the next transition.
+func buildScheduleIntervals(windows []templatesv1alpha1.ScheduleWindow, now time.Time) ([]scheduleInterval, error) {
+	var intervals []scheduleInterval
+	for _, w := range windows {
+		loc := time.UTC
+		if w.TimeZone != "" {
+			var err error
+			loc, err = time.LoadLocation(w.TimeZone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time zone %s: %w", w.TimeZone, err)
+			}
+		}
+		start, err := time.Parse("15:04", w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %s: %w", w.Start, err)
+		}
+		end, err := time.Parse("15:04", w.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time %s: %w", w.End, err)
+		}
+
+		days := map[time.Weekday]bool{}
+		for _, d := range w.Days {
+			wd, ok := scheduleWeekdays[d]
+			if !ok {
+				return nil, fmt.Errorf("invalid day %s", d)
+			}
+			days[wd] = true
+		}
+
+		localNow := now.In(loc)
+		for i := -1; i <= 7; i++ {
+			day := time.Date(localNow.Year(), localNow.Month(), localNow.Day()+i, 0, 0, 0, 0, loc)
+			if len(days) != 0 && !days[day.Weekday()] {
+				continue
+			}
+			iv := scheduleInterval{
+				start: time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc),
+				end:   time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc),
+			}
+			if !iv.end.After(iv.start) {
+				iv.end = iv.end.AddDate(0, 0, 1)
+			}
+			intervals = append(intervals, iv)
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var merged []scheduleInterval
+	for _, iv := range intervals {
+		if len(merged) != 0 && !iv.start.After(merged[len(merged)-1].end) {
+			if iv.end.After(merged[len(merged)-1].end) {
+				merged[len(merged)-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+
+	// windows that already ended are only removed after merging, so that the start of an active window does not
+	// change when an overlapping window ends
+	ret := make([]scheduleInterval, 0, len(merged))
+	for _, iv := range merged {
+		if iv.end.After(now) {
+			ret = append(ret, iv)
+		}
+	}
+	return ret, nil
+}
+
+// scheduleRequeueAfter returns the interval, shortened if the next scheduled action happens earlier.
+func scheduleRequeueAfter(interval time.Duration, status *templatesv1alpha1.ScheduleStatus, now time.Time) time.Duration {
+	if status == nil || status.NextActionTime == nil {
+		return interval
+	}
+	d := status.NextActionTime.Sub(now)
+	if d < time.Second {
+		d = time.Second
+	}
+	if d < interval {
+		return d
+	}
+	return interval
+}
+
+// buildScheduleInput returns a single matrix input containing the start and end of the currently active window, or no
+// input at all if no window is active.
+func buildScheduleInput(spec *templatesv1alpha1.MatrixEntrySchedule, now time.Time) ([]any, error) {
+	intervals, err := buildScheduleIntervals(spec.Windows, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(intervals) == 0 || now.Before(intervals[0].start) {
+		return nil, nil
+	}
+	return []any{
+		map[string]any{
+			"start": intervals[0].start.Format(time.RFC3339),
+			"end":   intervals[0].end.Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// matrixScheduleRequeueAfter returns requeueAfter, shortened if a window of a schedule matrix entry starts or ends
+// earlier.
+func matrixScheduleRequeueAfter(requeueAfter time.Duration, matrix []*templatesv1alpha1.MatrixEntry, now time.Time) time.Duration {
+	for _, me := range matrix {
+		requeueAfter = matrixScheduleRequeueAfter(requeueAfter, nestedMatrixEntries(me), now)
+		if me.Schedule == nil {
+			continue
+		}
+		intervals, err := buildScheduleIntervals(me.Schedule.Windows, now)
+		if err != nil || len(intervals) == 0 {
+			// invalid windows are reported while building the matrix
+			continue
+		}
+		next := intervals[0].start
+		if !now.Before(next) {
+			next = intervals[0].end
+		}
+		d := next.Sub(now)
+		if d < time.Second {
+			d = time.Second
+		}
+		if d < requeueAfter {
+			requeueAfter = d
+		}
+	}
+	return requeueAfter
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+)
+
+// 2024-01-01 is a Monday
+func mondayAt(hour int, minute int) time.Time {
+	return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func TestBuildScheduleInput(t *testing.T) {
+	spec := &templatesv1alpha1.MatrixEntrySchedule{
+		Windows: []templatesv1alpha1.ScheduleWindow{
+			{Days: []templatesv1alpha1.ScheduleWeekday{"Mon", "Tue"}, Start: "09:00", End: "12:00"},
+			// overlapping windows are merged
+			{Days: []templatesv1alpha1.ScheduleWeekday{"Mon"}, Start: "11:00", End: "13:00"},
+			// windows ending before they start span midnight
+			{Days: []templatesv1alpha1.ScheduleWeekday{"Sun"}, Start: "22:00", End: "02:00"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []any
+	}{
+		{name: "before", now: mondayAt(8, 59)},
+		{name: "start", now: mondayAt(9, 0), want: []any{map[string]any{"start": "2024-01-01T09:00:00Z", "end": "2024-01-01T13:00:00Z"}}},
+		{name: "merged", now: mondayAt(12, 30), want: []any{map[string]any{"start": "2024-01-01T09:00:00Z", "end": "2024-01-01T13:00:00Z"}}},
+		{name: "end", now: mondayAt(13, 0)},
+		{name: "midnight", now: mondayAt(1, 0), want: []any{map[string]any{"start": "2023-12-31T22:00:00Z", "end": "2024-01-01T02:00:00Z"}}},
+		{name: "other day", now: mondayAt(10, 0).AddDate(0, 0, 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			inputs, err := buildScheduleInput(spec, tt.now)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(inputs).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestBuildScheduleInputTimeZone(t *testing.T) {
+	g := NewWithT(t)
+
+	spec := &templatesv1alpha1.MatrixEntrySchedule{
+		Windows: []templatesv1alpha1.ScheduleWindow{
+			{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin"},
+		},
+	}
+	inputs, err := buildScheduleInput(spec, mondayAt(8, 30))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(Equal([]any{map[string]any{"start": "2024-01-01T09:00:00+01:00", "end": "2024-01-01T17:00:00+01:00"}}))
+
+	inputs, err = buildScheduleInput(spec, mondayAt(16, 30))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(inputs).To(BeEmpty())
+}
+
+func TestBuildScheduleInputErrors(t *testing.T) {
+	tests := []struct {
+		window templatesv1alpha1.ScheduleWindow
+		err    string
+	}{
+		{window: templatesv1alpha1.ScheduleWindow{Start: "9am", End: "17:00"}, err: "invalid start time 9am"},
+		{window: templatesv1alpha1.ScheduleWindow{Start: "09:00", End: "25:00"}, err: "invalid end time 25:00"},
+		{window: templatesv1alpha1.ScheduleWindow{Start: "09:00", End: "17:00", TimeZone: "Mars/Olympus"}, err: "invalid time zone Mars/Olympus"},
+		{window: templatesv1alpha1.ScheduleWindow{Start: "09:00", End: "17:00", Days: []templatesv1alpha1.ScheduleWeekday{"Monday"}}, err: "invalid day Monday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			g := NewWithT(t)
+			_, err := buildScheduleInput(&templatesv1alpha1.MatrixEntrySchedule{
+				Windows: []templatesv1alpha1.ScheduleWindow{tt.window},
+			}, mondayAt(10, 0))
+			g.Expect(err).To(MatchError(ContainSubstring(tt.err)))
+		})
+	}
+}
+
+func TestMatrixScheduleRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	matrix := []*templatesv1alpha1.MatrixEntry{
+		{Name: "list"},
+		{Name: "window", Schedule: &templatesv1alpha1.MatrixEntrySchedule{
+			Windows: []templatesv1alpha1.ScheduleWindow{{Start: "09:00", End: "12:00"}},
+		}},
+	}
+
+	// the template is requeued when the next window starts or the active window ends
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix, mondayAt(8, 30))).To(Equal(30 * time.Minute))
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix, mondayAt(11, 50))).To(Equal(10 * time.Minute))
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix, mondayAt(10, 0))).To(Equal(time.Hour))
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix[:1], mondayAt(8, 30))).To(Equal(time.Hour))
+
+	// invalid windows are ignored here
+	matrix[1].Schedule.Windows[0].Start = "invalid"
+	g.Expect(matrixScheduleRequeueAfter(time.Hour, matrix, mondayAt(8, 30))).To(Equal(time.Hour))
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"io"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+// getJson sends a GET request to the given URL and decodes the JSON response into v. It is used by the generators
+// that talk to SCM APIs without a dedicated client library. The response headers are returned so that callers can
+// handle pagination.
+func getJson(ctx context.Context, hc *http.Client, url string, headers map[string]string, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, x := range headers {
+		req.Header.Set(k, x)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("GET %s returned unexpected status code %d: %s", req.URL.Redacted(), resp.StatusCode, string(rb))
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response of GET %s: %w", req.URL.Redacted(), err)
+	}
+	return resp.Header, nil
+}
+
+// defaultRequestTimeout is the timeout of requests sent by matrix entries if none is specified
+const defaultRequestTimeout = 30 * time.Second
+
+// doJsonRequest sends a request with the given method and body to the given URL and decodes the JSON response into v.
+// It is used by matrix entries that query arbitrary endpoints.
+func doJsonRequest(ctx context.Context, hc *http.Client, method string, url string, body []byte, headers map[string]string, v any) error {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, x := range headers {
+		req.Header.Set(k, x)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rb, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned unexpected status code %d: %s", method, req.URL.Redacted(), resp.StatusCode, string(rb))
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// getSecretHeaders loads the values of the given headers from their secrets
+func getSecretHeaders(ctx context.Context, c client.Client, namespace string, p *policy.Policy, secretHeaders []templatesv1alpha1.HTTPSecretHeader, headers map[string]string) error {
+	for _, h := range secretHeaders {
+		v, err := GetSecretToken(ctx, c, namespace, p, h.ValueRef)
+		if err != nil {
+			return err
+		}
+		headers[h.Name] = v
+	}
+	return nil
+}
+
+// getBasicAuthHeader loads the password of the given BasicAuth and returns the value to use for the Authorization
+// header
+func getBasicAuthHeader(ctx context.Context, c client.Client, namespace string, p *policy.Policy, ba templatesv1alpha1.BasicAuth) (string, error) {
+	password, err := GetSecretToken(ctx, c, namespace, p, ba.PasswordRef)
+	if err != nil {
+		return "", err
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(ba.Username+":"+password)), nil
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+// statusFlushTimeout is the time reserved at the end of a graceful shutdown to write the final status (including the
+// inventory of applied resources) of in-flight reconciliations.
+const statusFlushTimeout = 10 * time.Second
+
+// drainContext returns a context for a single reconciliation that survives the cancellation of ctx, which happens when
+// the manager starts to shut down. This allows in-flight render/apply/prune operations to finish instead of being
+// aborted halfway through. Once ctx is cancelled, the returned context is cancelled after ShutdownTimeout minus
+// statusFlushTimeout, so that the manager's graceful shutdown timeout is never exceeded.
+//
+// If ShutdownTimeout is not set, ctx is returned unmodified.
+func (r *BaseTemplateReconciler) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	drainTimeout := r.ShutdownTimeout - statusFlushTimeout
+	if drainTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		log.FromContext(drainCtx).Info("Shutdown requested, waiting for in-flight reconciliation to finish", "timeout", drainTimeout)
+		t := time.AfterFunc(drainTimeout, cancel)
+		context.AfterFunc(drainCtx, func() {
+			t.Stop()
+		})
+	})
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// flushContext returns a context that is used to write the final status of a reconciliation. It is detached from the
+// cancellation of ctx so that the inventory of applied resources is persisted even when the reconciliation was drained
+// during shutdown.
+//
+// If ShutdownTimeout is not set, ctx is returned unmodified.
+func (r *BaseTemplateReconciler) flushContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.ShutdownTimeout-statusFlushTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), statusFlushTimeout)
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/name"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+)
+
+const (
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignSimpleSigningType   = "cosign container image signature"
+
+	// maxCosignPayloadSize limits the size of downloaded signature payloads
+	maxCosignPayloadSize = 1 << 20
+)
+
+// loadPublicKeys returns the public keys found in the referenced Secret, sorted. If the policy defines a trusted keys
+// namespace, the Secret is read from that namespace through c, i.e. with the controller's own permissions, so that
+// tenants can not trust keys they control. Otherwise, the Secret is read from objNamespace through objClient, so the
+// impersonated service account must have permissions to get it.
+func loadPublicKeys(ctx context.Context, c client.Reader, objClient client.Client, p *policy.Policy, objNamespace string, ref templatesv1alpha1.LocalObjectReference) ([]string, error) {
+	key := client.ObjectKey{Namespace: objNamespace, Name: ref.Name}
+	reader := client.Reader(objClient)
+	if p != nil && p.TrustedKeysNamespace != "" {
+		key.Namespace = p.TrustedKeysNamespace
+		reader = c
+	}
+
+	var secret corev1.Secret
+	err := reader.Get(ctx, key, &secret)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for _, k := range secret.Data {
+		keys = append(keys, strings.TrimSpace(string(k)))
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys found in secret %s", secret.Name)
+	}
+	return keys, nil
+}
+
+// parseCosignPublicKeys parses all PEM encoded public keys, as generated by `cosign generate-key-pair`
+func parseCosignPublicKeys(keys []string) ([]crypto.PublicKey, error) {
+	var ret []crypto.PublicKey
+	for _, k := range keys {
+		rest := []byte(k)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key: %w", err)
+			}
+			ret = append(ret, pub)
+		}
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no PEM encoded public keys found")
+	}
+	return ret, nil
+}
+
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// verifyCosignSignature verifies that the manifest with the given digest is signed by one of the given keys. Signatures
+// are expected in the format produced by `cosign sign --key`, which stores them in the `sha256-<digest>.sig` tag of
+// the same repository. Keyless signatures are not supported.
+func verifyCosignSignature(repo name.Repository, digest gcrv1.Hash, keys []string, opts ...remote.Option) error {
+	pubs, err := parseCosignPublicKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	sigTag := repo.Tag(fmt.Sprintf("%s-%s.sig", digest.Algorithm, digest.Hex))
+	sigImg, err := remote.Image(sigTag, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to get signatures of %s@%s: %w", repo.String(), digest.String(), err)
+	}
+	manifest, err := sigImg.Manifest()
+	if err != nil {
+		return err
+	}
+
+	for _, l := range manifest.Layers {
+		sig, err := base64.StdEncoding.DecodeString(l.Annotations[cosignSignatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		layer, err := sigImg.LayerByDigest(l.Digest)
+		if err != nil {
+			return err
+		}
+		payload, err := readLayer(layer)
+		if err != nil {
+			return err
+		}
+
+		var p cosignPayload
+		err = json.Unmarshal(payload, &p)
+		if err != nil || p.Critical.Type != cosignSimpleSigningType || p.Critical.Image.DockerManifestDigest != digest.String() {
+			// signatures of other artifacts must not be accepted, even if they were pushed to the signature tag
+			continue
+		}
+		for _, pub := range pubs {
+			if verifySignature(pub, payload, sig) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no valid signature found for %s@%s", repo.String(), digest.String())
+}
+
+func readLayer(layer gcrv1.Layer) ([]byte, error) {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxCosignPayloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxCosignPayloadSize {
+		return nil, fmt.Errorf("signature payload exceeds the maximum size of %d bytes", maxCosignPayloadSize)
+	}
+	return b, nil
+}
+
+func verifySignature(pub crypto.PublicKey, payload []byte, sig []byte) bool {
+	h := sha256.Sum256(payload)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, h[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, sig)
+	default:
+		return false
+	}
+}
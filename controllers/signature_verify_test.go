@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCosignKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// pushTestArtifact pushes a random image to a new in-memory registry and returns its repository and digest
+func pushTestArtifact(t *testing.T) (name.Repository, gcrv1.Hash) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(s.Close)
+
+	repo, err := name.NewRepository(strings.TrimPrefix(s.URL, "http://")+"/templates", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(128, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.Write(repo.Tag("v1"), img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, digest
+}
+
+// pushCosignSignature signs signedDigest with key the same way `cosign sign --key` does and pushes the signature to
+// the signature tag of digest
+func pushCosignSignature(t *testing.T, repo name.Repository, digest gcrv1.Hash, signedDigest gcrv1.Hash, key *ecdsa.PrivateKey) {
+	var p cosignPayload
+	p.Critical.Type = cosignSimpleSigningType
+	p.Critical.Image.DockerManifestDigest = signedDigest.String()
+	payload, err := json.Marshal(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigImg, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, types.MediaType("application/vnd.dev.cosign.simplesigning.v1+json")),
+		Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.Write(repo.Tag(fmt.Sprintf("%s-%s.sig", digest.Algorithm, digest.Hex)), sigImg)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyCosignSignature(t *testing.T) {
+	key, pub := newCosignKey(t)
+	_, otherPub := newCosignKey(t)
+
+	t.Run("valid", func(t *testing.T) {
+		g := NewWithT(t)
+		repo, digest := pushTestArtifact(t)
+		pushCosignSignature(t, repo, digest, digest, key)
+
+		g.Expect(verifyCosignSignature(repo, digest, []string{otherPub, pub})).To(Succeed())
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		g := NewWithT(t)
+		repo, digest := pushTestArtifact(t)
+		pushCosignSignature(t, repo, digest, digest, key)
+
+		g.Expect(verifyCosignSignature(repo, digest, []string{otherPub})).To(MatchError(ContainSubstring("no valid signature found")))
+	})
+
+	t.Run("signature of other artifact", func(t *testing.T) {
+		g := NewWithT(t)
+		repo, digest := pushTestArtifact(t)
+		other := gcrv1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+		pushCosignSignature(t, repo, digest, other, key)
+
+		g.Expect(verifyCosignSignature(repo, digest, []string{pub})).To(MatchError(ContainSubstring("no valid signature found")))
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		g := NewWithT(t)
+		repo, digest := pushTestArtifact(t)
+
+		g.Expect(verifyCosignSignature(repo, digest, []string{pub})).To(MatchError(ContainSubstring("failed to get signatures")))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		g := NewWithT(t)
+		repo, digest := pushTestArtifact(t)
+
+		g.Expect(verifyCosignSignature(repo, digest, []string{"not a key"})).To(MatchError("no PEM encoded public keys found"))
+	})
+}
+
+func newKeysSecret(namespace string, key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "keys"},
+		Data:       map[string][]byte{"cosign.pub": []byte(key)},
+	}
+}
+
+func TestLoadPublicKeys(t *testing.T) {
+	g := NewWithT(t)
+
+	ref := templatesv1alpha1.LocalObjectReference{Name: "keys"}
+	c := fake.NewClientBuilder().WithObjects(newKeysSecret("trusted", "trusted-key")).Build()
+	objClient := fake.NewClientBuilder().WithObjects(newKeysSecret("tenant", "tenant-key")).Build()
+
+	keys, err := loadPublicKeys(context.Background(), c, objClient, nil, "tenant", ref)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(keys).To(Equal([]string{"tenant-key"}))
+
+	// with a trusted keys namespace, tenants can not trust their own keys
+	p, err := policy.New(policy.Policy{TrustedKeysNamespace: "trusted"})
+	g.Expect(err).ToNot(HaveOccurred())
+	keys, err = loadPublicKeys(context.Background(), c, objClient, p, "tenant", ref)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(keys).To(Equal([]string{"trusted-key"}))
+
+	_, err = loadPublicKeys(context.Background(), c, objClient, p.ForClusterTemplates(), "tenant", ref)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = loadPublicKeys(context.Background(), c, objClient, p, "tenant", templatesv1alpha1.LocalObjectReference{Name: "missing"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRequireSignedTemplates(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := policy.New(policy.Policy{RequireSignedTemplates: true})
+	g.Expect(err).To(MatchError("requiring signed templates needs a trusted keys namespace"))
+
+	p, err := policy.New(policy.Policy{RequireSignedTemplates: true, TrustedKeysNamespace: "trusted"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	for _, p := range []*policy.Policy{p, p.ForClusterTemplates()} {
+		r := &BaseTemplateReconciler{Policy: p}
+		objClient := fake.NewClientBuilder().Build()
+
+		_, err = r.resolveGitRepositoryTemplates(context.Background(), objClient, "tenant", &templatesv1alpha1.GitRepositoryTemplateSource{Name: "repo"})
+		g.Expect(err).To(MatchError("loading templates from GitRepository tenant/repo without signature verification is not allowed by policy"))
+
+		_, err = r.resolveOCITemplates(context.Background(), objClient, "tenant", &templatesv1alpha1.OCITemplateSource{URL: "oci://registry.example.com/templates"})
+		g.Expect(err).To(MatchError("loading templates from oci://registry.example.com/templates without signature verification is not allowed by policy"))
+	}
+}
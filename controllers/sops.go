@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	"github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/getsops/sops/v3/kms"
+	sopsjson "github.com/getsops/sops/v3/stores/json"
+	sopsyaml "github.com/getsops/sops/v3/stores/yaml"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+const (
+	decryptionProviderSOPS = "sops"
+
+	sopsAgeKeyExt = ".agekey"
+	sopsAWSKMSKey = "sops.aws-kms"
+)
+
+// sopsDecryptor decrypts SOPS documents with the keys found in the decryption secret. It implements a key service
+// client, so that keys are never read from the environment or the file system of the controller.
+type sopsDecryptor struct {
+	identities age.ParsedIdentities
+	awsCreds   *kms.CredentialsProvider
+}
+
+// buildSOPSDecryptor reads the decryption keys through objClient. It returns nil if decryption is not enabled.
+func (r *BaseTemplateReconciler) buildSOPSDecryptor(ctx context.Context, objClient client.Client, objNamespace string, spec *templatesv1alpha1.Decryption) (*sopsDecryptor, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.Provider != decryptionProviderSOPS {
+		return nil, fmt.Errorf("unsupported decryption provider %s", spec.Provider)
+	}
+
+	d := &sopsDecryptor{}
+	if spec.SecretRef == nil {
+		return d, nil
+	}
+
+	var secret corev1.Secret
+	err := objClient.Get(ctx, client.ObjectKey{Namespace: objNamespace, Name: spec.SecretRef.Name}, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decryption secret %s: %w", spec.SecretRef.Name, err)
+	}
+	for k, v := range secret.Data {
+		switch {
+		case strings.HasSuffix(k, sopsAgeKeyExt):
+			err = d.identities.Import(string(v))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse age identities from key %s of secret %s: %w", k, spec.SecretRef.Name, err)
+			}
+		case k == sopsAWSKMSKey:
+			var creds struct {
+				AccessKeyID     string `json:"aws_access_key_id"`
+				SecretAccessKey string `json:"aws_secret_access_key"`
+				SessionToken    string `json:"aws_session_token"`
+			}
+			err = yaml.Unmarshal(v, &creds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AWS credentials from key %s of secret %s: %w", k, spec.SecretRef.Name, err)
+			}
+			redact.Register(ctx, creds.SecretAccessKey, creds.SessionToken)
+			d.awsCreds = kms.NewCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken))
+		}
+	}
+	return d, nil
+}
+
+// decryptValues returns a copy of v, with all strings that contain SOPS encrypted YAML or JSON documents replaced by
+// the decrypted documents
+func (d *sopsDecryptor) decryptValues(ctx context.Context, v any) (any, error) {
+	switch x := v.(type) {
+	case string:
+		if !isSOPSDocument(x) {
+			return x, nil
+		}
+		return d.decryptDocument(ctx, x)
+	case map[string]any:
+		ret := make(map[string]any, len(x))
+		for k, v2 := range x {
+			v2, err := d.decryptValues(ctx, v2)
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = v2
+		}
+		return ret, nil
+	case []any:
+		ret := make([]any, len(x))
+		for i, v2 := range x {
+			v2, err := d.decryptValues(ctx, v2)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = v2
+		}
+		return ret, nil
+	default:
+		return v, nil
+	}
+}
+
+// isSOPSDocument returns true if s is a YAML or JSON document with SOPS metadata
+func isSOPSDocument(s string) bool {
+	if !strings.Contains(s, "sops") || !strings.Contains(s, "ENC[") {
+		return false
+	}
+	var doc map[string]any
+	err := yaml.Unmarshal([]byte(s), &doc)
+	if err != nil {
+		return false
+	}
+	m, ok := doc["sops"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = m["mac"]
+	return ok
+}
+
+func (d *sopsDecryptor) decryptDocument(ctx context.Context, s string) (any, error) {
+	var store sops.Store = &sopsyaml.Store{}
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		store = &sopsjson.Store{}
+	}
+
+	tree, err := store.LoadEncryptedFile([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SOPS document: %w", err)
+	}
+	key, err := tree.Metadata.GetDataKeyWithKeyServices([]keyservice.KeyServiceClient{d})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SOPS data key: %w", err)
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(key, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS document: %w", err)
+	}
+	originalMac, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, key, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS mac: %w", err)
+	}
+	if originalMac != mac {
+		return nil, fmt.Errorf("failed to verify integrity of SOPS document")
+	}
+
+	b, err := store.EmitPlainFile(tree.Branches)
+	if err != nil {
+		return nil, err
+	}
+	var ret any
+	err = yaml.Unmarshal(b, &ret)
+	if err != nil {
+		return nil, err
+	}
+	var encrypted any
+	err = yaml.Unmarshal([]byte(s), &encrypted)
+	if err != nil {
+		return nil, err
+	}
+	registerDecryptedValues(ctx, encrypted, ret)
+	return ret, nil
+}
+
+// registerDecryptedValues registers all decrypted strings for redaction. Values that were not encrypted in the SOPS
+// document (e.g. because of unencrypted_suffix or encrypted_regex) are not secret and are therefore not registered.
+func registerDecryptedValues(ctx context.Context, encrypted any, decrypted any) {
+	switch x := decrypted.(type) {
+	case string:
+		if e, ok := encrypted.(string); ok && strings.HasPrefix(e, "ENC[") {
+			redact.Register(ctx, x)
+		}
+	case map[string]any:
+		e, _ := encrypted.(map[string]any)
+		for k, v2 := range x {
+			registerDecryptedValues(ctx, e[k], v2)
+		}
+	case []any:
+		e, _ := encrypted.([]any)
+		for i, v2 := range x {
+			if i < len(e) {
+				registerDecryptedValues(ctx, e[i], v2)
+			}
+		}
+	}
+}
+
+func (d *sopsDecryptor) Encrypt(ctx context.Context, in *keyservice.EncryptRequest, opts ...grpc.CallOption) (*keyservice.EncryptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "encryption is not supported")
+}
+
+func (d *sopsDecryptor) Decrypt(ctx context.Context, in *keyservice.DecryptRequest, opts ...grpc.CallOption) (*keyservice.DecryptResponse, error) {
+	switch k := in.Key.KeyType.(type) {
+	case *keyservice.Key_AgeKey:
+		key := &age.MasterKey{
+			Recipient:    k.AgeKey.Recipient,
+			EncryptedKey: string(in.Ciphertext),
+		}
+		d.identities.ApplyToMasterKey(key)
+		plaintext, err := key.Decrypt()
+		if err != nil {
+			return nil, err
+		}
+		return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+	case *keyservice.Key_KmsKey:
+		if d.awsCreds == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "no AWS credentials found in decryption secret")
+		}
+		encryptionContext := map[string]*string{}
+		for ck, cv := range k.KmsKey.Context {
+			cv := cv
+			encryptionContext[ck] = &cv
+		}
+		key := &kms.MasterKey{
+			Arn:               k.KmsKey.Arn,
+			Role:              k.KmsKey.Role,
+			EncryptionContext: encryptionContext,
+			EncryptedKey:      string(in.Ciphertext),
+		}
+		d.awsCreds.ApplyToMasterKey(key)
+		plaintext, err := key.Decrypt()
+		if err != nil {
+			return nil, err
+		}
+		return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+	default:
+		return nil, status.Errorf(codes.Unimplemented, "unsupported SOPS key type %T", k)
+	}
+}
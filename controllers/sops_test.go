@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kluctl/template-controller/controllers/redact"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegisterDecryptedValues(t *testing.T) {
+	g := NewWithT(t)
+
+	encrypted := map[string]any{
+		"password": "ENC[AES256_GCM,data:abc,iv:def,tag:ghi,type:str]",
+		"username": "plain-username",
+		"nested": map[string]any{
+			"token": "ENC[AES256_GCM,data:jkl,iv:mno,tag:pqr,type:str]",
+		},
+		"list": []any{"ENC[AES256_GCM,data:stu,iv:vwx,tag:yz,type:str]", "plain-item"},
+	}
+	decrypted := map[string]any{
+		"password": "secret-password",
+		"username": "plain-username",
+		"nested": map[string]any{
+			"token": "secret-token",
+		},
+		"list": []any{"secret-item", "plain-item"},
+	}
+
+	ctx := redact.NewContext(context.Background())
+	registerDecryptedValues(ctx, encrypted, decrypted)
+
+	// only values that were encrypted are secret
+	g.Expect(redact.String(ctx, "secret-password plain-username secret-token secret-item plain-item")).
+		To(Equal("***** plain-username ***** ***** plain-item"))
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"k8s.io/apimachinery/pkg/types"
+	"sort"
+	"sync"
+	"text/template"
+)
+
+// maxCompiledTemplatesPerObject limits the number of compiled templates kept per object. Templates loaded from
+// GitRepositories and OCI artifacts can change without the generation changing, so the cache is reset when it grows
+// beyond this limit.
+const maxCompiledTemplatesPerObject = 1000
+
+// compiledTemplateCache caches parsed Go templates per template object, so that repeated reconciliations of unchanged
+// objects don't need to parse the same templates again. The cached templates of an object are dropped when its
+// generation or its includes change. Objects are identified by their owner string, e.g. ObjectTemplate/ns/name.
+type compiledTemplateCache struct {
+	mutex   sync.Mutex
+	entries map[string]*compiledTemplates
+}
+
+// compiledTemplates holds the parsed templates of a single object, keyed by the template source
+type compiledTemplates struct {
+	uid          types.UID
+	generation   int64
+	includesHash string
+
+	mutex     sync.Mutex
+	templates map[string]*template.Template
+}
+
+// forObject returns the compiled templates of the given object, which are reset if uid, generation or includes differ
+// from the cached ones.
+func (c *compiledTemplateCache) forObject(owner string, uid types.UID, generation int64, includes map[string]string) *compiledTemplates {
+	includesHash := hashIncludes(includes)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]*compiledTemplates{}
+	}
+	e, ok := c.entries[owner]
+	if !ok || e.uid != uid || e.generation != generation || e.includesHash != includesHash {
+		e = &compiledTemplates{
+			uid:          uid,
+			generation:   generation,
+			includesHash: includesHash,
+			templates:    map[string]*template.Template{},
+		}
+		c.entries[owner] = e
+	}
+	return e
+}
+
+// remove drops the compiled templates of a deleted object
+func (c *compiledTemplateCache) remove(owner string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, owner)
+}
+
+func (c *compiledTemplates) get(t string) *template.Template {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.templates[t]
+}
+
+func (c *compiledTemplates) put(t string, tmpl *template.Template) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.templates) >= maxCompiledTemplatesPerObject {
+		c.templates = map[string]*template.Template{}
+	}
+	c.templates[t] = tmpl
+}
+
+func hashIncludes(includes map[string]string) string {
+	names := make([]string, 0, len(includes))
+	for name := range includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(includes[name]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"testing"
+	"text/template"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCompiledTemplateCache(t *testing.T) {
+	g := NewWithT(t)
+
+	var c compiledTemplateCache
+	tmpl := template.New("t")
+
+	e := c.forObject("ObjectTemplate/ns1/t1", "uid1", 1, nil)
+	g.Expect(e.get("src")).To(BeNil())
+	e.put("src", tmpl)
+	g.Expect(c.forObject("ObjectTemplate/ns1/t1", "uid1", 1, nil).get("src")).To(BeIdenticalTo(tmpl))
+
+	// other objects have their own entries
+	g.Expect(c.forObject("ObjectTemplate/ns1/t2", "uid2", 1, nil).get("src")).To(BeNil())
+
+	// generation changes invalidate the cached templates
+	g.Expect(c.forObject("ObjectTemplate/ns1/t1", "uid1", 2, nil).get("src")).To(BeNil())
+
+	e = c.forObject("ObjectTemplate/ns1/t1", "uid1", 2, map[string]string{"inc": "a"})
+	e.put("src", tmpl)
+	g.Expect(c.forObject("ObjectTemplate/ns1/t1", "uid1", 2, map[string]string{"inc": "a"}).get("src")).To(BeIdenticalTo(tmpl))
+
+	// changed includes invalidate the cached templates, as templates can include each other
+	g.Expect(c.forObject("ObjectTemplate/ns1/t1", "uid1", 2, map[string]string{"inc": "b"}).get("src")).To(BeNil())
+
+	// re-created objects with the same name must not reuse the templates of the deleted object
+	c.forObject("ObjectTemplate/ns1/t1", "uid1", 2, nil).put("src", tmpl)
+	g.Expect(c.forObject("ObjectTemplate/ns1/t1", "uid3", 2, nil).get("src")).To(BeNil())
+
+	c.forObject("ObjectTemplate/ns1/t1", "uid3", 2, nil).put("src", tmpl)
+	c.remove("ObjectTemplate/ns1/t1")
+	g.Expect(c.forObject("ObjectTemplate/ns1/t1", "uid3", 2, nil).get("src")).To(BeNil())
+}
+
+func TestCompiledTemplatesLimit(t *testing.T) {
+	g := NewWithT(t)
+
+	var c compiledTemplateCache
+	e := c.forObject("ObjectTemplate/ns1/t1", "uid1", 1, nil)
+	for i := 0; i < maxCompiledTemplatesPerObject; i++ {
+		e.put(string(rune(i)), template.New("t"))
+	}
+	g.Expect(e.templates).To(HaveLen(maxCompiledTemplatesPerObject))
+
+	// the cache is reset instead of growing beyond the limit
+	e.put("new", template.New("t"))
+	g.Expect(e.templates).To(HaveLen(1))
+	g.Expect(e.get("new")).ToNot(BeNil())
+}
+
+func TestHashIncludes(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(hashIncludes(map[string]string{"a": "1", "b": "2"})).To(Equal(hashIncludes(map[string]string{"b": "2", "a": "1"})))
+	// names and contents are separated, so that moving characters between them changes the hash
+	g.Expect(hashIncludes(map[string]string{"a": "b"})).ToNot(Equal(hashIncludes(map[string]string{"ab": ""})))
+}
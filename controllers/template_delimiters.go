@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"strings"
+)
+
+var errUnclosedDelimiter = errors.New("unclosed delimiter")
+
+func validateDelimiters(engine string, d *templatesv1alpha1.TemplateDelimiters) error {
+	if d.VariableStart == "" || d.VariableEnd == "" {
+		return fmt.Errorf("variableStart and variableEnd delimiters must both be set")
+	}
+	if (d.BlockStart == "") != (d.BlockEnd == "") {
+		return fmt.Errorf("blockStart and blockEnd delimiters must both be set")
+	}
+	if engine == templateEngineGoTemplate && d.BlockStart != "" {
+		return fmt.Errorf("block delimiters are not supported by Go templates")
+	}
+	if d.BlockStart != "" && (strings.HasPrefix(d.VariableStart, d.BlockStart) || strings.HasPrefix(d.BlockStart, d.VariableStart)) {
+		return fmt.Errorf("delimiters %s and %s are ambiguous", d.VariableStart, d.BlockStart)
+	}
+	return nil
+}
+
+// translateJinja2Delimiters converts a template with custom delimiters into a template with the default Jinja2
+// delimiters, as Jinja2 is configured outside of our control. Text outside the custom delimiters is wrapped into
+// raw blocks if it contains default delimiters, so that these end up in the rendered output unmodified. The number of
+// lines is preserved, so that line numbers of errors still match the original template.
+func translateJinja2Delimiters(t string, d *templatesv1alpha1.TemplateDelimiters) (string, error) {
+	type delims struct {
+		start, end             string
+		jinja2Start, jinja2End string
+	}
+	pairs := []delims{{d.VariableStart, d.VariableEnd, "{{", "}}"}}
+	if d.BlockStart != "" {
+		pairs = append(pairs, delims{d.BlockStart, d.BlockEnd, "{%", "%}"})
+	}
+
+	var sb strings.Builder
+	rest := t
+	for {
+		pos := -1
+		var p delims
+		for _, x := range pairs {
+			i := strings.Index(rest, x.start)
+			if i != -1 && (pos == -1 || i < pos) {
+				pos = i
+				p = x
+			}
+		}
+		if pos == -1 {
+			writeJinja2Literal(&sb, rest)
+			return sb.String(), nil
+		}
+		writeJinja2Literal(&sb, rest[:pos])
+		rest = rest[pos+len(p.start):]
+
+		end := strings.Index(rest, p.end)
+		if end == -1 {
+			line := strings.Count(t[:len(t)-len(rest)], "\n") + 1
+			return "", fmt.Errorf("%w %s at line %d, missing %s", errUnclosedDelimiter, p.start, line, p.end)
+		}
+		sb.WriteString(p.jinja2Start)
+		sb.WriteString(rest[:end])
+		sb.WriteString(p.jinja2End)
+		rest = rest[end+len(p.end):]
+	}
+}
+
+func writeJinja2Literal(sb *strings.Builder, s string) {
+	if !strings.Contains(s, "{{") && !strings.Contains(s, "{%") && !strings.Contains(s, "{#") {
+		sb.WriteString(s)
+		return
+	}
+	sb.WriteString("{% raw %}")
+	sb.WriteString(s)
+	sb.WriteString("{% endraw %}")
+}
+
+// translateJinja2DelimitersValue translates all strings (including keys) found in v
+func translateJinja2DelimitersValue(v any, d *templatesv1alpha1.TemplateDelimiters) (any, error) {
+	switch x := v.(type) {
+	case string:
+		return translateJinja2Delimiters(x, d)
+	case map[string]any:
+		ret := make(map[string]any, len(x))
+		for k, v2 := range x {
+			k2, err := translateJinja2Delimiters(k, d)
+			if err != nil {
+				return nil, err
+			}
+			v2, err = translateJinja2DelimitersValue(v2, d)
+			if err != nil {
+				return nil, err
+			}
+			ret[k2] = v2
+		}
+		return ret, nil
+	case []any:
+		ret := make([]any, len(x))
+		for i, v2 := range x {
+			v2, err := translateJinja2DelimitersValue(v2, d)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = v2
+		}
+		return ret, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/sprig/v3"
+	"github.com/kluctl/go-jinja2"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	templateEngineJinja2     = "jinja2"
+	templateEngineGoTemplate = "gotemplate"
+)
+
+// templateEngine renders the templates of an ObjectTemplate
+type templateEngine interface {
+	RenderString(t string, vars map[string]any) (string, error)
+	// RenderObject renders all string fields (and keys) of o in-place
+	RenderObject(o *unstructured.Unstructured, vars map[string]any) error
+	Close()
+}
+
+// templateEngineOptions configures a templateEngine
+type templateEngineOptions struct {
+	// includes maps names to template snippets that can be included or imported by the rendered templates
+	includes map[string]string
+	// lookup is optional and only supported by the gotemplate engine, as Jinja2 templates are rendered out of process
+	lookup templateLookupFunc
+	// strict causes references to undefined variables to fail rendering. Go templates are always strict.
+	strict bool
+	// compiled is optional and used to cache parsed Go templates across renderings
+	compiled *compiledTemplates
+	// delimiters optionally overrides the default delimiters
+	delimiters *templatesv1alpha1.TemplateDelimiters
+}
+
+// newTemplateEngine creates the engine with the given name, which defaults to Jinja2
+func newTemplateEngine(name string, opts templateEngineOptions) (templateEngine, error) {
+	if opts.delimiters != nil {
+		err := validateDelimiters(name, opts.delimiters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch name {
+	case "", templateEngineJinja2:
+		e := &jinja2Engine{strict: opts.strict, delimiters: opts.delimiters}
+		if len(opts.includes) != 0 {
+			err := e.writeIncludes(opts.includes)
+			if err != nil {
+				e.Close()
+				return nil, err
+			}
+		}
+		j2, err := NewJinja2()
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+		e.j2 = j2
+		return e, nil
+	case templateEngineGoTemplate:
+		funcs := template.FuncMap{}
+		for k, v := range goTemplateFuncs {
+			funcs[k] = v
+		}
+		if opts.lookup != nil {
+			funcs["lookup"] = opts.lookup
+		}
+		e := &goTemplateEngine{includes: opts.includes, funcs: funcs, compiled: opts.compiled, left: "{{", right: "}}"}
+		if opts.delimiters != nil {
+			e.left = opts.delimiters.VariableStart
+			e.right = opts.delimiters.VariableEnd
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("unknown template engine %s", name)
+	}
+}
+
+type jinja2Engine struct {
+	j2         *jinja2.Jinja2
+	strict     bool
+	delimiters *templatesv1alpha1.TemplateDelimiters
+	// includesDir contains one file per include and is used as search dir, as Jinja2 loads includes from files
+	includesDir string
+}
+
+func (e *jinja2Engine) writeIncludes(includes map[string]string) error {
+	dir, err := os.MkdirTemp("", "template-includes-")
+	if err != nil {
+		return err
+	}
+	e.includesDir = dir
+	for name, t := range includes {
+		if name != filepath.Base(name) {
+			return fmt.Errorf("invalid include name %s", name)
+		}
+		if e.delimiters != nil {
+			t, err = translateJinja2Delimiters(t, e.delimiters)
+			if err != nil {
+				return fmt.Errorf("failed to translate delimiters of include %s: %w", name, err)
+			}
+		}
+		err = os.WriteFile(filepath.Join(dir, name), []byte(t), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jinja2Engine) opts(vars map[string]any) []jinja2.Jinja2Opt {
+	opts := []jinja2.Jinja2Opt{jinja2.WithGlobals(vars)}
+	if e.includesDir != "" {
+		opts = append(opts, jinja2.WithSearchDir(e.includesDir))
+	}
+	if e.strict {
+		opts = append(opts, jinja2.WithStrict(true))
+	}
+	return opts
+}
+
+func (e *jinja2Engine) RenderString(t string, vars map[string]any) (string, error) {
+	if e.delimiters != nil {
+		var err error
+		t, err = translateJinja2Delimiters(t, e.delimiters)
+		if err != nil {
+			return "", err
+		}
+	}
+	return e.j2.RenderString(t, e.opts(vars)...)
+}
+
+func (e *jinja2Engine) RenderObject(o *unstructured.Unstructured, vars map[string]any) error {
+	if e.delimiters != nil {
+		x, err := translateJinja2DelimitersValue(o.Object, e.delimiters)
+		if err != nil {
+			return err
+		}
+		o.Object = x.(map[string]any)
+	}
+	_, err := e.j2.RenderStruct(o, e.opts(vars)...)
+	return err
+}
+
+func (e *jinja2Engine) Close() {
+	if e.j2 != nil {
+		e.j2.Close()
+	}
+	if e.includesDir != "" {
+		_ = os.RemoveAll(e.includesDir)
+	}
+}
+
+// goTemplateFuncs contains the Sprig functions, except the ones that would allow templates to read the environment of
+// the controller or to perform DNS lookups. The serialization functions known from Helm are added on top.
+var goTemplateFuncs = func() template.FuncMap {
+	m := sprig.TxtFuncMap()
+	delete(m, "env")
+	delete(m, "expandenv")
+	delete(m, "getHostByName")
+	// alias to match the name of the Jinja2 filter
+	m["adler32"] = m["adler32sum"]
+	m["toYaml"] = toYaml
+	m["fromYaml"] = fromYaml
+	m["fromJson"] = fromJson
+	return m
+}()
+
+// toYaml serializes v to YAML without the trailing newline, so that it can be combined with indent and nindent
+func toYaml(v any) (string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
+
+func fromYaml(s string) (any, error) {
+	var ret any
+	err := yaml.Unmarshal([]byte(s), &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func fromJson(s string) (any, error) {
+	var ret any
+	err := json.Unmarshal([]byte(s), &ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// goTemplateEngine renders Go templates with the variables as data, e.g. {{ .matrix.input1.x }}. Missing keys are
+// treated as errors. Includes are available as named templates, e.g. {{ template "labels.tpl" . }}. Objects can be
+// read from the cluster via {{ lookup "v1" "ConfigMap" "namespace" "name" }}.
+type goTemplateEngine struct {
+	includes map[string]string
+	funcs    template.FuncMap
+	compiled *compiledTemplates
+	// left and right are the action delimiters
+	left  string
+	right string
+}
+
+func (e *goTemplateEngine) RenderString(t string, vars map[string]any) (string, error) {
+	tmpl, err := e.parse(t)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = tmpl.Execute(&sb, vars)
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// parse parses t and all includes. Parsed templates are taken from and added to the compiled templates cache if
+// available. Cached templates are cloned and bound to the functions of this engine, as lookup is bound to the client
+// of the current reconciliation.
+func (e *goTemplateEngine) parse(t string) (*template.Template, error) {
+	if e.compiled != nil {
+		if tmpl := e.compiled.get(t); tmpl != nil {
+			tmpl, err := tmpl.Clone()
+			if err != nil {
+				return nil, err
+			}
+			return tmpl.Funcs(e.funcs), nil
+		}
+	}
+
+	tmpl, err := template.New("template").Delims(e.left, e.right).Option("missingkey=error").Funcs(e.funcs).Parse(t)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(e.includes))
+	for name := range e.includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, err = tmpl.New(name).Parse(e.includes[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse include %s: %w", name, err)
+		}
+	}
+
+	if e.compiled != nil {
+		e.compiled.put(t, tmpl)
+	}
+	return tmpl, nil
+}
+
+func (e *goTemplateEngine) RenderObject(o *unstructured.Unstructured, vars map[string]any) error {
+	x, err := e.renderValue(o.Object, vars)
+	if err != nil {
+		return err
+	}
+	o.Object = x.(map[string]any)
+	return nil
+}
+
+func (e *goTemplateEngine) renderValue(v any, vars map[string]any) (any, error) {
+	switch x := v.(type) {
+	case string:
+		if !strings.Contains(x, e.left) {
+			return x, nil
+		}
+		return e.RenderString(x, vars)
+	case map[string]any:
+		ret := make(map[string]any, len(x))
+		for k, v2 := range x {
+			k2, err := e.renderValue(k, vars)
+			if err != nil {
+				return nil, err
+			}
+			v2, err = e.renderValue(v2, vars)
+			if err != nil {
+				return nil, err
+			}
+			ret[k2.(string)] = v2
+		}
+		return ret, nil
+	case []any:
+		ret := make([]any, len(x))
+		for i, v2 := range x {
+			v2, err := e.renderValue(v2, vars)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = v2
+		}
+		return ret, nil
+	default:
+		return v, nil
+	}
+}
+
+func (e *goTemplateEngine) Close() {
+}
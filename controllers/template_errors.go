@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const templateErrorReason = "TemplateError"
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// templateError is returned when rendering a single template fails. It carries the position of the failed template,
+// so that users don't have to guess which of the templates is broken.
+type templateError struct {
+	// index is the index of the template in spec.templates
+	index int
+	// kind and name are taken from the unrendered template and might therefore contain template expressions
+	kind string
+	name string
+	// line and column are 1-based and 0 if unknown. Jinja2 does not report columns.
+	line   int
+	column int
+
+	err error
+}
+
+func (e *templateError) Error() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "template at index %d", e.index)
+	if e.kind != "" {
+		_, _ = fmt.Fprintf(&sb, " (%s", e.kind)
+		if e.name != "" {
+			_, _ = fmt.Fprintf(&sb, " %s", e.name)
+		}
+		sb.WriteString(")")
+	}
+	if e.line != 0 {
+		_, _ = fmt.Fprintf(&sb, ", line %d", e.line)
+		if e.column != 0 {
+			_, _ = fmt.Fprintf(&sb, ", column %d", e.column)
+		}
+	}
+	_, _ = fmt.Fprintf(&sb, ": %s", e.err.Error())
+	return sb.String()
+}
+
+func (e *templateError) Unwrap() error {
+	return e.err
+}
+
+var (
+	// matches Go template errors of the main template, e.g. "template: template:3:12: executing ..."
+	goTemplatePosRegex = regexp.MustCompile(`template: template:(\d+)(?::(\d+))?:`)
+	// matches the traceback entry of Jinja2 errors in string templates, e.g. `File "<unknown>", line 3, in template`
+	jinja2PosRegex = regexp.MustCompile(`\s*File "<unknown>", line (\d+), in template\n`)
+	// matches YAML errors of rendered raw templates, e.g. "yaml: line 3: mapping values are not allowed"
+	yamlPosRegex = regexp.MustCompile(`yaml: line (\d+):`)
+
+	rawKindRegex = regexp.MustCompile(`(?m)^kind:\s*(.*?)\s*$`)
+	rawNameRegex = regexp.MustCompile(`(?m)^  name:\s*(.*?)\s*$`)
+)
+
+// newTemplateError wraps err into a templateError. lineOffset is the number of lines that were prepended to the
+// template before rendering, e.g. the macros of a TemplateLibrary.
+func newTemplateError(index int, t *templatesv1alpha1.Template, lineOffset int, err error) *templateError {
+	// Jinja2 reports errors of object templates as multierror, even if only a single field failed
+	var merr *multierror.Error
+	if errors.As(err, &merr) && len(merr.Errors) == 1 {
+		err = merr.Errors[0]
+	}
+
+	e := &templateError{
+		index: index,
+		err:   err,
+	}
+
+	if t.Object != nil {
+		e.kind = t.Object.GetKind()
+		e.name = t.Object.GetName()
+	} else if t.Raw != nil && strings.Count(*t.Raw, "\n---") == 0 {
+		// kind and name can only be resolved reliably if the raw template contains a single document
+		if m := rawKindRegex.FindAllStringSubmatch(*t.Raw, -1); len(m) == 1 {
+			e.kind = m[0][1]
+			if m := rawNameRegex.FindStringSubmatch(*t.Raw); m != nil {
+				e.name = m[1]
+			}
+		}
+	}
+
+	msg := err.Error()
+	if m := goTemplatePosRegex.FindStringSubmatch(msg); m != nil {
+		e.line, _ = strconv.Atoi(m[1])
+		e.column, _ = strconv.Atoi(m[2])
+	} else if m := jinja2PosRegex.FindStringSubmatch(msg); m != nil {
+		e.line, _ = strconv.Atoi(m[1])
+		// the traceback entry is redundant now
+		e.err = errors.New(strings.TrimSpace(jinja2PosRegex.ReplaceAllString(msg, "")))
+	} else if m := yamlPosRegex.FindStringSubmatch(msg); m != nil && t.Raw != nil {
+		// YAML errors refer to the rendered output, which only matches the template if rendering did not change the
+		// number of lines. It is still the best hint available.
+		e.line, _ = strconv.Atoi(m[1])
+		lineOffset = 0
+	}
+	if e.line > lineOffset {
+		e.line -= lineOffset
+	}
+	return e
+}
+
+// splitReconcileError returns all errors found in err, with nested multierrors being flattened. Duplicate errors, e.g.
+// caused by the same template failing for multiple matrix entries, are only returned once.
+func splitReconcileError(err error) []error {
+	var ret []error
+	seen := map[string]bool{}
+	var walk func(err error)
+	walk = func(err error) {
+		var merr *multierror.Error
+		if errors.As(err, &merr) {
+			for _, e := range merr.Errors {
+				walk(e)
+			}
+			return
+		}
+		msg := err.Error()
+		if !seen[msg] {
+			seen[msg] = true
+			ret = append(ret, err)
+		}
+	}
+	walk(err)
+	return ret
+}
+
+// reportReconcileError returns the reason and message to use in the Ready condition. If err contains template errors,
+// a warning Event is emitted for each of them.
+func (r *BaseTemplateReconciler) reportReconcileError(ctx context.Context, obj runtime.Object, err error) (string, string) {
+	errs := splitReconcileError(err)
+
+	recorder := r.eventRecorder(ctx)
+
+	reason := "Error"
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msg := redact.Error(ctx, e)
+		msgs = append(msgs, msg)
+
+		var terr *templateError
+		if errors.As(e, &terr) {
+			reason = templateErrorReason
+			if recorder != nil {
+				recorder.Event(obj, corev1.EventTypeWarning, templateErrorReason, msg)
+			}
+		}
+	}
+	if reason != templateErrorReason {
+		return reason, redact.Error(ctx, err)
+	}
+	return reason, strings.Join(msgs, "\n")
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/health"
+	"github.com/kluctl/template-controller/controllers/redact"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const healthyCondition = "Healthy"
+
+// computeAppliedHealth computes the health of an applied object, based on the object returned by the apply
+func computeAppliedHealth(ctx context.Context, obj *unstructured.Unstructured) (string, string) {
+	h, message, err := health.Compute(obj)
+	if err != nil {
+		return templatesv1alpha1.HealthUnknown, redact.Error(ctx, err)
+	}
+	return h, redact.String(ctx, message)
+}
+
+// setHealthyCondition aggregates the health of all applied resources into the Healthy condition, with the worst health
+// of all resources being used as reason. The condition is removed if rendered objects are not applied to the cluster.
+func setHealthyCondition(conditions *[]metav1.Condition, generation int64, spec *templatesv1alpha1.ObjectTemplateSpec, appliedResources []templatesv1alpha1.AppliedResourceInfo) {
+	if spec.DryRun || spec.GitOutput != nil || spec.OCIOutput != nil {
+		apimeta.RemoveStatusCondition(conditions, healthyCondition)
+		return
+	}
+
+	worst := templatesv1alpha1.HealthHealthy
+	message := ""
+	unhealthy := 0
+	for _, ari := range appliedResources {
+		h, msg := ari.Health, ari.HealthMessage
+		if !ari.Success {
+			h, msg = templatesv1alpha1.HealthDegraded, "Failed to apply object"
+		} else if h == "" {
+			h, msg = templatesv1alpha1.HealthUnknown, "Health not determined yet"
+		}
+		if h != templatesv1alpha1.HealthHealthy {
+			unhealthy++
+		}
+		if health.Order[h] > health.Order[worst] {
+			worst = h
+			message = fmt.Sprintf("%s: %s", ari.Ref.String(), msg)
+		}
+	}
+
+	c := metav1.Condition{
+		Type:               healthyCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             worst,
+		Message:            "All applied objects are healthy",
+	}
+	if worst != templatesv1alpha1.HealthHealthy {
+		c.Status = metav1.ConditionFalse
+		c.Message = fmt.Sprintf("%d of %d objects are not healthy, %s", unhealthy, len(appliedResources), message)
+	}
+	apimeta.SetStatusCondition(conditions, c)
+}
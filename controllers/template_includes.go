@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateIncludeRefs returns references to all ConfigMaps included by the templates
+func templateIncludeRefs(includes []templatesv1alpha1.TemplateInclude) []templatesv1alpha1.ObjectRef {
+	var ret []templatesv1alpha1.ObjectRef
+	for _, inc := range includes {
+		if inc.ConfigMap != nil {
+			ret = append(ret, templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: inc.ConfigMap.Namespace, Name: inc.ConfigMap.Name})
+		}
+	}
+	return ret
+}
+
+// loadTemplateIncludes reads all included ConfigMaps through objClient and returns their merged data. Keys of later
+// ConfigMaps override the keys of earlier ones.
+func (r *BaseTemplateReconciler) loadTemplateIncludes(ctx context.Context, objClient client.Client, objNamespace string, includes []templatesv1alpha1.TemplateInclude) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, inc := range includes {
+		if inc.ConfigMap == nil {
+			return nil, fmt.Errorf("missing include value")
+		}
+
+		key := client.ObjectKey{Namespace: objNamespace, Name: inc.ConfigMap.Name}
+		if inc.ConfigMap.Namespace != "" {
+			key.Namespace = inc.ConfigMap.Namespace
+		}
+		err := r.Policy.CheckRefNamespace(objNamespace, key.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		var cm corev1.ConfigMap
+		err = objClient.Get(ctx, key, &cm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get included ConfigMap %s: %w", key.String(), err)
+		}
+		for k, v := range cm.Data {
+			ret[k] = v
+		}
+	}
+	return ret, nil
+}
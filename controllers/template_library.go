@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+const forLibraryKey = "spec.templates.library"
+
+//+kubebuilder:rbac:groups=templates.kluctl.io,resources=templatelibraries,verbs=get;list;watch
+
+// buildLibraryRef returns the ObjectRef of the TemplateLibrary referenced by ref.
+func buildLibraryRef(ref templatesv1alpha1.LibraryTemplateRef) templatesv1alpha1.ObjectRef {
+	return templatesv1alpha1.ObjectRef{
+		APIVersion: templatesv1alpha1.GroupVersion.String(),
+		Kind:       "TemplateLibrary",
+		Namespace:  ref.Namespace,
+		Name:       ref.Library,
+	}
+}
+
+func buildLibraryIndexValues(templates []templatesv1alpha1.Template, ns string) []string {
+	var ret []string
+	for _, t := range templates {
+		if t.Library != nil {
+			ret = append(ret, BuildRefIndexValue(buildLibraryRef(*t.Library), ns))
+		}
+	}
+	return ret
+}
+
+// resolvedTemplate is a template with all library, GitRepository and OCI references resolved
+type resolvedTemplate struct {
+	templatesv1alpha1.Template
+
+	// index is the index in spec.templates the template was resolved from
+	index int
+	// lineOffset is the number of lines prepended to raw templates, e.g. the macros of a TemplateLibrary
+	lineOffset int
+}
+
+// resolveTemplates replaces all library references with the referenced library templates. Libraries are read through
+// objClient, so the service account must have permissions to get them.
+func (r *ObjectTemplateReconciler) resolveTemplates(ctx context.Context, objClient client.Client, objNamespace string, templates []templatesv1alpha1.Template) ([]resolvedTemplate, error) {
+	libraries := map[client.ObjectKey]*templatesv1alpha1.TemplateLibrary{}
+
+	ret := make([]resolvedTemplate, 0, len(templates))
+	for index, t := range templates {
+		if t.GitRepository != nil {
+			ts, err := r.resolveGitRepositoryTemplates(ctx, objClient, objNamespace, t.GitRepository)
+			if err != nil {
+				return nil, err
+			}
+			for _, x := range ts {
+				x.ForEach = t.ForEach
+				ret = append(ret, resolvedTemplate{Template: x, index: index})
+			}
+			continue
+		}
+		if t.OCI != nil {
+			ts, err := r.resolveOCITemplates(ctx, objClient, objNamespace, t.OCI)
+			if err != nil {
+				return nil, err
+			}
+			for _, x := range ts {
+				x.ForEach = t.ForEach
+				ret = append(ret, resolvedTemplate{Template: x, index: index})
+			}
+			continue
+		}
+		if t.Library == nil {
+			ret = append(ret, resolvedTemplate{Template: t, index: index})
+			continue
+		}
+
+		key := client.ObjectKey{Namespace: objNamespace, Name: t.Library.Library}
+		if t.Library.Namespace != "" {
+			key.Namespace = t.Library.Namespace
+		}
+		err := r.Policy.CheckRefNamespace(objNamespace, key.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		l, ok := libraries[key]
+		if !ok {
+			l = &templatesv1alpha1.TemplateLibrary{}
+			err = objClient.Get(ctx, key, l)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get TemplateLibrary %s: %w", key.String(), err)
+			}
+			libraries[key] = l
+		}
+
+		lt, err := findLibraryTemplate(l, t.Library.Name, t.Library.Version)
+		if err != nil {
+			return nil, err
+		}
+		if lt.Library != nil {
+			return nil, fmt.Errorf("template %s:%s in TemplateLibrary %s references another library, which is not supported", lt.Name, lt.Version, key.String())
+		}
+		if lt.GitRepository != nil {
+			return nil, fmt.Errorf("template %s:%s in TemplateLibrary %s references a GitRepository, which is not supported", lt.Name, lt.Version, key.String())
+		}
+		if lt.OCI != nil {
+			return nil, fmt.Errorf("template %s:%s in TemplateLibrary %s references an OCI artifact, which is not supported", lt.Name, lt.Version, key.String())
+		}
+
+		x := resolvedTemplate{Template: *lt.Template.DeepCopy(), index: index}
+		if t.ForEach != nil {
+			x.ForEach = t.ForEach
+		}
+		if lt.Macros != nil && x.Raw != nil {
+			raw := *lt.Macros + "\n" + *x.Raw
+			x.Raw = &raw
+			x.lineOffset = strings.Count(*lt.Macros, "\n") + 1
+		}
+		ret = append(ret, x)
+	}
+	return ret, nil
+}
+
+// findLibraryTemplate finds the template with the given name and version. version can either be an exact version or
+// a semantic version constraint, in which case the highest matching version is returned.
+func findLibraryTemplate(l *templatesv1alpha1.TemplateLibrary, name string, version string) (*templatesv1alpha1.LibraryTemplate, error) {
+	var candidates []*templatesv1alpha1.LibraryTemplate
+	for i := range l.Spec.Templates {
+		lt := &l.Spec.Templates[i]
+		if lt.Name != name {
+			continue
+		}
+		if lt.Version == version {
+			return lt, nil
+		}
+		candidates = append(candidates, lt)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("template %s not found in TemplateLibrary %s/%s", name, l.GetNamespace(), l.GetName())
+	}
+
+	c, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, fmt.Errorf("version %s of template %s not found in TemplateLibrary %s/%s", version, name, l.GetNamespace(), l.GetName())
+	}
+
+	var best *templatesv1alpha1.LibraryTemplate
+	var bestVersion *semver.Version
+	for _, lt := range candidates {
+		v, err := semver.NewVersion(lt.Version)
+		if err != nil {
+			continue
+		}
+		if !c.Check(v) {
+			continue
+		}
+		if bestVersion == nil || v.GreaterThan(bestVersion) {
+			best = lt
+			bestVersion = v
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no version of template %s in TemplateLibrary %s/%s matches %s", name, l.GetNamespace(), l.GetName(), version)
+	}
+	return best, nil
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"sync"
+)
+
+// templateLookupFunc implements the lookup template function. It returns the object with the given name or, if name is
+// empty, a list of all objects in the form {"items": [...]}. An empty object is returned if the object does not exist.
+type templateLookupFunc func(apiVersion string, kind string, namespace string, name string) (map[string]any, error)
+
+// buildTemplateLookupFunc returns a lookup function that reads objects through objClient, so that the permissions of
+// the service account apply. Namespaced objects default to objNamespace. Results are cached for the lifetime of the
+// returned function, so that looking up the same object for every matrix entry only reads it once.
+func (r *BaseTemplateReconciler) buildTemplateLookupFunc(ctx context.Context, objClient client.Client, objNamespace string) templateLookupFunc {
+	var mutex sync.Mutex
+	cache := map[string]map[string]any{}
+
+	return func(apiVersion string, kind string, namespace string, name string) (map[string]any, error) {
+		cacheKey := fmt.Sprintf("%s/%s/%s/%s", apiVersion, kind, namespace, name)
+		mutex.Lock()
+		defer mutex.Unlock()
+		if x, ok := cache[cacheKey]; ok {
+			return runtime.DeepCopyJSON(x), nil
+		}
+
+		x, err := r.lookupObject(ctx, objClient, objNamespace, apiVersion, kind, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("lookup of %s %s/%s failed: %w", kind, namespace, name, err)
+		}
+		cache[cacheKey] = x
+		return runtime.DeepCopyJSON(x), nil
+	}
+}
+
+func (r *BaseTemplateReconciler) lookupObject(ctx context.Context, objClient client.Client, objNamespace string, apiVersion string, kind string, namespace string, name string) (map[string]any, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvk := gv.WithKind(kind)
+	err = r.Policy.CheckLookupKind(gvk.GroupKind())
+	if err != nil {
+		return nil, err
+	}
+
+	var dummy unstructured.Unstructured
+	dummy.SetGroupVersionKind(gvk)
+	namespaced, err := objClient.IsObjectNamespaced(&dummy)
+	if err != nil {
+		return nil, err
+	}
+	if namespaced {
+		if namespace == "" {
+			namespace = objNamespace
+		}
+		err = r.Policy.CheckRefNamespace(objNamespace, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if gvk.Group == "" && gvk.Kind == "Secret" {
+			err = r.Policy.CheckSecretNamespace(objNamespace, namespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		namespace = ""
+	}
+
+	if name == "" {
+		var l unstructured.UnstructuredList
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err = objClient.List(ctx, &l, client.InNamespace(namespace))
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(l.Items, func(i, j int) bool {
+			if l.Items[i].GetNamespace() != l.Items[j].GetNamespace() {
+				return l.Items[i].GetNamespace() < l.Items[j].GetNamespace()
+			}
+			return l.Items[i].GetName() < l.Items[j].GetName()
+		})
+		items := make([]any, 0, len(l.Items))
+		for _, o := range l.Items {
+			o := o
+			if gvk.Group == "" && gvk.Kind == "Secret" {
+				registerSecretValues(ctx, &o)
+			}
+			items = append(items, o.Object)
+		}
+		return map[string]any{"items": items}, nil
+	}
+
+	var o unstructured.Unstructured
+	o.SetGroupVersionKind(gvk)
+	err = objClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &o)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return map[string]any{}, nil
+		}
+		return nil, err
+	}
+	if gvk.Group == "" && gvk.Kind == "Secret" {
+		registerSecretValues(ctx, &o)
+	}
+	return o.Object, nil
+}
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/go-containerregistry/pkg/name"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"io"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"net/http"
+	"path"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxTemplateArchiveSize limits the size of downloaded template archives
+const maxTemplateArchiveSize = 50 << 20
+
+// maxCachedTemplateArchives limits the number of extracted archives kept in memory
+const maxCachedTemplateArchives = 32
+
+var gitRepositoryRef = templatesv1alpha1.ObjectRef{APIVersion: "source.toolkit.fluxcd.io/v1", Kind: "GitRepository"}
+
+// templateArchiveCache caches extracted archives by digest, so that unchanged artifacts are not downloaded on every
+// reconciliation
+var templateArchiveCache = struct {
+	sync.Mutex
+	m map[string]map[string][]byte
+}{m: map[string]map[string][]byte{}}
+
+// templateSourceRefs returns references to all source objects the given templates are loaded from
+func templateSourceRefs(templates []templatesv1alpha1.Template) []templatesv1alpha1.ObjectRef {
+	var ret []templatesv1alpha1.ObjectRef
+	for _, t := range templates {
+		if t.GitRepository != nil {
+			ref := gitRepositoryRef
+			ref.Namespace = t.GitRepository.Namespace
+			ref.Name = t.GitRepository.Name
+			ret = append(ret, ref)
+		}
+	}
+	return ret
+}
+
+// resolveGitRepositoryTemplates loads the raw templates found at the given path of the artifact of a Flux
+// GitRepository. The GitRepository is read through objClient, so the service account must have permissions to get it.
+func (r *BaseTemplateReconciler) resolveGitRepositoryTemplates(ctx context.Context, objClient client.Client, objNamespace string, src *templatesv1alpha1.GitRepositoryTemplateSource) ([]templatesv1alpha1.Template, error) {
+	key := client.ObjectKey{Namespace: objNamespace, Name: src.Name}
+	if src.Namespace != "" {
+		key.Namespace = src.Namespace
+	}
+	err := r.Policy.CheckUnsignedTemplateSource("GitRepository " + key.String())
+	if err != nil {
+		return nil, err
+	}
+	err = r.Policy.CheckRefNamespace(objNamespace, key.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk, err := gitRepositoryRef.GroupVersionKind()
+	if err != nil {
+		return nil, err
+	}
+	var u unstructured.Unstructured
+	u.SetGroupVersionKind(gvk)
+	err = objClient.Get(ctx, key, &u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitRepository %s: %w", key.String(), err)
+	}
+
+	url, _, _ := unstructured.NestedString(u.Object, "status", "artifact", "url")
+	if url == "" {
+		return nil, fmt.Errorf("GitRepository %s has no artifact yet", key.String())
+	}
+	digest, _, _ := unstructured.NestedString(u.Object, "status", "artifact", "digest")
+	if digest == "" {
+		return nil, fmt.Errorf("GitRepository %s has no artifact digest", key.String())
+	}
+
+	files, err := fetchTemplateArchive(ctx, r.Policy.HTTPClient(), url, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artifact of GitRepository %s: %w", key.String(), err)
+	}
+	return loadArchiveTemplates(files, src.Path)
+}
+
+// fetchTemplateArchive downloads the tar.gz archive from url, verifies it against the given sha256 digest and returns
+// the contents of all regular files by path.
+func fetchTemplateArchive(ctx context.Context, hc *http.Client, url string, digest string) (map[string][]byte, error) {
+	expected := strings.TrimPrefix(digest, "sha256:")
+
+	files, ok := getCachedTemplateArchive(expected)
+	if ok {
+		return files, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned unexpected status code %d", req.URL.Redacted(), resp.StatusCode)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxTemplateArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxTemplateArchiveSize {
+		return nil, fmt.Errorf("archive exceeds the maximum size of %d bytes", maxTemplateArchiveSize)
+	}
+	h := sha256.Sum256(b)
+	if hex.EncodeToString(h[:]) != expected {
+		return nil, fmt.Errorf("archive digest does not match %s", digest)
+	}
+
+	files, err = extractTarGz(b)
+	if err != nil {
+		return nil, err
+	}
+	putCachedTemplateArchive(expected, files)
+	return files, nil
+}
+
+// resolveOCITemplates loads the raw templates found at the given path of an OCI artifact. The content layer is
+// expected to be a tar.gz archive, as pushed by `flux push artifact` or by spec.ociOutput. If src.Verify is set, the
+// artifact is only loaded if it carries a valid cosign signature of one of the trusted keys.
+func (r *BaseTemplateReconciler) resolveOCITemplates(ctx context.Context, objClient client.Client, objNamespace string, src *templatesv1alpha1.OCITemplateSource) ([]templatesv1alpha1.Template, error) {
+	repo, err := name.NewRepository(strings.TrimPrefix(src.URL, "oci://"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCI url %s: %w", src.URL, err)
+	}
+	err = r.Policy.CheckRegistry(repo.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+	if src.Verify == nil {
+		err = r.Policy.CheckUnsignedTemplateSource(src.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	auth, err := buildOCIAuth(ctx, objClient, objNamespace, src.SecretRef, repo.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+
+	var ref name.Reference
+	if src.Digest != "" {
+		ref, err = name.NewDigest(repo.String() + "@" + src.Digest)
+	} else {
+		tag := src.Tag
+		if tag == "" {
+			tag = "latest"
+		}
+		ref, err = name.NewTag(repo.String()+":"+tag, name.StrictValidation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuth(auth),
+		remote.WithTransport(r.Policy.HTTPClient().Transport),
+	}
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", ref.String(), err)
+	}
+	if src.Verify != nil {
+		keys, err := loadPublicKeys(ctx, r.Client, objClient, r.Policy, objNamespace, src.Verify.PublicKeysRef)
+		if err != nil {
+			return nil, err
+		}
+		// the digest of the pulled manifest is verified, so that the tag can not be moved in the meantime
+		digest, err := img.Digest()
+		if err != nil {
+			return nil, err
+		}
+		err = verifyCosignSignature(repo, digest, keys, opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	layer, err := findOCIContentLayer(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", ref.String(), err)
+	}
+	layerDigest, err := layer.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	files, ok := getCachedTemplateArchive(layerDigest.Hex)
+	if !ok {
+		rc, err := layer.Compressed()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(io.LimitReader(rc, maxTemplateArchiveSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to pull %s: %w", ref.String(), err)
+		}
+		if len(b) > maxTemplateArchiveSize {
+			return nil, fmt.Errorf("archive exceeds the maximum size of %d bytes", maxTemplateArchiveSize)
+		}
+		files, err = extractTarGz(b)
+		if err != nil {
+			return nil, err
+		}
+		putCachedTemplateArchive(layerDigest.Hex, files)
+	}
+	return loadArchiveTemplates(files, src.Path)
+}
+
+// findOCIContentLayer returns the Flux content layer of the given image, falling back to the first layer for artifacts
+// pushed with other tools
+func findOCIContentLayer(img gcrv1.Image) (gcrv1.Layer, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("artifact has no layers")
+	}
+	for _, l := range layers {
+		mt, err := l.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		if mt == ociContentMediaType {
+			return l, nil
+		}
+	}
+	return layers[0], nil
+}
+
+func getCachedTemplateArchive(digest string) (map[string][]byte, bool) {
+	templateArchiveCache.Lock()
+	defer templateArchiveCache.Unlock()
+	files, ok := templateArchiveCache.m[digest]
+	return files, ok
+}
+
+func putCachedTemplateArchive(digest string, files map[string][]byte) {
+	templateArchiveCache.Lock()
+	defer templateArchiveCache.Unlock()
+	if len(templateArchiveCache.m) >= maxCachedTemplateArchives {
+		for k := range templateArchiveCache.m {
+			delete(templateArchiveCache.m, k)
+			break
+		}
+	}
+	templateArchiveCache.m[digest] = files
+}
+
+func extractTarGz(b []byte) (map[string][]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	files := map[string][]byte{}
+	total := 0
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// limit the extracted size as well, as compressed archives can expand to arbitrary sizes
+		data, err := io.ReadAll(io.LimitReader(tr, int64(maxTemplateArchiveSize-total+1)))
+		if err != nil {
+			return nil, err
+		}
+		total += len(data)
+		if total > maxTemplateArchiveSize {
+			return nil, fmt.Errorf("extracted archive exceeds the maximum size of %d bytes", maxTemplateArchiveSize)
+		}
+		files[path.Clean(strings.TrimPrefix(hdr.Name, "/"))] = data
+	}
+	return files, nil
+}
+
+// loadArchiveTemplates returns the file at p as raw template or, if p is a directory, all templates found directly
+// inside it
+func loadArchiveTemplates(files map[string][]byte, p string) ([]templatesv1alpha1.Template, error) {
+	p = path.Clean(strings.TrimPrefix(p, "/"))
+
+	if data, ok := files[p]; ok {
+		raw := string(data)
+		return []templatesv1alpha1.Template{{Raw: &raw}}, nil
+	}
+
+	var names []string
+	for name := range files {
+		if path.Dir(name) != p {
+			continue
+		}
+		switch path.Ext(name) {
+		case ".yaml", ".yml", ".j2":
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no templates found at path %s", p)
+	}
+	sort.Strings(names)
+
+	ret := make([]templatesv1alpha1.Template, 0, len(names))
+	for _, name := range names {
+		raw := string(files[name])
+		ret = append(ret, templatesv1alpha1.Template{Raw: &raw})
+	}
+	return ret, nil
+}
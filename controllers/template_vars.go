@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateVarsRefs returns references to all ConfigMaps and Secrets that are loaded as variables
+func templateVarsRefs(vars []templatesv1alpha1.TemplateVarsSource) []templatesv1alpha1.ObjectRef {
+	var ret []templatesv1alpha1.ObjectRef
+	for _, v := range vars {
+		if v.ConfigMapRef != nil {
+			ret = append(ret, templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: v.ConfigMapRef.Namespace, Name: v.ConfigMapRef.Name})
+		}
+		if v.SecretRef != nil {
+			ret = append(ret, templatesv1alpha1.ObjectRef{APIVersion: "v1", Kind: "Secret", Namespace: v.SecretRef.Namespace, Name: v.SecretRef.Name})
+		}
+	}
+	return ret
+}
+
+// loadTemplateVars reads all ConfigMaps and Secrets through objClient and returns their merged data. Keys of later
+// entries override the keys of earlier ones.
+func (r *BaseTemplateReconciler) loadTemplateVars(ctx context.Context, objClient client.Client, objNamespace string, vars []templatesv1alpha1.TemplateVarsSource) (map[string]any, error) {
+	ret := map[string]any{}
+	for _, v := range vars {
+		if v.ConfigMapRef != nil {
+			key, err := r.templateVarsObjectKey(objNamespace, v.ConfigMapRef, false)
+			if err != nil {
+				return nil, err
+			}
+			var cm corev1.ConfigMap
+			err = objClient.Get(ctx, key, &cm)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get ConfigMap %s: %w", key.String(), err)
+			}
+			for k, x := range cm.Data {
+				ret[k] = x
+			}
+		} else if v.SecretRef != nil {
+			key, err := r.templateVarsObjectKey(objNamespace, v.SecretRef, true)
+			if err != nil {
+				return nil, err
+			}
+			var secret corev1.Secret
+			err = objClient.Get(ctx, key, &secret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get Secret %s: %w", key.String(), err)
+			}
+			for k, x := range secret.Data {
+				redact.RegisterBytes(ctx, x)
+				ret[k] = string(x)
+			}
+		} else {
+			return nil, fmt.Errorf("missing vars value")
+		}
+	}
+	return ret, nil
+}
+
+func (r *BaseTemplateReconciler) templateVarsObjectKey(objNamespace string, ref *templatesv1alpha1.TemplateVarsObjectRef, isSecret bool) (client.ObjectKey, error) {
+	key := client.ObjectKey{Namespace: objNamespace, Name: ref.Name}
+	if ref.Namespace != "" {
+		key.Namespace = ref.Namespace
+	}
+	err := r.Policy.CheckRefNamespace(objNamespace, key.Namespace)
+	if err != nil {
+		return key, err
+	}
+	if isSecret {
+		err = r.Policy.CheckSecretNamespace(objNamespace, key.Namespace)
+		if err != nil {
+			return key, err
+		}
+	}
+	return key, nil
+}
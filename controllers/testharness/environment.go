@@ -0,0 +1,307 @@
+package testharness
+
+import (
+	"context"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/comments"
+	"github.com/kluctl/template-controller/controllers/notifications"
+	"github.com/kluctl/template-controller/controllers/objecthandler"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/receiver"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+const fieldManager = "template-controller"
+
+// Environment runs all template-controller reconcilers inside an envtest environment, with all requests to GitHub
+// and GitLab redirected to a FakeSCM. It allows to write integration tests for generators and handlers without real
+// credentials:
+//
+//	env := &testharness.Environment{}
+//	err := env.Start()
+//	...
+//	defer env.Stop()
+//
+//	env.SCM.AddGithubPullRequest("my-org", "my-repo", &github.PullRequest{...})
+//	err = env.Client.Create(ctx, &templatesv1alpha1.ListGithubPullRequests{...})
+//
+// The envtest binaries (etcd and kube-apiserver) must be available, see envtest.Environment for details.
+type Environment struct {
+	// CRDDirectoryPaths specifies the directories to load CRDs from. Defaults to the config/crd/bases directory of
+	// this module.
+	CRDDirectoryPaths []string
+
+	// Policy is passed to all reconcilers. Its Transport is replaced with the transport of the FakeSCM.
+	Policy policy.Policy
+
+	// The following fields are set by Start
+	TestEnv *envtest.Environment
+	Config  *rest.Config
+	Scheme  *runtime.Scheme
+	Client  client.Client
+	Manager manager.Manager
+	SCM     *FakeSCM
+
+	tmpDir string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// DefaultCRDDirectoryPath returns the path of the config/crd/bases directory of this module
+func DefaultCRDDirectoryPath() string {
+	_, file, _, _ := goruntime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "crd", "bases")
+}
+
+// Start starts the fake SCM, the envtest environment and a manager with all reconcilers
+func (e *Environment) Start() error {
+	crdPaths := e.CRDDirectoryPaths
+	if len(crdPaths) == 0 {
+		crdPaths = []string{DefaultCRDDirectoryPath()}
+	}
+
+	e.Scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(e.Scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(e.Scheme))
+	utilruntime.Must(templatesv1alpha1.AddToScheme(e.Scheme))
+
+	e.SCM = NewFakeSCM()
+	e.Policy.Transport = e.SCM.Transport()
+
+	e.TestEnv = &envtest.Environment{
+		CRDDirectoryPaths:     crdPaths,
+		ErrorIfCRDPathMissing: true,
+	}
+
+	var err error
+	e.Config, err = e.TestEnv.Start()
+	if err != nil {
+		e.SCM.Close()
+		return err
+	}
+
+	err = e.startManager()
+	if err != nil {
+		_ = e.Stop()
+		return err
+	}
+	return nil
+}
+
+func (e *Environment) startManager() error {
+	var err error
+	e.Client, err = client.New(e.Config, client.Options{Scheme: e.Scheme})
+	if err != nil {
+		return err
+	}
+
+	e.Manager, err = ctrl.NewManager(e.Config, ctrl.Options{
+		Scheme: e.Scheme,
+		Metrics: metricsserver.Options{
+			BindAddress: "0",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	e.tmpDir, err = os.MkdirTemp("", "template-controller-test-")
+	if err != nil {
+		return err
+	}
+
+	err = e.setupReconcilers()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan error, 1)
+	go func() {
+		e.done <- e.Manager.Start(ctx)
+	}()
+
+	if !e.Manager.GetCache().WaitForCacheSync(ctx) {
+		return fmt.Errorf("failed to wait for cache sync")
+	}
+	return nil
+}
+
+func (e *Environment) setupReconcilers() error {
+	mgr := e.Manager
+	p, err := policy.New(e.Policy)
+	if err != nil {
+		return err
+	}
+
+	baseTemplateReconciler := func(p *policy.Policy) controllers.BaseTemplateReconciler {
+		return controllers.BaseTemplateReconciler{
+			Client:       mgr.GetClient(),
+			Scheme:       mgr.GetScheme(),
+			FieldManager: fieldManager,
+			Policy:       p,
+		}
+	}
+	baseCommentReconciler := comments.BaseCommentReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}
+
+	if err := (&controllers.ObjectTemplateReconciler{
+		BaseTemplateReconciler: baseTemplateReconciler(p),
+	}).SetupWithManager(mgr, 1); err != nil {
+		return err
+	}
+	if err := (&controllers.ClusterObjectTemplateReconciler{
+		ObjectTemplateReconciler: controllers.ObjectTemplateReconciler{
+			BaseTemplateReconciler: baseTemplateReconciler(p.ForClusterTemplates()),
+		},
+	}).SetupWithManager(mgr, 1); err != nil {
+		return err
+	}
+	if err := (&controllers.TextTemplateReconciler{
+		BaseTemplateReconciler: baseTemplateReconciler(p),
+	}).SetupWithManager(mgr, 1); err != nil {
+		return err
+	}
+	if err := (&objecthandler.ObjectHandlerReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr, 1); err != nil {
+		return err
+	}
+	if err := (&notifications.NotificationPolicyReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr, 1); err != nil {
+		return err
+	}
+	if err := (&controllers.ListGitlabMergeRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.ListGithubPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.ListGiteaPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.ListBitbucketServerPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.ListBitbucketCloudPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.ListAzureDevOpsPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.ListRepositoriesReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&controllers.GitProjectorReconciler{
+		Client:       mgr.GetClient(),
+		Manager:      mgr,
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+		TmpBaseDir:   e.tmpDir,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&comments.GitlabCommentReconciler{
+		BaseCommentReconciler: baseCommentReconciler,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&comments.GithubCommentReconciler{
+		BaseCommentReconciler: baseCommentReconciler,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&receiver.ReceiverReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       p,
+	}).SetupWithManager(mgr); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Stop stops the manager, the envtest environment and the fake SCM
+func (e *Environment) Stop() error {
+	var err error
+	if e.cancel != nil {
+		e.cancel()
+		err = <-e.done
+		e.cancel = nil
+	}
+	if e.TestEnv != nil {
+		if err2 := e.TestEnv.Stop(); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	if e.SCM != nil {
+		e.SCM.Close()
+	}
+	if e.tmpDir != "" {
+		_ = os.RemoveAll(e.tmpDir)
+	}
+	return err
+}
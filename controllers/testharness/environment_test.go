@@ -0,0 +1,194 @@
+package testharness
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v47/github"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	"github.com/xanzy/go-gitlab"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	timeout  = 30 * time.Second
+	interval = 250 * time.Millisecond
+)
+
+// startEnvironment starts an Environment for the test and stops it when the test finishes. The test is skipped if the
+// envtest binaries are not available.
+func startEnvironment(t *testing.T) *Environment {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		if _, err := os.Stat("/usr/local/kubebuilder/bin/etcd"); err != nil {
+			t.Skip("envtest binaries not found, set KUBEBUILDER_ASSETS to run this test")
+		}
+	}
+
+	env := &Environment{}
+	err := env.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = env.Stop()
+	})
+	return env
+}
+
+func createTokenSecret(t *testing.T, env *Environment, ctx context.Context, namespace string) *templatesv1alpha1.SecretRef {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "scm-token",
+		},
+		StringData: map[string]string{
+			"token": "fake-token",
+		},
+	}
+	NewWithT(t).Expect(env.Client.Create(ctx, secret)).To(Succeed())
+	return &templatesv1alpha1.SecretRef{SecretName: secret.Name, Key: "token"}
+}
+
+func decodeRawExtensions(t *testing.T, l []runtime.RawExtension) []map[string]any {
+	var ret []map[string]any
+	for _, x := range l {
+		var m map[string]any
+		NewWithT(t).Expect(json.Unmarshal(x.Raw, &m)).To(Succeed())
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+func TestListGithubPullRequests(t *testing.T) {
+	g := NewWithT(t)
+	env := startEnvironment(t)
+	ctx := context.Background()
+
+	env.SCM.AddGithubPullRequest("my-org", "my-repo", &github.PullRequest{
+		Title: github.String("PR 1"),
+		Head:  &github.PullRequestBranch{Ref: github.String("feature-1")},
+	})
+	env.SCM.AddGithubPullRequest("my-org", "my-repo", &github.PullRequest{
+		Title: github.String("PR 2"),
+		Head:  &github.PullRequestBranch{Ref: github.String("fix-1")},
+	})
+
+	obj := &templatesv1alpha1.ListGithubPullRequests{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "prs",
+		},
+		Spec: templatesv1alpha1.ListGithubPullRequestsSpec{
+			Interval: metav1.Duration{Duration: time.Minute},
+			GithubProject: templatesv1alpha1.GithubProject{
+				Owner:    "my-org",
+				Repo:     "my-repo",
+				TokenRef: createTokenSecret(t, env, ctx, "default"),
+			},
+			State: "all",
+			Limit: 100,
+		},
+	}
+	g.Expect(env.Client.Create(ctx, obj)).To(Succeed())
+
+	g.Eventually(func() []any {
+		_ = env.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		var titles []any
+		for _, pr := range decodeRawExtensions(t, obj.Status.PullRequests) {
+			titles = append(titles, pr["title"])
+		}
+		return titles
+	}, timeout, interval).Should(ConsistOf("PR 1", "PR 2"))
+}
+
+func TestListGitlabMergeRequests(t *testing.T) {
+	g := NewWithT(t)
+	env := startEnvironment(t)
+	ctx := context.Background()
+
+	env.SCM.AddGitlabMergeRequest("my-group/my-project", &gitlab.MergeRequest{
+		Title:        "MR 1",
+		SourceBranch: "feature-1",
+	})
+
+	project := intstr.FromString("my-group/my-project")
+	state := "opened"
+	obj := &templatesv1alpha1.ListGitlabMergeRequests{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "mrs",
+		},
+		Spec: templatesv1alpha1.ListGitlabMergeRequestsSpec{
+			Interval: metav1.Duration{Duration: time.Minute},
+			GitlabProjectOrGroup: templatesv1alpha1.GitlabProjectOrGroup{
+				Project: &project,
+				GitlabAuth: templatesv1alpha1.GitlabAuth{
+					TokenRef: createTokenSecret(t, env, ctx, "default"),
+				},
+			},
+			State: &state,
+			Limit: 100,
+		},
+	}
+	g.Expect(env.Client.Create(ctx, obj)).To(Succeed())
+
+	g.Eventually(func() []any {
+		_ = env.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		var titles []any
+		for _, mr := range decodeRawExtensions(t, obj.Status.MergeRequests) {
+			titles = append(titles, mr["title"])
+		}
+		return titles
+	}, timeout, interval).Should(ConsistOf("MR 1"))
+}
+
+func TestGithubComment(t *testing.T) {
+	g := NewWithT(t)
+	env := startEnvironment(t)
+	ctx := context.Background()
+
+	pr := env.SCM.AddGithubPullRequest("my-org", "my-repo", &github.PullRequest{
+		Title: github.String("PR 1"),
+	})
+
+	prId := intstr.FromInt(pr.GetNumber())
+	text := "Preview environment is ready"
+	obj := &templatesv1alpha1.GithubComment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "comment",
+		},
+		Spec: templatesv1alpha1.GithubCommentSpec{
+			GithubPullRequestRef: templatesv1alpha1.GithubPullRequestRef{
+				GithubProject: templatesv1alpha1.GithubProject{
+					Owner:    "my-org",
+					Repo:     "my-repo",
+					TokenRef: createTokenSecret(t, env, ctx, "default"),
+				},
+				PullRequestId: &prId,
+			},
+			CommentSpec: templatesv1alpha1.CommentSpec{
+				Source: templatesv1alpha1.CommentSourceSpec{
+					Text: &text,
+				},
+			},
+		},
+	}
+	g.Expect(env.Client.Create(ctx, obj)).To(Succeed())
+
+	g.Eventually(func() []string {
+		var bodies []string
+		for _, c := range env.SCM.GithubComments("my-org", "my-repo", pr.GetNumber()) {
+			bodies = append(bodies, c.GetBody())
+		}
+		return bodies
+	}, timeout, interval).Should(ConsistOf(ContainSubstring(text)))
+}
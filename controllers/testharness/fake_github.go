@@ -0,0 +1,325 @@
+package testharness
+
+import (
+	"fmt"
+	"github.com/google/go-github/v47/github"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type fakeGithubComment struct {
+	repo    string
+	number  int
+	comment *github.IssueComment
+}
+
+type fakeGithubReview struct {
+	repo   string
+	number int
+	review *github.PullRequestReview
+}
+
+func githubRepoKey(owner string, repo string) string {
+	return owner + "/" + repo
+}
+
+func githubUser(login string, id int64) *github.User {
+	return &github.User{
+		ID:    github.Int64(id),
+		Login: github.String(login),
+	}
+}
+
+// AddGithubPullRequest adds a pull request to the given repository. Number, ID, State and timestamps are filled in if
+// not set.
+func (s *FakeSCM) AddGithubPullRequest(owner string, repo string, pr *github.PullRequest) *github.PullRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := githubRepoKey(owner, repo)
+	pr = copyJson(pr)
+	if pr.ID == nil {
+		pr.ID = github.Int64(s.newId())
+	}
+	if pr.Number == nil {
+		pr.Number = github.Int(len(s.githubPullRequests[key]) + 1)
+	}
+	if pr.State == nil {
+		pr.State = github.String("open")
+	}
+	now := time.Now()
+	if pr.CreatedAt == nil {
+		pr.CreatedAt = &now
+	}
+	if pr.UpdatedAt == nil {
+		pr.UpdatedAt = &now
+	}
+	if pr.Head == nil {
+		pr.Head = &github.PullRequestBranch{}
+	}
+	if pr.Base == nil {
+		pr.Base = &github.PullRequestBranch{Ref: github.String("main")}
+	}
+	s.githubPullRequests[key] = append(s.githubPullRequests[key], pr)
+	return copyJson(pr)
+}
+
+// UpdateGithubPullRequest calls f with the given pull request, allowing to modify it
+func (s *FakeSCM) UpdateGithubPullRequest(owner string, repo string, number int, f func(pr *github.PullRequest)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pr := s.findGithubPullRequest(githubRepoKey(owner, repo), number)
+	if pr == nil {
+		return fmt.Errorf("pull request %s/%s#%d not found", owner, repo, number)
+	}
+	f(pr)
+	now := time.Now()
+	pr.UpdatedAt = &now
+	return nil
+}
+
+// RemoveGithubPullRequest removes the given pull request
+func (s *FakeSCM) RemoveGithubPullRequest(owner string, repo string, number int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := githubRepoKey(owner, repo)
+	var l []*github.PullRequest
+	for _, pr := range s.githubPullRequests[key] {
+		if pr.GetNumber() != number {
+			l = append(l, pr)
+		}
+	}
+	s.githubPullRequests[key] = l
+}
+
+// AddGithubComment adds a comment from the given user to the given pull request, e.g. to simulate commands
+func (s *FakeSCM) AddGithubComment(owner string, repo string, number int, login string, body string) *github.IssueComment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c := s.addGithubComment(githubRepoKey(owner, repo), number, githubUser(login, s.newId()+1000), body)
+	return copyJson(c)
+}
+
+// GithubComments returns all comments of the given pull request, sorted by creation time
+func (s *FakeSCM) GithubComments(owner string, repo string, number int) []*github.IssueComment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var ret []*github.IssueComment
+	for _, c := range s.githubComments {
+		if c.repo == githubRepoKey(owner, repo) && c.number == number {
+			ret = append(ret, copyJson(c.comment))
+		}
+	}
+	return ret
+}
+
+// GithubApproved returns true if the most recent review of FakeUserName on the pull request is an approval
+func (s *FakeSCM) GithubApproved(owner string, repo string, number int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	approved := false
+	for _, r := range s.githubReviews {
+		if r.repo == githubRepoKey(owner, repo) && r.number == number && r.review.GetUser().GetID() == FakeUserID {
+			approved = r.review.GetState() == "APPROVED"
+		}
+	}
+	return approved
+}
+
+func (s *FakeSCM) findGithubPullRequest(key string, number int) *github.PullRequest {
+	for _, pr := range s.githubPullRequests[key] {
+		if pr.GetNumber() == number {
+			return pr
+		}
+	}
+	return nil
+}
+
+func (s *FakeSCM) addGithubComment(key string, number int, user *github.User, body string) *github.IssueComment {
+	now := time.Now()
+	c := &github.IssueComment{
+		ID:        github.Int64(s.newId()),
+		Body:      github.String(body),
+		User:      user,
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	s.githubComments = append(s.githubComments, &fakeGithubComment{
+		repo:    key,
+		number:  number,
+		comment: c,
+	})
+	return c
+}
+
+func (s *FakeSCM) registerGithubRoutes() {
+	s.handle("GET", "/github/rate_limit", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		w.Header().Set("X-OAuth-Scopes", strings.Join(s.GithubScopes, ", "))
+		writeJson(w, http.StatusOK, map[string]any{"resources": map[string]any{}})
+	})
+	s.handle("GET", "/github/user", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		writeJson(w, http.StatusOK, githubUser(FakeUserName, FakeUserID))
+	})
+
+	s.handle("GET", "/github/repos/:owner/:repo/pulls", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		state := r.URL.Query().Get("state")
+		if state == "" {
+			state = "open"
+		}
+		var l []*github.PullRequest
+		for _, pr := range s.githubPullRequests[githubRepoKey(params["owner"], params["repo"])] {
+			if state == "all" || pr.GetState() == state {
+				l = append(l, pr)
+			}
+		}
+		writeJson(w, http.StatusOK, paginate(r, l, 30))
+	})
+	s.handle("GET", "/github/repos/:owner/:repo/pulls/:number", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		number, _ := strconv.Atoi(params["number"])
+		pr := s.findGithubPullRequest(githubRepoKey(params["owner"], params["repo"]), number)
+		if pr == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJson(w, http.StatusOK, pr)
+	})
+
+	s.handle("GET", "/github/repos/:owner/:repo/pulls/:number/reviews", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		number, _ := strconv.Atoi(params["number"])
+		key := githubRepoKey(params["owner"], params["repo"])
+		l := []*github.PullRequestReview{}
+		for _, x := range s.githubReviews {
+			if x.repo == key && x.number == number {
+				l = append(l, x.review)
+			}
+		}
+		writeJson(w, http.StatusOK, paginate(r, l, 30))
+	})
+	s.handle("POST", "/github/repos/:owner/:repo/pulls/:number/reviews", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		number, _ := strconv.Atoi(params["number"])
+		var req github.PullRequestReviewRequest
+		if err := readJson(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		state := "COMMENTED"
+		switch req.GetEvent() {
+		case "APPROVE":
+			state = "APPROVED"
+		case "REQUEST_CHANGES":
+			state = "CHANGES_REQUESTED"
+		}
+		review := &github.PullRequestReview{
+			ID:    github.Int64(s.newId()),
+			User:  githubUser(FakeUserName, FakeUserID),
+			Body:  req.Body,
+			State: github.String(state),
+		}
+		s.githubReviews = append(s.githubReviews, &fakeGithubReview{
+			repo:   githubRepoKey(params["owner"], params["repo"]),
+			number: number,
+			review: review,
+		})
+		writeJson(w, http.StatusOK, review)
+	})
+	s.handle("PUT", "/github/repos/:owner/:repo/pulls/:number/reviews/:id/dismissals", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		id, _ := strconv.ParseInt(params["id"], 10, 64)
+		for _, x := range s.githubReviews {
+			if x.review.GetID() == id {
+				x.review.State = github.String("DISMISSED")
+				writeJson(w, http.StatusOK, x.review)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Not Found")
+	})
+
+	s.handle("GET", "/github/repos/:owner/:repo/issues/:number/comments", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		number, _ := strconv.Atoi(params["number"])
+		key := githubRepoKey(params["owner"], params["repo"])
+		var since time.Time
+		if x := r.URL.Query().Get("since"); x != "" {
+			since, _ = time.Parse(time.RFC3339, x)
+		}
+		l := []*github.IssueComment{}
+		for _, c := range s.githubComments {
+			if c.repo == key && c.number == number && !c.comment.GetUpdatedAt().Before(since) {
+				l = append(l, c.comment)
+			}
+		}
+		if r.URL.Query().Get("direction") == "desc" {
+			sort.SliceStable(l, func(i, j int) bool {
+				return l[i].GetCreatedAt().After(l[j].GetCreatedAt())
+			})
+		}
+		writeJson(w, http.StatusOK, paginate(r, l, 30))
+	})
+	s.handle("POST", "/github/repos/:owner/:repo/issues/:number/comments", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		number, _ := strconv.Atoi(params["number"])
+		var req github.IssueComment
+		if err := readJson(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		c := s.addGithubComment(githubRepoKey(params["owner"], params["repo"]), number, githubUser(FakeUserName, FakeUserID), req.GetBody())
+		writeJson(w, http.StatusCreated, c)
+	})
+	s.handle("GET", "/github/repos/:owner/:repo/issues/comments/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		c := s.findGithubComment(params)
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJson(w, http.StatusOK, c.comment)
+	})
+	s.handle("PATCH", "/github/repos/:owner/:repo/issues/comments/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		c := s.findGithubComment(params)
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		var req github.IssueComment
+		if err := readJson(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		now := time.Now()
+		c.comment.Body = req.Body
+		c.comment.UpdatedAt = &now
+		writeJson(w, http.StatusOK, c.comment)
+	})
+	s.handle("DELETE", "/github/repos/:owner/:repo/issues/comments/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		c := s.findGithubComment(params)
+		if c == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		var l []*fakeGithubComment
+		for _, x := range s.githubComments {
+			if x != c {
+				l = append(l, x)
+			}
+		}
+		s.githubComments = l
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *FakeSCM) findGithubComment(params map[string]string) *fakeGithubComment {
+	id, _ := strconv.ParseInt(params["id"], 10, 64)
+	key := githubRepoKey(params["owner"], params["repo"])
+	for _, c := range s.githubComments {
+		if c.repo == key && c.comment.GetID() == id {
+			return c
+		}
+	}
+	return nil
+}
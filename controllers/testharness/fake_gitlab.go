@@ -0,0 +1,327 @@
+package testharness
+
+import (
+	"fmt"
+	"github.com/xanzy/go-gitlab"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type fakeGitlabNote struct {
+	project string
+	iid     int
+	note    *gitlab.Note
+}
+
+func gitlabMergeRequestKey(project string, iid int) string {
+	return fmt.Sprintf("%s!%d", project, iid)
+}
+
+// AddGitlabMergeRequest adds a merge request to the given project. The project must be specified in the same form
+// (numeric ID or path) as used by the objects under test. IID, ID, State and timestamps are filled in if not set.
+func (s *FakeSCM) AddGitlabMergeRequest(project string, mr *gitlab.MergeRequest) *gitlab.MergeRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	mr = copyJson(mr)
+	if mr.ID == 0 {
+		mr.ID = int(s.newId())
+	}
+	if mr.IID == 0 {
+		mr.IID = len(s.gitlabMergeRequests[project]) + 1
+	}
+	if mr.State == "" {
+		mr.State = "opened"
+	}
+	if mr.TargetBranch == "" {
+		mr.TargetBranch = "main"
+	}
+	now := time.Now()
+	if mr.CreatedAt == nil {
+		mr.CreatedAt = &now
+	}
+	if mr.UpdatedAt == nil {
+		mr.UpdatedAt = &now
+	}
+	s.gitlabMergeRequests[project] = append(s.gitlabMergeRequests[project], mr)
+	return copyJson(mr)
+}
+
+// UpdateGitlabMergeRequest calls f with the given merge request, allowing to modify it
+func (s *FakeSCM) UpdateGitlabMergeRequest(project string, iid int, f func(mr *gitlab.MergeRequest)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	mr := s.findGitlabMergeRequest(project, iid)
+	if mr == nil {
+		return fmt.Errorf("merge request %s not found", gitlabMergeRequestKey(project, iid))
+	}
+	f(mr)
+	now := time.Now()
+	mr.UpdatedAt = &now
+	return nil
+}
+
+// RemoveGitlabMergeRequest removes the given merge request
+func (s *FakeSCM) RemoveGitlabMergeRequest(project string, iid int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var l []*gitlab.MergeRequest
+	for _, mr := range s.gitlabMergeRequests[project] {
+		if mr.IID != iid {
+			l = append(l, mr)
+		}
+	}
+	s.gitlabMergeRequests[project] = l
+}
+
+// AddGitlabNote adds a note from the given user to the given merge request, e.g. to simulate commands
+func (s *FakeSCM) AddGitlabNote(project string, iid int, username string, body string) *gitlab.Note {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n := s.addGitlabNote(project, iid, username, int(s.newId())+1000, body)
+	return copyJson(n)
+}
+
+// GitlabNotes returns all notes of the given merge request, sorted by creation time
+func (s *FakeSCM) GitlabNotes(project string, iid int) []*gitlab.Note {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var ret []*gitlab.Note
+	for _, n := range s.gitlabNotes {
+		if n.project == project && n.iid == iid {
+			ret = append(ret, copyJson(n.note))
+		}
+	}
+	return ret
+}
+
+// GitlabApproved returns true if the merge request is currently approved by FakeUserName
+func (s *FakeSCM) GitlabApproved(project string, iid int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.gitlabApprovals[gitlabMergeRequestKey(project, iid)]
+}
+
+func (s *FakeSCM) findGitlabMergeRequest(project string, iid int) *gitlab.MergeRequest {
+	for _, mr := range s.gitlabMergeRequests[project] {
+		if mr.IID == iid {
+			return mr
+		}
+	}
+	return nil
+}
+
+func (s *FakeSCM) addGitlabNote(project string, iid int, username string, userId int, body string) *gitlab.Note {
+	now := time.Now()
+	n := &gitlab.Note{
+		ID:        int(s.newId()),
+		Body:      body,
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	n.Author.ID = userId
+	n.Author.Username = username
+	s.gitlabNotes = append(s.gitlabNotes, &fakeGitlabNote{
+		project: project,
+		iid:     iid,
+		note:    n,
+	})
+	return n
+}
+
+func (s *FakeSCM) findGitlabNote(params map[string]string) *fakeGitlabNote {
+	iid, _ := strconv.Atoi(params["iid"])
+	id, _ := strconv.Atoi(params["id"])
+	for _, n := range s.gitlabNotes {
+		if n.project == params["project"] && n.iid == iid && n.note.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+func (s *FakeSCM) registerGitlabRoutes() {
+	s.handle("GET", "/api/v4/personal_access_tokens/self", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		writeJson(w, http.StatusOK, &gitlab.PersonalAccessToken{
+			ID:     1,
+			Name:   "fake",
+			Active: true,
+			UserID: FakeUserID,
+			Scopes: s.GitlabScopes,
+		})
+	})
+	s.handle("GET", "/api/v4/user", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		writeJson(w, http.StatusOK, &gitlab.User{
+			ID:       FakeUserID,
+			Username: FakeUserName,
+		})
+	})
+
+	s.handle("GET", "/api/v4/projects/:project/merge_requests", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		q := r.URL.Query()
+		state := q.Get("state")
+		var labels []string
+		if x := q.Get("labels"); x != "" {
+			labels = strings.Split(x, ",")
+		}
+
+		l := []*gitlab.MergeRequest{}
+		for _, mr := range s.gitlabMergeRequests[params["project"]] {
+			if state != "" && state != "all" && mr.State != state {
+				continue
+			}
+			if x := q.Get("source_branch"); x != "" && mr.SourceBranch != x {
+				continue
+			}
+			if x := q.Get("target_branch"); x != "" && mr.TargetBranch != x {
+				continue
+			}
+			if !hasAllLabels(mr.Labels, labels) {
+				continue
+			}
+			l = append(l, mr)
+		}
+		writeJson(w, http.StatusOK, paginate(r, l, 20))
+	})
+	s.handle("GET", "/api/v4/projects/:project/merge_requests/:iid", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		iid, _ := strconv.Atoi(params["iid"])
+		mr := s.findGitlabMergeRequest(params["project"], iid)
+		if mr == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		writeJson(w, http.StatusOK, mr)
+	})
+
+	s.handle("GET", "/api/v4/projects/:project/merge_requests/:iid/approvals", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		iid, _ := strconv.Atoi(params["iid"])
+		a := &gitlab.MergeRequestApprovals{
+			IID:             iid,
+			ApprovedBy:      []*gitlab.MergeRequestApproverUser{},
+			UserCanApprove:  true,
+			UserHasApproved: s.gitlabApprovals[gitlabMergeRequestKey(params["project"], iid)],
+		}
+		if a.UserHasApproved {
+			a.ApprovedBy = append(a.ApprovedBy, &gitlab.MergeRequestApproverUser{
+				User: &gitlab.BasicUser{ID: FakeUserID, Username: FakeUserName},
+			})
+		}
+		writeJson(w, http.StatusOK, a)
+	})
+	s.handle("POST", "/api/v4/projects/:project/merge_requests/:iid/approve", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		iid, _ := strconv.Atoi(params["iid"])
+		key := gitlabMergeRequestKey(params["project"], iid)
+		if s.gitlabApprovals[key] {
+			writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+			return
+		}
+		s.gitlabApprovals[key] = true
+		writeJson(w, http.StatusCreated, &gitlab.MergeRequestApprovals{IID: iid, UserHasApproved: true})
+	})
+	s.handle("POST", "/api/v4/projects/:project/merge_requests/:iid/unapprove", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		iid, _ := strconv.Atoi(params["iid"])
+		key := gitlabMergeRequestKey(params["project"], iid)
+		if !s.gitlabApprovals[key] {
+			writeError(w, http.StatusNotFound, "404 Not Found")
+			return
+		}
+		delete(s.gitlabApprovals, key)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	s.handle("GET", "/api/v4/projects/:project/merge_requests/:iid/notes", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		iid, _ := strconv.Atoi(params["iid"])
+		l := []*gitlab.Note{}
+		for _, n := range s.gitlabNotes {
+			if n.project == params["project"] && n.iid == iid {
+				l = append(l, n.note)
+			}
+		}
+		// GitLab sorts by created_at in descending order by default
+		if r.URL.Query().Get("sort") != "asc" {
+			sort.SliceStable(l, func(i, j int) bool {
+				return l[i].CreatedAt.After(*l[j].CreatedAt)
+			})
+		}
+		writeJson(w, http.StatusOK, paginate(r, l, 20))
+	})
+	s.handle("POST", "/api/v4/projects/:project/merge_requests/:iid/notes", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		iid, _ := strconv.Atoi(params["iid"])
+		var req gitlab.CreateMergeRequestNoteOptions
+		if err := readJson(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		body := ""
+		if req.Body != nil {
+			body = *req.Body
+		}
+		n := s.addGitlabNote(params["project"], iid, FakeUserName, FakeUserID, body)
+		writeJson(w, http.StatusCreated, n)
+	})
+	s.handle("GET", "/api/v4/projects/:project/merge_requests/:iid/notes/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		n := s.findGitlabNote(params)
+		if n == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		writeJson(w, http.StatusOK, n.note)
+	})
+	s.handle("PUT", "/api/v4/projects/:project/merge_requests/:iid/notes/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		n := s.findGitlabNote(params)
+		if n == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		var req gitlab.UpdateMergeRequestNoteOptions
+		if err := readJson(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if req.Body != nil {
+			n.note.Body = *req.Body
+		}
+		now := time.Now()
+		n.note.UpdatedAt = &now
+		writeJson(w, http.StatusOK, n.note)
+	})
+	s.handle("DELETE", "/api/v4/projects/:project/merge_requests/:iid/notes/:id", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		n := s.findGitlabNote(params)
+		if n == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		var l []*fakeGitlabNote
+		for _, x := range s.gitlabNotes {
+			if x != n {
+				l = append(l, x)
+			}
+		}
+		s.gitlabNotes = l
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func hasAllLabels(have []string, want []string) bool {
+	for _, l := range want {
+		found := false
+		for _, l2 := range have {
+			if l == l2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
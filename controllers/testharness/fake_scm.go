@@ -0,0 +1,214 @@
+package testharness
+
+import (
+	"encoding/json"
+	"github.com/google/go-github/v47/github"
+	"github.com/xanzy/go-gitlab"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// FakeUserID is the ID of the user that is authenticated by all tokens passed to the FakeSCM
+	FakeUserID = 1
+	// FakeUserName is the name of the user that is authenticated by all tokens passed to the FakeSCM
+	FakeUserName = "template-controller"
+)
+
+// FakeSCM is an in-process fake of the GitHub and GitLab APIs used by the generators and handlers. Pull requests and
+// merge requests are programmed via the Add/Update/Remove methods, while comments, notes and approvals created by the
+// controllers can be inspected afterwards.
+//
+// Requests to api.github.com and gitlab.com are redirected to the fake server when the transport returned by
+// Transport is used, e.g. by setting it as policy.Policy.Transport.
+type FakeSCM struct {
+	// GithubScopes is returned in the X-OAuth-Scopes header of GitHub responses
+	GithubScopes []string
+	// GitlabScopes is returned as the scopes of the personal access token on GitLab
+	GitlabScopes []string
+
+	server *httptest.Server
+	routes []fakeRoute
+
+	mutex  sync.Mutex
+	nextId int64
+
+	githubPullRequests map[string][]*github.PullRequest
+	githubComments     []*fakeGithubComment
+	githubReviews      []*fakeGithubReview
+
+	gitlabMergeRequests map[string][]*gitlab.MergeRequest
+	gitlabNotes         []*fakeGitlabNote
+	gitlabApprovals     map[string]bool
+}
+
+type fakeRoute struct {
+	method  string
+	pattern []string
+	handler func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// NewFakeSCM creates and starts a new FakeSCM. It must be closed via Close when not needed anymore.
+func NewFakeSCM() *FakeSCM {
+	s := &FakeSCM{
+		GithubScopes:        []string{"repo"},
+		GitlabScopes:        []string{"api"},
+		githubPullRequests:  map[string][]*github.PullRequest{},
+		gitlabMergeRequests: map[string][]*gitlab.MergeRequest{},
+		gitlabApprovals:     map[string]bool{},
+	}
+	s.registerGithubRoutes()
+	s.registerGitlabRoutes()
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts down the fake server
+func (s *FakeSCM) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the fake server
+func (s *FakeSCM) URL() string {
+	return s.server.URL
+}
+
+// Transport returns a http.RoundTripper that redirects all requests to api.github.com and gitlab.com to the fake
+// server. Requests to other hosts are passed to http.DefaultTransport.
+func (s *FakeSCM) Transport() http.RoundTripper {
+	return &fakeTransport{scm: s}
+}
+
+type fakeTransport struct {
+	scm *FakeSCM
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var prefix string
+	switch req.URL.Hostname() {
+	case "api.github.com":
+		prefix = "/github"
+	case "gitlab.com":
+		prefix = ""
+	default:
+		return http.DefaultTransport.RoundTrip(req)
+	}
+
+	u, err := url.Parse(t.scm.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = u.Scheme
+	req.URL.Host = u.Host
+	req.URL.Path = prefix + req.URL.Path
+	if req.URL.RawPath != "" {
+		req.URL.RawPath = prefix + req.URL.RawPath
+	}
+	req.Host = u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *FakeSCM) handle(method string, pattern string, handler func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	s.routes = append(s.routes, fakeRoute{
+		method:  method,
+		pattern: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handler: handler,
+	})
+}
+
+func (s *FakeSCM) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// split the escaped path so that url encoded project paths (e.g. group%2Fproject) end up in a single segment
+	var segments []string
+	for _, x := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		x2, err := url.PathUnescape(x)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		segments = append(segments, x2)
+	}
+
+	for _, route := range s.routes {
+		if route.method != r.Method || len(route.pattern) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		match := true
+		for i, p := range route.pattern {
+			if strings.HasPrefix(p, ":") {
+				params[p[1:]] = segments[i]
+			} else if p != segments[i] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		route.handler(w, r, params)
+		return
+	}
+	writeError(w, http.StatusNotFound, "404 Not Found")
+}
+
+func (s *FakeSCM) newId() int64 {
+	s.nextId++
+	return s.nextId
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, map[string]any{"message": message})
+}
+
+func readJson(r *http.Request, v any) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+// paginate returns the requested page of the given list
+func paginate[T any](r *http.Request, l []T, defaultPerPage int) []T {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	start := (page - 1) * perPage
+	if start >= len(l) {
+		return []T{}
+	}
+	end := start + perPage
+	if end > len(l) {
+		end = len(l)
+	}
+	return l[start:end]
+}
+
+// copyJson deep copies the given value by marshalling it to JSON and back
+func copyJson[T any](v *T) *T {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var ret T
+	err = json.Unmarshal(b, &ret)
+	if err != nil {
+		panic(err)
+	}
+	return &ret
+}
@@ -18,10 +18,12 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"github.com/kluctl/go-jinja2"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/redact"
 )
 
 const forTemplateRefKey = "spec.templateRef"
@@ -51,6 +54,9 @@ type TextTemplateReconciler struct {
 
 // Reconcile a resource
 func (r *TextTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := r.drainContext(ctx)
+	defer cancel()
+
 	logger := log.FromContext(ctx)
 
 	logger.V(1).Info("Starting reconcile")
@@ -99,7 +105,7 @@ func (r *TextTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: tt.GetGeneration(),
 			Reason:             "Error",
-			Message:            err.Error(),
+			Message:            redact.Error(ctx, err),
 		}
 		apimeta.SetStatusCondition(&tt.Status.Conditions, c)
 	} else {
@@ -112,7 +118,10 @@ func (r *TextTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		apimeta.SetStatusCondition(&tt.Status.Conditions, c)
 	}
-	err = r.Status().Patch(ctx, &tt, patch, SubResourceFieldOwner(r.FieldManager))
+	// use a separate context so that the inventory of applied resources is persisted even when draining timed out
+	flushCtx, flushCancel := r.flushContext(ctx)
+	defer flushCancel()
+	err = r.Status().Patch(flushCtx, &tt, patch, SubResourceFieldOwner(r.FieldManager))
 	return
 }
 
@@ -138,7 +147,7 @@ func (r *TextTemplateReconciler) doReconcile(ctx context.Context, tt *templatesv
 		}
 		if tt.Spec.TemplateRef.ConfigMap != nil {
 			jp := fmt.Sprintf("data[\"%s\"]", tt.Spec.TemplateRef.ConfigMap.Key)
-			elems, err := r.buildObjectInput(ctx, r.Client, tt.GetNamespace(), *ref, &jp, false, true)
+			elems, err := r.buildObjectInput(ctx, objClient, tt.GetNamespace(), *ref, &jp, false, true)
 			if err != nil {
 				return fmt.Errorf("failed to template from %s: %w", ref, err)
 			}
@@ -184,11 +193,59 @@ func (r *TextTemplateReconciler) doReconcile(ctx context.Context, tt *templatesv
 		return err
 	}
 
+	if tt.Spec.Target != nil {
+		err = r.writeTarget(ctx, objClient, tt, rendered)
+		if err != nil {
+			return err
+		}
+		if tt.Spec.Target.Kind == "Secret" {
+			// don't leak the result via the status
+			tt.Status.Result = ""
+			return nil
+		}
+	}
+
 	tt.Status.Result = rendered
 
 	return nil
 }
 
+// writeTarget writes the rendered result into the target ConfigMap or Secret via server-side apply. Each TextTemplate
+// uses its own field manager, so that multiple TextTemplates can write different keys of the same object.
+func (r *TextTemplateReconciler) writeTarget(ctx context.Context, objClient client.Client, tt *templatesv1alpha1.TextTemplate, rendered string) error {
+	target := tt.Spec.Target
+	kind := target.Kind
+	if kind == "" {
+		kind = "ConfigMap"
+	}
+	var value any = rendered
+	if kind == "Secret" {
+		value = base64.StdEncoding.EncodeToString([]byte(rendered))
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]any{
+			"name":      target.Name,
+			"namespace": tt.GetNamespace(),
+		},
+		"data": map[string]any{
+			target.Key: value,
+		},
+	}}
+
+	fieldManager := fmt.Sprintf("%s/%s", r.FieldManager, tt.GetName())
+	if len(fieldManager) > 128 {
+		fieldManager = fieldManager[:128]
+	}
+	err := objClient.Patch(ctx, u, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return fmt.Errorf("failed to write result into %s %s: %w", kind, target.Name, err)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TextTemplateReconciler) SetupWithManager(mgr ctrl.Manager, concurrent int) error {
 	r.Manager = mgr
@@ -223,12 +280,12 @@ func (r *TextTemplateReconciler) SetupWithManager(mgr ctrl.Manager, concurrent i
 
 	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&templatesv1alpha1.TextTemplate{}, builder.WithPredicates(
-			predicate.Or(predicate.GenerationChangedPredicate{}),
+			predicate.Or(predicate.GenerationChangedPredicate{}, ReconcileRequestedPredicate{}),
 		)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: concurrent,
 		}).
-		Build(r)
+		Build(redact.Reconciler(r))
 	if err != nil {
 		return err
 	}
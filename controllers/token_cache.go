@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+)
+
+// tokens are renewed this long before they expire, so that they stay valid while being used
+const tokenExpiryMargin = 5 * time.Minute
+
+// shortLivedToken is a cached token that was requested from a token issuer. The mutex is held while a new token is
+// requested, so that concurrent reconciliations only request one token per key while requests for other keys proceed.
+type shortLivedToken struct {
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// shortLivedTokenCache caches short-lived tokens (e.g. GitHub App installation tokens) until shortly before they
+// expire
+type shortLivedTokenCache struct {
+	mutex  sync.Mutex
+	tokens map[string]*shortLivedToken
+}
+
+// get returns the cached token for key or calls request to get a new one. request returns the token and the time at
+// which it expires.
+func (c *shortLivedTokenCache) get(key string, request func() (string, time.Time, error)) (string, error) {
+	c.mutex.Lock()
+	if c.tokens == nil {
+		c.tokens = map[string]*shortLivedToken{}
+	}
+	t, ok := c.tokens[key]
+	if !ok {
+		c.evictExpired()
+		t = &shortLivedToken{}
+		c.tokens[key] = t
+	}
+	c.mutex.Unlock()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// another reconciliation might have requested the token while we were waiting for the lock
+	if t.token != "" && time.Now().Add(tokenExpiryMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	token, expiresAt, err := request()
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.expiresAt = expiresAt
+	return token, nil
+}
+
+// evictExpired removes expired tokens, e.g. tokens of rotated keys. Tokens that are currently being requested are
+// skipped. Must be called with c.mutex held.
+func (c *shortLivedTokenCache) evictExpired() {
+	now := time.Now()
+	for k, t := range c.tokens {
+		if !t.mutex.TryLock() {
+			continue
+		}
+		if t.token != "" && now.After(t.expiresAt) {
+			delete(c.tokens, k)
+		}
+		t.mutex.Unlock()
+	}
+}
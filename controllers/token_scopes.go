@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/go-github/v47/github"
+	"github.com/xanzy/go-gitlab"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scopes are re-verified after this duration, so that updated tokens are eventually picked up
+const tokenScopesCacheDuration = time.Hour
+
+// TokenScopeError is returned when a token lacks the scopes required for an operation. Retrying will not help in this
+// case, which is why reconcilers set the Stalled condition for these errors.
+type TokenScopeError struct {
+	Operation string
+	Required  []string
+	Scopes    []string
+}
+
+func (e *TokenScopeError) Error() string {
+	return fmt.Sprintf("token lacks %s scope for %s (token has scopes: %s)", strings.Join(e.Required, " or "), e.Operation, strings.Join(e.Scopes, ", "))
+}
+
+type tokenScopes struct {
+	// known is false when the provider does not tell us about the scopes of the token, e.g. for fine-grained tokens
+	known     bool
+	scopes    map[string]bool
+	checkedAt time.Time
+}
+
+var tokenScopesCache = map[string]tokenScopes{}
+var tokenScopesMutex sync.Mutex
+
+func getCachedTokenScopes(key string, load func() (tokenScopes, error)) (tokenScopes, error) {
+	tokenScopesMutex.Lock()
+	defer tokenScopesMutex.Unlock()
+
+	if s, ok := tokenScopesCache[key]; ok && time.Now().Before(s.checkedAt.Add(tokenScopesCacheDuration)) {
+		return s, nil
+	}
+	s, err := load()
+	if err != nil {
+		return tokenScopes{}, err
+	}
+	s.checkedAt = time.Now()
+	tokenScopesCache[key] = s
+	return s, nil
+}
+
+func (s *tokenScopes) check(operation string, anyOf []string) error {
+	if !s.known {
+		return nil
+	}
+	for _, r := range anyOf {
+		if s.scopes[r] {
+			return nil
+		}
+	}
+	var scopes []string
+	for k := range s.scopes {
+		scopes = append(scopes, k)
+	}
+	sort.Strings(scopes)
+	return &TokenScopeError{
+		Operation: operation,
+		Required:  anyOf,
+		Scopes:    scopes,
+	}
+}
+
+// CheckGitlabTokenScopes verifies that the given GitLab token has at least one of the given scopes. The scopes are
+// only queried once per token and then cached. Tokens that do not allow querying their own scopes (e.g. job tokens or
+// older GitLab versions) are not verified.
+func CheckGitlabTokenScopes(ctx context.Context, gl *gitlab.Client, token string, operation string, anyOf ...string) error {
+	if token == "" {
+		return nil
+	}
+	key := fmt.Sprintf("gitlab/%s/%s", gl.BaseURL().String(), Sha256String(token))
+	s, err := getCachedTokenScopes(key, func() (tokenScopes, error) {
+		pat, resp, err := gl.PersonalAccessTokens.GetSinglePersonalAccessToken(gitlab.WithContext(ctx))
+		if err != nil {
+			if resp != nil && resp.StatusCode < http.StatusInternalServerError {
+				return tokenScopes{}, nil
+			}
+			return tokenScopes{}, err
+		}
+		s := tokenScopes{
+			known:  true,
+			scopes: map[string]bool{},
+		}
+		for _, x := range pat.Scopes {
+			s.scopes[x] = true
+		}
+		if s.scopes["api"] {
+			s.scopes["read_api"] = true
+		}
+		return s, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify token scopes: %w", err)
+	}
+	return s.check(operation, anyOf)
+}
+
+// CheckGithubTokenScopes verifies that the given GitHub token has at least one of the given scopes. The scopes are
+// only queried once per token and then cached. Only classic OAuth/personal access tokens report scopes, fine-grained
+// tokens and installation tokens are not verified.
+func CheckGithubTokenScopes(ctx context.Context, gh *github.Client, token string, operation string, anyOf ...string) error {
+	if token == "" {
+		return nil
+	}
+	key := fmt.Sprintf("github/%s/%s", gh.BaseURL.String(), Sha256String(token))
+	s, err := getCachedTokenScopes(key, func() (tokenScopes, error) {
+		// the rate limit endpoint does not count against the rate limit
+		_, resp, err := gh.RateLimits(ctx)
+		if err != nil {
+			return tokenScopes{}, err
+		}
+		h, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+		if !ok {
+			return tokenScopes{}, nil
+		}
+		s := tokenScopes{
+			known:  true,
+			scopes: map[string]bool{},
+		}
+		for _, x := range strings.Split(strings.Join(h, ","), ",") {
+			x = strings.TrimSpace(x)
+			if x != "" {
+				s.scopes[x] = true
+			}
+		}
+		if s.scopes["repo"] {
+			s.scopes["public_repo"] = true
+		}
+		return s, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify token scopes: %w", err)
+	}
+	return s.check(operation, anyOf)
+}
+
+// SetStalledCondition sets the Stalled condition in case err was caused by missing token scopes and removes it
+// otherwise.
+func SetStalledCondition(conditions *[]metav1.Condition, generation int64, err error) {
+	var tse *TokenScopeError
+	if err == nil || !errors.As(err, &tse) {
+		apimeta.RemoveStatusCondition(conditions, "Stalled")
+		return
+	}
+	c := metav1.Condition{
+		Type:               "Stalled",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "InsufficientTokenScope",
+		Message:            tse.Error(),
+	}
+	apimeta.SetStatusCondition(conditions, c)
+}
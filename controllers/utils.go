@@ -4,12 +4,19 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/gobwas/glob"
 	"github.com/kluctl/go-jinja2"
 	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/redact"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
 )
 
 func NewJinja2(opts ...jinja2.Jinja2Opt) (*jinja2.Jinja2, error) {
@@ -21,6 +28,7 @@ func NewJinja2(opts ...jinja2.Jinja2Opt) (*jinja2.Jinja2, error) {
 		jinja2.WithExtension("go_jinja2.ext.kluctl"),
 		jinja2.WithExtension("go_jinja2.ext.time"),
 	)
+	opts2 = append(opts2, jinja2FilterOpts()...)
 	return jinja2.NewJinja2("template-controller", 1, opts2...)
 }
 
@@ -57,14 +65,26 @@ func Sha256Bytes(data []byte) string {
 	return hex.EncodeToString(h[:])
 }
 
-func GetSecretToken(ctx context.Context, client client.Client, namespace string, ref v1alpha1.SecretRef) (string, error) {
+func GetSecretToken(ctx context.Context, client client.Client, namespace string, p *policy.Policy, ref v1alpha1.SecretRef) (string, error) {
 	sn := types.NamespacedName{
 		Namespace: namespace,
 		Name:      ref.SecretName,
 	}
+	if ref.Namespace != "" {
+		sn.Namespace = ref.Namespace
+	}
+	err := p.CheckSecretNamespace(namespace, sn.Namespace)
+	if err != nil {
+		return "", err
+	}
 
 	var secret v1.Secret
-	err := client.Get(ctx, sn, &secret)
+	err = client.Get(ctx, sn, &secret)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkSecretAccessGrant(p, &secret, namespace)
 	if err != nil {
 		return "", err
 	}
@@ -74,9 +94,111 @@ func GetSecretToken(ctx context.Context, client client.Client, namespace string,
 		return "", fmt.Errorf("token is missing in secret")
 	}
 	token := string(tokenBytes)
+	redact.Register(ctx, token)
 	return token, nil
 }
 
+// requestServiceAccountToken requests a short-lived token for the given service account, which allows to authenticate
+// against external systems (e.g. Vault or AWS) with the identity of the service account. The request is performed with
+// objClient, so the service account must be allowed to create tokens for itself.
+func requestServiceAccountToken(ctx context.Context, objClient client.Client, namespace string, serviceAccountName string, audience string) (string, error) {
+	if serviceAccountName == "" {
+		serviceAccountName = "default"
+	}
+	sa := v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      serviceAccountName,
+		},
+	}
+	// this is the minimum allowed by the API server
+	expirationSeconds := int64(600)
+	tr := authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	if audience != "" {
+		tr.Spec.Audiences = []string{audience}
+	}
+	err := objClient.SubResource("token").Create(ctx, &sa, &tr)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token for service account %s/%s: %w", namespace, serviceAccountName, err)
+	}
+	redact.Register(ctx, tr.Status.Token)
+	return tr.Status.Token, nil
+}
+
+// HashFields replaces the given fields of the JSON object j with the SHA256 hash of their JSON encoded values. Fields
+// are dot separated paths into nested objects. Missing fields are ignored.
+func HashFields(j []byte, fields []string) ([]byte, error) {
+	if len(fields) == 0 {
+		return j, nil
+	}
+
+	var o map[string]any
+	err := json.Unmarshal(j, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		path := strings.Split(f, ".")
+		m := o
+		for i, k := range path {
+			v, ok := m[k]
+			if !ok || v == nil {
+				break
+			}
+			if i == len(path)-1 {
+				b, err := json.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				m[k] = "sha256:" + Sha256Bytes(b)
+				break
+			}
+			m, ok = v.(map[string]any)
+			if !ok {
+				break
+			}
+		}
+	}
+
+	return json.Marshal(o)
+}
+
+// checkSecretAccessGrant verifies that objects from the given namespace may access the secret. Secrets from other
+// namespaces must explicitly grant access and the controller policy must allow to read secrets from their namespace,
+// so that a secret can not grant access which is forbidden by the policy.
+func checkSecretAccessGrant(p *policy.Policy, secret *v1.Secret, namespace string) error {
+	if secret.Namespace == namespace {
+		return nil
+	}
+	err := p.CheckSecretNamespace(namespace, secret.Namespace)
+	if err != nil {
+		return err
+	}
+
+	a, ok := secret.GetAnnotations()[v1alpha1.SecretAllowedNamespacesAnnotation]
+	if ok {
+		for _, p := range strings.Split(a, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			g, err := glob.Compile(p)
+			if err != nil {
+				return fmt.Errorf("invalid pattern in %s annotation of secret %s/%s: %w", v1alpha1.SecretAllowedNamespacesAnnotation, secret.Namespace, secret.Name, err)
+			}
+			if g.Match(namespace) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("secret %s/%s does not grant access to namespace %s", secret.Namespace, secret.Name, namespace)
+}
+
 type SubResourceFieldOwner string
 
 func (f SubResourceFieldOwner) ApplyToSubResourceUpdate(opts *client.SubResourceUpdateOptions) {
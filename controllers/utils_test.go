@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHashFields(t *testing.T) {
+	g := NewWithT(t)
+
+	j := []byte(`{"title":"t","author":{"email":"a@example.com","name":"a"},"labels":["x"],"body":null}`)
+	h, err := HashFields(j, []string{"author.email", "labels", "body", "missing", "title.nested"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var o map[string]any
+	g.Expect(json.Unmarshal(h, &o)).To(Succeed())
+	g.Expect(o["title"]).To(Equal("t"))
+	g.Expect(o["body"]).To(BeNil())
+	g.Expect(o["author"]).To(HaveKeyWithValue("name", "a"))
+	// the hash is calculated from the JSON encoded value
+	g.Expect(o["author"]).To(HaveKeyWithValue("email", "sha256:"+Sha256String(`"a@example.com"`)))
+	g.Expect(o["labels"]).To(Equal("sha256:" + Sha256String(`["x"]`)))
+
+	// no fields leaves the input untouched
+	h, err = HashFields(j, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(h).To(Equal(j))
+
+	_, err = HashFields([]byte("invalid"), []string{"x"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCheckSecretAccessGrant(t *testing.T) {
+	secret := func(annotation string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "token"}}
+		if annotation != "" {
+			s.Annotations = map[string]string{templatesv1alpha1.SecretAllowedNamespacesAnnotation: annotation}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		annotation string
+		policy     *policy.Policy
+		namespace  string
+		err        string
+	}{
+		{name: "same namespace", namespace: "shared"},
+		{name: "no grant", namespace: "team-a", err: "does not grant access to namespace team-a"},
+		{name: "granted", annotation: "team-b, team-a", namespace: "team-a"},
+		{name: "granted via glob", annotation: "preview-*", namespace: "preview-1"},
+		{name: "not granted", annotation: "preview-*", namespace: "team-a", err: "does not grant access"},
+		{name: "invalid pattern", annotation: "[", namespace: "team-a", err: "invalid pattern"},
+		{
+			name:       "granted but denied by policy",
+			annotation: "*",
+			policy:     &policy.Policy{SecretNamespaces: []string{"other"}},
+			namespace:  "team-a",
+			err:        "reading secrets from namespace shared is not allowed by policy",
+		},
+		{
+			name:       "granted and allowed by policy",
+			annotation: "*",
+			policy:     &policy.Policy{SecretNamespaces: []string{"shared"}},
+			namespace:  "team-a",
+		},
+		{
+			name:      "allowed by policy but not granted",
+			policy:    &policy.Policy{SecretNamespaces: []string{"shared"}},
+			namespace: "team-a",
+			err:       "does not grant access",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := checkSecretAccessGrant(tc.policy, secret(tc.annotation), tc.namespace)
+			if tc.err != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.err)))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+		})
+	}
+}
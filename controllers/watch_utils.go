@@ -4,6 +4,8 @@ import (
 	"fmt"
 	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 func BuildRefIndexValue(ref templatesv1alpha1.ObjectRef, ns string) string {
@@ -17,3 +19,18 @@ func BuildObjectIndexValue(obj client.Object) string {
 	gvk := obj.GetObjectKind().GroupVersionKind()
 	return fmt.Sprintf("%s/%s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
 }
+
+// ReconcileRequestedPredicate passes update events that change the templates.kluctl.io/reconcile-requested-at
+// annotation, so that reconciliation can be requested for objects that are otherwise filtered by generation.
+type ReconcileRequestedPredicate struct {
+	predicate.Funcs
+}
+
+func (ReconcileRequestedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	a1 := e.ObjectOld.GetAnnotations()[templatesv1alpha1.ReconcileRequestedAtAnnotation]
+	a2 := e.ObjectNew.GetAnnotations()[templatesv1alpha1.ReconcileRequestedAtAnnotation]
+	return a2 != "" && a1 != a2
+}
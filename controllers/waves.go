@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pendingApplyRequeueInterval is the interval at which templates are reconciled while objects wait for earlier waves or
+// dependencies to become ready
+const pendingApplyRequeueInterval = 5 * time.Second
+
+// objectWave holds the rendered objects of a single wave
+type objectWave struct {
+	wave    int
+	objects []*unstructured.Unstructured
+}
+
+// groupWaves groups the rendered objects by their wave annotation, sorted by ascending wave
+func groupWaves(objects []*unstructured.Unstructured) ([]*objectWave, error) {
+	byWave := map[int]*objectWave{}
+	for _, x := range objects {
+		wave := 0
+		if s, ok := x.GetAnnotations()[templatesv1alpha1.WaveAnnotation]; ok {
+			var err error
+			wave, err = strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				ref := templatesv1alpha1.ObjectRefFromObject(x)
+				return nil, fmt.Errorf("invalid %s annotation on %s: %w", templatesv1alpha1.WaveAnnotation, ref.String(), err)
+			}
+		}
+		w, ok := byWave[wave]
+		if !ok {
+			w = &objectWave{wave: wave}
+			byWave[wave] = w
+		}
+		w.objects = append(w.objects, x)
+	}
+
+	ret := make([]*objectWave, 0, len(byWave))
+	for _, w := range byWave {
+		ret = append(ret, w)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].wave < ret[j].wave
+	})
+	return ret, nil
+}
+
+// isAppliedReady returns true if the applied object is ready to be used by objects of later waves and by dependent
+// objects. Readiness is determined via kstatus (see health.Compute), so that objects without a Ready condition are
+// only ready if kstatus considers them current.
+func isAppliedReady(ari *templatesv1alpha1.AppliedResourceInfo) bool {
+	return ari.Success && ari.Health == templatesv1alpha1.HealthHealthy
+}
+
+// pendingApplyRequeueAfter returns the interval at which pending objects are re-checked, if it is earlier than
+// requeueAfter. Applied objects are not watched, so readiness can only be noticed by reconciling again.
+func pendingApplyRequeueAfter(requeueAfter time.Duration, status *templatesv1alpha1.ObjectTemplateStatus) time.Duration {
+	if status.PendingApply != nil && (requeueAfter == 0 || pendingApplyRequeueInterval < requeueAfter) {
+		return pendingApplyRequeueInterval
+	}
+	return requeueAfter
+}
+
+// resolveScope sets the namespace of namespaced objects without a namespace to the namespace of rt and returns true if
+// x is cluster-scoped
+func (r *ObjectTemplateReconciler) resolveScope(rt *templatesv1alpha1.ObjectTemplate, x *unstructured.Unstructured) (bool, error) {
+	rm, err := r.Client.RESTMapper().RESTMapping(x.GroupVersionKind().GroupKind(), x.GroupVersionKind().Version)
+	if err != nil {
+		return false, err
+	}
+	if rm.Scope.Name() == apimeta.RESTScopeNameNamespace && x.GetNamespace() == "" {
+		x.SetNamespace(rt.Namespace)
+	}
+	return rm.Scope.Name() == apimeta.RESTScopeNameRoot, nil
+}
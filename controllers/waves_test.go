@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func wave(s string) map[string]string {
+	return map[string]string{templatesv1alpha1.WaveAnnotation: s}
+}
+
+func TestGroupWaves(t *testing.T) {
+	g := NewWithT(t)
+
+	crd := newTestObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "crd", wave("-1"))
+	ns := newTestObject("v1", "Namespace", "", "ns", wave(" -1 "))
+	cm := newTestObject("v1", "ConfigMap", "default", "cm", nil)
+	deploy := newTestObject("apps/v1", "Deployment", "default", "app", wave("0"))
+	job := newTestObject("batch/v1", "Job", "default", "migrate", wave("10"))
+
+	waves, err := groupWaves([]*unstructured.Unstructured{job, crd, cm, deploy, ns})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(waves).To(HaveLen(3))
+
+	// waves are sorted ascending, objects keep their rendered order and objects without annotation are in wave 0
+	g.Expect(waves[0].wave).To(Equal(-1))
+	g.Expect(waves[0].objects).To(HaveExactElements(crd, ns))
+	g.Expect(waves[1].wave).To(Equal(0))
+	g.Expect(waves[1].objects).To(HaveExactElements(cm, deploy))
+	g.Expect(waves[2].wave).To(Equal(10))
+	g.Expect(waves[2].objects).To(HaveExactElements(job))
+
+	waves, err = groupWaves(nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(waves).To(BeEmpty())
+
+	_, err = groupWaves([]*unstructured.Unstructured{newTestObject("v1", "ConfigMap", "default", "cm", wave("first"))})
+	g.Expect(err).To(MatchError(ContainSubstring("invalid templates.kluctl.io/wave annotation on")))
+}
+
+func TestIsAppliedReady(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(isAppliedReady(&templatesv1alpha1.AppliedResourceInfo{Success: true, Health: templatesv1alpha1.HealthHealthy})).To(BeTrue())
+	g.Expect(isAppliedReady(&templatesv1alpha1.AppliedResourceInfo{Success: true, Health: templatesv1alpha1.HealthProgressing})).To(BeFalse())
+	g.Expect(isAppliedReady(&templatesv1alpha1.AppliedResourceInfo{Success: true})).To(BeFalse())
+	g.Expect(isAppliedReady(&templatesv1alpha1.AppliedResourceInfo{Health: templatesv1alpha1.HealthHealthy})).To(BeFalse())
+}
+
+func TestPendingApplyRequeueAfter(t *testing.T) {
+	g := NewWithT(t)
+
+	pending := &templatesv1alpha1.ObjectTemplateStatus{PendingApply: &templatesv1alpha1.PendingApplyStatus{NotReady: []string{"x"}}}
+	g.Expect(pendingApplyRequeueAfter(time.Minute, &templatesv1alpha1.ObjectTemplateStatus{})).To(Equal(time.Minute))
+	g.Expect(pendingApplyRequeueAfter(0, &templatesv1alpha1.ObjectTemplateStatus{})).To(Equal(time.Duration(0)))
+	g.Expect(pendingApplyRequeueAfter(time.Minute, pending)).To(Equal(pendingApplyRequeueInterval))
+	g.Expect(pendingApplyRequeueAfter(0, pending)).To(Equal(pendingApplyRequeueInterval))
+	g.Expect(pendingApplyRequeueAfter(time.Second, pending)).To(Equal(time.Second))
+}
+
+func TestResolveScope(t *testing.T) {
+	g := NewWithT(t)
+
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, apimeta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, apimeta.RESTScopeRoot)
+	r := &ObjectTemplateReconciler{}
+	r.Client = fake.NewClientBuilder().WithRESTMapper(mapper).Build()
+	rt := &templatesv1alpha1.ObjectTemplate{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "example"}}
+
+	cm := newTestObject("v1", "ConfigMap", "", "cm", nil)
+	cs, err := r.resolveScope(rt, cm)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cs).To(BeFalse())
+	g.Expect(cm.GetNamespace()).To(Equal("team-a"))
+
+	cm = newTestObject("v1", "ConfigMap", "other", "cm", nil)
+	_, err = r.resolveScope(rt, cm)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cm.GetNamespace()).To(Equal("other"))
+
+	cs, err = r.resolveScope(rt, newTestObject("v1", "Namespace", "", "ns", nil))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cs).To(BeTrue())
+
+	// kinds of CRDs applied in earlier waves are unknown until the CRD is applied
+	_, err = r.resolveScope(rt, newTestObject("example.com/v1", "Widget", "", "w", nil))
+	g.Expect(apimeta.IsNoMatchError(err)).To(BeTrue())
+}
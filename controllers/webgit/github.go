@@ -10,9 +10,8 @@ import (
 
 	"github.com/google/go-github/v47/github"
 	"github.com/kluctl/template-controller/api/v1alpha1"
-	"golang.org/x/oauth2"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -21,6 +20,7 @@ type GithubMergeRequest struct {
 	ctx context.Context
 
 	client *github.Client
+	token  string
 	owner  string
 	repo   string
 	prId   int
@@ -57,6 +57,10 @@ func (g *GithubMergeRequest) getCurrentUser() (*github.User, error) {
 	return g.currentUserCache, nil
 }
 
+func (g *GithubMergeRequest) CheckTokenScopes(ctx context.Context, operation string) error {
+	return controllers.CheckGithubTokenScopes(ctx, g.client, g.token, operation, "repo", "public_repo")
+}
+
 func (g *GithubMergeRequest) convertComment(n *github.IssueComment) Note {
 	return &GithubNote{
 		g:       g,
@@ -264,38 +268,37 @@ func (n *GithubNote) UpdateBody(body string) error {
 	return nil
 }
 
-func BuildWebgitMergeRequestGithub(ctx context.Context, client client.Client, namespace string, info v1alpha1.GithubPullRequestRef) (*GithubMergeRequest, error) {
+func (n *GithubNote) Delete() error {
+	_, err := n.g.client.Issues.DeleteComment(n.g.ctx, n.g.owner, n.g.repo, *n.comment.ID)
+	return err
+}
+
+func BuildWebgitMergeRequestGithub(ctx context.Context, client client.Client, namespace string, p *policy.Policy, info v1alpha1.GithubPullRequestRef) (*GithubMergeRequest, error) {
 	if info.Owner == "" {
 		return nil, fmt.Errorf("missing github owner")
 	}
 	if info.Repo == "" {
 		return nil, fmt.Errorf("missing github owner")
 	}
-	if info.TokenRef == nil {
+	if info.TokenRef == nil && info.App == nil {
 		return nil, fmt.Errorf("missing github tokenRef")
 	}
 
-	sn := types.NamespacedName{
-		Namespace: namespace,
-		Name:      info.TokenRef.SecretName,
+	token, err := controllers.GetGithubToken(ctx, client, namespace, p, info.GithubProject)
+	if err != nil {
+		return nil, err
 	}
-
-	var secret v1.Secret
-	err := client.Get(ctx, sn, &secret)
+	tc := controllers.NewGithubHttpClient(ctx, p, token)
+	gh, err := controllers.NewGithubClient(tc, info.API)
 	if err != nil {
 		return nil, err
 	}
 
-	tokenBytes, ok := secret.Data[info.TokenRef.Key]
-	if !ok {
-		return nil, fmt.Errorf("github token is missing in secret")
+	scopesToken := token
+	if info.App != nil {
+		// installation tokens have no scopes, permissions are granted to the app installation instead
+		scopesToken = ""
 	}
-	token := string(tokenBytes)
-
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
 
 	var prId int
 	switch info.PullRequestId.Type {
@@ -314,7 +317,8 @@ func BuildWebgitMergeRequestGithub(ctx context.Context, client client.Client, na
 
 	return &GithubMergeRequest{
 		ctx:    ctx,
-		client: github.NewClient(tc),
+		client: gh,
+		token:  scopesToken,
 		owner:  info.Owner,
 		repo:   info.Repo,
 		prId:   prId,
@@ -9,15 +9,16 @@ import (
 	"time"
 
 	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/policy"
 	"github.com/xanzy/go-gitlab"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type GitlabMergeRequest struct {
 	client *gitlab.Client
+	token  string
 
 	projectId interface{}
 	mrId      int
@@ -68,6 +69,10 @@ func (g *GitlabMergeRequest) HasApproved() (bool, error) {
 	return false, nil
 }
 
+func (g *GitlabMergeRequest) CheckTokenScopes(ctx context.Context, operation string) error {
+	return controllers.CheckGitlabTokenScopes(ctx, g.client, g.token, operation, "api")
+}
+
 func (g *GitlabMergeRequest) Approve() error {
 	opt := &gitlab.ApproveMergeRequestOptions{}
 	_, _, err := g.client.MergeRequestApprovals.ApproveMergeRequest(g.projectId, g.mrId, opt)
@@ -205,36 +210,20 @@ func (n *GitlabNote) UpdateBody(body string) error {
 	return nil
 }
 
-func BuildWebgitMergeRequestGitlab(ctx context.Context, client client.Client, namespace string, info v1alpha1.GitlabMergeRequestRef) (*GitlabMergeRequest, error) {
+func (n *GitlabNote) Delete() error {
+	_, err := n.g.client.Notes.DeleteMergeRequestNote(n.g.projectId, n.g.mrId, n.note.ID)
+	return err
+}
+
+func BuildWebgitMergeRequestGitlab(ctx context.Context, client client.Client, namespace string, p *policy.Policy, info v1alpha1.GitlabMergeRequestRef) (*GitlabMergeRequest, error) {
 	if info.Project == nil {
 		return nil, fmt.Errorf("missing gitlab project")
 	}
-	if info.TokenRef == nil {
-		return nil, fmt.Errorf("missing tokenRef")
-	}
-
-	sn := types.NamespacedName{
-		Namespace: namespace,
-		Name:      info.TokenRef.SecretName,
-	}
-
-	var secret v1.Secret
-	err := client.Get(ctx, sn, &secret)
-	if err != nil {
-		return nil, err
+	if info.TokenRef == nil && info.JobTokenRef == nil && info.OIDC == nil {
+		return nil, fmt.Errorf("missing tokenRef, jobTokenRef or oidc")
 	}
 
-	tokenBytes, ok := secret.Data[info.TokenRef.Key]
-	if !ok {
-		return nil, fmt.Errorf("gitlab token is missing in secret")
-	}
-	token := string(tokenBytes)
-
-	var opts []gitlab.ClientOptionFunc
-	if info.API != nil {
-		opts = append(opts, gitlab.WithBaseURL(*info.API))
-	}
-	glClient, err := gitlab.NewClient(token, opts...)
+	glClient, token, err := controllers.BuildGitlabClient(ctx, client, namespace, p, info.API, info.GitlabAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +245,7 @@ func BuildWebgitMergeRequestGitlab(ctx context.Context, client client.Client, na
 
 	glmr := &GitlabMergeRequest{
 		client: glClient,
+		token:  token,
 		mrId:   mrId,
 	}
 
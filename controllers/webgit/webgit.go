@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers/policy"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 )
@@ -13,6 +14,7 @@ type Note interface {
 	GetBody() string
 
 	UpdateBody(body string) error
+	Delete() error
 	GetCreatedAt() time.Time
 }
 
@@ -25,13 +27,17 @@ type MergeRequestInterface interface {
 	GetMergeRequestNote(noteId string) (Note, error)
 	ListMergeRequestNotes() ([]Note, error)
 	ListMergeRequestNotesAfter(t time.Time) ([]Note, error)
+
+	// CheckTokenScopes verifies that the token has the scopes required for the given operation ("approvals" or
+	// "comments"). A *controllers.TokenScopeError is returned if not.
+	CheckTokenScopes(ctx context.Context, operation string) error
 }
 
-func BuildWebgitMergeRequest(ctx context.Context, client client.Client, namespace string, holder v1alpha1.PullRequestRefHolder) (MergeRequestInterface, error) {
+func BuildWebgitMergeRequest(ctx context.Context, client client.Client, namespace string, p *policy.Policy, holder v1alpha1.PullRequestRefHolder) (MergeRequestInterface, error) {
 	if holder.Gitlab != nil {
-		return BuildWebgitMergeRequestGitlab(ctx, client, namespace, *holder.Gitlab)
+		return BuildWebgitMergeRequestGitlab(ctx, client, namespace, p, *holder.Gitlab)
 	} else if holder.Github != nil {
-		return BuildWebgitMergeRequestGithub(ctx, client, namespace, *holder.Github)
+		return BuildWebgitMergeRequestGithub(ctx, client, namespace, p, *holder.Github)
 	} else {
 		return nil, fmt.Errorf("no git merge request spec provided")
 	}
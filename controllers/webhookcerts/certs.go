@@ -0,0 +1,381 @@
+package webhookcerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"time"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+
+	// certificates are rotated when they expire in less than this
+	rotateBefore = 30 * 24 * time.Hour
+
+	// the CA is rotated well before the serving certificate would have to be, as serving certificates never outlive
+	// the CA that signed them
+	caRotateBefore = 3 * rotateBefore
+
+	checkInterval = time.Hour
+
+	// maxConflictRetries limits how often the Secret is re-read when other replicas update it at the same time
+	maxConflictRetries = 5
+
+	caCertKey   = "ca.crt"
+	caKeyKey    = "ca.key"
+	caBundleKey = "ca-bundle.crt"
+)
+
+//+kubebuilder:rbac:groups="",namespace=kluctl-system,resources=secrets,verbs=get;create;update;patch
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;update;patch
+
+// CertManager generates and rotates self-signed certificates for the webhook server. Certificates are persisted in a
+// Secret so that all replicas share the same certificates, written to the webhook server's certificate directory and
+// injected as CA bundle into the configured webhook configurations.
+type CertManager struct {
+	// Client must be a non-caching client, as certificates are required before the manager and its caches are started
+	Client client.Client
+
+	Namespace   string
+	SecretName  string
+	ServiceName string
+	CertDir     string
+
+	// ValidatingWebhookConfigurations specifies the names of the ValidatingWebhookConfigurations to inject the CA bundle
+	// into
+	ValidatingWebhookConfigurations []string
+}
+
+// Ensure makes sure that valid certificates exist in the Secret, the certificate directory and the webhook
+// configurations.
+func (m *CertManager) Ensure(ctx context.Context) error {
+	var secret *corev1.Secret
+	for i := 0; ; i++ {
+		var err error
+		secret, err = m.ensureSecret(ctx)
+		if err == nil {
+			break
+		}
+		// another replica created or rotated the certificates in the meantime, so use these instead
+		if i >= maxConflictRetries || (!errors.IsAlreadyExists(err) && !errors.IsConflict(err)) {
+			return err
+		}
+	}
+
+	err := m.writeFiles(secret)
+	if err != nil {
+		return err
+	}
+
+	return m.injectCABundle(ctx, secret.Data[caBundleKey])
+}
+
+// ensureSecret rotates the CA and the serving certificate stored in the Secret if required. The CA bundle contains the
+// previous CAs until they expire, so that replicas which did not pick up the new serving certificate yet are still
+// trusted. Returns an AlreadyExists or Conflict error if another replica modified the Secret at the same time.
+func (m *CertManager) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	logger := log.FromContext(ctx)
+	now := time.Now()
+
+	var secret corev1.Secret
+	err := m.Client.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.SecretName}, &secret)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: m.Namespace,
+				Name:      m.SecretName,
+			},
+		}
+	}
+
+	data := map[string][]byte{}
+	for k, v := range secret.Data {
+		data[k] = v
+	}
+
+	ca, caKey := parseCA(data)
+	if ca == nil || now.Add(caRotateBefore).After(ca.NotAfter) {
+		logger.Info("Generating webhook CA", "secret", m.SecretName)
+
+		var caPem, caKeyPem []byte
+		caPem, caKeyPem, err = m.generateCA(now)
+		if err != nil {
+			return nil, err
+		}
+		data[caCertKey] = caPem
+		data[caKeyKey] = caKeyPem
+		ca, caKey = parseCA(data)
+	}
+
+	// previous CAs are taken from the old bundle and, for Secrets written before bundles existed, from the old CA
+	previous := append(parseCertificates(secret.Data[caBundleKey]), parseCertificates(secret.Data[caCertKey])...)
+	data[caBundleKey] = buildCABundle(ca, previous, now)
+
+	if m.needsRotation(data, ca, now) {
+		logger.Info("Generating webhook certificates", "secret", m.SecretName)
+
+		certPem, keyPem, err := m.generateCert(now, ca, caKey)
+		if err != nil {
+			return nil, err
+		}
+		data[corev1.TLSCertKey] = certPem
+		data[corev1.TLSPrivateKeyKey] = keyPem
+	}
+
+	if secret.ResourceVersion != "" && reflect.DeepEqual(data, secret.Data) {
+		return &secret, nil
+	}
+
+	secret.Type = corev1.SecretTypeTLS
+	secret.Data = data
+	if secret.ResourceVersion == "" {
+		err = m.Client.Create(ctx, &secret)
+	} else {
+		err = m.Client.Update(ctx, &secret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// Start implements manager.Runnable and periodically rotates the certificates.
+func (m *CertManager) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	t := time.NewTicker(checkInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			err := m.Ensure(ctx)
+			if err != nil {
+				logger.Error(err, "Failed to rotate webhook certificates")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. All replicas serve webhooks, so all of them need
+// up-to-date certificates.
+func (m *CertManager) NeedLeaderElection() bool {
+	return false
+}
+
+func (m *CertManager) dnsNames() []string {
+	return []string{
+		m.ServiceName,
+		fmt.Sprintf("%s.%s", m.ServiceName, m.Namespace),
+		fmt.Sprintf("%s.%s.svc", m.ServiceName, m.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", m.ServiceName, m.Namespace),
+	}
+}
+
+// needsRotation returns true if the serving certificate is missing, about to expire, not signed by ca or not valid for
+// the service.
+func (m *CertManager) needsRotation(data map[string][]byte, ca *x509.Certificate, now time.Time) bool {
+	if len(data[corev1.TLSPrivateKeyKey]) == 0 {
+		return true
+	}
+	certs := parseCertificates(data[corev1.TLSCertKey])
+	if len(certs) == 0 {
+		return true
+	}
+	cert := certs[0]
+	if now.Add(rotateBefore).After(cert.NotAfter) {
+		return true
+	}
+	if cert.CheckSignatureFrom(ca) != nil {
+		return true
+	}
+	for _, n := range m.dnsNames() {
+		if cert.VerifyHostname(n) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CertManager) generateCA(now time.Time) ([]byte, []byte, error) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca", m.ServiceName)},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer})
+	caKeyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(caKey)})
+	return caPem, caKeyPem, nil
+}
+
+// generateCert generates a serving certificate signed by ca. The certificate never outlives the CA.
+func (m *CertManager) generateCert(now time.Time, ca *x509.Certificate, caKey *rsa.PrivateKey) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	notAfter := now.Add(certValidity)
+	if notAfter.After(ca.NotAfter) {
+		notAfter = ca.NotAfter
+	}
+	dnsNames := m.dnsNames()
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{CommonName: dnsNames[2]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, certTemplate, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPem, keyPem, nil
+}
+
+// parseCA returns the CA and its key, or nil if any of them is missing or invalid
+func parseCA(data map[string][]byte) (*x509.Certificate, *rsa.PrivateKey) {
+	certs := parseCertificates(data[caCertKey])
+	if len(certs) == 0 || !certs[0].IsCA {
+		return nil, nil
+	}
+	block, _ := pem.Decode(data[caKeyKey])
+	if block == nil {
+		return nil, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil || !key.PublicKey.Equal(certs[0].PublicKey) {
+		return nil, nil
+	}
+	return certs[0], key
+}
+
+// parseCertificates returns all valid certificates found in the PEM encoded data
+func parseCertificates(data []byte) []*x509.Certificate {
+	var ret []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return ret
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			ret = append(ret, cert)
+		}
+	}
+}
+
+// buildCABundle returns the PEM encoded bundle of ca, followed by all previous CAs that did not expire yet
+func buildCABundle(ca *x509.Certificate, previous []*x509.Certificate, now time.Time) []byte {
+	ret := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})
+	added := []*x509.Certificate{ca}
+	for _, c := range previous {
+		if !c.IsCA || now.After(c.NotAfter) {
+			continue
+		}
+		duplicate := false
+		for _, a := range added {
+			if a.Equal(c) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		added = append(added, c)
+		ret = append(ret, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+	return ret
+}
+
+func (m *CertManager) writeFiles(secret *corev1.Secret) error {
+	err := os.MkdirAll(m.CertDir, 0o700)
+	if err != nil {
+		return err
+	}
+	for _, k := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		p := filepath.Join(m.CertDir, k)
+		existing, err := os.ReadFile(p)
+		if err == nil && bytes.Equal(existing, secret.Data[k]) {
+			continue
+		}
+		// the webhook server watches the files and reloads them on changes
+		err = os.WriteFile(p, secret.Data[k], 0o600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *CertManager) injectCABundle(ctx context.Context, caPem []byte) error {
+	for _, name := range m.ValidatingWebhookConfigurations {
+		var wc admissionregistrationv1.ValidatingWebhookConfiguration
+		err := m.Client.Get(ctx, client.ObjectKey{Name: name}, &wc)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		patch := client.MergeFrom(wc.DeepCopy())
+		changed := false
+		for i := range wc.Webhooks {
+			if !bytes.Equal(wc.Webhooks[i].ClientConfig.CABundle, caPem) {
+				wc.Webhooks[i].ClientConfig.CABundle = caPem
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		err = m.Client.Patch(ctx, &wc, patch)
+		if err != nil {
+			return fmt.Errorf("failed to inject CA bundle into %s: %w", name, err)
+		}
+	}
+	return nil
+}
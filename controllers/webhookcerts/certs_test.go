@@ -0,0 +1,243 @@
+package webhookcerts
+
+import (
+	"context"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newTestCertManager(t *testing.T, c client.Client) *CertManager {
+	return &CertManager{
+		Client:                          c,
+		Namespace:                       "kluctl-system",
+		SecretName:                      "webhook-certs",
+		ServiceName:                     "webhook",
+		CertDir:                         t.TempDir(),
+		ValidatingWebhookConfigurations: []string{"webhook"},
+	}
+}
+
+func newTestWebhookConfiguration() *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook"},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "a"}, {Name: "b"}},
+	}
+}
+
+func getSecret(t *testing.T, m *CertManager) *corev1.Secret {
+	var secret corev1.Secret
+	err := m.Client.Get(context.Background(), client.ObjectKey{Namespace: m.Namespace, Name: m.SecretName}, &secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &secret
+}
+
+// expectTrusted verifies that certPem is a valid serving certificate for the webhook service when caBundle is trusted
+func expectTrusted(g *WithT, m *CertManager, caBundle []byte, certPem []byte) {
+	pool := x509.NewCertPool()
+	g.Expect(pool.AppendCertsFromPEM(caBundle)).To(BeTrue())
+	certs := parseCertificates(certPem)
+	g.Expect(certs).To(HaveLen(1))
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName: m.dnsNames()[2],
+		Roots:   pool,
+	})
+	g.ExpectWithOffset(1, err).ToNot(HaveOccurred())
+}
+
+// expireCA replaces the CA of the Secret with one that expires after the given duration, and re-signs the serving
+// certificate with it
+func expireCA(t *testing.T, m *CertManager, expiresIn time.Duration) *corev1.Secret {
+	secret := getSecret(t, m)
+	caPem, caKeyPem, err := m.generateCA(time.Now().Add(expiresIn - caValidity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret.Data[caCertKey] = caPem
+	secret.Data[caKeyKey] = caKeyPem
+	secret.Data[caBundleKey] = caPem
+	ca, caKey := parseCA(secret.Data)
+	secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], err = m.generateCert(time.Now(), ca, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Client.Update(context.Background(), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func TestEnsure(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newTestCertManager(t, fake.NewClientBuilder().WithObjects(newTestWebhookConfiguration()).Build())
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	secret := getSecret(t, m)
+	g.Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+	g.Expect(secret.Data[caBundleKey]).To(Equal(secret.Data[caCertKey]))
+	expectTrusted(g, m, secret.Data[caBundleKey], secret.Data[corev1.TLSCertKey])
+
+	for _, k := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		b, err := os.ReadFile(filepath.Join(m.CertDir, k))
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(b).To(Equal(secret.Data[k]))
+	}
+
+	var wc admissionregistrationv1.ValidatingWebhookConfiguration
+	g.Expect(m.Client.Get(context.Background(), client.ObjectKey{Name: "webhook"}, &wc)).To(Succeed())
+	for _, w := range wc.Webhooks {
+		g.Expect(w.ClientConfig.CABundle).To(Equal(secret.Data[caBundleKey]))
+	}
+
+	// valid certificates are not touched
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+	g.Expect(getSecret(t, m).ResourceVersion).To(Equal(secret.ResourceVersion))
+}
+
+func TestEnsureRotatesServingCertificate(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newTestCertManager(t, fake.NewClientBuilder().Build())
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	// the CA is still valid for long enough, so only the serving certificate is rotated
+	old := expireCA(t, m, caRotateBefore+time.Hour)
+	oldCert := parseCertificates(old.Data[corev1.TLSCertKey])[0]
+	g.Expect(oldCert.NotAfter).To(BeTemporally("<=", parseCertificates(old.Data[caCertKey])[0].NotAfter))
+
+	old.Data[corev1.TLSCertKey] = nil
+	g.Expect(m.Client.Update(context.Background(), old)).To(Succeed())
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	secret := getSecret(t, m)
+	g.Expect(secret.Data[caCertKey]).To(Equal(old.Data[caCertKey]))
+	g.Expect(secret.Data[caBundleKey]).To(Equal(old.Data[caBundleKey]))
+	expectTrusted(g, m, secret.Data[caBundleKey], secret.Data[corev1.TLSCertKey])
+}
+
+func TestEnsureRotatesCA(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newTestCertManager(t, fake.NewClientBuilder().WithObjects(newTestWebhookConfiguration()).Build())
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	old := expireCA(t, m, caRotateBefore-time.Hour)
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	secret := getSecret(t, m)
+	g.Expect(secret.Data[caCertKey]).ToNot(Equal(old.Data[caCertKey]))
+	g.Expect(parseCertificates(secret.Data[caBundleKey])).To(HaveLen(2))
+
+	// replicas still serving the old certificate must be trusted until they pick up the new one
+	expectTrusted(g, m, secret.Data[caBundleKey], secret.Data[corev1.TLSCertKey])
+	expectTrusted(g, m, secret.Data[caBundleKey], old.Data[corev1.TLSCertKey])
+
+	var wc admissionregistrationv1.ValidatingWebhookConfiguration
+	g.Expect(m.Client.Get(context.Background(), client.ObjectKey{Name: "webhook"}, &wc)).To(Succeed())
+	g.Expect(wc.Webhooks[0].ClientConfig.CABundle).To(Equal(secret.Data[caBundleKey]))
+}
+
+func TestEnsureDropsExpiredCAs(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newTestCertManager(t, fake.NewClientBuilder().Build())
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	expired, _, err := m.generateCA(time.Now().Add(-caValidity - time.Hour))
+	g.Expect(err).ToNot(HaveOccurred())
+	secret := getSecret(t, m)
+	secret.Data[caBundleKey] = append(secret.Data[caBundleKey], expired...)
+	g.Expect(m.Client.Update(context.Background(), secret)).To(Succeed())
+
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+	g.Expect(getSecret(t, m).Data[caBundleKey]).To(Equal(secret.Data[caCertKey]))
+}
+
+func TestEnsureMigratesSecretsWithoutCAKey(t *testing.T) {
+	g := NewWithT(t)
+
+	m := newTestCertManager(t, fake.NewClientBuilder().Build())
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	// Secrets written by older versions only contain the CA certificate
+	old := getSecret(t, m)
+	delete(old.Data, caKeyKey)
+	delete(old.Data, caBundleKey)
+	g.Expect(m.Client.Update(context.Background(), old)).To(Succeed())
+
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+	secret := getSecret(t, m)
+	g.Expect(secret.Data[caCertKey]).ToNot(Equal(old.Data[caCertKey]))
+	expectTrusted(g, m, secret.Data[caBundleKey], old.Data[corev1.TLSCertKey])
+	expectTrusted(g, m, secret.Data[caBundleKey], secret.Data[corev1.TLSCertKey])
+}
+
+func TestEnsureConcurrentReplicas(t *testing.T) {
+	g := NewWithT(t)
+
+	var other *CertManager
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if other != nil {
+				// another replica wins the race and creates the Secret first
+				o := other
+				other = nil
+				g.Expect(o.Ensure(ctx)).To(Succeed())
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+	m := newTestCertManager(t, c)
+	other = newTestCertManager(t, c)
+
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	// the certificates of the other replica must be used
+	secret := getSecret(t, m)
+	b, err := os.ReadFile(filepath.Join(m.CertDir, corev1.TLSCertKey))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(b).To(Equal(secret.Data[corev1.TLSCertKey]))
+}
+
+func TestEnsureConflict(t *testing.T) {
+	g := NewWithT(t)
+
+	conflicts := -1
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if conflicts >= 0 && conflicts < 2 {
+				conflicts++
+				return apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, obj.GetName(), nil)
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	}).Build()
+	m := newTestCertManager(t, c)
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+
+	secret := getSecret(t, m)
+	delete(secret.Data, corev1.TLSCertKey)
+	g.Expect(c.Update(context.Background(), secret)).To(Succeed())
+
+	// updates are retried with the re-read Secret
+	conflicts = 0
+	g.Expect(m.Ensure(context.Background())).To(Succeed())
+	g.Expect(conflicts).To(Equal(2))
+	g.Expect(getSecret(t, m).Data[corev1.TLSCertKey]).ToNot(BeEmpty())
+}
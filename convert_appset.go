@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kluctl/template-controller/controllers/appset"
+	"k8s.io/apimachinery/pkg/runtime"
+	"os"
+	yaml2 "sigs.k8s.io/yaml"
+	"time"
+)
+
+// runConvertAppSet implements the convert-appset command. It converts all Argo CD ApplicationSets found in the given
+// files into equivalent ObjectTemplates (and their matrix inputs) and prints the result to stdout.
+func runConvertAppSet(args []string) error {
+	fs := flag.NewFlagSet("convert-appset", flag.ExitOnError)
+	var files stringsFlag
+	var namespace string
+	var serviceAccountName string
+	var interval time.Duration
+	fs.Var(&files, "f", "A file containing ApplicationSets to convert. Use - to read from stdin. Can be specified multiple times.")
+	fs.StringVar(&namespace, "namespace", "default", "The namespace to use for ApplicationSets without a namespace.")
+	fs.StringVar(&serviceAccountName, "service-account", "", "The service account to set in the resulting ObjectTemplates.")
+	fs.DurationVar(&interval, "interval", time.Minute, "The interval to use when the generator does not specify requeueAfterSeconds.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s convert-appset -f <file> [--service-account <name>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if len(files) == 0 {
+		fs.Usage()
+		return fmt.Errorf("no files specified")
+	}
+
+	opts := appset.ConvertOptions{
+		ServiceAccountName: serviceAccountName,
+		Interval:           interval,
+	}
+
+	first := true
+	for _, f := range files {
+		objs, err := readObjects(f, namespace)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			converted, err := appset.Convert(o, opts)
+			if err != nil {
+				return err
+			}
+			for _, x := range converted {
+				m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(x)
+				if err != nil {
+					return err
+				}
+				// drop empty metadata/status fields from the output
+				delete(m, "status")
+				if md, ok := m["metadata"].(map[string]any); ok {
+					delete(md, "creationTimestamp")
+				}
+				b, err := yaml2.Marshal(m)
+				if err != nil {
+					return err
+				}
+				if !first {
+					fmt.Println("---")
+				}
+				first = false
+				fmt.Print(string(b))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"io"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"os"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	yaml2 "sigs.k8s.io/yaml"
+	"sort"
+)
+
+// inventoryBackup is the format written by "inventory export" and read by "inventory import"
+type inventoryBackup struct {
+	Templates []inventoryEntry `json:"templates"`
+}
+
+// inventoryEntry contains the applied resources of a single ObjectTemplate or ClusterObjectTemplate
+type inventoryEntry struct {
+	Kind             string                                  `json:"kind"`
+	Namespace        string                                  `json:"namespace,omitempty"`
+	Name             string                                  `json:"name"`
+	AppliedResources []templatesv1alpha1.AppliedResourceInfo `json:"appliedResources"`
+}
+
+// runInventory implements the inventory command. It exports the inventories (status.appliedResources) of all
+// ObjectTemplates and ClusterObjectTemplates from the cluster of the current kubeconfig context and imports them
+// again, e.g. after restoring the templates from a backup or when migrating to another cluster.
+func runInventory(args []string) error {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s inventory export|import [flags]\n", os.Args[0])
+	}
+	if len(args) == 0 {
+		usage()
+		return fmt.Errorf("no sub-command specified")
+	}
+	switch args[0] {
+	case "export":
+		return runInventoryExport(args[1:])
+	case "import":
+		return runInventoryImport(args[1:])
+	default:
+		usage()
+		return fmt.Errorf("unknown sub-command %s", args[0])
+	}
+}
+
+func runInventoryExport(args []string) error {
+	fs := flag.NewFlagSet("inventory export", flag.ExitOnError)
+	var output string
+	var namespace string
+	fs.StringVar(&output, "o", "-", "The file to write the inventory backup to. Use - to write to stdout.")
+	fs.StringVar(&namespace, "namespace", "", "Only export ObjectTemplates from this namespace. ClusterObjectTemplates "+
+		"are only exported if no namespace is specified.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s inventory export [-o <file>] [--namespace <namespace>]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	c, err := newInventoryClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var backup inventoryBackup
+
+	var ots templatesv1alpha1.ObjectTemplateList
+	err = c.List(ctx, &ots, client.InNamespace(namespace))
+	if err != nil {
+		return err
+	}
+	for _, ot := range ots.Items {
+		if len(ot.Status.AppliedResources) == 0 {
+			continue
+		}
+		backup.Templates = append(backup.Templates, inventoryEntry{
+			Kind:             "ObjectTemplate",
+			Namespace:        ot.GetNamespace(),
+			Name:             ot.GetName(),
+			AppliedResources: ot.Status.AppliedResources,
+		})
+	}
+
+	if namespace == "" {
+		var cots templatesv1alpha1.ClusterObjectTemplateList
+		err = c.List(ctx, &cots)
+		if err != nil {
+			return err
+		}
+		for _, cot := range cots.Items {
+			if len(cot.Status.AppliedResources) == 0 {
+				continue
+			}
+			backup.Templates = append(backup.Templates, inventoryEntry{
+				Kind:             "ClusterObjectTemplate",
+				Name:             cot.GetName(),
+				AppliedResources: cot.Status.AppliedResources,
+			})
+		}
+	}
+
+	b, err := yaml2.Marshal(&backup)
+	if err != nil {
+		return err
+	}
+	if output == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(output, b, 0o600)
+}
+
+func runInventoryImport(args []string) error {
+	fs := flag.NewFlagSet("inventory import", flag.ExitOnError)
+	var input string
+	var dryRun bool
+	fs.StringVar(&input, "f", "", "The inventory backup to import. Use - to read from stdin.")
+	fs.BoolVar(&dryRun, "dry-run", false, "Only print what would be imported, without modifying the cluster.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s inventory import -f <file> [--dry-run]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if input == "" {
+		fs.Usage()
+		return fmt.Errorf("no file specified")
+	}
+
+	var b []byte
+	var err error
+	if input == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(input)
+	}
+	if err != nil {
+		return err
+	}
+	var backup inventoryBackup
+	err = yaml2.UnmarshalStrict(b, &backup)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", input, err)
+	}
+
+	c, err := newInventoryClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	for _, e := range backup.Templates {
+		name := e.Name
+		if e.Namespace != "" {
+			name = e.Namespace + "/" + e.Name
+		}
+		added, err := importInventoryEntry(ctx, c, e, dryRun)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				fmt.Fprintf(os.Stderr, "%s %s not found, skipping\n", e.Kind, name)
+				continue
+			}
+			return fmt.Errorf("failed to import inventory of %s %s: %w", e.Kind, name, err)
+		}
+		fmt.Fprintf(os.Stderr, "%s %s: imported %d of %d applied resources\n", e.Kind, name, added, len(e.AppliedResources))
+	}
+	return nil
+}
+
+// importInventoryEntry merges the applied resources of the given entry into the status of the referenced template.
+// Applied resources that are already known to the template are left untouched. The next reconciliation of the
+// template will then either re-apply the imported resources or prune them, in case they are not rendered anymore.
+func importInventoryEntry(ctx context.Context, c client.Client, e inventoryEntry, dryRun bool) (int, error) {
+	var obj client.Object
+	var status *templatesv1alpha1.ObjectTemplateStatus
+	switch e.Kind {
+	case "ObjectTemplate":
+		x := &templatesv1alpha1.ObjectTemplate{}
+		obj, status = x, &x.Status
+	case "ClusterObjectTemplate":
+		x := &templatesv1alpha1.ClusterObjectTemplate{}
+		obj, status = x, &x.Status
+	default:
+		return 0, fmt.Errorf("unsupported kind %s", e.Kind)
+	}
+
+	err := c.Get(ctx, client.ObjectKey{Namespace: e.Namespace, Name: e.Name}, obj)
+	if err != nil {
+		return 0, err
+	}
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+
+	existing := map[templatesv1alpha1.ObjectRef]bool{}
+	for _, ari := range status.AppliedResources {
+		existing[ari.Ref.WithoutVersion()] = true
+	}
+	added := 0
+	for _, ari := range e.AppliedResources {
+		if existing[ari.Ref.WithoutVersion()] {
+			continue
+		}
+		existing[ari.Ref.WithoutVersion()] = true
+		status.AppliedResources = append(status.AppliedResources, ari)
+		added++
+	}
+	if added == 0 || dryRun {
+		return added, nil
+	}
+	sort.Slice(status.AppliedResources, func(i, j int) bool {
+		return status.AppliedResources[i].Ref.String() < status.AppliedResources[j].Ref.String()
+	})
+
+	err = c.Status().Patch(ctx, obj, patch, controllers.SubResourceFieldOwner("template-controller"))
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+func newInventoryClient() (client.Client, error) {
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: scheme})
+}
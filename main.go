@@ -17,15 +17,24 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"github.com/kluctl/template-controller/controllers"
 	"github.com/kluctl/template-controller/controllers/comments"
+	"github.com/kluctl/template-controller/controllers/policy"
+	"github.com/kluctl/template-controller/controllers/webhookcerts"
 	"os"
 	"path/filepath"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"time"
 
+	"github.com/kluctl/template-controller/controllers/notifications"
 	"github.com/kluctl/template-controller/controllers/objecthandler"
+	"github.com/kluctl/template-controller/controllers/receiver"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -56,19 +65,129 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		if err := runRender(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "convert-appset" {
+		if err := runConvertAppSet(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "inventory" {
+		if err := runInventory(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var receiverAddr string
 	var watchAllNamespaces bool
 	var concurrent int
+	var gracefulShutdownTimeout time.Duration
+	var policyFlags policy.Policy
+	var enableWebhooks bool
+	var webhookPort int
+	var webhookCertDir string
+	var webhookSelfSignedCerts bool
+	var webhookServiceName string
+	var webhookSecretName string
+	var webhookConfigurationName string
+	var fieldManager string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&receiverAddr, "receiver-bind-address", ":9292",
+		"The address the Receiver webhook endpoint binds to. Set it to an empty string to disable the endpoint.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&watchAllNamespaces, "watch-all-namespaces", true,
 		"Watch for custom resources in all namespaces, if set to false it will only watch the runtime namespace.")
 	flag.IntVar(&concurrent, "concurrent", 4, "The number of concurrent reconciliations for each type.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 50*time.Second,
+		"The time to wait for in-flight reconciliations to finish applying/pruning objects and to write their status "+
+			"when the controller is shutting down. Must be lower than the terminationGracePeriodSeconds of the pod.")
+	flag.Func("allowed-target-namespaces",
+		"Comma separated list of namespace glob patterns that templates may apply objects to. "+
+			"Templates can always apply objects into their own namespace. If not specified, all namespaces are allowed.",
+		func(s string) error {
+			policyFlags.TargetNamespaces = policy.ParseList(s)
+			return nil
+		})
+	flag.Func("allowed-secret-namespaces",
+		"Comma separated list of namespace glob patterns that templates may read secrets from. "+
+			"Secrets from the template's own namespace can always be read. If not specified, all namespaces are allowed.",
+		func(s string) error {
+			policyFlags.SecretNamespaces = policy.ParseList(s)
+			return nil
+		})
+	flag.Func("allowed-kinds",
+		"Comma separated list of kind glob patterns in the form Kind.group (e.g. ConfigMap or Deployment.apps) "+
+			"that templates may apply. If not specified, all kinds are allowed.",
+		func(s string) error {
+			policyFlags.AllowedKinds = policy.ParseList(s)
+			return nil
+		})
+	flag.Func("denied-kinds",
+		"Comma separated list of kind glob patterns in the form Kind.group that templates may not apply. "+
+			"Takes precedence over --allowed-kinds.",
+		func(s string) error {
+			policyFlags.DeniedKinds = policy.ParseList(s)
+			return nil
+		})
+	flag.Func("allowed-lookup-kinds",
+		"Comma separated list of kind glob patterns in the form Kind.group that templates may read via the lookup "+
+			"function. If not specified, all kinds are allowed.",
+		func(s string) error {
+			policyFlags.AllowedLookupKinds = policy.ParseList(s)
+			return nil
+		})
+	flag.Func("allowed-hosts",
+		"Comma separated list of hostname glob patterns that generators and handlers may send requests to "+
+			"(e.g. gitlab.example.com,api.github.com). If not specified, all hosts are allowed.",
+		func(s string) error {
+			policyFlags.AllowedHosts = policy.ParseList(s)
+			return nil
+		})
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true,
+		"Enable the admission webhook server. Only disable this if the ValidatingWebhookConfiguration is not deployed, "+
+			"as its failure policy rejects all templates while the webhook server is unavailable.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server listens on.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", filepath.Join(os.TempDir(), "template-controller", "webhook-certs"),
+		"The directory that contains the webhook server certificates.")
+	flag.BoolVar(&webhookSelfSignedCerts, "webhook-self-signed-certs", true,
+		"Generate and rotate self-signed webhook certificates. Disable this if certificates are provided by other means, "+
+			"e.g. via cert-manager.")
+	flag.StringVar(&webhookServiceName, "webhook-service-name", "template-controller-webhook",
+		"The name of the service that exposes the webhook server.")
+	flag.StringVar(&webhookSecretName, "webhook-secret-name", "template-controller-webhook-certs",
+		"The name of the secret that self-signed webhook certificates are stored in.")
+	flag.StringVar(&webhookConfigurationName, "webhook-configuration-name", "template-controller-validating-webhook",
+		"The name of the ValidatingWebhookConfiguration to inject the CA bundle of self-signed certificates into.")
+	flag.BoolVar(&policyFlags.AllowClusterScopedObjects, "allow-cluster-scoped-objects", false,
+		"Allow all namespaced templates to apply cluster-scoped objects. If disabled, only templates in namespaces "+
+			"annotated with templates.kluctl.io/allow-cluster-scoped-objects=true may apply cluster-scoped objects.")
+	flag.BoolVar(&policyFlags.NoCrossNamespaceRefs, "no-cross-namespace-refs", false,
+		"When set, references to objects, secrets and target namespaces outside of the referencing object's "+
+			"namespace are rejected.")
+	flag.StringVar(&policyFlags.TrustedKeysNamespace, "trusted-keys-namespace", "",
+		"The namespace that public keys for signature verification are read from. If set, verify.publicKeysRef "+
+			"always refers to a secret in this namespace, so that tenants can not trust their own keys.")
+	flag.BoolVar(&policyFlags.RequireSignedTemplates, "require-signed-templates", false,
+		"When set, templates are only loaded from OCI sources that are verified against keys from the trusted keys "+
+			"namespace. GitRepository sources are rejected. Requires --trusted-keys-namespace.")
+	flag.StringVar(&fieldManager, "field-manager", "template-controller",
+		"The field manager name used when server-side applying rendered objects and updating custom resources. "+
+			"Can be overridden per ObjectTemplate via spec.fieldManager.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -82,14 +201,55 @@ func main() {
 		watchNamespace = os.Getenv("RUNTIME_NAMESPACE")
 	}
 
+	templatePolicy, err := policy.New(policyFlags)
+	if err != nil {
+		setupLog.Error(err, "invalid policy")
+		os.Exit(1)
+	}
+
 	var cacheNamespaces map[string]cache.Config
 	if watchNamespace != "" {
 		cacheNamespaces = map[string]cache.Config{
 			watchNamespace: {},
 		}
+		if policyFlags.TrustedKeysNamespace != "" {
+			cacheNamespaces[policyFlags.TrustedKeysNamespace] = cache.Config{}
+		}
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+
+	var webhookServer webhook.Server
+	var certManager *webhookcerts.CertManager
+	if enableWebhooks {
+		webhookServer = webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		})
+
+		if webhookSelfSignedCerts {
+			certClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+			if err != nil {
+				setupLog.Error(err, "unable to create client for webhook certificates")
+				os.Exit(1)
+			}
+			certManager = &webhookcerts.CertManager{
+				Client:                          certClient,
+				Namespace:                       os.Getenv("RUNTIME_NAMESPACE"),
+				SecretName:                      webhookSecretName,
+				ServiceName:                     webhookServiceName,
+				CertDir:                         webhookCertDir,
+				ValidatingWebhookConfigurations: []string{webhookConfigurationName},
+			}
+			// the webhook server requires the certificates to be present when it starts
+			if err := certManager.Ensure(ctrl.LoggerInto(context.Background(), setupLog)); err != nil {
+				setupLog.Error(err, "unable to ensure webhook certificates")
+				os.Exit(1)
+			}
+		}
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
@@ -111,29 +271,54 @@ func main() {
 		Cache: cache.Options{
 			DefaultNamespaces: cacheNamespaces,
 		},
+		WebhookServer:           webhookServer,
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	fieldManager := "template-controller"
+	if certManager != nil {
+		if err := mgr.Add(certManager); err != nil {
+			setupLog.Error(err, "unable to add webhook certificate manager")
+			os.Exit(1)
+		}
+	}
 
 	if err = (&controllers.ObjectTemplateReconciler{
 		BaseTemplateReconciler: controllers.BaseTemplateReconciler{
-			Client:       mgr.GetClient(),
-			Scheme:       mgr.GetScheme(),
-			FieldManager: fieldManager,
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			FieldManager:    fieldManager,
+			Policy:          templatePolicy,
+			ShutdownTimeout: gracefulShutdownTimeout,
 		},
 	}).SetupWithManager(mgr, concurrent); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ObjectTemplate")
 		os.Exit(1)
 	}
+	if err = (&controllers.ClusterObjectTemplateReconciler{
+		ObjectTemplateReconciler: controllers.ObjectTemplateReconciler{
+			BaseTemplateReconciler: controllers.BaseTemplateReconciler{
+				Client:          mgr.GetClient(),
+				Scheme:          mgr.GetScheme(),
+				FieldManager:    fieldManager,
+				Policy:          templatePolicy.ForClusterTemplates(),
+				ShutdownTimeout: gracefulShutdownTimeout,
+			},
+		},
+	}).SetupWithManager(mgr, concurrent); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterObjectTemplate")
+		os.Exit(1)
+	}
 	if err = (&controllers.TextTemplateReconciler{
 		BaseTemplateReconciler: controllers.BaseTemplateReconciler{
-			Client:       mgr.GetClient(),
-			Scheme:       mgr.GetScheme(),
-			FieldManager: fieldManager,
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			FieldManager:    fieldManager,
+			Policy:          templatePolicy,
+			ShutdownTimeout: gracefulShutdownTimeout,
 		},
 	}).SetupWithManager(mgr, concurrent); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TextTemplate")
@@ -143,14 +328,25 @@ func main() {
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
 		FieldManager: fieldManager,
+		Policy:       templatePolicy,
 	}).SetupWithManager(mgr, concurrent); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ObjectHandler")
 		os.Exit(1)
 	}
+	if err = (&notifications.NotificationPolicyReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr, concurrent); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NotificationPolicy")
+		os.Exit(1)
+	}
 	if err = (&controllers.ListGitlabMergeRequestsReconciler{
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
 		FieldManager: fieldManager,
+		Policy:       templatePolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ListGitlabMergeRequests")
 		os.Exit(1)
@@ -159,14 +355,62 @@ func main() {
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
 		FieldManager: fieldManager,
+		Policy:       templatePolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ListGithubPullRequests")
 		os.Exit(1)
 	}
+	if err = (&controllers.ListGiteaPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ListGiteaPullRequests")
+		os.Exit(1)
+	}
+	if err = (&controllers.ListBitbucketServerPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ListBitbucketServerPullRequests")
+		os.Exit(1)
+	}
+	if err = (&controllers.ListBitbucketCloudPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ListBitbucketCloudPullRequests")
+		os.Exit(1)
+	}
+	if err = (&controllers.ListAzureDevOpsPullRequestsReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ListAzureDevOpsPullRequests")
+		os.Exit(1)
+	}
+	if err = (&controllers.ListRepositoriesReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ListRepositories")
+		os.Exit(1)
+	}
 	if err = (&controllers.GitProjectorReconciler{
 		Client:       mgr.GetClient(),
+		Manager:      mgr,
 		Scheme:       mgr.GetScheme(),
 		FieldManager: fieldManager,
+		Policy:       templatePolicy,
 		TmpBaseDir:   filepath.Join(os.TempDir(), "template-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GitProjector")
@@ -177,6 +421,7 @@ func main() {
 			Client:       mgr.GetClient(),
 			Scheme:       mgr.GetScheme(),
 			FieldManager: fieldManager,
+			Policy:       templatePolicy,
 		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GitlabComment")
@@ -187,11 +432,38 @@ func main() {
 			Client:       mgr.GetClient(),
 			Scheme:       mgr.GetScheme(),
 			FieldManager: fieldManager,
+			Policy:       templatePolicy,
 		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GithubComment")
 		os.Exit(1)
 	}
+	if err = (&receiver.ReceiverReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		FieldManager: fieldManager,
+		Policy:       templatePolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Receiver")
+		os.Exit(1)
+	}
+	if receiverAddr != "" {
+		if err := mgr.Add(&receiver.Server{
+			Client:       mgr.GetClient(),
+			FieldManager: fieldManager,
+			Policy:       templatePolicy,
+			BindAddress:  receiverAddr,
+		}); err != nil {
+			setupLog.Error(err, "unable to add receiver server")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		if err = (&controllers.ObjectTemplateValidator{Policy: templatePolicy}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ObjectTemplate")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
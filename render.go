@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	templatesv1alpha1 "github.com/kluctl/template-controller/api/v1alpha1"
+	"github.com/kluctl/template-controller/controllers"
+	"github.com/kluctl/template-controller/controllers/redact"
+	"io"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"os"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	yaml2 "sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// runRender implements the render command. It renders all ObjectTemplates found in the given files and prints the
+// rendered objects to stdout, without applying them. Matrix inputs are either read from fixture files or from the
+// cluster of the current kubeconfig context.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	var files stringsFlag
+	var fixtures stringsFlag
+	var matrixMocks stringsFlag
+	var live bool
+	var namespace string
+	fs.Var(&files, "f", "A file containing ObjectTemplates to render. Use - to read from stdin. Can be specified multiple times. "+
+		"All other objects found in the file are used as fixtures.")
+	fs.Var(&fixtures, "fixtures", "A file containing objects (e.g. ListGithubPullRequests with status) to use as matrix "+
+		"inputs and libraries. Can be specified multiple times.")
+	fs.Var(&matrixMocks, "matrix", "Replaces the matrix entry with the given name by the items found in a file, in the "+
+		"form <name>=<file>. The file must contain a YAML list. This allows to mock matrix inputs that can not be "+
+		"read locally, e.g. HTTP or AWS inputs. Can be specified multiple times.")
+	fs.BoolVar(&live, "live", false, "Read matrix inputs and libraries from the cluster of the current kubeconfig "+
+		"context instead of fixture files.")
+	fs.StringVar(&namespace, "namespace", "default", "The namespace to use for objects without a namespace.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s render -f <file> [--fixtures <file>] [--matrix <name>=<file>] [--live]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if len(files) == 0 {
+		fs.Usage()
+		return fmt.Errorf("no files specified")
+	}
+	if live && len(fixtures) != 0 {
+		return fmt.Errorf("--live and --fixtures are mutually exclusive")
+	}
+
+	var templates []*templatesv1alpha1.ObjectTemplate
+	var fixtureObjects []client.Object
+	for _, f := range files {
+		objs, err := readObjects(f, namespace)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			if o.GroupVersionKind() == templatesv1alpha1.GroupVersion.WithKind("ObjectTemplate") {
+				var rt templatesv1alpha1.ObjectTemplate
+				err = runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, &rt)
+				if err != nil {
+					return fmt.Errorf("failed to parse ObjectTemplate %s: %w", o.GetName(), err)
+				}
+				templates = append(templates, &rt)
+			} else {
+				fixtureObjects = append(fixtureObjects, o)
+			}
+		}
+	}
+	for _, f := range fixtures {
+		objs, err := readObjects(f, namespace)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			fixtureObjects = append(fixtureObjects, o)
+		}
+	}
+
+	mocks := map[string][]runtime.RawExtension{}
+	for _, m := range matrixMocks {
+		name, path, ok := strings.Cut(m, "=")
+		if !ok {
+			return fmt.Errorf("invalid --matrix value %s, must be in the form <name>=<file>", m)
+		}
+		items, err := readMatrixMock(path)
+		if err != nil {
+			return err
+		}
+		mocks[name] = items
+	}
+	for name := range mocks {
+		found := false
+		for _, rt := range templates {
+			for _, me := range rt.Spec.Matrix {
+				if me.Name == name {
+					*me = templatesv1alpha1.MatrixEntry{Name: name, List: mocks[name]}
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("matrix entry %s passed via --matrix not found in any ObjectTemplate", name)
+		}
+	}
+
+	var c client.Client
+	if live {
+		restConfig, err := ctrl.GetConfig()
+		if err != nil {
+			return err
+		}
+		c, err = client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			return err
+		}
+	} else {
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(fixtureObjects...).Build()
+	}
+
+	first := true
+	for _, rt := range templates {
+		ctx := redact.NewContext(context.Background())
+		rendered, err := controllers.RenderObjectTemplate(ctx, c, rt)
+		if err != nil {
+			return fmt.Errorf("failed to render ObjectTemplate %s/%s: %s", rt.GetNamespace(), rt.GetName(), redact.Error(ctx, err))
+		}
+		// rendering happens concurrently, so sort the results to get stable output
+		sort.SliceStable(rendered, func(i, j int) bool {
+			a := templatesv1alpha1.ObjectRefFromObject(rendered[i])
+			b := templatesv1alpha1.ObjectRefFromObject(rendered[j])
+			return a.String() < b.String()
+		})
+		for _, x := range rendered {
+			b, err := yaml2.Marshal(x.Object)
+			if err != nil {
+				return err
+			}
+			if !first {
+				fmt.Println("---")
+			}
+			first = false
+			fmt.Print(string(b))
+		}
+	}
+	return nil
+}
+
+// readMatrixMock reads a YAML list of matrix items
+func readMatrixMock(path string) ([]runtime.RawExtension, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []any
+	err = yaml2.Unmarshal(b, &items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	ret := make([]runtime.RawExtension, 0, len(items))
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, runtime.RawExtension{Raw: raw})
+	}
+	return ret, nil
+}
+
+func readObjects(path string, namespace string) ([]*unstructured.Unstructured, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var ret []*unstructured.Unstructured
+	d := yaml.NewYAMLToJSONDecoder(r)
+	for {
+		var m map[string]any
+		err := d.Decode(&m)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if m == nil {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: m}
+		if u.GetNamespace() == "" {
+			u.SetNamespace(namespace)
+		}
+		ret = append(ret, u)
+	}
+	return ret, nil
+}